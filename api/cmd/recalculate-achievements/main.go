@@ -171,14 +171,14 @@ func main() {
 
 		// 8. first_step
 		var movesCount int
-		err = database.QueryRow(`SELECT COUNT(*) FROM game_moves WHERE player_id = $1`, u.ID).Scan(&movesCount)
+		err = database.QueryRow(`SELECT COUNT(*) FROM game_moves WHERE player_id = $1 AND move->>'type' = 'play' AND COALESCE(move->>'status', '') NOT IN ('withdrawn', 'retracted')`, u.ID).Scan(&movesCount)
 		if err == nil && movesCount >= 1 {
 			unlock("first_step")
 		}
 
 		// 9. Fetch moves to evaluate gameplay accomplishments:
 		// bingo, high_scorer, half_century, word_smith, joker_master, long_word
-		mRows, err := database.Query(`SELECT move FROM game_moves WHERE player_id = $1`, u.ID)
+		mRows, err := database.Query(`SELECT move FROM game_moves WHERE player_id = $1 AND move->>'type' = 'play' AND COALESCE(move->>'status', '') NOT IN ('withdrawn', 'retracted')`, u.ID)
 		if err == nil {
 			hasBingo := false
 			hasHighScorer := false
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/ZiplEix/scrabble/api/engine"
 	"github.com/ZiplEix/scrabble/api/services"
 )
 
@@ -90,7 +91,7 @@ func main() {
 		log.Printf("Solving for rack %q...", string(cleanedRack))
 
 		// Normalize board to uppercase
-		var normalizedBoard [15][15]string
+		normalizedBoard := engine.StandardLayout.NewBoard()
 		for y := 0; y < 15; y++ {
 			for x := 0; x < 15; x++ {
 				normalizedBoard[y][x] = strings.ToUpper(strings.TrimSpace(req.Board[y][x]))
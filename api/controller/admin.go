@@ -4,9 +4,12 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/models/request"
 	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
 	"github.com/labstack/echo/v4"
 )
 
@@ -188,3 +191,61 @@ func GetAdminGameByID(c echo.Context) error {
 	}
 	return c.JSON(200, echo.Map{"game": game})
 }
+
+func GetAdminUnseenTiles(c echo.Context) error {
+	logctx.Add(c, "role", "admin")
+	id := c.Param("id")
+	playerID, err := strconv.ParseInt(c.QueryParam("player_id"), 10, 64)
+	if err != nil {
+		return c.JSON(400, echo.Map{"error": "invalid player_id"})
+	}
+	unseen, err := services.GetAdminUnseenTiles(id, playerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "not in game") {
+			return c.JSON(404, echo.Map{"error": err.Error()})
+		}
+		logctx.Merge(c, map[string]any{"reason": "failed_to_get_admin_unseen_tiles", "error": err.Error()})
+		return c.JSON(500, echo.Map{"error": "failed to get unseen tiles"})
+	}
+	return c.JSON(200, unseen)
+}
+
+func ImportGameGCG(c echo.Context) error {
+	logctx.Add(c, "role", "admin")
+	adminID, _ := utils.GetUserID(c)
+
+	var req request.ImportGameRequest
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.GCG) == "" {
+		logctx.Add(c, "reason", "invalid_request")
+		return c.JSON(400, echo.Map{
+			"error":   "invalid request: gcg file is required",
+			"message": "Le fichier GCG est requis",
+		})
+	}
+
+	gameID, err := services.ImportGameGCG(adminID, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "invalid gcg"):
+			logctx.Merge(c, map[string]any{"reason": "invalid_gcg", "error": err.Error()})
+			return c.JSON(400, echo.Map{
+				"error":   err.Error(),
+				"message": "Le fichier GCG est invalide ou ne correspond pas aux règles",
+			})
+		case strings.Contains(err.Error(), "unsupported language"), strings.Contains(err.Error(), "invalid variant"):
+			logctx.Merge(c, map[string]any{"reason": "invalid_options", "error": err.Error()})
+			return c.JSON(400, echo.Map{
+				"error":   err.Error(),
+				"message": "Langue ou variante invalide",
+			})
+		}
+		logctx.Merge(c, map[string]any{"reason": "failed_to_import_game", "error": err.Error()})
+		return c.JSON(500, echo.Map{
+			"error":   fmt.Sprintf("failed to import game: %v", err),
+			"message": "Erreur lors de l'import de la partie",
+		})
+	}
+
+	logctx.Add(c, "game_id", gameID.String())
+	return c.JSON(201, echo.Map{"game_id": gameID})
+}
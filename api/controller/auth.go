@@ -62,7 +62,8 @@ func Register(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusCreated, response.AuthResponse{Token: tokenString})
+	gameID, inviteError := redeemInviteOnAuth(c, user.ID, req.InviteToken)
+	return c.JSON(http.StatusCreated, response.AuthResponse{Token: tokenString, GameID: gameID, InviteError: inviteError})
 }
 
 func Login(c echo.Context) error {
@@ -99,7 +100,8 @@ func Login(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, response.AuthResponse{Token: tokenString})
+	gameID, inviteError := redeemInviteOnAuth(c, user.ID, req.InviteToken)
+	return c.JSON(http.StatusOK, response.AuthResponse{Token: tokenString, GameID: gameID, InviteError: inviteError})
 }
 
 func AdminLogin(c echo.Context) error {
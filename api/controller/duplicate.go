@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func GetDuplicateGame(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir une partie en duplicate",
+		})
+	}
+
+	gameID := c.Param("id")
+	logctx.Add(c, "game_id", gameID)
+
+	game, err := services.GetDuplicateGame(userID, gameID)
+	if err != nil {
+		return duplicateError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, game)
+}
+
+func SubmitDuplicateMove(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour proposer un coup",
+		})
+	}
+
+	gameID := c.Param("id")
+	logctx.Add(c, "game_id", gameID)
+
+	var req request.PlayMoveRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+
+	if err := services.SubmitDuplicateMove(gameID, userID, req); err != nil {
+		return duplicateError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "move submitted successfully",
+	})
+}
+
+// duplicateError traduit les erreurs d'une partie en duplicate en réponse HTTP.
+func duplicateError(c echo.Context, err error) error {
+	var (
+		status  int
+		reason  string
+		message string
+	)
+	switch {
+	case strings.Contains(err.Error(), "game not found"):
+		status, reason, message = http.StatusNotFound, "game_not_found", "La partie n'existe pas ou a été supprimée."
+	case strings.Contains(err.Error(), "failed to validate player"), strings.Contains(err.Error(), "player not in game"):
+		status, reason, message = http.StatusForbidden, "not_in_game", "Vous ne participez pas à cette partie."
+	case strings.Contains(err.Error(), "not in duplicate mode"):
+		status, reason, message = http.StatusBadRequest, "not_duplicate", "Cette partie ne se joue pas en duplicate."
+	case strings.Contains(err.Error(), "game is not ongoing"):
+		status, reason, message = http.StatusConflict, "game_ended", "La partie est terminée."
+	case strings.Contains(err.Error(), "no open duplicate round"):
+		status, reason, message = http.StatusConflict, "no_open_round", "Le temps de la ronde est écoulé : attendez le tirage suivant."
+	case strings.Contains(err.Error(), "invalid move notation"):
+		status, reason, message = http.StatusBadRequest, "invalid_notation", "La notation du coup ne correspond pas au plateau (ex. « H8 CHAT » ou « 8H cHAT », minuscule = joker)."
+	case strings.Contains(err.Error(), "invalid move"):
+		status, reason, message = http.StatusBadRequest, "invalid_move", "Coup invalide : les lettres jouées doivent venir du tirage."
+	case strings.Contains(err.Error(), "cannot place more letters"):
+		status, reason, message = http.StatusBadRequest, "too_many_letters", "Vous ne pouvez pas jouer plus de lettres que le tirage n'en contient."
+	case strings.Contains(err.Error(), "must be aligned"):
+		status, reason, message = http.StatusBadRequest, "letters_not_aligned", "Les lettres doivent être alignées."
+	case strings.Contains(err.Error(), "first move must cover the center cell"):
+		status, reason, message = http.StatusBadRequest, "first_move_not_centered", "Le premier coup doit couvrir la case centrale."
+	case strings.Contains(err.Error(), "word must connect to existing letters"):
+		status, reason, message = http.StatusBadRequest, "word_not_connected", "Le mot doit se connecter à des lettres existantes."
+	case strings.Contains(err.Error(), "invalid word played:"):
+		word := strings.TrimSpace(strings.Split(err.Error(), ":")[1])
+		status, reason, message = http.StatusBadRequest, "invalid_word", fmt.Sprintf("Le mot '%s' n'est pas valide.", word)
+	default:
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_handle_duplicate",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to handle duplicate game: %v", err),
+			"message": "Erreur lors du traitement de la partie, veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+	logctx.Add(c, "reason", reason)
+	return c.JSON(status, echo.Map{
+		"error":   fmt.Sprintf("duplicate error: %v", err),
+		"message": message,
+	})
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// eventsHeartbeat est l'intervalle des commentaires envoyés pour garder le
+// flux ouvert à travers les proxys.
+const eventsHeartbeat = 25 * time.Second
+
+// StreamEvents ouvre un flux Server-Sent Events des événements de
+// l'utilisateur : coups, fin de partie, chat et succès, chacun sous son type
+// ("event:") avec sa charge JSON ("data:"). Avec ?game=<id>, il suit à la
+// place une partie en spectateur, si sa visibilité le permet.
+func StreamEvents(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour suivre vos parties en direct",
+		})
+	}
+
+	var (
+		stream      <-chan events.Event
+		unsubscribe func()
+	)
+	if gameID := c.QueryParam("game"); gameID != "" {
+		logctx.Add(c, "game_id", gameID)
+		if err := services.CanSpectate(userID, gameID); err != nil {
+			if strings.Contains(err.Error(), "not open to spectators") {
+				logctx.Add(c, "reason", "not_spectatable")
+				return c.JSON(http.StatusNotFound, echo.Map{
+					"error":   fmt.Sprintf("failed to spectate game: %v", err),
+					"message": "La partie n'existe pas ou n'est pas ouverte aux spectateurs.",
+				})
+			}
+			logctx.Merge(c, map[string]any{
+				"reason": "failed_to_spectate_game",
+				"error":  err.Error(),
+			})
+			return c.JSON(http.StatusInternalServerError, echo.Map{
+				"error":   fmt.Sprintf("failed to spectate game: %v", err),
+				"message": "Erreur lors de l'ouverture du direct. Veuillez réessayer.",
+			})
+		}
+		stream, unsubscribe = events.SubscribeGame(gameID)
+	} else {
+		stream, unsubscribe = events.Subscribe(userID)
+	}
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, ": connected\n\n")
+	res.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(res, ": ping\n\n")
+			res.Flush()
+		case ev, ok := <-stream:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", ev.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ZiplEix/scrabble/api/middleware/logctx"
@@ -48,14 +50,6 @@ func CreateGame(c echo.Context) error {
 		usernames = append(usernames, strings.ToLower(strings.TrimSpace(player)))
 	}
 
-	if len(usernames) > 3 {
-		logctx.Add(c, "reason", "too_many_players")
-		return c.JSON(http.StatusBadRequest, echo.Map{
-			"error":   "too many players",
-			"message": "Vous ne pouvez pas inviter plus de 3 joueurs",
-		})
-	}
-
 	difficulty := strings.ToLower(strings.TrimSpace(req.Difficulty))
 	if difficulty == "" {
 		difficulty = "hard"
@@ -64,8 +58,127 @@ func CreateGame(c echo.Context) error {
 		difficulty = "hard"
 	}
 
-	gameID, err := services.CreateGame(userID, req.Name, usernames, req.RevangeFrom, difficulty)
+	gameID, err := services.CreateGameWithOptions(userID, req.Name, usernames, req.RevangeFrom, services.GameOptions{
+		Difficulty:            difficulty,
+		ChallengeRule:         strings.ToLower(strings.TrimSpace(req.ChallengeRule)),
+		Rules:                 req.Rules,
+		Language:              strings.ToLower(strings.TrimSpace(req.Language)),
+		Variant:               strings.ToLower(strings.TrimSpace(req.Variant)),
+		TurnTimeLimitHours:    req.TurnTimeLimitHours,
+		TimeoutAction:         strings.ToLower(strings.TrimSpace(req.TimeoutAction)),
+		Training:              req.Training,
+		Visibility:            strings.ToLower(strings.TrimSpace(req.Visibility)),
+		SpectatorChat:         req.SpectatorChat,
+		Seats:                 req.Seats,
+		MinRating:             req.MinRating,
+		InviteLinks:           req.InviteLinks,
+		Mode:                  strings.ToLower(strings.TrimSpace(req.Mode)),
+		RoundTimeLimitSeconds: req.RoundTimeLimitSeconds,
+	})
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid game mode") {
+			logctx.Add(c, "reason", "invalid_mode")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Mode de jeu invalide (classic ou duplicate)",
+			})
+		} else if strings.Contains(err.Error(), "invalid duplicate options") {
+			logctx.Add(c, "reason", "invalid_duplicate_options")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Le duplicate se joue par rondes chronométrées, sans délai par tour, contestation ni mode entraînement",
+			})
+		} else if strings.Contains(err.Error(), "invalid round time limit") {
+			logctx.Add(c, "reason", "invalid_round_time_limit")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Durée de ronde invalide (entre 30 secondes et 24 heures)",
+			})
+		} else if strings.Contains(err.Error(), "duplicate mode is not available against the bot") {
+			logctx.Add(c, "reason", "duplicate_against_bot")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Le duplicate n'est pas disponible contre le bot",
+			})
+		} else if strings.Contains(err.Error(), "invalid challenge rule") {
+			logctx.Add(c, "reason", "invalid_challenge_rule")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Règle de contestation invalide (none, free, single ou double)",
+			})
+		} else if strings.Contains(err.Error(), "invalid ruleset") {
+			logctx.Add(c, "reason", "invalid_ruleset")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Les règles personnalisées de la partie sont invalides",
+			})
+		} else if strings.Contains(err.Error(), "unsupported language") {
+			logctx.Add(c, "reason", "unsupported_language")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Langue de partie non disponible",
+			})
+		} else if strings.Contains(err.Error(), "invalid variant") {
+			logctx.Add(c, "reason", "invalid_variant")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Variante de plateau invalide (standard ou super)",
+			})
+		} else if strings.Contains(err.Error(), "invalid turn time limit") {
+			logctx.Add(c, "reason", "invalid_turn_time_limit")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Délai de jeu invalide (entre 0 et 168 heures)",
+			})
+		} else if strings.Contains(err.Error(), "invalid timeout action") {
+			logctx.Add(c, "reason", "invalid_timeout_action")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Action à l'expiration du délai invalide (pass ou forfeit)",
+			})
+		} else if strings.Contains(err.Error(), "training mode requires") {
+			logctx.Add(c, "reason", "training_requires_bot")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Le mode entraînement n'est disponible que pour les parties contre le bot",
+			})
+		} else if strings.Contains(err.Error(), "invalid visibility") {
+			logctx.Add(c, "reason", "invalid_visibility")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Visibilité invalide (private, friends ou public)",
+			})
+		} else if strings.Contains(err.Error(), "invalid seats") {
+			logctx.Add(c, "reason", "invalid_seats")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Nombre de places invalide : il doit laisser au moins une place libre sans dépasser le nombre de joueurs permis",
+			})
+		} else if strings.Contains(err.Error(), "unknown users") {
+			var unknown *services.UnknownUsersError
+			errors.As(err, &unknown)
+			logctx.Merge(c, map[string]any{
+				"reason":            "unknown_users",
+				"unknown_usernames": unknown.Usernames,
+			})
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":             fmt.Sprintf("failed to create game: %v", err),
+				"message":           fmt.Sprintf("Joueurs introuvables : %s", strings.Join(unknown.Usernames, ", ")),
+				"unknown_usernames": unknown.Usernames,
+			})
+		} else if strings.Contains(err.Error(), "too many players") {
+			logctx.Add(c, "reason", "too_many_players")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Trop de joueurs invités pour les règles de cette partie",
+			})
+		} else if strings.Contains(err.Error(), "invalid invite links count") {
+			logctx.Add(c, "reason", "invalid_invite_links")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create game: %v", err),
+				"message": "Le nombre de liens d'invitation ne peut pas être négatif",
+			})
+		}
 		logctx.Merge(c, map[string]any{
 			"reason": "failed_to_create_game",
 			"error":  err.Error(),
@@ -208,6 +321,17 @@ func GetGame(c echo.Context) error {
 	return c.JSON(http.StatusOK, game)
 }
 
+// idempotencyKey retourne la clé de l'en-tête Idempotency-Key (vide si
+// absente) : un client qui renvoie une requête de coup avec la même clé
+// obtient le résultat d'origine au lieu de rejouer le coup.
+func idempotencyKey(c echo.Context) string {
+	key := strings.TrimSpace(c.Request().Header.Get("Idempotency-Key"))
+	if key != "" {
+		logctx.Add(c, "idempotency_key", key)
+	}
+	return key
+}
+
 func PlayMove(c echo.Context) error {
 	userID, ok := utils.GetUserID(c)
 	if !ok {
@@ -240,24 +364,48 @@ func PlayMove(c echo.Context) error {
 		})
 	}
 
-	if err := services.PlayMove(gameID, userID, req); err != nil {
-		if strings.Contains(err.Error(), "not your turn") {
+	if err := services.PlayMoveIdempotent(gameID, userID, req, idempotencyKey(c)); err != nil {
+		if strings.Contains(err.Error(), "idempotency key") {
+			logctx.Add(c, "reason", "idempotency_key_reused")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to play move: %v", err),
+				"message": "Cette clé d'idempotence a déjà servi pour une autre requête.",
+			})
+		} else if strings.Contains(err.Error(), "game not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to play move: %v", err),
+				"message": "La partie n'existe pas ou a été supprimée. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+			})
+		} else if strings.Contains(err.Error(), "not your turn") {
 			logctx.Add(c, "reason", "not_your_turn")
 			return c.JSON(http.StatusForbidden, echo.Map{
 				"error":   fmt.Sprintf("failed to play move: %v", err),
 				"message": "Ce n'est pas votre tour de jouer. Veuillez attendre votre tour.",
 			})
+		} else if strings.Contains(err.Error(), "not available in duplicate mode") {
+			logctx.Add(c, "reason", "duplicate_mode")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to play move: %v", err),
+				"message": "En duplicate, les coups se proposent pour la ronde en cours (POST /game/:id/duplicate).",
+			})
+		} else if strings.Contains(err.Error(), "invalid move notation") {
+			logctx.Add(c, "reason", "invalid_notation")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to play move: %v", err),
+				"message": "La notation du coup ne correspond pas au plateau (ex. « H8 CHAT » ou « 8H cHAT », minuscule = joker).",
+			})
 		} else if strings.Contains(err.Error(), "invalid move") {
 			logctx.Add(c, "reason", "invalid_move")
 			return c.JSON(http.StatusBadRequest, echo.Map{
 				"error":   fmt.Sprintf("failed to play move: %v", err),
 				"message": "Coup invalide. Veuillez vérifier votre coup et réessayer.",
 			})
-		} else if strings.Contains(err.Error(), "more than 7 letters") {
+		} else if strings.Contains(err.Error(), "cannot place more letters") {
 			logctx.Add(c, "reason", "too_many_letters")
 			return c.JSON(http.StatusBadRequest, echo.Map{
 				"error":   fmt.Sprintf("failed to play move: %v", err),
-				"message": "Vous ne pouvez pas jouer plus de 7 lettres à la fois. Veuillez réduire le nombre de lettres et réessayer.",
+				"message": "Vous ne pouvez pas jouer plus de lettres que votre chevalet n'en contient. Veuillez réduire le nombre de lettres et réessayer.",
 			})
 		} else if strings.Contains(err.Error(), "must be aligned") {
 			logctx.Add(c, "reason", "letters_not_aligned")
@@ -321,8 +469,15 @@ func GetNewRack(c echo.Context) error {
 	}
 	logctx.Add(c, "game_id", gameID)
 
-	newRack, err := services.GetNewRack(userID, gameID)
+	newRack, err := services.GetNewRackIdempotent(userID, gameID, idempotencyKey(c))
 	if err != nil {
+		if strings.Contains(err.Error(), "idempotency key") {
+			logctx.Add(c, "reason", "idempotency_key_reused")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to get new rack: %v", err),
+				"message": "Cette clé d'idempotence a déjà servi pour une autre requête.",
+			})
+		}
 		logctx.Merge(c, map[string]any{
 			"reason": "failed_to_get_new_rack",
 			"error":  err.Error(),
@@ -336,6 +491,135 @@ func GetNewRack(c echo.Context) error {
 	return c.JSON(http.StatusOK, newRack)
 }
 
+func GetUnseenTiles(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour consulter les tuiles restantes",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour consulter les tuiles restantes",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	unseen, err := services.GetUnseenTiles(userID, gameID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to get unseen tiles: %v", err),
+				"message": "La partie n'existe pas ou vous n'y participez pas.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_get_unseen_tiles",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to get unseen tiles: %v", err),
+			"message": "Erreur lors du calcul des tuiles restantes. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, unseen)
+}
+
+func ExchangeTiles(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour échanger des lettres",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour échanger des lettres",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	var req request.ExchangeTilesRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+
+	drawn, err := services.ExchangeTilesIdempotent(userID, gameID, req.Tiles, idempotencyKey(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "idempotency key") {
+			logctx.Add(c, "reason", "idempotency_key_reused")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+				"message": "Cette clé d'idempotence a déjà servi pour une autre requête.",
+			})
+		} else if strings.Contains(err.Error(), "not your turn") {
+			logctx.Add(c, "reason", "not_your_turn")
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+				"message": "Ce n'est pas votre tour de jouer. Veuillez attendre votre tour.",
+			})
+		} else if strings.Contains(err.Error(), "game not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+				"message": "La partie n'existe pas ou a été supprimée. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+			})
+		} else if strings.Contains(err.Error(), "no tiles to exchange") {
+			logctx.Add(c, "reason", "no_tiles")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+				"message": "Veuillez sélectionner au moins une lettre à échanger.",
+			})
+		} else if strings.Contains(err.Error(), "not in rack") {
+			logctx.Add(c, "reason", "tile_not_in_rack")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+				"message": "Vous ne possédez pas les lettres sélectionnées. Veuillez recharger la page et réessayer.",
+			})
+		} else if strings.Contains(err.Error(), "left in the bag") {
+			logctx.Add(c, "reason", "bag_too_small")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+				"message": "Il ne reste plus assez de lettres dans le sac, l'échange n'est plus autorisé.",
+			})
+		}
+
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_exchange_tiles",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to exchange tiles: %v", err),
+			"message": "Erreur lors de l'échange des lettres. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"drawn": drawn,
+	})
+}
+
 func GetUserGames(c echo.Context) error {
 	userID, ok := utils.GetUserID(c)
 	if !ok {
@@ -378,7 +662,8 @@ func SimulateScore(c echo.Context) error {
 	logctx.Add(c, "game_id", gameID)
 
 	var body struct {
-		Letters []request.PlacedLetter `json:"letters"`
+		Letters  []request.PlacedLetter `json:"letters"`
+		Notation string                 `json:"notation"`
 	}
 	if err := c.Bind(&body); err != nil {
 		logctx.Merge(c, map[string]any{
@@ -388,7 +673,7 @@ func SimulateScore(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid body")
 	}
 
-	score, err := services.SimulateScore(gameID, userID, body.Letters)
+	score, err := services.SimulateScore(gameID, userID, body.Letters, body.Notation)
 	if err != nil {
 		logctx.Merge(c, map[string]any{
 			"reason": "failed_to_simulate_score",
@@ -420,9 +705,15 @@ func PassTurn(c echo.Context) error {
 	}
 	logctx.Add(c, "game_id", gameID)
 
-	err := services.PassTurn(userID, gameID)
+	err := services.PassTurnIdempotent(userID, gameID, idempotencyKey(c))
 	if err != nil {
-		if strings.Contains(err.Error(), "not your turn") {
+		if strings.Contains(err.Error(), "idempotency key") {
+			logctx.Add(c, "reason", "idempotency_key_reused")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to pass turn: %v", err),
+				"message": "Cette clé d'idempotence a déjà servi pour une autre requête.",
+			})
+		} else if strings.Contains(err.Error(), "not your turn") {
 			logctx.Add(c, "reason", "not_your_turn")
 			return c.JSON(http.StatusForbidden, echo.Map{
 				"error":   fmt.Sprintf("failed to pass turn: %v", err),
@@ -447,3 +738,381 @@ func PassTurn(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func ResignGame(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour abandonner une partie",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour abandonner",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	err := services.ResignGame(userID, gameID)
+	if err != nil {
+		if strings.Contains(err.Error(), "player not in game") {
+			logctx.Add(c, "reason", "not_in_game")
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error":   fmt.Sprintf("failed to resign: %v", err),
+				"message": "Vous ne participez pas (ou plus) à cette partie.",
+			})
+		} else if strings.Contains(err.Error(), "game is not ongoing") {
+			logctx.Add(c, "reason", "game_not_ongoing")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to resign: %v", err),
+				"message": "La partie est déjà terminée.",
+			})
+		} else if strings.Contains(err.Error(), "game not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to resign: %v", err),
+				"message": "La partie n'existe pas ou a été supprimée. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_resign",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to resign: %v", err),
+			"message": "Erreur lors de l'abandon de la partie. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func ChallengeMove(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour contester un coup",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour contester un coup",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	res, err := services.ChallengeMove(userID, gameID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not your turn") {
+			logctx.Add(c, "reason", "not_your_turn")
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error":   fmt.Sprintf("failed to challenge move: %v", err),
+				"message": "Seul le joueur dont c'est le tour peut contester le dernier coup.",
+			})
+		} else if strings.Contains(err.Error(), "cannot challenge your own move") {
+			logctx.Add(c, "reason", "own_move")
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error":   fmt.Sprintf("failed to challenge move: %v", err),
+				"message": "Vous ne pouvez pas contester votre propre coup.",
+			})
+		} else if strings.Contains(err.Error(), "no move to challenge") {
+			logctx.Add(c, "reason", "nothing_to_challenge")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to challenge move: %v", err),
+				"message": "Aucun coup ne peut être contesté pour le moment.",
+			})
+		} else if strings.Contains(err.Error(), "game not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to challenge move: %v", err),
+				"message": "La partie n'existe pas ou a été supprimée. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+			})
+		}
+
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_challenge_move",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to challenge move: %v", err),
+			"message": "Erreur lors de la contestation du coup. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	logctx.Add(c, "challenge_success", res.Success)
+	return c.JSON(http.StatusOK, response.ChallengeResponse{
+		Success:      res.Success,
+		InvalidWords: res.InvalidWords,
+		Penalty:      res.Penalty,
+		LostTurn:     res.LostTurn,
+	})
+}
+
+func RequestTakeback(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour reprendre un coup",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour reprendre un coup",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	applied, err := services.RequestTakeback(userID, gameID)
+	if err != nil {
+		return takebackError(c, err)
+	}
+
+	logctx.Add(c, "takeback_applied", applied)
+	return c.JSON(http.StatusOK, response.TakebackResponse{Applied: applied})
+}
+
+func AcceptTakeback(c echo.Context) error {
+	return respondTakeback(c, true)
+}
+
+func DeclineTakeback(c echo.Context) error {
+	return respondTakeback(c, false)
+}
+
+func respondTakeback(c echo.Context, accept bool) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour répondre à une demande de reprise",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour répondre à une demande de reprise",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"game_id": gameID,
+		"accept":  accept,
+	})
+
+	if err := services.RespondTakeback(userID, gameID, accept); err != nil {
+		return takebackError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// takebackError traduit les erreurs de reprise de coup en réponse HTTP.
+func takebackError(c echo.Context, err error) error {
+	if strings.Contains(err.Error(), "player not in game") {
+		logctx.Add(c, "reason", "not_in_game")
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "Vous ne participez pas à cette partie.",
+		})
+	} else if strings.Contains(err.Error(), "cannot answer your own takeback request") {
+		logctx.Add(c, "reason", "own_takeback")
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "Vous ne pouvez pas répondre à votre propre demande de reprise.",
+		})
+	} else if strings.Contains(err.Error(), "no move to take back") || strings.Contains(err.Error(), "move cannot be taken back") {
+		logctx.Add(c, "reason", "nothing_to_take_back")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "Votre dernier coup ne peut plus être repris : le joueur suivant a déjà joué.",
+		})
+	} else if strings.Contains(err.Error(), "takeback already requested") {
+		logctx.Add(c, "reason", "takeback_already_requested")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "Une reprise a déjà été demandée pour ce coup.",
+		})
+	} else if strings.Contains(err.Error(), "no pending takeback request") {
+		logctx.Add(c, "reason", "no_takeback_request")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "Aucune demande de reprise n'attend de réponse.",
+		})
+	} else if strings.Contains(err.Error(), "game is not ongoing") {
+		logctx.Add(c, "reason", "game_not_ongoing")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "La partie est terminée.",
+		})
+	} else if strings.Contains(err.Error(), "game not found") {
+		logctx.Add(c, "reason", "game_not_found")
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error":   fmt.Sprintf("failed to take back move: %v", err),
+			"message": "La partie n'existe pas ou a été supprimée. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	logctx.Merge(c, map[string]any{
+		"reason": "failed_to_take_back_move",
+		"error":  err.Error(),
+	})
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error":   fmt.Sprintf("failed to take back move: %v", err),
+		"message": "Erreur lors de la reprise du coup. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+	})
+}
+
+func GetGameReplay(c echo.Context) error {
+	// route publique : les parties terminées sont visibles sans être connecté
+	userID, _ := utils.GetUserID(c)
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour revoir la partie",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	var ply *int
+	if raw := c.QueryParam("ply"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			logctx.Add(c, "reason", "invalid_ply")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("invalid ply: %v", err),
+				"message": "Le numéro de coup doit être un entier",
+			})
+		}
+		ply = &n
+	}
+
+	replay, err := services.GetGameReplay(userID, gameID, ply)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid ply") {
+			logctx.Add(c, "reason", "invalid_ply")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to load replay: %v", err),
+				"message": "Ce coup n'existe pas dans la partie",
+			})
+		} else if strings.Contains(err.Error(), "not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to load replay: %v", err),
+				"message": "La partie n'existe pas ou n'est pas encore terminée.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_load_replay",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to load replay: %v", err),
+			"message": "Erreur lors du chargement du rejeu de la partie. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, replay)
+}
+
+func ExportGameGCG(c echo.Context) error {
+	// route publique, comme le rejeu : les parties terminées s'exportent sans être connecté
+	userID, _ := utils.GetUserID(c)
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour l'exporter",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	data, err := services.ExportGameGCG(userID, gameID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to export game: %v", err),
+				"message": "La partie n'existe pas ou n'est pas encore terminée.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_export_game",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to export game: %v", err),
+			"message": "Erreur lors de l'export de la partie. Veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.gcg"`, gameID))
+	return c.Blob(http.StatusOK, "text/plain; charset=utf-8", data)
+}
+
+func GetGameDraws(c echo.Context) error {
+	// route publique, comme le rejeu : la graine et les tirages d'une partie terminée sont vérifiables par tous
+	userID, _ := utils.GetUserID(c)
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour consulter ses tirages",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	draws, err := services.GetGameDraws(userID, gameID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no seeded bag") {
+			logctx.Add(c, "reason", "no_seeded_bag")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to load draws: %v", err),
+				"message": "Cette partie a été créée avant les tirages vérifiables.",
+			})
+		} else if strings.Contains(err.Error(), "not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to load draws: %v", err),
+				"message": "La partie n'existe pas ou n'est pas encore terminée.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_load_draws",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to load draws: %v", err),
+			"message": "Erreur lors du chargement des tirages de la partie. Veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, draws)
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func GetInvitations(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir vos invitations",
+		})
+	}
+
+	invitations, err := services.GetInvitations(userID)
+	if err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_get_invitations",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to get invitations: %v", err),
+			"message": "Erreur lors du chargement de vos invitations. Veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"invitations": invitations})
+}
+
+func AcceptInvitation(c echo.Context) error {
+	return respondInvitation(c, true)
+}
+
+func DeclineInvitation(c echo.Context) error {
+	return respondInvitation(c, false)
+}
+
+func respondInvitation(c echo.Context, accept bool) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour répondre à une invitation",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour répondre à une invitation",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"game_id": gameID,
+		"accept":  accept,
+	})
+
+	var err error
+	if accept {
+		err = services.AcceptInvitation(userID, gameID)
+	} else {
+		err = services.DeclineInvitation(userID, gameID)
+	}
+	if err != nil {
+		return invitationError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func StartGame(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour lancer une partie",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour la lancer",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	if err := services.StartPendingGame(userID, gameID); err != nil {
+		return invitationError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// invitationError traduit les erreurs d'invitation en réponse HTTP.
+func invitationError(c echo.Context, err error) error {
+	if strings.Contains(err.Error(), "invitation not found") || strings.Contains(err.Error(), "game not found") {
+		logctx.Add(c, "reason", "invitation_not_found")
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error":   fmt.Sprintf("failed to answer invitation: %v", err),
+			"message": "Aucune invitation en attente pour cette partie.",
+		})
+	} else if strings.Contains(err.Error(), "not the creator") {
+		logctx.Add(c, "reason", "not_creator")
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error":   fmt.Sprintf("failed to start game: %v", err),
+			"message": "Seul le créateur de la partie peut la lancer.",
+		})
+	} else if strings.Contains(err.Error(), "not waiting for players") {
+		logctx.Add(c, "reason", "game_not_pending")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("failed to start game: %v", err),
+			"message": "La partie a déjà commencé.",
+		})
+	} else if strings.Contains(err.Error(), "not enough players") {
+		logctx.Add(c, "reason", "not_enough_players")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("failed to start game: %v", err),
+			"message": "Personne n'a encore rejoint la partie : impossible de la lancer.",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"reason": "failed_to_handle_invitation",
+		"error":  err.Error(),
+	})
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error":   fmt.Sprintf("failed to handle invitation: %v", err),
+		"message": "Erreur lors du traitement de l'invitation, veuillez réessayer. Si le problème persiste, contactez le support.",
+	})
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func CreateInviteLink(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour créer un lien d'invitation",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour créer un lien d'invitation",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	var req request.CreateInviteLinkRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+
+	link, err := services.CreateInviteLink(userID, gameID, req.ExpiresInHours)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid invite link expiry") {
+			logctx.Add(c, "reason", "invalid_expiry")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create invite link: %v", err),
+				"message": "La durée de validité du lien doit être comprise entre 1 heure et 30 jours.",
+			})
+		} else if strings.Contains(err.Error(), "no open seat") {
+			logctx.Add(c, "reason", "no_open_seat")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to create invite link: %v", err),
+				"message": "Toutes les places de cette partie sont déjà prises ou réservées.",
+			})
+		}
+		return inviteLinkError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, link)
+}
+
+func ListInviteLinks(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir les liens d'invitation",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour voir ses liens d'invitation",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	links, err := services.ListInviteLinks(userID, gameID)
+	if err != nil {
+		return inviteLinkError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"links": links})
+}
+
+func RevokeInviteLink(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour révoquer un lien d'invitation",
+		})
+	}
+
+	gameID := c.Param("id")
+	linkID := c.Param("link_id")
+	if gameID == "" || linkID == "" {
+		logctx.Add(c, "reason", "missing_params")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id or link id",
+			"message": "L'ID de la partie et celui du lien sont requis",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"game_id": gameID,
+		"link_id": linkID,
+	})
+
+	if err := services.RevokeInviteLink(userID, gameID, linkID); err != nil {
+		return inviteLinkError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func RedeemInviteLink(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour rejoindre une partie",
+		})
+	}
+
+	var req request.RedeemInviteLinkRequest
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.Token) == "" {
+		logctx.Add(c, "reason", "missing_token")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing invite token",
+			"message": "Le jeton du lien d'invitation est requis",
+		})
+	}
+
+	gameID, err := services.RedeemInviteLink(userID, strings.TrimSpace(req.Token), c.RealIP())
+	if err != nil {
+		return inviteLinkError(c, err)
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	return c.JSON(http.StatusOK, echo.Map{"game_id": gameID})
+}
+
+// redeemInviteOnAuth utilise le lien d'invitation fourni à l'inscription ou à
+// la connexion. Un lien inutilisable ne fait pas échouer l'authentification :
+// la raison est renvoyée au client avec son jeton.
+func redeemInviteOnAuth(c echo.Context, userID int64, token string) (string, string) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", ""
+	}
+	gameID, err := services.RedeemInviteLink(userID, token, c.RealIP())
+	if err != nil {
+		logctx.Add(c, "invite_error", err.Error())
+		return "", inviteLinkMessage(err)
+	}
+	logctx.Add(c, "game_id", gameID)
+	return gameID, ""
+}
+
+// inviteLinkMessage explique à l'utilisateur pourquoi un lien d'invitation
+// n'a pas pu être utilisé.
+func inviteLinkMessage(err error) string {
+	switch {
+	case strings.Contains(err.Error(), "invalid invite token"), strings.Contains(err.Error(), "invite link not found"):
+		return "Ce lien d'invitation n'est pas valide."
+	case strings.Contains(err.Error(), "expired"):
+		return "Ce lien d'invitation a expiré."
+	case strings.Contains(err.Error(), "revoked"):
+		return "Ce lien d'invitation a été révoqué par le créateur de la partie."
+	case strings.Contains(err.Error(), "already used"):
+		return "Ce lien d'invitation a déjà été utilisé."
+	case strings.Contains(err.Error(), "already in game"):
+		return "Vous avez déjà une place ou une invitation dans cette partie."
+	case strings.Contains(err.Error(), "not waiting for players"):
+		return "La partie a déjà commencé."
+	default:
+		return "Impossible de rejoindre la partie avec ce lien, veuillez réessayer."
+	}
+}
+
+// inviteLinkError traduit les erreurs des liens d'invitation en réponse HTTP.
+func inviteLinkError(c echo.Context, err error) error {
+	if strings.Contains(err.Error(), "game not found") || strings.Contains(err.Error(), "invite link not found") ||
+		strings.Contains(err.Error(), "invalid invite token") {
+		logctx.Add(c, "reason", "invite_link_not_found")
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error":   fmt.Sprintf("invite link error: %v", err),
+			"message": inviteLinkMessage(err),
+		})
+	} else if strings.Contains(err.Error(), "not the creator") {
+		logctx.Add(c, "reason", "not_creator")
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error":   fmt.Sprintf("invite link error: %v", err),
+			"message": "Seul le créateur de la partie peut gérer ses liens d'invitation.",
+		})
+	} else if strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "revoked") {
+		logctx.Add(c, "reason", "invite_link_unusable")
+		return c.JSON(http.StatusGone, echo.Map{
+			"error":   fmt.Sprintf("invite link error: %v", err),
+			"message": inviteLinkMessage(err),
+		})
+	} else if strings.Contains(err.Error(), "already used") || strings.Contains(err.Error(), "already in game") ||
+		strings.Contains(err.Error(), "not waiting for players") {
+		logctx.Add(c, "reason", "invite_link_conflict")
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error":   fmt.Sprintf("invite link error: %v", err),
+			"message": inviteLinkMessage(err),
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"reason": "failed_to_handle_invite_link",
+		"error":  err.Error(),
+	})
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error":   fmt.Sprintf("failed to handle invite link: %v", err),
+		"message": "Erreur lors du traitement du lien d'invitation, veuillez réessayer. Si le problème persiste, contactez le support.",
+	})
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func ListLobbyGames(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir le lobby",
+		})
+	}
+
+	games, err := services.ListLobbyGames(userID)
+	if err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_list_lobby_games",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to list lobby games: %v", err),
+			"message": "Erreur lors du chargement du lobby. Veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"games": games})
+}
+
+func JoinLobbyGame(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour rejoindre une partie",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour la rejoindre",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	if err := services.JoinLobbyGame(userID, gameID); err != nil {
+		if strings.Contains(err.Error(), "game not found") || strings.Contains(err.Error(), "not open to join") {
+			logctx.Add(c, "reason", "not_in_lobby")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to join game: %v", err),
+				"message": "Cette partie n'existe pas ou n'a pas de place ouverte.",
+			})
+		} else if strings.Contains(err.Error(), "already in game") {
+			logctx.Add(c, "reason", "already_in_game")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to join game: %v", err),
+				"message": "Vous avez déjà une place ou une invitation dans cette partie.",
+			})
+		} else if strings.Contains(err.Error(), "no open seat") || strings.Contains(err.Error(), "game is full") {
+			logctx.Add(c, "reason", "no_open_seat")
+			return c.JSON(http.StatusConflict, echo.Map{
+				"error":   fmt.Sprintf("failed to join game: %v", err),
+				"message": "Toutes les places de cette partie sont prises.",
+			})
+		} else if strings.Contains(err.Error(), "rating too low") {
+			logctx.Add(c, "reason", "rating_too_low")
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error":   fmt.Sprintf("failed to join game: %v", err),
+				"message": "Votre classement est inférieur au minimum demandé pour cette partie.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_join_game",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to join game: %v", err),
+			"message": "Erreur lors de l'inscription à la partie, veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
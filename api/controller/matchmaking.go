@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func JoinMatchmaking(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour chercher une partie",
+		})
+	}
+
+	var req request.JoinMatchmakingRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+	req.Mode = strings.ToLower(strings.TrimSpace(req.Mode))
+	logctx.Add(c, "mode", req.Mode)
+
+	entry, err := services.JoinMatchmaking(userID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid matchmaking mode") {
+			logctx.Add(c, "reason", "invalid_mode")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to join matchmaking: %v", err),
+				"message": "Mode invalide (duel ou multi)",
+			})
+		} else if strings.Contains(err.Error(), "invalid rating window") {
+			logctx.Add(c, "reason", "invalid_rating_window")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to join matchmaking: %v", err),
+				"message": "L'écart de classement doit être positif",
+			})
+		} else if strings.Contains(err.Error(), "invalid ruleset") {
+			logctx.Add(c, "reason", "invalid_ruleset")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to join matchmaking: %v", err),
+				"message": "Les règles personnalisées de la partie sont invalides",
+			})
+		} else if strings.Contains(err.Error(), "unsupported language") {
+			logctx.Add(c, "reason", "unsupported_language")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to join matchmaking: %v", err),
+				"message": "Langue de partie non disponible",
+			})
+		} else if strings.Contains(err.Error(), "invalid variant") {
+			logctx.Add(c, "reason", "invalid_variant")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to join matchmaking: %v", err),
+				"message": "Variante de plateau invalide (standard ou super)",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_join_matchmaking",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to join matchmaking: %v", err),
+			"message": "Erreur lors de l'inscription à la recherche de partie, veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+func GetMatchmaking(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir votre recherche de partie",
+		})
+	}
+
+	entry, err := services.GetMatchmakingEntry(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not in matchmaking queue") {
+			logctx.Add(c, "reason", "not_in_queue")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to get matchmaking: %v", err),
+				"message": "Vous ne cherchez pas de partie.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_get_matchmaking",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to get matchmaking: %v", err),
+			"message": "Erreur lors du chargement de votre recherche de partie. Veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+func LeaveMatchmaking(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour annuler votre recherche de partie",
+		})
+	}
+
+	if err := services.LeaveMatchmaking(userID); err != nil {
+		if strings.Contains(err.Error(), "not in matchmaking queue") {
+			logctx.Add(c, "reason", "not_in_queue")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to leave matchmaking: %v", err),
+				"message": "Vous ne cherchez pas de partie.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_leave_matchmaking",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to leave matchmaking: %v", err),
+			"message": "Erreur lors de l'annulation de votre recherche de partie, veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -184,7 +184,8 @@ func SimulatePuzzleScore(c echo.Context) error {
 	}
 
 	var body struct {
-		Letters []request.PlacedLetter `json:"letters"`
+		Letters  []request.PlacedLetter `json:"letters"`
+		Notation string                 `json:"notation"`
 	}
 	if err := c.Bind(&body); err != nil {
 		logctx.Add(c, "reason", "bind_failed")
@@ -195,7 +196,7 @@ func SimulatePuzzleScore(c echo.Context) error {
 		})
 	}
 
-	score, err := services.SimulatePuzzleScore(c.Request().Context(), userID, puzzleID, body.Letters)
+	score, err := services.SimulatePuzzleScore(c.Request().Context(), userID, puzzleID, body.Letters, body.Notation)
 	if err != nil {
 		logctx.Add(c, "reason", "simulate_puzzle_score_failed")
 		logctx.Add(c, "error", err.Error())
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func SpectateGame(c echo.Context) error {
+	// route publique : les parties publiques se regardent sans être connecté
+	userID, _ := utils.GetUserID(c)
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour la regarder",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	view, err := services.GetSpectatorView(userID, gameID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not open to spectators") {
+			logctx.Add(c, "reason", "not_spectatable")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to spectate game: %v", err),
+				"message": "La partie n'existe pas ou n'est pas ouverte aux spectateurs.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_spectate_game",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to spectate game: %v", err),
+			"message": "Erreur lors du chargement de la partie. Veuillez recharger la page ou réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, view)
+}
+
+func ListPublicGames(c echo.Context) error {
+	games, err := services.ListPublicGames()
+	if err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_list_public_games",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to list public games: %v", err),
+			"message": "Erreur lors du chargement des parties publiques. Veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"games": games})
+}
+
+func SetGameVisibility(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour changer la visibilité d'une partie",
+		})
+	}
+
+	gameID := c.Param("id")
+	if gameID == "" {
+		logctx.Add(c, "reason", "missing_game_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing game id",
+			"message": "L'ID de la partie est requis pour changer sa visibilité",
+		})
+	}
+	logctx.Add(c, "game_id", gameID)
+
+	var req request.SetVisibilityRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+
+	visibility := strings.ToLower(strings.TrimSpace(req.Visibility))
+	if err := services.SetGameVisibility(userID, gameID, visibility, req.SpectatorChat); err != nil {
+		if strings.Contains(err.Error(), "invalid visibility") {
+			logctx.Add(c, "reason", "invalid_visibility")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to set visibility: %v", err),
+				"message": "Visibilité invalide (private, friends ou public)",
+			})
+		} else if strings.Contains(err.Error(), "not found") {
+			logctx.Add(c, "reason", "game_not_found")
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error":   fmt.Sprintf("failed to set visibility: %v", err),
+				"message": "La partie n'existe pas.",
+			})
+		} else if strings.Contains(err.Error(), "not the creator") {
+			logctx.Add(c, "reason", "not_creator")
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error":   fmt.Sprintf("failed to set visibility: %v", err),
+				"message": "Seul le créateur de la partie peut changer sa visibilité.",
+			})
+		}
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_set_visibility",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to set visibility: %v", err),
+			"message": "Erreur lors du changement de visibilité, veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Game visibility updated successfully",
+	})
+}
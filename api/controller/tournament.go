@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/middleware/logctx"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/services"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/labstack/echo/v4"
+)
+
+func CreateTournament(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour créer un tournoi",
+		})
+	}
+
+	var req request.CreateTournamentRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+	req.Format = strings.ToLower(strings.TrimSpace(req.Format))
+	logctx.Merge(c, map[string]any{
+		"format": req.Format,
+		"rounds": req.Rounds,
+	})
+
+	t, err := services.CreateTournament(userID, req)
+	if err != nil {
+		var message string
+		switch {
+		case strings.Contains(err.Error(), "tournament name is required"):
+			message = "Le nom du tournoi est requis"
+		case strings.Contains(err.Error(), "invalid tournament format"):
+			message = "Format de tournoi invalide (round_robin ou swiss)"
+		case strings.Contains(err.Error(), "invalid tournament rounds"):
+			message = "Nombre de rondes invalide : entre 1 et 50 pour un tournoi suisse, 0 pour un toutes-rondes complet"
+		case strings.Contains(err.Error(), "invalid registration window"):
+			message = "La fin des inscriptions doit être dans le futur et après leur ouverture"
+		case strings.Contains(err.Error(), "invalid ruleset"):
+			message = "Les règles personnalisées du tournoi sont invalides"
+		case strings.Contains(err.Error(), "unsupported language"):
+			message = "Langue de partie non disponible"
+		case strings.Contains(err.Error(), "invalid variant"):
+			message = "Variante de plateau invalide (standard ou super)"
+		}
+		if message != "" {
+			logctx.Add(c, "reason", "invalid_tournament")
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error":   fmt.Sprintf("failed to create tournament: %v", err),
+				"message": message,
+			})
+		}
+		return tournamentError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, t)
+}
+
+func ListTournaments(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir les tournois",
+		})
+	}
+
+	tournaments, err := services.ListTournaments(userID)
+	if err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"tournaments": tournaments})
+}
+
+func GetTournament(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour voir un tournoi",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	logctx.Add(c, "tournament_id", tournamentID)
+
+	t, err := services.GetTournament(userID, tournamentID)
+	if err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, t)
+}
+
+func RegisterForTournament(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour vous inscrire à un tournoi",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	logctx.Add(c, "tournament_id", tournamentID)
+
+	if err := services.RegisterForTournament(userID, tournamentID); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func UnregisterFromTournament(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour vous désinscrire d'un tournoi",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	logctx.Add(c, "tournament_id", tournamentID)
+
+	if err := services.UnregisterFromTournament(userID, tournamentID); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func StartTournament(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour lancer un tournoi",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	logctx.Add(c, "tournament_id", tournamentID)
+
+	if err := services.StartTournament(userID, tournamentID); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func WithdrawFromTournament(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour vous retirer d'un tournoi",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	var req request.TournamentPlayerRequest
+	if err := c.Bind(&req); err != nil {
+		logctx.Merge(c, map[string]any{
+			"reason": "bind_failed",
+			"body":   err.Error(),
+		})
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   fmt.Sprintf("invalid request: %v", err),
+			"message": "Requête invalide, veuillez vérifier les données saisies",
+		})
+	}
+	if req.UserID == 0 {
+		req.UserID = userID
+	}
+	logctx.Merge(c, map[string]any{
+		"tournament_id": tournamentID,
+		"player_id":     req.UserID,
+	})
+
+	if err := services.WithdrawFromTournament(userID, tournamentID, req.UserID); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func ForfeitTournamentGame(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour déclarer un forfait",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	var req request.TournamentPlayerRequest
+	if err := c.Bind(&req); err != nil || req.UserID == 0 {
+		logctx.Add(c, "reason", "missing_user_id")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing user id",
+			"message": "Le joueur déclaré forfait est requis",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"tournament_id": tournamentID,
+		"player_id":     req.UserID,
+	})
+
+	if err := services.ForfeitTournamentGame(userID, tournamentID, req.UserID); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func AssignTournamentBye(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour attribuer un bye",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	var req request.TournamentByeRequest
+	if err := c.Bind(&req); err != nil || req.UserID == 0 {
+		logctx.Add(c, "reason", "invalid_bye")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "missing user id or round",
+			"message": "Le joueur et la ronde du bye sont requis",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"tournament_id": tournamentID,
+		"player_id":     req.UserID,
+		"round":         req.Round,
+	})
+
+	if err := services.AssignTournamentBye(userID, tournamentID, req.UserID, req.Round); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+func RemoveTournamentBye(c echo.Context) error {
+	userID, ok := utils.GetUserID(c)
+	if !ok {
+		logctx.Add(c, "reason", "unauthorized")
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error":   "unauthorized, no user_id",
+			"message": "Vous devez être connecté pour retirer un bye",
+		})
+	}
+
+	tournamentID := c.Param("id")
+	round, errRound := strconv.Atoi(c.Param("round"))
+	playerID, errPlayer := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if errRound != nil || errPlayer != nil {
+		logctx.Add(c, "reason", "invalid_params")
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error":   "invalid round or user id",
+			"message": "La ronde et le joueur du bye sont invalides",
+		})
+	}
+	logctx.Merge(c, map[string]any{
+		"tournament_id": tournamentID,
+		"player_id":     playerID,
+		"round":         round,
+	})
+
+	if err := services.RemoveTournamentBye(userID, tournamentID, playerID, round); err != nil {
+		return tournamentError(c, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// tournamentError traduit les erreurs des tournois en réponse HTTP.
+func tournamentError(c echo.Context, err error) error {
+	var (
+		status  int
+		reason  string
+		message string
+	)
+	switch {
+	case strings.Contains(err.Error(), "tournament not found"):
+		status, reason, message = http.StatusNotFound, "tournament_not_found", "Tournoi introuvable."
+	case strings.Contains(err.Error(), "unauthorized"):
+		status, reason, message = http.StatusForbidden, "not_tournament_manager", "Seuls l'organisateur du tournoi et les administrateurs peuvent faire cela."
+	case strings.Contains(err.Error(), "registration is closed"):
+		status, reason, message = http.StatusConflict, "registration_closed", "Les inscriptions à ce tournoi ne sont pas ouvertes."
+	case strings.Contains(err.Error(), "already registered"):
+		status, reason, message = http.StatusConflict, "already_registered", "Vous êtes déjà inscrit à ce tournoi."
+	case strings.Contains(err.Error(), "not registered"):
+		status, reason, message = http.StatusNotFound, "not_registered", "Ce joueur n'est pas inscrit à ce tournoi."
+	case strings.Contains(err.Error(), "not running"):
+		status, reason, message = http.StatusConflict, "tournament_not_running", "Le tournoi n'est pas en cours."
+	case strings.Contains(err.Error(), "not enough players"):
+		status, reason, message = http.StatusConflict, "not_enough_players", "Il faut au moins deux inscrits pour lancer le tournoi."
+	case strings.Contains(err.Error(), "invalid bye round"):
+		status, reason, message = http.StatusBadRequest, "invalid_bye_round", "Un bye ne peut concerner qu'une ronde à venir du tournoi."
+	case strings.Contains(err.Error(), "already paired"):
+		status, reason, message = http.StatusConflict, "already_paired", "Ce joueur est déjà apparié pour cette ronde."
+	case strings.Contains(err.Error(), "bye not found"):
+		status, reason, message = http.StatusNotFound, "bye_not_found", "Aucun bye attribué à ce joueur pour cette ronde."
+	case strings.Contains(err.Error(), "no pending game"):
+		status, reason, message = http.StatusConflict, "no_pending_game", "Ce joueur n'a pas de partie en cours dans la ronde actuelle."
+	default:
+		logctx.Merge(c, map[string]any{
+			"reason": "failed_to_handle_tournament",
+			"error":  err.Error(),
+		})
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error":   fmt.Sprintf("failed to handle tournament: %v", err),
+			"message": "Erreur lors du traitement du tournoi, veuillez réessayer. Si le problème persiste, contactez le support.",
+		})
+	}
+	logctx.Add(c, "reason", reason)
+	return c.JSON(status, echo.Map{
+		"error":   fmt.Sprintf("tournament error: %v", err),
+		"message": message,
+	})
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"slices"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// SeedSize est la taille en octets de la graine d'une partie.
+const SeedSize = 32
+
+// ErrDrawMismatch est retournée par VerifyDraws quand un tirage ne découle
+// pas de la graine.
+var ErrDrawMismatch = errors.New("draw does not match the game seed")
+
+// Draw est un tirage du sac : le sac juste avant le tirage et les tuiles
+// tirées. Seq numérote les tirages de la partie à partir de 0.
+type Draw struct {
+	Seq      int
+	PlayerID int64
+	Bag      word.Tiles
+	Tiles    word.Tiles
+}
+
+// BagChange est une modification du sac hors tirage : tuiles remises
+// (échange, coup contesté ou repris, abandon, tirage duplicate rejeté) ou
+// reprises (échange annulé). Seq est le numéro du tirage qui la suit.
+type BagChange struct {
+	Seq      int
+	Returned word.Tiles
+	Removed  word.Tiles
+}
+
+// returnToBag remet tiles dans le sac et journalise la remise.
+func (s *GameState) returnToBag(tiles word.Tiles) {
+	s.Bag = s.Bag.Concat(tiles)
+	s.logBagChange(BagChange{Returned: tiles})
+}
+
+// logBagChange ajoute c à BagChanges dans une partie avec graine (rien si c
+// est vide).
+func (s *GameState) logBagChange(c BagChange) {
+	if s.Seed == nil || len(c.Returned)+len(c.Removed) == 0 {
+		return
+	}
+	c.Seq = s.DrawCount
+	c.Returned = append(word.Tiles(nil), c.Returned...)
+	c.Removed = append(word.Tiles(nil), c.Removed...)
+	s.BagChanges = append(s.BagChanges, c)
+}
+
+// NewSeed tire une graine cryptographiquement aléatoire pour une partie.
+func NewSeed() ([]byte, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := crand.Read(seed); err != nil {
+		return nil, fmt.Errorf("failed to generate game seed: %w", err)
+	}
+	return seed, nil
+}
+
+// SeedHash est l'engagement publié à la création de la partie : l'empreinte
+// SHA-256 de la graine, en hexadécimal.
+func SeedHash(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
+
+// DrawRand retourne la source du tirage numéro seq : un flux ChaCha8 dont la
+// clé est HMAC-SHA256(seed, seq sur 8 octets big-endian). Chaque tirage a sa
+// propre source, si bien qu'il se vérifie indépendamment des autres.
+func DrawRand(seed []byte, seq int) *rand.Rand {
+	mac := hmac.New(sha256.New, seed)
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(seq))
+	mac.Write(n[:])
+	var key [32]byte
+	copy(key[:], mac.Sum(nil))
+	return rand.New(chachaSource{randv2.NewChaCha8(key)})
+}
+
+// chachaSource adapte ChaCha8 à l'interface rand.Source utilisée par DrawTiles.
+type chachaSource struct{ *randv2.ChaCha8 }
+
+func (s chachaSource) Int63() int64 { return int64(s.Uint64() >> 1) }
+func (s chachaSource) Seed(int64)   {}
+
+// VerifyDraws vérifie que chaque tirage découle de la graine. Le sac est
+// reconstitué à partir du sac de départ bag : chaque tirage en retire ses
+// tuiles et changes (triées par Seq) y remettent ou en reprennent d'autres.
+// Avant chaque tirage, le sac reconstitué, dans l'ordre canonique, doit être
+// celui du journal, et le tirage rejoué avec DrawRand doit donner les mêmes
+// tuiles ; les tirages se suivent sans trou à partir de 0.
+func VerifyDraws(seed []byte, bag word.Tiles, draws []Draw, changes []BagChange) error {
+	bag = append(word.Tiles{}, bag...)
+	next := 0
+	for i, d := range draws {
+		if d.Seq != i {
+			return fmt.Errorf("%w: draw %d is numbered %d", ErrDrawMismatch, i, d.Seq)
+		}
+		for ; next < len(changes) && changes[next].Seq <= i; next++ {
+			c := changes[next]
+			bag = bag.Concat(c.Returned)
+			for _, t := range c.Removed {
+				var ok bool
+				if bag, ok = bag.Remove(t); !ok {
+					return fmt.Errorf("%w: tile %s taken back before draw %d is not in the bag", ErrDrawMismatch, t, c.Seq)
+				}
+			}
+		}
+		bag = bag.Sorted()
+		if !slices.Equal(bag, d.Bag) {
+			return fmt.Errorf("%w: draw %d was made from bag %s, expected %s", ErrDrawMismatch, d.Seq, d.Bag, bag)
+		}
+		got := DrawTiles(&bag, len(d.Tiles), DrawRand(seed, d.Seq))
+		if !slices.Equal(got, d.Tiles) {
+			return fmt.Errorf("%w: draw %d gave %s, expected %s", ErrDrawMismatch, d.Seq, got, d.Tiles)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestNewSeededGame_IsReproducible(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, SeedSize)
+	a := NewSeededGame([]int64{1, 2}, nil, word.French.Bag, DefaultRuleset(), seed)
+	b := NewSeededGame([]int64{1, 2}, nil, word.French.Bag, DefaultRuleset(), seed)
+	if a.Racks[1].String() != b.Racks[1].String() || a.Racks[2].String() != b.Racks[2].String() || a.Bag.String() != b.Bag.String() {
+		t.Fatalf("same seed dealt different racks: %q/%q vs %q/%q", a.Racks[1], a.Racks[2], b.Racks[1], b.Racks[2])
+	}
+	if a.DrawCount != 2 || len(a.Draws) != 2 || a.Draws[1].PlayerID != 2 || a.Draws[1].Tiles.String() != a.Racks[2].String() {
+		t.Fatalf("unexpected draw log: %+v", a.Draws)
+	}
+
+	other := NewSeededGame([]int64{1, 2}, nil, word.French.Bag, DefaultRuleset(), bytes.Repeat([]byte{8}, SeedSize))
+	if other.Bag.String() == a.Bag.String() {
+		t.Fatalf("different seeds should not give the same bag")
+	}
+}
+
+func TestVerifyDraws_ReplaysEveryDraw(t *testing.T) {
+	seed, err := NewSeed()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(SeedHash(seed)) != 64 {
+		t.Fatalf("unexpected seed hash %q", SeedHash(seed))
+	}
+
+	s := NewSeededGame([]int64{1, 2}, nil, word.French.Bag, DefaultRuleset(), seed)
+	next, _, err := s.Exchange(1, s.Racks[1][:3])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.DrawCount != 3 || len(next.Draws) != 3 || len(s.Draws) != 2 {
+		t.Fatalf("expected the exchange to log a third draw, got %d/%d", next.DrawCount, len(next.Draws))
+	}
+	if len(next.BagChanges) != 1 || next.BagChanges[0].Seq != 3 || len(next.BagChanges[0].Returned) != 3 {
+		t.Fatalf("expected the exchange to log the returned tiles, got %+v", next.BagChanges)
+	}
+	if err := VerifyDraws(seed, word.French.Bag, next.Draws, next.BagChanges); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	forged := append([]Draw(nil), next.Draws...)
+	forged[2].Tiles = append(word.Tiles{}, forged[2].Tiles...)
+	forged[2].Tiles[0] = "#"
+	if err := VerifyDraws(seed, word.French.Bag, forged, next.BagChanges); !errors.Is(err, ErrDrawMismatch) {
+		t.Fatalf("expected ErrDrawMismatch for a forged draw, got %v", err)
+	}
+	if err := VerifyDraws(seed, word.French.Bag, next.Draws[1:], next.BagChanges); !errors.Is(err, ErrDrawMismatch) {
+		t.Fatalf("expected ErrDrawMismatch for a missing draw, got %v", err)
+	}
+}
+
+func TestVerifyDraws_RebuildsTheBag(t *testing.T) {
+	seed := bytes.Repeat([]byte{5}, SeedSize)
+	s := NewSeededGame([]int64{1, 2}, nil, word.French.Bag, DefaultRuleset(), seed)
+	exchanged, res, err := s.Exchange(1, s.Racks[1][:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// l'échange annulé reprend les tuiles remises et rend les tuiles piochées
+	undone, err := exchanged.Takeback([]PlayedMove{{PlayerID: 1, Returned: s.Racks[1][:2], Drawn: res.Drawn}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, _, err := undone.Exchange(1, undone.Racks[1][:4])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := VerifyDraws(seed, word.French.Bag, again.Draws, again.BagChanges); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, d := range again.Draws {
+		if !slices.IsSorted(d.Bag) {
+			t.Fatalf("draw %d was not made from the canonical bag", d.Seq)
+		}
+	}
+
+	// un sac journalisé remanié pour choisir le tirage ne passe plus
+	tampered := append([]Draw(nil), again.Draws...)
+	last := len(tampered) - 1
+	tampered[last].Bag = append(word.Tiles{}, tampered[last].Bag...)
+	slices.Reverse(tampered[last].Bag)
+	bag := append(word.Tiles{}, tampered[last].Bag...)
+	tampered[last].Tiles = DrawTiles(&bag, len(tampered[last].Tiles), DrawRand(seed, tampered[last].Seq))
+	if err := VerifyDraws(seed, word.French.Bag, tampered, again.BagChanges); !errors.Is(err, ErrDrawMismatch) {
+		t.Fatalf("expected ErrDrawMismatch for a tampered bag, got %v", err)
+	}
+
+	// un sac journalisé d'une autre composition non plus
+	swapped := append([]Draw(nil), again.Draws...)
+	swapped[1].Bag = append(word.Tiles{}, swapped[1].Bag...)
+	swapped[1].Bag[0] = "Z"
+	if err := VerifyDraws(seed, word.French.Bag, swapped, again.BagChanges); !errors.Is(err, ErrDrawMismatch) {
+		t.Fatalf("expected ErrDrawMismatch for a bag with other tiles, got %v", err)
+	}
+
+	// ni des remises passées sous silence
+	if err := VerifyDraws(seed, word.French.Bag, again.Draws, nil); !errors.Is(err, ErrDrawMismatch) {
+		t.Fatalf("expected ErrDrawMismatch without the bag changes, got %v", err)
+	}
+}
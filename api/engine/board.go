@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// Board représente le plateau ("" pour une case vide), indexé [y][x]. Sa
+// taille dépend de la disposition de la partie (15x15 en standard).
+type Board [][]string
+
+// NewBoard retourne un plateau vide de size x size cases.
+func NewBoard(size int) Board {
+	b := make(Board, size)
+	for y := range b {
+		b[y] = make([]string, size)
+	}
+	return b
+}
+
+// Size retourne le nombre de lignes (et de colonnes) du plateau.
+func (b Board) Size() int {
+	return len(b)
+}
+
+// InBounds indique si la case (x, y) est sur le plateau.
+func (b Board) InBounds(x, y int) bool {
+	return y >= 0 && y < len(b) && x >= 0 && x < len(b[y])
+}
+
+// Clone retourne une copie profonde du plateau.
+func (b Board) Clone() Board {
+	c := make(Board, len(b))
+	for y, row := range b {
+		c[y] = append([]string(nil), row...)
+	}
+	return c
+}
+
+// Codes des cases spéciales d'une disposition.
+const (
+	PremiumDL     = "DL" // lettre compte double
+	PremiumTL     = "TL" // lettre compte triple
+	PremiumQL     = "QL" // lettre compte quadruple
+	PremiumDW     = "DW" // mot compte double
+	PremiumTW     = "TW" // mot compte triple
+	PremiumQW     = "QW" // mot compte quadruple
+	PremiumCenter = "★"  // case centrale, mot compte double
+)
+
+// Variantes de plateau disponibles.
+const (
+	LayoutStandard = "standard"
+	LayoutSuper    = "super"
+)
+
+// Layout décrit la géométrie d'un plateau : taille, case centrale imposée au
+// premier coup, cases spéciales et taille du sac associé.
+type Layout struct {
+	Name     string
+	Size     int
+	Center   int            // la case centrale est (Center, Center)
+	Premiums map[Pos]string // codes Premium* par case
+	// BagSize est le nombre de tuiles du sac de cette variante ; 0 conserve
+	// le sac standard de la langue.
+	BagSize int
+}
+
+// StandardLayout est le plateau classique 15x15.
+var StandardLayout = newStandardLayout()
+
+// SuperLayout est le plateau 21x21 du Super Scrabble, avec cases mot compte
+// quadruple et lettre compte quadruple, joué avec un sac de 200 tuiles.
+var SuperLayout = parseLayout(LayoutSuper, 200, []string{
+	"Q..d...T..d..T...d..Q",
+	".D..t...D...D...t..D.",
+	"..D..q...d.d...q..D..",
+	"d..D..d...T...d..D..d",
+	".t..D...t...t...D..t.",
+	"..q..D...d.d...D..q..",
+	"...d..D...d...D..d...",
+	"T......D.....D......T",
+	".D..t...t...t...t..D.",
+	"..d..d...d.d...d..d..",
+	"d..T..d...*...d..T..d",
+})
+
+// LayoutByName retourne la disposition de la variante name ("" = standard).
+func LayoutByName(name string) (*Layout, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", LayoutStandard:
+		return StandardLayout, true
+	case LayoutSuper:
+		return SuperLayout, true
+	}
+	return nil, false
+}
+
+// NewBoard retourne un plateau vide aux dimensions de la disposition.
+func (l *Layout) NewBoard() Board {
+	return NewBoard(l.Size)
+}
+
+// Premium retourne le code de la case (x, y), "" pour une case normale.
+func (l *Layout) Premium(x, y int) string {
+	return l.Premiums[Pos{x, y}]
+}
+
+// layoutOrDefault retourne l, ou le plateau standard si l est nil.
+func layoutOrDefault(l *Layout) *Layout {
+	if l == nil {
+		return StandardLayout
+	}
+	return l
+}
+
+func newStandardLayout() *Layout {
+	l := &Layout{
+		Name:     LayoutStandard,
+		Size:     15,
+		Center:   7,
+		Premiums: make(map[Pos]string, len(word.SpecialCells)),
+	}
+	for c, p := range word.SpecialCells {
+		l.Premiums[Pos{c[0], c[1]}] = p
+	}
+	return l
+}
+
+// parseLayout construit une disposition symétrique à partir de sa moitié
+// haute (ligne centrale comprise) : Q/T/D pour mot compte quadruple, triple ou
+// double, q/t/d pour lettre compte quadruple, triple ou double, * pour le centre.
+func parseLayout(name string, bagSize int, top []string) *Layout {
+	size := 2*len(top) - 1
+	codes := map[rune]string{
+		'Q': PremiumQW, 'T': PremiumTW, 'D': PremiumDW,
+		'q': PremiumQL, 't': PremiumTL, 'd': PremiumDL,
+		'*': PremiumCenter,
+	}
+	l := &Layout{
+		Name:     name,
+		Size:     size,
+		Center:   len(top) - 1,
+		Premiums: map[Pos]string{},
+		BagSize:  bagSize,
+	}
+	for y, row := range top {
+		for x, r := range []rune(row) {
+			code, ok := codes[r]
+			if !ok {
+				continue
+			}
+			l.Premiums[Pos{x, y}] = code
+			l.Premiums[Pos{x, size - 1 - y}] = code
+		}
+	}
+	return l
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestLayouts_Symmetric(t *testing.T) {
+	for _, l := range []*Layout{StandardLayout, SuperLayout} {
+		n := l.Size - 1
+		for p, code := range l.Premiums {
+			for _, q := range []Pos{{n - p.X, p.Y}, {p.X, n - p.Y}, {p.Y, p.X}} {
+				if l.Premiums[q] != code {
+					t.Fatalf("%s: %v is %q but %v is %q", l.Name, p, code, q, l.Premiums[q])
+				}
+			}
+		}
+		if l.Premium(l.Center, l.Center) != PremiumCenter {
+			t.Fatalf("%s: expected center at %d,%d", l.Name, l.Center, l.Center)
+		}
+	}
+}
+
+func TestSuperLayout_Geometry(t *testing.T) {
+	if SuperLayout.Size != 21 || SuperLayout.Center != 10 || SuperLayout.BagSize != 200 {
+		t.Fatalf("unexpected super layout: size %d, center %d, bag %d", SuperLayout.Size, SuperLayout.Center, SuperLayout.BagSize)
+	}
+	count := map[string]int{}
+	for _, code := range SuperLayout.Premiums {
+		count[code]++
+	}
+	if count[PremiumQW] != 4 || count[PremiumQL] != 8 {
+		t.Fatalf("expected 4 QW and 8 QL squares, got %d and %d", count[PremiumQW], count[PremiumQL])
+	}
+	if SuperLayout.Premium(0, 0) != PremiumQW || SuperLayout.Premium(5, 2) != PremiumQL {
+		t.Fatalf("unexpected premiums at corners")
+	}
+}
+
+func TestLayoutByName(t *testing.T) {
+	if l, ok := LayoutByName(""); !ok || l != StandardLayout {
+		t.Fatalf("expected standard layout by default")
+	}
+	if l, ok := LayoutByName(" Super "); !ok || l != SuperLayout {
+		t.Fatalf("expected super layout")
+	}
+	if _, ok := LayoutByName("hexagonal"); ok {
+		t.Fatalf("expected unknown layout to be rejected")
+	}
+}
+
+func TestApplyMove_SuperLayout(t *testing.T) {
+	s := NewGame([]int64{1, 2}, SuperLayout, tiles("CHATEEEEEEEEEEEEEEE"), DefaultRuleset(), rand.New(rand.NewSource(1)))
+	s.Racks[1] = tiles("CHATXYZ")
+
+	// Le centre standard n'est pas le centre du Super Scrabble
+	if _, _, err := s.ApplyMove(1, chatAtCenter()); !errors.Is(err, ErrFirstMoveCenter) {
+		t.Fatalf("expected ErrFirstMoveCenter, got %v", err)
+	}
+
+	chat := []request.PlacedLetter{
+		{X: 8, Y: 10, Char: "C"},
+		{X: 9, Y: 10, Char: "H"},
+		{X: 10, Y: 10, Char: "A"},
+		{X: 11, Y: 10, Char: "T"},
+	}
+	next, res, err := s.ApplyMove(1, chat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// C(3)+H(4)+A(1)+T(1) = 9, centre mot compte double
+	if res.Score != 18 {
+		t.Fatalf("expected 18, got %d", res.Score)
+	}
+	if next.Board.Size() != 21 || s.Board[10][10] != "" {
+		t.Fatalf("expected a fresh 21x21 board on the new state only")
+	}
+}
+
+func TestComputeWordScore_QuadruplePremiums(t *testing.T) {
+	board := SuperLayout.NewBoard()
+	// "ZOO" depuis le coin : Z sur mot compte quadruple
+	placed := []request.PlacedLetter{
+		{X: 0, Y: 0, Char: "Z"},
+		{X: 1, Y: 0, Char: "O"},
+		{X: 2, Y: 0, Char: "O"},
+	}
+	if err := ApplyLetters(board, placed); err != nil {
+		t.Fatalf("ApplyLetters: %v", err)
+	}
+	// Z(10)+O(1)+O(1) = 12, x4
+	if got := DefaultRuleset().MoveScore(word.French, SuperLayout, board, placed, nil); got != 48 {
+		t.Fatalf("expected 48, got %d", got)
+	}
+
+	board = SuperLayout.NewBoard()
+	// "ZA" vertical : Z sur lettre compte quadruple en (5,2)
+	placed = []request.PlacedLetter{
+		{X: 5, Y: 2, Char: "Z"},
+		{X: 5, Y: 3, Char: "A"},
+	}
+	if err := ApplyLetters(board, placed); err != nil {
+		t.Fatalf("ApplyLetters: %v", err)
+	}
+	// Z(10x4)+A(1) = 41
+	if got := DefaultRuleset().MoveScore(word.French, SuperLayout, board, placed, nil); got != 41 {
+		t.Fatalf("expected 41, got %d", got)
+	}
+}
+
+func TestApplyLetters_OutOfBounds(t *testing.T) {
+	board := StandardLayout.NewBoard()
+	if err := ApplyLetters(board, []request.PlacedLetter{{X: 18, Y: 7, Char: "A"}}); err == nil {
+		t.Fatalf("expected out of bounds error on a 15x15 board")
+	}
+	if err := ApplyLetters(SuperLayout.NewBoard(), []request.PlacedLetter{{X: 18, Y: 7, Char: "A"}}); err != nil {
+		t.Fatalf("unexpected error on a 21x21 board: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// Règles de contestation disponibles pour une partie.
+const (
+	// ChallengeNone : les mots sont vérifiés avant d'être posés (comportement historique).
+	ChallengeNone = "none"
+	// ChallengeFree : une contestation ratée ne coûte rien.
+	ChallengeFree = "free"
+	// ChallengeSingle : une contestation ratée coûte ChallengePenalty points au contestataire.
+	ChallengeSingle = "single"
+	// ChallengeDouble : une contestation ratée fait perdre son tour au contestataire.
+	ChallengeDouble = "double"
+
+	// ChallengePenalty est le nombre de points retirés en cas de contestation ratée (ChallengeSingle).
+	ChallengePenalty = 5
+)
+
+var (
+	ErrInvalidChallengeRule = errors.New("invalid challenge rule")
+	ErrNothingToChallenge   = errors.New("no move to challenge")
+	ErrChallengeOwnMove     = errors.New("cannot challenge your own move")
+)
+
+// ValidChallengeRule indique si rule est une règle de contestation connue.
+func ValidChallengeRule(rule string) bool {
+	switch rule {
+	case ChallengeNone, ChallengeFree, ChallengeSingle, ChallengeDouble:
+		return true
+	}
+	return false
+}
+
+// PendingMove décrit un coup accepté provisoirement, avec ce qu'il faut pour l'annuler.
+type PendingMove struct {
+	PlayerID      int64
+	Letters       []request.PlacedLetter
+	Drawn         word.Tiles
+	Score         int
+	PrevPassCount int
+}
+
+// ChallengeResult décrit l'issue d'une contestation.
+type ChallengeResult struct {
+	Success      bool     // au moins un mot était invalide : le coup est retiré
+	InvalidWords []string // mots invalides trouvés
+	Penalty      int      // points retirés au contestataire
+	LostTurn     bool     // le contestataire a perdu son tour
+	NextTurn     int64
+	GameOver     bool // trop de tours sans score : la partie doit être terminée
+}
+
+// InvalidWords retourne les mots formés par placed sur board qui sont absents
+// du dictionnaire de lang (français si nil).
+func InvalidWords(lang *word.Language, board Board, placed []request.PlacedLetter) []string {
+	lang = languageOrDefault(lang)
+	var invalid []string
+	for _, fw := range ExtractFormedWords(board, placed) {
+		if !lang.WordExists(fw.Word) {
+			invalid = append(invalid, fw.Word)
+		}
+	}
+	return invalid
+}
+
+// Challenge conteste le coup mv, qui doit être le dernier coup joué. Seul le
+// joueur dont c'est le tour peut contester. Si un mot est invalide, les tuiles
+// sont retirées du plateau, le rack et le sac sont restaurés et le score
+// annulé ; sinon la pénalité de ChallengeRule est appliquée au contestataire.
+func (s *GameState) Challenge(challengerID int64, mv PendingMove) (*GameState, *ChallengeResult, error) {
+	if err := s.checkTurn(challengerID); err != nil {
+		return nil, nil, err
+	}
+	if challengerID == mv.PlayerID {
+		return nil, nil, ErrChallengeOwnMove
+	}
+	if len(mv.Letters) == 0 {
+		return nil, nil, ErrNothingToChallenge
+	}
+
+	next := s.Clone()
+	res := &ChallengeResult{InvalidWords: InvalidWords(s.Language, s.Board, mv.Letters)}
+
+	if len(res.InvalidWords) > 0 {
+		res.Success = true
+		if err := next.withdraw(mv); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		switch s.ChallengeRule {
+		case ChallengeSingle:
+			res.Penalty = ChallengePenalty
+			next.Scores[challengerID] -= ChallengePenalty
+		case ChallengeDouble:
+			res.LostTurn = true
+			next.PassCount++
+			next.Turn = next.NextPlayer(challengerID)
+		}
+	}
+	res.NextTurn = next.Turn
+	res.GameOver = next.scorelessLimitReached()
+
+	return next, res, nil
+}
+
+// withdraw annule mv sur l'état courant : les tuiles quittent le plateau et
+// reviennent dans le rack, les lettres piochées retournent dans le sac et le
+// score est retiré. Le coup retiré compte comme un tour sans score.
+func (s *GameState) withdraw(mv PendingMove) error {
+	rack := s.Racks[mv.PlayerID]
+	for _, t := range mv.Drawn {
+		var ok bool
+		if rack, ok = rack.Remove(t); !ok {
+			return errors.New("drawn tiles are no longer in the rack")
+		}
+	}
+
+	for _, pl := range mv.Letters {
+		s.Board[pl.Y][pl.X] = ""
+		delete(s.Blanks, Pos{pl.X, pl.Y})
+		rack = append(rack, placedTile(pl))
+	}
+
+	s.Racks[mv.PlayerID] = rack
+	s.returnToBag(mv.Drawn)
+	s.Scores[mv.PlayerID] -= mv.Score
+	s.PassCount = mv.PrevPassCount + 1
+	return nil
+}
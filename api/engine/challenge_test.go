@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+)
+
+func xyzAtCenter() []request.PlacedLetter {
+	return []request.PlacedLetter{
+		{X: 7, Y: 7, Char: "X"},
+		{X: 8, Y: 7, Char: "Y"},
+		{X: 9, Y: 7, Char: "Z"},
+	}
+}
+
+// playPending joue un coup provisoire pour le joueur 1 et retourne l'état
+// obtenu avec le PendingMove correspondant.
+func playPending(t *testing.T, rule string, letters []request.PlacedLetter) (*GameState, PendingMove) {
+	t.Helper()
+	s := newTestGame("CHATXYZ", "ABCDEFG")
+	s.ChallengeRule = rule
+	s.PassCount = 1
+	next, res, err := s.ApplyMove(1, letters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Pending {
+		t.Fatalf("expected move to be pending")
+	}
+	return next, PendingMove{
+		PlayerID:      1,
+		Letters:       res.Letters,
+		Drawn:         res.Drawn,
+		Score:         res.Score,
+		PrevPassCount: res.PrevPassCount,
+	}
+}
+
+func TestApplyMove_ChallengeRuleAcceptsPhony(t *testing.T) {
+	s := newTestGame("CHATXYZ", "ABCDEFG")
+	if _, _, err := s.ApplyMove(1, xyzAtCenter()); err == nil {
+		t.Fatalf("expected phony to be rejected without challenge rule")
+	}
+	s.ChallengeRule = ChallengeFree
+	if _, _, err := s.ApplyMove(1, xyzAtCenter()); err != nil {
+		t.Fatalf("expected phony to be provisionally accepted, got %v", err)
+	}
+}
+
+func TestApplyMove_ChallengeRuleChecksGameEndingMove(t *testing.T) {
+	s := newTestGame("XYZ", "ABCDEFG")
+	s.ChallengeRule = ChallengeDouble
+	s.Bag = tiles("")
+	var invalid *InvalidWordError
+	if _, _, err := s.ApplyMove(1, xyzAtCenter()); !errors.As(err, &invalid) {
+		t.Fatalf("expected game ending phony to be rejected, got %v", err)
+	}
+}
+
+func TestChallenge_SuccessRestoresState(t *testing.T) {
+	s, mv := playPending(t, ChallengeDouble, xyzAtCenter())
+
+	next, res, err := s.Challenge(2, mv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Success || len(res.InvalidWords) != 1 || res.InvalidWords[0] != "XYZ" {
+		t.Fatalf("expected successful challenge on XYZ, got %+v", res)
+	}
+	if next.Board[7][7] != "" || next.Board[7][9] != "" {
+		t.Fatalf("expected tiles to be removed from the board")
+	}
+	if next.Racks[1].String() != "CHATXYZ" {
+		t.Fatalf("expected rack to be restored, got %q", next.Racks[1])
+	}
+	if next.Bag.String() != "EEEEEEEEEE" {
+		t.Fatalf("expected bag to be restored, got %q", next.Bag)
+	}
+	if next.Scores[1] != 0 {
+		t.Fatalf("expected score to be cancelled, got %d", next.Scores[1])
+	}
+	if next.Turn != 2 || next.PassCount != 2 {
+		t.Fatalf("expected challenger to keep the turn after a scoreless turn, got turn %d passes %d", next.Turn, next.PassCount)
+	}
+}
+
+func TestChallenge_FailedPenalties(t *testing.T) {
+	chat := chatAtCenter()
+
+	s, mv := playPending(t, ChallengeFree, chat)
+	next, res, err := s.Challenge(2, mv)
+	if err != nil || res.Success || res.Penalty != 0 || res.LostTurn {
+		t.Fatalf("free challenge should cost nothing: %+v %v", res, err)
+	}
+	if next.Board[7][7] != "A" || next.Turn != 2 {
+		t.Fatalf("expected move to stand and challenger to keep the turn")
+	}
+
+	s, mv = playPending(t, ChallengeSingle, chat)
+	next, res, err = s.Challenge(2, mv)
+	if err != nil || res.Penalty != ChallengePenalty || next.Scores[2] != -ChallengePenalty {
+		t.Fatalf("single challenge should cost %d points: %+v %v", ChallengePenalty, res, err)
+	}
+
+	s, mv = playPending(t, ChallengeDouble, chat)
+	next, res, err = s.Challenge(2, mv)
+	if err != nil || !res.LostTurn || next.Turn != 1 {
+		t.Fatalf("double challenge should cost a turn: %+v %v", res, err)
+	}
+}
+
+func TestChallenge_Errors(t *testing.T) {
+	s, mv := playPending(t, ChallengeFree, xyzAtCenter())
+	if _, _, err := s.Challenge(1, mv); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("expected ErrNotYourTurn, got %v", err)
+	}
+	s.Turn = 1
+	if _, _, err := s.Challenge(1, mv); !errors.Is(err, ErrChallengeOwnMove) {
+		t.Fatalf("expected ErrChallengeOwnMove, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// Modes de jeu.
+const (
+	ModeClassic = "classic"
+	// ModeDuplicate : tous les joueurs reçoivent le même tirage à chaque
+	// ronde et jouent chacun de leur côté ; seul le meilleur coup possible
+	// (le « top ») est posé sur le plateau commun.
+	ModeDuplicate = "duplicate"
+)
+
+// Composition minimale d'un tirage en duplicate. Le joker compte, au choix,
+// comme voyelle ou comme consonne.
+const (
+	DuplicateMinVowels     = 2
+	DuplicateMinConsonants = 2
+)
+
+// SharedRackDrawer est le joueur inscrit au journal des tirages pour le
+// tirage commun d'une partie en duplicate.
+const SharedRackDrawer int64 = 0
+
+// duplicateMaxRejects borne le nombre de tirages rejetés d'affilée.
+const duplicateMaxRejects = 1000
+
+var (
+	ErrInvalidMode   = errors.New("invalid game mode")
+	ErrDuplicateMode = errors.New("action not available in duplicate mode")
+	ErrNotDuplicate  = errors.New("game is not in duplicate mode")
+	// ErrRackExhausted : les lettres restantes ne permettent plus un tirage
+	// réglementaire, la partie est terminée.
+	ErrRackExhausted = errors.New("not enough vowels or consonants left for a duplicate rack")
+)
+
+// ValidMode indique si mode est un mode de jeu connu ("" vaut ModeClassic).
+func ValidMode(mode string) bool {
+	return mode == "" || mode == ModeClassic || mode == ModeDuplicate
+}
+
+// Duplicate indique si la partie se joue en duplicate.
+func (s *GameState) Duplicate() bool {
+	return s.Mode == ModeDuplicate
+}
+
+// SharedRack retourne le tirage commun de la ronde en cours (le même rack
+// est stocké pour chaque joueur).
+func (s *GameState) SharedRack() word.Tiles {
+	if len(s.Players) == 0 {
+		return nil
+	}
+	return s.Racks[s.Players[0]]
+}
+
+// setSharedRack donne rack à tous les joueurs.
+func (s *GameState) setSharedRack(rack word.Tiles) {
+	for _, pid := range s.Players {
+		s.Racks[pid] = append(word.Tiles{}, rack...)
+	}
+}
+
+// ValidDuplicateRack indique si rack compte au moins DuplicateMinVowels
+// voyelles et DuplicateMinConsonants consonnes, jokers compris.
+func ValidDuplicateRack(rack word.Tiles) bool {
+	return canFormDuplicateRack(rack, len(rack))
+}
+
+// canFormDuplicateRack indique si tiles contient n tuiles formant un tirage
+// réglementaire.
+func canFormDuplicateRack(tiles word.Tiles, n int) bool {
+	if n < DuplicateMinVowels+DuplicateMinConsonants || n > len(tiles) {
+		return false
+	}
+	vowels, consonants, blanks := 0, 0, 0
+	for _, t := range tiles {
+		switch {
+		case t == Blank:
+			blanks++
+		case t.IsVowel():
+			vowels++
+		default:
+			consonants++
+		}
+	}
+	return max(0, DuplicateMinVowels-vowels)+max(0, DuplicateMinConsonants-consonants) <= blanks
+}
+
+// DrawDuplicateRack complète le tirage commun avec des lettres du sac. Si le
+// reliquat complété ne compte pas assez de voyelles ou de consonnes, tout le
+// tirage retourne dans le sac et un nouveau rack complet est tiré, autant de
+// fois que nécessaire. ErrRackExhausted est retournée quand les lettres
+// restantes ne le permettent plus : la partie est alors terminée.
+func (s *GameState) DrawDuplicateRack() (*GameState, error) {
+	if !s.Duplicate() {
+		return nil, ErrNotDuplicate
+	}
+	if s.Ended {
+		return nil, ErrGameEnded
+	}
+	kept := s.SharedRack()
+	n := min(s.rules().RackSize, len(kept)+len(s.Bag))
+	if !canFormDuplicateRack(kept.Concat(s.Bag), n) {
+		return nil, ErrRackExhausted
+	}
+
+	next := s.Clone()
+	rack := kept.Concat(next.draw(SharedRackDrawer, n-len(kept)))
+	for rejects := 0; !ValidDuplicateRack(rack); rejects++ {
+		if rejects == duplicateMaxRejects {
+			return nil, ErrRackExhausted
+		}
+		next.returnToBag(rack)
+		rack = next.draw(SharedRackDrawer, n)
+	}
+	next.setSharedRack(rack)
+	return next, nil
+}
+
+// ReturnSharedRack remet tout le tirage commun dans le sac, quand il ne
+// permet aucun coup.
+func (s *GameState) ReturnSharedRack() *GameState {
+	next := s.Clone()
+	next.returnToBag(s.SharedRack())
+	next.setSharedRack(word.Tiles{})
+	return next
+}
+
+// ScoreDuplicate valide le coup letters joué avec le tirage commun (rack,
+// placement et mots formés) et calcule son score, sans modifier l'état.
+func (s *GameState) ScoreDuplicate(letters []request.PlacedLetter) (*MoveResult, error) {
+	if !s.Duplicate() {
+		return nil, ErrNotDuplicate
+	}
+	if s.Ended {
+		return nil, ErrGameEnded
+	}
+	rack := s.SharedRack()
+	resolved, err := ResolveBlanks(rack, letters)
+	if err != nil || !RackContains(rack, resolved) {
+		return nil, ErrMissingLetters
+	}
+	if len(resolved) == 0 {
+		return nil, ErrNoLetters
+	}
+	rules := s.rules()
+	if len(resolved) > rules.RackSize {
+		return nil, ErrTooManyLetters
+	}
+	if err := validatePlacement(s.Layout, s.Board, resolved); err != nil {
+		return nil, err
+	}
+
+	board := s.Board.Clone()
+	if err := ApplyLetters(board, resolved); err != nil {
+		return nil, err
+	}
+	if invalid := InvalidWords(s.Language, board, resolved); len(invalid) > 0 {
+		return nil, &InvalidWordError{Word: invalid[0]}
+	}
+	return &MoveResult{
+		Letters: resolved,
+		Words:   ScoreWords(s.Language, s.Layout, board, resolved, s.Blanks),
+		Score:   rules.MoveScore(s.Language, s.Layout, board, resolved, s.Blanks),
+	}, nil
+}
+
+// PlayDuplicateTop clôt la ronde : le top est posé sur le plateau, chaque
+// joueur marque les points de son propre coup (scores, 0 s'il n'a rien
+// joué) et le reliquat du tirage est gardé pour la ronde suivante (voir
+// DrawDuplicateRack). GameOver indique que le reliquat et le sac sont vides.
+func (s *GameState) PlayDuplicateTop(top []request.PlacedLetter, scores map[int64]int) (*GameState, *MoveResult, error) {
+	res, err := s.ScoreDuplicate(top)
+	if err != nil {
+		return nil, nil, err
+	}
+	leftover, err := RemoveFromRack(s.SharedRack(), res.Letters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := s.Clone()
+	if err := ApplyLetters(next.Board, res.Letters); err != nil {
+		return nil, nil, err
+	}
+	for _, pl := range res.Letters {
+		if pl.Blank {
+			next.Blanks[Pos{pl.X, pl.Y}] = true
+		}
+	}
+	next.setSharedRack(leftover)
+	for _, pid := range next.Players {
+		next.Scores[pid] += scores[pid]
+	}
+	res.GameOver = len(leftover) == 0 && len(next.Bag) == 0
+	return next, res, nil
+}
+
+// FinishDuplicate termine la partie : pas de décompte des lettres restantes,
+// le vainqueur est le meilleur total, le premier dans l'ordre de passage en
+// cas d'égalité.
+func (s *GameState) FinishDuplicate() (*GameState, *FinishResult) {
+	next := s.Clone()
+	next.Ended = true
+	res := &FinishResult{Penalties: map[int64]int{}}
+	for _, pid := range next.Players {
+		if res.WinnerID == 0 || next.Scores[pid] > next.Scores[res.WinnerID] {
+			res.WinnerID = pid
+		}
+	}
+	return next, res
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func newDuplicateGame(rack, bag string) *GameState {
+	s := newTestGame(rack, rack)
+	s.Mode = ModeDuplicate
+	s.Bag = tiles(bag)
+	return s
+}
+
+func TestValidDuplicateRack(t *testing.T) {
+	cases := map[string]bool{
+		"AEBCDFG": true,
+		"AEIOUBC": true,
+		"ABCDFGH": false, // une seule voyelle
+		"AEIOUYB": false, // une seule consonne
+		"A?BCDFG": true,  // le joker tient lieu de voyelle
+		"AEIOUY?": false,
+		"AEIOU??": true,
+		"AEB":     false,
+	}
+	for rack, want := range cases {
+		if got := ValidDuplicateRack(tiles(rack)); got != want {
+			t.Errorf("ValidDuplicateRack(%s) = %v; want %v", rack, got, want)
+		}
+	}
+}
+
+func TestDrawDuplicateRack_KeepsLeftoverAndEnforcesMix(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		s := newDuplicateGame("BC", "AEDFGHJKLMNP")
+		s.Rand = rand.New(rand.NewSource(seed))
+		next, err := s.DrawDuplicateRack()
+		if err != nil {
+			t.Fatalf("seed %d: unexpected error: %v", seed, err)
+		}
+		rack := next.SharedRack()
+		if len(rack) != RackSize || !ValidDuplicateRack(rack) {
+			t.Fatalf("seed %d: invalid rack %q", seed, rack)
+		}
+		if next.Racks[2].String() != rack.String() {
+			t.Fatalf("seed %d: racks differ: %q and %q", seed, rack, next.Racks[2])
+		}
+		if len(rack)+len(next.Bag) != 14 {
+			t.Fatalf("seed %d: tiles lost: rack %q, bag %q", seed, rack, next.Bag)
+		}
+		if s.SharedRack().String() != "BC" {
+			t.Fatalf("receiver modified: %q", s.SharedRack())
+		}
+	}
+}
+
+func TestDrawDuplicateRack_Exhausted(t *testing.T) {
+	s := newDuplicateGame("AB", "CDFGHJKLMN")
+	if _, err := s.DrawDuplicateRack(); !errors.Is(err, ErrRackExhausted) {
+		t.Fatalf("expected ErrRackExhausted, got %v", err)
+	}
+	if _, err := newTestGame("AB").DrawDuplicateRack(); !errors.Is(err, ErrNotDuplicate) {
+		t.Fatalf("expected ErrNotDuplicate, got %v", err)
+	}
+}
+
+func TestPlayDuplicateTop(t *testing.T) {
+	s := newDuplicateGame("CHATXYZ", "EEEE")
+
+	if _, _, err := s.ApplyMove(1, chatAtCenter()); !errors.Is(err, ErrDuplicateMode) {
+		t.Fatalf("expected ErrDuplicateMode, got %v", err)
+	}
+	res, err := s.ScoreDuplicate(chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Score != 18 {
+		t.Fatalf("expected 18, got %d", res.Score)
+	}
+
+	next, top, err := s.PlayDuplicateTop(chatAtCenter(), map[int64]int{1: 18, 2: 6})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if top.Score != 18 || top.GameOver {
+		t.Fatalf("unexpected top result: %+v", top)
+	}
+	if next.Board[7][7] != "A" || s.Board[7][7] != "" {
+		t.Fatalf("top not placed on a new board")
+	}
+	for _, pid := range []int64{1, 2} {
+		if next.Racks[pid].String() != "XYZ" {
+			t.Fatalf("expected leftover XYZ for player %d, got %q", pid, next.Racks[pid])
+		}
+	}
+	if next.Scores[1] != 18 || next.Scores[2] != 6 {
+		t.Fatalf("unexpected scores: %v", next.Scores)
+	}
+	if len(next.Bag) != 4 {
+		t.Fatalf("bag should be untouched until the next draw, got %q", next.Bag)
+	}
+
+	final, fin := next.FinishDuplicate()
+	if !final.Ended || fin.WinnerID != 1 || len(fin.Penalties) != 0 {
+		t.Fatalf("unexpected finish: %+v", fin)
+	}
+}
+
+func TestSeat_DuplicateDrawsNoRack(t *testing.T) {
+	s := &GameState{
+		Mode:   ModeDuplicate,
+		Racks:  map[int64]word.Tiles{},
+		Scores: map[int64]int{},
+		Bag:    tiles("ABCDEFGHIJ"),
+	}
+	next, err := s.Seat(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.Racks[7]) != 0 || len(next.Bag) != 10 {
+		t.Fatalf("expected no draw, got rack %q and bag %q", next.Racks[7], next.Bag)
+	}
+}
+
+func TestResign_DuplicateKeepsSharedRack(t *testing.T) {
+	s := newTestGame("CHAT", "CHAT", "CHAT")
+	s.Mode = ModeDuplicate
+	next, res, err := s.Resign(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Returned) != 0 || len(next.Bag) != len(s.Bag) {
+		t.Fatalf("shared rack returned to the bag: %q", next.Bag)
+	}
+	if next.SharedRack().String() != "CHAT" {
+		t.Fatalf("unexpected shared rack %q", next.SharedRack())
+	}
+}
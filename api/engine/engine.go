@@ -0,0 +1,546 @@
+// Package engine implémente les règles du Scrabble (pose de mots, score,
+// rack, sac, tours de jeu et fin de partie) sans aucun accès à la base de
+// données. Les services chargent un GameState, appellent l'une de ses
+// méthodes puis persistent l'état retourné.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+const (
+	RackSize   = 7
+	BingoBonus = 50
+
+	// Blank est la tuile joker dans les racks et le sac.
+	Blank = word.BlankTile
+)
+
+var (
+	ErrGameEnded       = errors.New("game is not ongoing")
+	ErrNotYourTurn     = errors.New("not your turn")
+	ErrNotInGame       = errors.New("player not in game")
+	ErrNoLetters       = errors.New("no letters provided")
+	ErrTooManyLetters  = errors.New("cannot place more letters than the rack holds in one move")
+	ErrMissingLetters  = errors.New("invalid move: you don't have the required letters")
+	ErrNotAligned      = errors.New("letters must be aligned in the same row or column")
+	ErrFirstMoveCenter = errors.New("first move must cover the center cell")
+	ErrNotConnected    = errors.New("word must connect to existing letters")
+	ErrBagEmpty        = errors.New("no letters left in the bag")
+	ErrBagTooSmall     = errors.New("not enough letters left in the bag to exchange")
+	ErrNoTiles         = errors.New("no tiles to exchange")
+)
+
+// InvalidWordError est retournée quand un coup forme un mot absent du dictionnaire.
+type InvalidWordError struct {
+	Word string
+}
+
+func (e *InvalidWordError) Error() string {
+	return fmt.Sprintf("invalid word played: %s", e.Word)
+}
+
+// Pos identifie une case du plateau.
+type Pos struct{ X, Y int }
+
+// GameState est l'état complet d'une partie, indépendant de son stockage.
+// Les méthodes ne modifient jamais le receveur : elles retournent un nouvel état.
+type GameState struct {
+	Board     Board
+	Blanks    map[Pos]bool         // positions des jokers déjà posés
+	Racks     map[int64]word.Tiles // rack de chaque joueur
+	Scores    map[int64]int        // score de chaque joueur
+	Bag       word.Tiles           // tuiles restantes dans le sac
+	Players   []int64              // ordre de passage
+	Turn      int64                // joueur dont c'est le tour
+	PassCount int                  // nombre de passes consécutives
+	Ended     bool
+
+	// ChallengeRule est la règle de contestation de la partie (ChallengeNone,
+	// ChallengeFree, ChallengeSingle ou ChallengeDouble).
+	ChallengeRule string
+	// Rules sont les règles configurables de la partie ; la valeur zéro
+	// équivaut à DefaultRuleset().
+	Rules Ruleset
+	// Language est le pack de langue de la partie (dictionnaire et valeur des
+	// lettres) ; nil équivaut à word.French.
+	Language *word.Language
+	// Layout est la disposition du plateau (taille, centre et cases
+	// spéciales) ; nil équivaut à StandardLayout.
+	Layout *Layout
+	// Mode est le mode de jeu (ModeClassic ou ModeDuplicate) ; "" équivaut à
+	// ModeClassic.
+	Mode string
+
+	// Rand est la source utilisée pour les tirages ; nil = source globale.
+	// Elle est ignorée quand la partie a une graine.
+	Rand *rand.Rand
+	// Seed est la graine de la partie : le tirage numéro DrawCount utilise
+	// DrawRand(Seed, DrawCount). Nil pour les parties antérieures aux tirages
+	// vérifiables.
+	Seed      []byte
+	DrawCount int
+	// Draws sont les tirages effectués depuis le chargement de l'état, à
+	// journaliser par le service.
+	Draws []Draw
+	// BagChanges sont les autres modifications du sac depuis le chargement de
+	// l'état (tuiles remises ou reprises), à journaliser avec les tirages.
+	BagChanges []BagChange
+}
+
+// MoveResult décrit le résultat d'un coup joué.
+type MoveResult struct {
+	Letters  []request.PlacedLetter // lettres posées, jokers résolus
+	Words    []ScoredWord
+	Score    int
+	Drawn    word.Tiles
+	NextTurn int64
+	GameOver bool // rack et sac vides : la partie doit être terminée
+
+	// Pending indique que les mots n'ont pas été vérifiés : le coup est accepté
+	// provisoirement et peut être contesté par le joueur suivant.
+	Pending bool
+	// PrevPassCount est le compteur de passes avant le coup, nécessaire pour
+	// annuler un coup contesté.
+	PrevPassCount int
+}
+
+// ExchangeResult décrit le résultat d'un échange de lettres.
+type ExchangeResult struct {
+	Returned word.Tiles
+	Drawn    word.Tiles
+	NextTurn int64
+	GameOver bool // trop de tours sans score : la partie doit être terminée
+}
+
+// PassResult décrit le résultat d'un tour passé.
+type PassResult struct {
+	NextTurn  int64
+	PassCount int
+	GameOver  bool // trop de passes consécutives : la partie doit être terminée
+}
+
+// ResignResult décrit l'abandon d'un joueur.
+type ResignResult struct {
+	Returned word.Tiles // tuiles du joueur remises dans le sac
+	NextTurn int64
+	// Finish est renseigné quand l'abandon termine la partie (il ne restait
+	// qu'un adversaire) ; le joueur a alors perdu par forfait.
+	Finish *FinishResult
+}
+
+// FinishResult décrit les ajustements de fin de partie.
+type FinishResult struct {
+	Penalties map[int64]int // points de rack retirés à chaque joueur
+	Bonus     int           // points ajoutés au joueur qui a fini
+	WinnerID  int64         // 0 si aucun joueur ne peut gagner
+	Forfeited int64         // joueur ayant perdu par forfait, 0 sinon
+}
+
+// NewGame crée l'état initial d'une partie sur un plateau vide de la
+// disposition layout (standard si nil) : sac mélangé et racks distribués dans
+// l'ordre des joueurs. Le premier joueur commence.
+func NewGame(players []int64, layout *Layout, bag word.Tiles, rules Ruleset, rng *rand.Rand) *GameState {
+	return newGame(players, layout, bag, rules, rng, nil)
+}
+
+// NewSeededGame est NewGame pour une partie dont tous les tirages, racks de
+// départ compris, sont dérivés de seed (voir DrawRand).
+func NewSeededGame(players []int64, layout *Layout, bag word.Tiles, rules Ruleset, seed []byte) *GameState {
+	return newGame(players, layout, bag, rules, nil, seed)
+}
+
+func newGame(players []int64, layout *Layout, bag word.Tiles, rules Ruleset, rng *rand.Rand, seed []byte) *GameState {
+	layout = layoutOrDefault(layout)
+	s := &GameState{
+		Board:   layout.NewBoard(),
+		Layout:  layout,
+		Blanks:  map[Pos]bool{},
+		Racks:   make(map[int64]word.Tiles, len(players)),
+		Scores:  make(map[int64]int, len(players)),
+		Bag:     bag,
+		Players: append([]int64(nil), players...),
+		Rules:   rules,
+		Rand:    rng,
+		Seed:    seed,
+	}
+	for _, pid := range players {
+		s.Racks[pid] = s.draw(pid, s.rules().RackSize)
+		s.Scores[pid] = 0
+	}
+	if len(players) > 0 {
+		s.Turn = players[0]
+	}
+	return s
+}
+
+// Clone retourne une copie profonde de l'état.
+func (s *GameState) Clone() *GameState {
+	c := *s
+	c.Board = s.Board.Clone()
+	c.Blanks = make(map[Pos]bool, len(s.Blanks))
+	for p, b := range s.Blanks {
+		c.Blanks[p] = b
+	}
+	c.Racks = make(map[int64]word.Tiles, len(s.Racks))
+	for pid, r := range s.Racks {
+		c.Racks[pid] = append(word.Tiles{}, r...)
+	}
+	c.Bag = append(word.Tiles{}, s.Bag...)
+	c.Scores = make(map[int64]int, len(s.Scores))
+	for pid, sc := range s.Scores {
+		c.Scores[pid] = sc
+	}
+	c.Players = append([]int64(nil), s.Players...)
+	c.Draws = append([]Draw(nil), s.Draws...)
+	c.BagChanges = append([]BagChange(nil), s.BagChanges...)
+	return &c
+}
+
+// NextPlayer retourne le joueur qui suit playerID dans l'ordre de passage.
+func (s *GameState) NextPlayer(playerID int64) int64 {
+	for i, pid := range s.Players {
+		if pid == playerID {
+			return s.Players[(i+1)%len(s.Players)]
+		}
+	}
+	return playerID
+}
+
+// rules retourne les règles de la partie (DefaultRuleset si non renseignées).
+func (s *GameState) rules() Ruleset {
+	if s.Rules == (Ruleset{}) {
+		return DefaultRuleset()
+	}
+	return s.Rules
+}
+
+// scorelessLimitReached indique si le nombre de tours consécutifs sans score
+// termine la partie.
+func (s *GameState) scorelessLimitReached() bool {
+	return s.PassCount >= s.rules().scorelessLimit(len(s.Players))
+}
+
+func (s *GameState) checkTurn(playerID int64) error {
+	if s.Ended {
+		return ErrGameEnded
+	}
+	if s.Duplicate() {
+		return ErrDuplicateMode
+	}
+	if _, ok := s.Racks[playerID]; !ok {
+		return ErrNotInGame
+	}
+	if s.Turn != playerID {
+		return ErrNotYourTurn
+	}
+	return nil
+}
+
+// ApplyMove valide et joue un coup pour playerID : contrôle du rack, du
+// placement et des mots formés, calcul du score, puis tirage de nouvelles lettres.
+func (s *GameState) ApplyMove(playerID int64, letters []request.PlacedLetter) (*GameState, *MoveResult, error) {
+	if err := s.checkTurn(playerID); err != nil {
+		return nil, nil, err
+	}
+
+	rack := s.Racks[playerID]
+	// Déduction automatique des jokers si le client ne les a pas marqués
+	resolved, err := ResolveBlanks(rack, letters)
+	if err != nil || !RackContains(rack, resolved) {
+		return nil, nil, ErrMissingLetters
+	}
+	if len(resolved) == 0 {
+		return nil, nil, ErrNoLetters
+	}
+	rules := s.rules()
+	if len(resolved) > rules.RackSize {
+		return nil, nil, ErrTooManyLetters
+	}
+
+	if err := validatePlacement(s.Layout, s.Board, resolved); err != nil {
+		return nil, nil, err
+	}
+
+	next := s.Clone()
+	if err := ApplyLetters(next.Board, resolved); err != nil {
+		return nil, nil, err
+	}
+
+	words := ScoreWords(s.Language, s.Layout, next.Board, resolved, s.Blanks)
+	score := rules.MoveScore(s.Language, s.Layout, next.Board, resolved, s.Blanks)
+	for _, pl := range resolved {
+		if pl.Blank {
+			next.Blanks[Pos{pl.X, pl.Y}] = true
+		}
+	}
+
+	newRack, err := RemoveFromRack(rack, resolved)
+	if err != nil {
+		return nil, nil, err
+	}
+	drawn := next.draw(playerID, rules.RackSize-len(newRack))
+	next.Racks[playerID] = newRack.Concat(drawn)
+	next.Scores[playerID] += score
+	next.PassCount = 0
+
+	res := &MoveResult{
+		Letters:       resolved,
+		Words:         words,
+		Score:         score,
+		Drawn:         drawn,
+		GameOver:      len(next.Racks[playerID]) == 0 && len(next.Bag) == 0,
+		PrevPassCount: s.PassCount,
+	}
+
+	// Avec contestation, les mots ne sont vérifiés que sur demande d'un
+	// adversaire. Le coup qui termine la partie est toujours vérifié car il
+	// ne laisse plus de fenêtre de contestation.
+	res.Pending = s.ChallengeRule != "" && s.ChallengeRule != ChallengeNone && !res.GameOver
+	if !res.Pending {
+		if invalid := InvalidWords(s.Language, next.Board, resolved); len(invalid) > 0 {
+			return nil, nil, &InvalidWordError{Word: invalid[0]}
+		}
+	}
+	if !res.GameOver {
+		next.Turn = next.NextPlayer(playerID)
+	}
+	res.NextTurn = next.Turn
+
+	return next, res, nil
+}
+
+// ScoreMove calcule le score qu'obtiendraient les lettres posées, sans
+// vérifier le tour ni les mots formés. Les jokers sont déduits du rack de
+// playerID lorsque c'est possible.
+func (s *GameState) ScoreMove(playerID int64, letters []request.PlacedLetter) (int, error) {
+	if len(letters) == 0 {
+		return 0, nil
+	}
+	if rack, ok := s.Racks[playerID]; ok {
+		if rl, err := ResolveBlanks(rack, letters); err == nil {
+			letters = rl
+		}
+	}
+
+	board := s.Board.Clone()
+	if err := ApplyLetters(board, letters); err != nil {
+		return 0, err
+	}
+	return s.rules().MoveScore(s.Language, s.Layout, board, letters, s.Blanks), nil
+}
+
+// Exchange remet les tuiles indiquées dans le sac et complète le rack de
+// playerID avec de nouvelles lettres, puis passe au joueur suivant.
+// Conformément à la règle officielle, l'échange est refusé s'il reste moins
+// de lettres dans le sac que la taille du rack, sauf si Rules.SmallBagExchange
+// l'autorise. Un échange compte comme un tour sans score.
+func (s *GameState) Exchange(playerID int64, tiles word.Tiles) (*GameState, *ExchangeResult, error) {
+	if err := s.checkTurn(playerID); err != nil {
+		return nil, nil, err
+	}
+	if len(tiles) == 0 {
+		return nil, nil, ErrNoTiles
+	}
+	if len(s.Bag) == 0 {
+		return nil, nil, ErrBagEmpty
+	}
+	rules := s.rules()
+	bagSize := len(s.Bag)
+	if (!rules.SmallBagExchange && bagSize < rules.RackSize) || len(tiles) > bagSize {
+		return nil, nil, ErrBagTooSmall
+	}
+
+	rack := s.Racks[playerID]
+	for _, t := range tiles {
+		var ok bool
+		if rack, ok = rack.Remove(t); !ok {
+			return nil, nil, fmt.Errorf("letter %s not in rack", t)
+		}
+	}
+
+	next := s.Clone()
+	drawn := next.draw(playerID, rules.RackSize-len(rack))
+	next.returnToBag(tiles)
+	next.Racks[playerID] = rack.Concat(drawn)
+	next.PassCount++
+	next.Turn = next.NextPlayer(playerID)
+
+	return next, &ExchangeResult{
+		Returned: tiles,
+		Drawn:    drawn,
+		NextTurn: next.Turn,
+		GameOver: next.scorelessLimitReached(),
+	}, nil
+}
+
+// Pass fait passer son tour à playerID. La partie doit être terminée quand
+// la limite de tours consécutifs sans score est atteinte (par défaut, deux
+// par joueur).
+func (s *GameState) Pass(playerID int64) (*GameState, *PassResult, error) {
+	if err := s.checkTurn(playerID); err != nil {
+		return nil, nil, err
+	}
+
+	next := s.Clone()
+	next.PassCount++
+	next.Turn = next.NextPlayer(playerID)
+
+	return next, &PassResult{
+		NextTurn:  next.Turn,
+		PassCount: next.PassCount,
+		GameOver:  next.scorelessLimitReached(),
+	}, nil
+}
+
+// Finish termine la partie en décomptant les lettres restantes selon
+// Rules.Leftover : avec LeftoverStandard, chaque joueur perd la valeur de son
+// rack et lastPlayerID (0 si la partie se termine sur des passes) récupère la
+// somme des racks adverses. Le vainqueur est le meilleur score, le premier dans
+// l'ordre de passage en cas d'égalité.
+func (s *GameState) Finish(lastPlayerID int64) (*GameState, *FinishResult) {
+	next := s.Clone()
+	res := &FinishResult{Penalties: make(map[int64]int, len(s.Players))}
+	leftover := s.rules().Leftover
+
+	if leftover != LeftoverNone {
+		for _, pid := range next.Players {
+			lp := RackPoints(s.Language, next.Racks[pid])
+			res.Penalties[pid] = lp
+			next.Scores[pid] -= lp
+			if pid != lastPlayerID {
+				res.Bonus += lp
+			}
+		}
+	}
+	if lastPlayerID != 0 && leftover == LeftoverStandard {
+		next.Scores[lastPlayerID] += res.Bonus
+	} else {
+		res.Bonus = 0
+	}
+
+	for i, pid := range next.Players {
+		if i == 0 || next.Scores[pid] > next.Scores[res.WinnerID] {
+			res.WinnerID = pid
+		}
+	}
+	next.Ended = true
+
+	return next, res
+}
+
+// Forfeit termine la partie par forfait de playerID : les scores sont figés
+// sans décompte des racks et le vainqueur est le meilleur score parmi les
+// autres joueurs (le premier dans l'ordre de passage en cas d'égalité).
+func (s *GameState) Forfeit(playerID int64) (*GameState, *FinishResult, error) {
+	if s.Ended {
+		return nil, nil, ErrGameEnded
+	}
+	if _, ok := s.Racks[playerID]; !ok {
+		return nil, nil, ErrNotInGame
+	}
+
+	next := s.Clone()
+	res := &FinishResult{Penalties: map[int64]int{}, Forfeited: playerID}
+	for _, pid := range next.Players {
+		if pid == playerID {
+			continue
+		}
+		if res.WinnerID == 0 || next.Scores[pid] > next.Scores[res.WinnerID] {
+			res.WinnerID = pid
+		}
+	}
+	next.Ended = true
+
+	return next, res, nil
+}
+
+// Resign fait abandonner playerID, à tout moment de la partie. Face à un seul
+// adversaire, la partie se termine par forfait (voir Forfeit). Sinon le joueur
+// quitte l'ordre de passage, ses tuiles retournent dans le sac (sauf en
+// duplicate) et la partie continue ; si c'était son tour, la main passe au
+// joueur suivant.
+func (s *GameState) Resign(playerID int64) (*GameState, *ResignResult, error) {
+	if s.Ended {
+		return nil, nil, ErrGameEnded
+	}
+	if _, ok := s.Racks[playerID]; !ok {
+		return nil, nil, ErrNotInGame
+	}
+
+	if len(s.Players) <= 2 {
+		final, fin, err := s.Forfeit(playerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return final, &ResignResult{Finish: fin}, nil
+	}
+
+	next := s.Clone()
+	// en duplicate, le tirage commun reste aux autres joueurs
+	var returned word.Tiles
+	if !s.Duplicate() {
+		returned = next.Racks[playerID]
+		next.returnToBag(returned)
+	}
+	delete(next.Racks, playerID)
+	if s.Turn == playerID {
+		next.Turn = s.NextPlayer(playerID)
+	}
+	next.Players = next.Players[:0]
+	for _, pid := range s.Players {
+		if pid != playerID {
+			next.Players = append(next.Players, pid)
+		}
+	}
+
+	return next, &ResignResult{Returned: returned, NextTurn: next.Turn}, nil
+}
+
+// draw tire n lettres au hasard dans le sac pour playerID (moins si le sac
+// est presque vide). Dans une partie avec graine, le sac est d'abord remis
+// dans l'ordre canonique (voir word.Tiles.Sorted), le tirage est dérivé de la
+// graine et ajouté à Draws.
+func (s *GameState) draw(playerID int64, n int) word.Tiles {
+	if s.Seed == nil {
+		return DrawTiles(&s.Bag, n, s.Rand)
+	}
+	s.Bag = s.Bag.Sorted()
+	before := s.Bag
+	drawn := DrawTiles(&s.Bag, n, DrawRand(s.Seed, s.DrawCount))
+	s.Draws = append(s.Draws, Draw{Seq: s.DrawCount, PlayerID: playerID, Bag: before, Tiles: drawn})
+	s.DrawCount++
+	return drawn
+}
+
+// DrawTiles tire n tuiles au hasard dans bag et les en retire. Si rng est
+// nil, la source globale de math/rand est utilisée. La tranche d'origine
+// n'est jamais modifiée : *bag est remplacé par une nouvelle tranche.
+func DrawTiles(bag *word.Tiles, n int, rng *rand.Rand) word.Tiles {
+	available := append(word.Tiles{}, (*bag)...)
+	if n > len(available) {
+		n = len(available)
+	}
+	if n <= 0 {
+		return word.Tiles{}
+	}
+
+	intn := rand.Intn
+	if rng != nil {
+		intn = rng.Intn
+	}
+
+	drawn := make(word.Tiles, 0, n)
+	for i := 0; i < n; i++ {
+		idx := intn(len(available))
+		drawn = append(drawn, available[idx])
+		available = append(available[:idx], available[idx+1:]...)
+	}
+	*bag = available
+	return drawn
+}
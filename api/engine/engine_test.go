@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func newTestGame(racks ...string) *GameState {
+	s := &GameState{
+		Board:  StandardLayout.NewBoard(),
+		Blanks: map[Pos]bool{},
+		Racks:  map[int64]word.Tiles{},
+		Scores: map[int64]int{},
+		Bag:    tiles("EEEEEEEEEE"),
+		Rand:   rand.New(rand.NewSource(1)),
+	}
+	for i, r := range racks {
+		pid := int64(i + 1)
+		s.Players = append(s.Players, pid)
+		s.Racks[pid] = tiles(r)
+		s.Scores[pid] = 0
+	}
+	s.Turn = 1
+	return s
+}
+
+// tiles convertit une chaîne en tuiles d'une lettre.
+func tiles(s string) word.Tiles {
+	return word.ParseTiles(s)
+}
+
+func chatAtCenter() []request.PlacedLetter {
+	return []request.PlacedLetter{
+		{X: 5, Y: 7, Char: "C"},
+		{X: 6, Y: 7, Char: "H"},
+		{X: 7, Y: 7, Char: "A"},
+		{X: 8, Y: 7, Char: "T"},
+	}
+}
+
+func TestNewGame_DealsRacks(t *testing.T) {
+	s := NewGame([]int64{10, 20}, nil, word.French.Bag, DefaultRuleset(), rand.New(rand.NewSource(1)))
+	if len(s.Racks[10]) != RackSize || len(s.Racks[20]) != RackSize {
+		t.Fatalf("expected two racks of %d tiles, got %q and %q", RackSize, s.Racks[10], s.Racks[20])
+	}
+	if len(s.Bag) != len(word.French.Bag)-2*RackSize {
+		t.Fatalf("expected bag of %d tiles, got %d", len(word.French.Bag)-2*RackSize, len(s.Bag))
+	}
+	if s.Turn != 10 {
+		t.Fatalf("expected first player to start, got %d", s.Turn)
+	}
+}
+
+func TestApplyMove_FirstMove(t *testing.T) {
+	s := newTestGame("CHATXYZ", "ABCDEFG")
+	next, res, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// C=3 H=4 A=1 T=1, centre ★ => mot x2
+	if res.Score != 18 {
+		t.Fatalf("expected 18, got %d", res.Score)
+	}
+	if len(res.Words) != 1 || res.Words[0].Word != "CHAT" || res.Words[0].Score != 18 {
+		t.Fatalf("unexpected words breakdown: %+v", res.Words)
+	}
+	if next.Racks[1].String() != "XYZEEEE" {
+		t.Fatalf("expected rack refilled from bag, got %q", next.Racks[1])
+	}
+	if next.Turn != 2 || res.NextTurn != 2 {
+		t.Fatalf("expected turn to pass to player 2, got %d", next.Turn)
+	}
+	if next.Scores[1] != 18 {
+		t.Fatalf("expected score 18, got %d", next.Scores[1])
+	}
+	// l'état d'origine ne doit pas être modifié
+	if s.Board[7][7] != "" || s.Racks[1].String() != "CHATXYZ" || s.Turn != 1 {
+		t.Fatalf("original state was mutated")
+	}
+}
+
+func TestApplyMove_Errors(t *testing.T) {
+	s := newTestGame("CHATXYZ", "ABCDEFG")
+
+	if _, _, err := s.ApplyMove(2, chatAtCenter()); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("expected ErrNotYourTurn, got %v", err)
+	}
+	if _, _, err := s.ApplyMove(1, nil); !errors.Is(err, ErrNoLetters) {
+		t.Fatalf("expected ErrNoLetters, got %v", err)
+	}
+	if _, _, err := s.ApplyMove(1, []request.PlacedLetter{{X: 7, Y: 7, Char: "Q"}}); !errors.Is(err, ErrMissingLetters) {
+		t.Fatalf("expected ErrMissingLetters, got %v", err)
+	}
+	if _, _, err := s.ApplyMove(1, []request.PlacedLetter{{X: 6, Y: 7, Char: "C"}, {X: 7, Y: 8, Char: "H"}}); !errors.Is(err, ErrNotAligned) {
+		t.Fatalf("expected ErrNotAligned, got %v", err)
+	}
+	if _, _, err := s.ApplyMove(1, []request.PlacedLetter{{X: 0, Y: 0, Char: "C"}}); !errors.Is(err, ErrFirstMoveCenter) {
+		t.Fatalf("expected ErrFirstMoveCenter, got %v", err)
+	}
+
+	var invalid *InvalidWordError
+	_, _, err := s.ApplyMove(1, []request.PlacedLetter{{X: 7, Y: 7, Char: "X"}, {X: 8, Y: 7, Char: "Y"}, {X: 9, Y: 7, Char: "Z"}})
+	if !errors.As(err, &invalid) || invalid.Word != "XYZ" {
+		t.Fatalf("expected InvalidWordError for XYZ, got %v", err)
+	}
+
+	played, _, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	played.Racks[2] = tiles("BBBBBBB")
+	if _, _, err := played.ApplyMove(2, []request.PlacedLetter{{X: 0, Y: 0, Char: "B"}}); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("expected ErrNotConnected, got %v", err)
+	}
+}
+
+func TestApplyMove_BlankResolvedAndRemembered(t *testing.T) {
+	s := newTestGame("CHA?XYZ", "ABCDEFG")
+	next, res, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Letters[3].Blank {
+		t.Fatalf("expected T to be played with the blank")
+	}
+	// C=3 H=4 A=1 T(joker)=0, centre ★ => mot x2
+	if res.Score != 16 {
+		t.Fatalf("expected 16, got %d", res.Score)
+	}
+	if !next.Blanks[Pos{8, 7}] {
+		t.Fatalf("expected blank position to be recorded in the state")
+	}
+}
+
+func TestApplyMove_GameOverWhenRackAndBagEmpty(t *testing.T) {
+	s := newTestGame("CHAT", "ABCDEFG")
+	s.Bag = tiles("")
+	next, res, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.GameOver {
+		t.Fatalf("expected game over")
+	}
+	if next.Turn != 1 {
+		t.Fatalf("expected turn to stay on the finisher, got %d", next.Turn)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	s := newTestGame("ABCDEFG", "HIJKLMN")
+	next, res, err := s.Exchange(1, tiles("ABC"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.Racks[1]) != RackSize || res.Drawn.String() != "EEE" {
+		t.Fatalf("expected rack refilled with EEE, got rack %q drawn %q", next.Racks[1], res.Drawn)
+	}
+	if len(next.Bag) != len(s.Bag) {
+		t.Fatalf("expected bag size to be unchanged, got %d", len(next.Bag))
+	}
+	if next.Turn != 2 {
+		t.Fatalf("expected turn to pass to player 2")
+	}
+
+	if _, _, err := s.Exchange(1, tiles("Z")); err == nil {
+		t.Fatalf("expected error when exchanging a tile not in rack")
+	}
+	if _, _, err := s.Exchange(1, tiles("")); !errors.Is(err, ErrNoTiles) {
+		t.Fatalf("expected ErrNoTiles, got %v", err)
+	}
+	s.Bag = tiles("EEEEEE")
+	if _, _, err := s.Exchange(1, tiles("A")); !errors.Is(err, ErrBagTooSmall) {
+		t.Fatalf("expected ErrBagTooSmall, got %v", err)
+	}
+	s.Bag = tiles("")
+	if _, _, err := s.Exchange(1, tiles("A")); !errors.Is(err, ErrBagEmpty) {
+		t.Fatalf("expected ErrBagEmpty, got %v", err)
+	}
+}
+
+func TestExchange_KeepsBlankAndLeave(t *testing.T) {
+	s := newTestGame("A?KWEST", "HIJKLMN")
+	next, res, err := s.Exchange(1, tiles("KW"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.Racks[1].String() != "A?ESTEE" || res.Drawn.String() != "EE" || res.Returned.String() != "KW" {
+		t.Fatalf("unexpected exchange result: rack %q %+v", next.Racks[1], res)
+	}
+	if !strings.HasSuffix(next.Bag.String(), "KW") {
+		t.Fatalf("expected returned tiles back in the bag, got %q", next.Bag)
+	}
+
+	if _, _, err := s.Exchange(1, tiles("??")); err == nil {
+		t.Fatalf("expected error when exchanging more blanks than held")
+	}
+}
+
+func TestPass_GameOverAfterTwoRounds(t *testing.T) {
+	s := newTestGame("ABCDEFG", "HIJKLMN")
+	var res *PassResult
+	var err error
+	for i := 0; i < 4; i++ {
+		s, res, err = s.Pass(s.Turn)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if i < 3 && res.GameOver {
+			t.Fatalf("game ended too early after %d passes", i+1)
+		}
+	}
+	if !res.GameOver || res.PassCount != 4 {
+		t.Fatalf("expected game over after 4 passes, got %+v", res)
+	}
+}
+
+func TestFinish_LeftoverPenaltiesAndWinner(t *testing.T) {
+	s := newTestGame("", "KZ")
+	s.Scores[1] = 10
+	s.Scores[2] = 25
+	final, res := s.Finish(1)
+	// K=10 Z=10 : le joueur 2 perd 20, le joueur 1 les récupère
+	if res.Penalties[2] != 20 || res.Bonus != 20 {
+		t.Fatalf("unexpected penalties %+v", res)
+	}
+	if final.Scores[1] != 30 || final.Scores[2] != 5 {
+		t.Fatalf("unexpected final scores %+v", final.Scores)
+	}
+	if res.WinnerID != 1 || !final.Ended {
+		t.Fatalf("expected player 1 to win, got %d", res.WinnerID)
+	}
+	if _, _, err := final.Pass(1); !errors.Is(err, ErrGameEnded) {
+		t.Fatalf("expected ErrGameEnded, got %v", err)
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	s := newTestGame("KZ", "A", "B")
+	s.Scores[1] = 90
+	s.Scores[2] = 40
+	s.Scores[3] = 40
+	final, res, err := s.Forfeit(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// pas de décompte des racks, le meilleur adversaire gagne malgré l'écart
+	if res.WinnerID != 2 || res.Forfeited != 1 || final.Scores[1] != 90 || !final.Ended {
+		t.Fatalf("unexpected forfeit result %+v, scores %+v", res, final.Scores)
+	}
+	if s.Ended {
+		t.Fatalf("Forfeit must not modify the receiver")
+	}
+	if _, _, err := final.Forfeit(2); !errors.Is(err, ErrGameEnded) {
+		t.Fatalf("expected ErrGameEnded, got %v", err)
+	}
+	if _, _, err := s.Forfeit(42); !errors.Is(err, ErrNotInGame) {
+		t.Fatalf("expected ErrNotInGame, got %v", err)
+	}
+}
+
+func TestResign_HeadToHead(t *testing.T) {
+	s := newTestGame("KZ", "A")
+	s.Scores[1] = 50
+	final, res, err := s.Resign(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Finish == nil || res.Finish.WinnerID != 2 || res.Finish.Forfeited != 1 || !final.Ended {
+		t.Fatalf("expected the opponent to win by forfeit, got %+v", res.Finish)
+	}
+}
+
+func TestResign_Multiplayer(t *testing.T) {
+	s := newTestGame("KZ", "AB", "CD")
+	next, res, err := s.Resign(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Finish != nil || next.Ended {
+		t.Fatalf("game should continue")
+	}
+	if res.Returned.String() != "KZ" || len(next.Bag) != len(s.Bag)+2 {
+		t.Fatalf("expected KZ back in the bag, got %q (bag %d)", res.Returned, len(next.Bag))
+	}
+	if len(next.Players) != 2 || next.Turn != 2 || res.NextTurn != 2 {
+		t.Fatalf("expected player 2 to play next among %v, got %d", next.Players, next.Turn)
+	}
+	if _, _, err := next.Pass(1); !errors.Is(err, ErrNotInGame) {
+		t.Fatalf("expected ErrNotInGame for the resigned player, got %v", err)
+	}
+	if len(s.Players) != 3 || len(s.Racks) != 3 {
+		t.Fatalf("Resign must not modify the receiver")
+	}
+
+	// le tour ne change pas quand un autre joueur abandonne
+	next, _, err = next.Resign(3)
+	if err != nil || next.Turn != 2 || !next.Ended {
+		t.Fatalf("expected the last opponent's resignation to end the game, got %v", err)
+	}
+}
+
+func TestApplyMove_MultiLetterTile(t *testing.T) {
+	lang := &word.Language{
+		Code:         "xx",
+		LetterValues: map[string]int{"CH": 5, "A": 1, "O": 1},
+		Digraphs:     []word.Tile{"CH"},
+	}
+	s := newTestGame("", "ABCDEFG")
+	s.Racks[1] = word.Tiles{"CH", "A", "O", "O", "O", "O", "O"}
+	s.Language = lang
+	// pas de dictionnaire pour cette langue : le coup est accepté provisoirement
+	s.ChallengeRule = ChallengeFree
+
+	next, res, err := s.ApplyMove(1, []request.PlacedLetter{
+		{X: 7, Y: 7, Char: "CH"},
+		{X: 8, Y: 7, Char: "A"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// CH=5 A=1, centre ★ => mot x2
+	if res.Score != 12 || len(res.Words) != 1 || res.Words[0].Word != "CHA" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if next.Racks[1].Index("CH") != -1 || len(next.Racks[1]) != RackSize {
+		t.Fatalf("expected the CH tile to leave the rack, got %v", next.Racks[1])
+	}
+
+	// une tuile "C" ne peut pas remplacer la tuile "CH"
+	s.Racks[1] = word.Tiles{"C", "H", "A", "O", "O", "O", "O"}
+	if _, _, err := s.ApplyMove(1, []request.PlacedLetter{{X: 7, Y: 7, Char: "CH"}, {X: 8, Y: 7, Char: "A"}}); !errors.Is(err, ErrMissingLetters) {
+		t.Fatalf("expected ErrMissingLetters, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ZiplEix/scrabble/api/gcg"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// ErrInvalidNotation est renvoyée quand la notation d'un coup est mal formée
+// ou ne correspond pas au plateau.
+var ErrInvalidNotation = errors.New("invalid move notation")
+
+// Notation est un coup décrit comme sur une feuille de match : "H8 CHAT"
+// (vertical, colonne puis ligne) ou "8H cHAT" (horizontal, ligne puis
+// colonne), une minuscule désignant un joker.
+type Notation struct {
+	X, Y     int
+	Vertical bool
+	Word     string                 // mot complet, lettres du plateau comprises
+	Letters  []request.PlacedLetter // seules les tuiles à poser
+}
+
+// Direction retourne "H" ou "V", comme PlayMoveRequest.Direction.
+func (n *Notation) Direction() string {
+	if n.Vertical {
+		return "V"
+	}
+	return "H"
+}
+
+// ParseNotation traduit notation en lettres à poser sur board. Les lettres
+// déjà présentes sur le plateau sont sautées ; on peut aussi les écrire "."
+// ou les mettre entre parenthèses ("8D (CH)AT"). Les digrammes de lang
+// s'écrivent tels quels ou entre crochets ("[CH]").
+func ParseNotation(lang *word.Language, board Board, notation string) (*Notation, error) {
+	lang = languageOrDefault(lang)
+	fields := strings.Fields(notation)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("%w: expected a coordinate and a word, e.g. \"H8 CHAT\"", ErrInvalidNotation)
+	}
+	pos, err := gcg.ParsePosition(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid coordinate %q", ErrInvalidNotation, fields[0])
+	}
+	faces, err := gcg.SplitTiles(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: unterminated tile in %q", ErrInvalidNotation, fields[1])
+	}
+
+	dx, dy := 1, 0
+	if pos.Vertical {
+		dx, dy = 0, 1
+	}
+	square := func(x, y int) string { return gcg.Position{X: x, Y: y}.String() }
+
+	n := &Notation{X: pos.X, Y: pos.Y, Vertical: pos.Vertical}
+	var mainWord strings.Builder
+	x, y := pos.X, pos.Y
+	through := false
+	for i := 0; i < len(faces); {
+		f := faces[i]
+		switch f {
+		case "(", ")":
+			if through == (f == ")") {
+				through = !through
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("%w: unbalanced parenthesis in %q", ErrInvalidNotation, fields[1])
+		}
+		size := digraphLength(lang, faces[i:])
+		f = strings.Join(faces[i:i+size], "")
+		i += size
+
+		if !board.InBounds(x, y) {
+			return nil, fmt.Errorf("%w: %s runs off the board", ErrInvalidNotation, fields[1])
+		}
+		upper := strings.ToUpper(f)
+		switch cell := board[y][x]; {
+		case cell == "" && (f == "." || through):
+			return nil, fmt.Errorf("%w: no tile to play through at %s", ErrInvalidNotation, square(x, y))
+		case cell != "" && f != "." && cell != upper:
+			return nil, fmt.Errorf("%w: square %s holds %s, not %s", ErrInvalidNotation, square(x, y), cell, upper)
+		case cell != "":
+			mainWord.WriteString(cell)
+		case strings.IndexFunc(f, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0:
+			return nil, fmt.Errorf("%w: invalid tile %q at %s", ErrInvalidNotation, f, square(x, y))
+		default:
+			blank := strings.IndexFunc(f, unicode.IsLower) >= 0
+			n.Letters = append(n.Letters, request.PlacedLetter{X: x, Y: y, Char: upper, Blank: blank})
+			mainWord.WriteString(upper)
+		}
+		x, y = x+dx, y+dy
+	}
+	if through {
+		return nil, fmt.Errorf("%w: unbalanced parenthesis in %q", ErrInvalidNotation, fields[1])
+	}
+	if len(n.Letters) == 0 {
+		return nil, fmt.Errorf("%w: %s places no new tile", ErrInvalidNotation, notation)
+	}
+	if bx, by := pos.X-dx, pos.Y-dy; board.InBounds(bx, by) && board[by][bx] != "" {
+		return nil, fmt.Errorf("%w: the word on the board starts before %s", ErrInvalidNotation, fields[0])
+	}
+	if board.InBounds(x, y) && board[y][x] != "" {
+		return nil, fmt.Errorf("%w: the word on the board continues at %s", ErrInvalidNotation, square(x, y))
+	}
+	n.Word = mainWord.String()
+	return n, nil
+}
+
+// digraphLength retourne le nombre de faces en tête de faces qui forment un
+// digramme de lang (1 si aucun). Les lettres d'un digramme doivent avoir la
+// même casse : "ch" est un joker CH, "cH" un joker C suivi d'un H.
+func digraphLength(lang *word.Language, faces []string) int {
+	best := 1
+	if utf8.RuneCountInString(faces[0]) != 1 {
+		return best
+	}
+	lower := strings.ToLower(faces[0]) == faces[0]
+	for _, d := range lang.Digraphs {
+		size := utf8.RuneCountInString(string(d))
+		if size <= best || size > len(faces) {
+			continue
+		}
+		joined := strings.Join(faces[:size], "")
+		if utf8.RuneCountInString(joined) == size && strings.ToUpper(joined) == string(d) &&
+			(joined == strings.ToLower(joined)) == lower && (joined == strings.ToUpper(joined)) == !lower {
+			best = size
+		}
+	}
+	return best
+}
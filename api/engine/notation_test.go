@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// boardWithChat retourne un plateau standard avec CHAT posé en 8F-8I.
+func boardWithChat() Board {
+	b := StandardLayout.NewBoard()
+	for i, c := range []string{"C", "H", "A", "T"} {
+		b[7][5+i] = c
+	}
+	return b
+}
+
+func TestParseNotation_Directions(t *testing.T) {
+	n, err := ParseNotation(nil, StandardLayout.NewBoard(), "8H cHAT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []request.PlacedLetter{
+		{X: 7, Y: 7, Char: "C", Blank: true},
+		{X: 8, Y: 7, Char: "H"},
+		{X: 9, Y: 7, Char: "A"},
+		{X: 10, Y: 7, Char: "T"},
+	}
+	if !reflect.DeepEqual(n.Letters, want) || n.Word != "CHAT" || n.Direction() != "H" {
+		t.Fatalf("unexpected notation: %+v", n)
+	}
+
+	n, err = ParseNotation(nil, StandardLayout.NewBoard(), "h8 chat")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n.Direction() != "V" || n.Letters[3] != (request.PlacedLetter{X: 7, Y: 10, Char: "T", Blank: true}) {
+		t.Fatalf("unexpected notation: %+v", n)
+	}
+}
+
+func TestParseNotation_SkipsBoardTiles(t *testing.T) {
+	board := boardWithChat()
+	for _, notation := range []string{"G7 OHE", "G7 O.E", "G7 O(H)E"} {
+		n, err := ParseNotation(nil, board, notation)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", notation, err)
+		}
+		want := []request.PlacedLetter{{X: 6, Y: 6, Char: "O"}, {X: 6, Y: 8, Char: "E"}}
+		if !reflect.DeepEqual(n.Letters, want) || n.Word != "OHE" {
+			t.Fatalf("%s: unexpected notation: %+v", notation, n)
+		}
+	}
+}
+
+func TestParseNotation_Digraphs(t *testing.T) {
+	spanish := &word.Language{Code: "es", Digraphs: []word.Tile{"CH", "LL"}}
+	n, err := ParseNotation(spanish, StandardLayout.NewBoard(), "8H chE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []request.PlacedLetter{{X: 7, Y: 7, Char: "CH", Blank: true}, {X: 8, Y: 7, Char: "E"}}
+	if !reflect.DeepEqual(n.Letters, want) {
+		t.Fatalf("unexpected letters: %+v", n.Letters)
+	}
+
+	n, err = ParseNotation(spanish, StandardLayout.NewBoard(), "8H cHE")
+	if err != nil || len(n.Letters) != 3 {
+		t.Fatalf("expected a blank C then H and E, got %+v, %v", n, err)
+	}
+	n, err = ParseNotation(spanish, StandardLayout.NewBoard(), "8H [CH]E")
+	if err != nil || n.Letters[0].Char != "CH" || n.Letters[0].Blank {
+		t.Fatalf("expected a CH tile, got %+v, %v", n, err)
+	}
+}
+
+func TestParseNotation_RejectsMismatches(t *testing.T) {
+	board := boardWithChat()
+	cases := []string{
+		"",
+		"CHAT",
+		"8H",
+		"Z8 CHAT",
+		"8O CHAT",   // sort du plateau
+		"G7 OXE",    // G8 porte un H
+		"G6 .O",     // rien à traverser en G6
+		"G7 (O)HE",  // rien à traverser en G7
+		"G7 O(HE",   // parenthèse non fermée
+		"8F CHAT",   // aucune tuile nouvelle
+		"8G HAT",    // le mot commence en 8F
+		"8F CH",     // le mot continue en 8H
+		"G7 O?E",    // joker sans lettre
+		"8A [CH",    // crochet non fermé
+		"8F CHAT S", // trop de champs
+	}
+	for _, notation := range cases {
+		if _, err := ParseNotation(nil, board, notation); !errors.Is(err, ErrInvalidNotation) {
+			t.Errorf("%q: expected ErrInvalidNotation, got %v", notation, err)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// FormedWord décrit un mot (principal ou croisé) formé par un coup.
+type FormedWord struct {
+	Word           string
+	StartX, StartY int
+	DX, DY         int
+}
+
+// ScoredWord associe un mot formé au score qu'il rapporte.
+type ScoredWord struct {
+	FormedWord
+	Score int
+}
+
+// IsBoardEmpty retourne true si aucune tuile n'est posée sur le plateau.
+func IsBoardEmpty(board Board) bool {
+	for _, row := range board {
+		for _, cell := range row {
+			if cell != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsConnected vérifie qu'au moins une des lettres posées est adjacente à une tuile existante du plateau.
+func IsConnected(board Board, placed []request.PlacedLetter) bool {
+	for _, pl := range placed {
+		for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			x, y := pl.X+d[0], pl.Y+d[1]
+			if board.InBounds(x, y) && board[y][x] != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ApplyLetters pose les lettres sur le plateau (retourne une erreur si une case est déjà occupée).
+func ApplyLetters(board Board, letters []request.PlacedLetter) error {
+	for _, l := range letters {
+		if !board.InBounds(l.X, l.Y) {
+			return fmt.Errorf("cell at %d,%d is out of the board", l.X, l.Y)
+		}
+		if board[l.Y][l.X] != "" {
+			return fmt.Errorf("cell at %d,%d already occupied", l.X, l.Y)
+		}
+		board[l.Y][l.X] = l.Char
+	}
+	return nil
+}
+
+// ExtractFormedWords retourne tous les mots (principal + croisés) créés par les lettres posées.
+// Le plateau doit déjà contenir les lettres posées.
+func ExtractFormedWords(board Board, placed []request.PlacedLetter) []FormedWord {
+	letterMap := make(map[Pos]struct{}, len(placed))
+	for _, l := range placed {
+		letterMap[Pos{l.X, l.Y}] = struct{}{}
+	}
+
+	visited := make(map[[4]int]bool)
+	words := []FormedWord{}
+	dirs := []struct{ dx, dy int }{{1, 0}, {0, 1}}
+
+	for _, l := range placed {
+		for _, dir := range dirs {
+			startX, startY := l.X, l.Y
+			for {
+				nx, ny := startX-dir.dx, startY-dir.dy
+				if !board.InBounds(nx, ny) || board[ny][nx] == "" {
+					break
+				}
+				startX, startY = nx, ny
+			}
+
+			wordText := ""
+			tiles := 0
+			touchesNewTile := false
+			x, y := startX, startY
+			for board.InBounds(x, y) {
+				letter := board[y][x]
+				if letter == "" {
+					break
+				}
+				wordText += letter
+				tiles++
+				if _, ok := letterMap[Pos{x, y}]; ok {
+					touchesNewTile = true
+				}
+				x += dir.dx
+				y += dir.dy
+			}
+
+			// une tuile isolée (même un digramme comme "CH") ne forme pas un mot
+			if tiles <= 1 || !touchesNewTile {
+				continue
+			}
+
+			key := [4]int{startX, startY, dir.dx, dir.dy}
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			words = append(words, FormedWord{
+				Word:   wordText,
+				StartX: startX,
+				StartY: startY,
+				DX:     dir.dx,
+				DY:     dir.dy,
+			})
+		}
+	}
+
+	return words
+}
+
+// ComputeWordScore calcule le score d'un mot formé avec les valeurs de lettres
+// de lang (français si nil) et les cases spéciales de layout (standard si
+// nil). Les multiplicateurs ne s'appliquent qu'aux cases nouvellement posées,
+// et les jokers valent 0.
+func ComputeWordScore(lang *word.Language, layout *Layout, board Board, fw FormedWord, isNew map[Pos]bool, isBlank map[Pos]bool) int {
+	lang = languageOrDefault(lang)
+	layout = layoutOrDefault(layout)
+	wordMultiplier := 1
+	wordScore := 0
+	x, y := fw.StartX, fw.StartY
+
+	for board.InBounds(x, y) {
+		letter := board[y][x]
+		if letter == "" {
+			break
+		}
+
+		letterScore := 0
+		if !isBlank[Pos{x, y}] {
+			letterScore = lang.LetterValue(letter)
+		}
+
+		if isNew[Pos{x, y}] {
+			switch layout.Premium(x, y) {
+			case PremiumDL:
+				letterScore *= 2
+			case PremiumTL:
+				letterScore *= 3
+			case PremiumQL:
+				letterScore *= 4
+			case PremiumDW, PremiumCenter:
+				wordMultiplier *= 2
+			case PremiumTW:
+				wordMultiplier *= 3
+			case PremiumQW:
+				wordMultiplier *= 4
+			}
+		}
+
+		wordScore += letterScore
+		x += fw.DX
+		y += fw.DY
+	}
+
+	return wordScore * wordMultiplier
+}
+
+// ScoreWords calcule le détail du score de chaque mot formé par le coup.
+// boardBlank indique quelles positions du plateau sont des jokers (0 point), y compris celles posées lors de coups précédents.
+func ScoreWords(lang *word.Language, layout *Layout, board Board, placed []request.PlacedLetter, boardBlank map[Pos]bool) []ScoredWord {
+	isNew := make(map[Pos]bool, len(placed))
+	isBlank := make(map[Pos]bool, len(placed)+len(boardBlank))
+	for _, l := range placed {
+		pos := Pos{l.X, l.Y}
+		isNew[pos] = true
+		if l.Blank {
+			isBlank[pos] = true
+		}
+	}
+	for p, b := range boardBlank {
+		if b {
+			isBlank[p] = true
+		}
+	}
+
+	formed := ExtractFormedWords(board, placed)
+	scored := make([]ScoredWord, 0, len(formed))
+	for _, fw := range formed {
+		scored = append(scored, ScoredWord{FormedWord: fw, Score: ComputeWordScore(lang, layout, board, fw, isNew, isBlank)})
+	}
+	return scored
+}
+
+// ComputeMoveScore calcule le score total du coup (mots formés + bonus
+// scrabble) avec les règles, la langue et le plateau par défaut.
+func ComputeMoveScore(board Board, placed []request.PlacedLetter, boardBlank map[Pos]bool) int {
+	return DefaultRuleset().MoveScore(nil, nil, board, placed, boardBlank)
+}
+
+// placedTile retourne la tuile du rack consommée par une lettre posée :
+// le joker pour une lettre blanche, la tuile de même face sinon.
+func placedTile(l request.PlacedLetter) word.Tile {
+	if l.Blank {
+		return Blank
+	}
+	return word.Tile(l.Char)
+}
+
+// RackContains vérifie que le rack contient les tuiles nécessaires (gère les jokers '?').
+func RackContains(rack word.Tiles, letters []request.PlacedLetter) bool {
+	rackCount := rack.Count()
+	for _, l := range letters {
+		if !l.Blank && l.Char == "" {
+			return false
+		}
+		t := placedTile(l)
+		if rackCount[t] == 0 {
+			return false
+		}
+		rackCount[t]--
+	}
+	return true
+}
+
+// ResolveBlanks tente d'attribuer automatiquement des jokers ('?') aux lettres manquantes
+// lorsque le client n'a pas renseigné le champ Blank. Respecte aussi les Blank déjà posés.
+func ResolveBlanks(rack word.Tiles, letters []request.PlacedLetter) ([]request.PlacedLetter, error) {
+	rackCount := rack.Count()
+
+	// Consommer d'abord les blanks déjà marqués
+	used := make([]request.PlacedLetter, len(letters))
+	copy(used, letters)
+	for _, l := range used {
+		if l.Blank {
+			if rackCount[Blank] == 0 {
+				return nil, fmt.Errorf("invalid move: no blank in rack")
+			}
+			rackCount[Blank]--
+		}
+	}
+
+	// Puis consommer les vraies tuiles, en basculant sur un joker si besoin
+	for i := range used {
+		if used[i].Blank {
+			continue
+		}
+		if used[i].Char == "" {
+			return nil, ErrMissingLetters
+		}
+		t := placedTile(used[i])
+		if rackCount[t] > 0 {
+			rackCount[t]--
+			continue
+		}
+		if rackCount[Blank] > 0 {
+			used[i].Blank = true
+			rackCount[Blank]--
+		} else {
+			return nil, ErrMissingLetters
+		}
+	}
+
+	return used, nil
+}
+
+// RemoveFromRack retire du rack les tuiles correspondant aux lettres posées
+// (un '?' pour chaque joker).
+func RemoveFromRack(rack word.Tiles, played []request.PlacedLetter) (word.Tiles, error) {
+	for _, l := range played {
+		t := placedTile(l)
+		var ok bool
+		if rack, ok = rack.Remove(t); !ok {
+			return nil, fmt.Errorf("letter %s not in rack", t)
+		}
+	}
+	return rack, nil
+}
+
+// RackPoints retourne la valeur totale des tuiles restantes d'un rack (joker = 0)
+// avec les valeurs de lettres de lang (français si nil).
+func RackPoints(lang *word.Language, rack word.Tiles) int {
+	lang = languageOrDefault(lang)
+	pts := 0
+	for _, t := range rack {
+		if t == Blank {
+			continue
+		}
+		pts += lang.LetterValue(string(t))
+	}
+	return pts
+}
+
+// languageOrDefault retourne lang, ou le pack français si lang est nil.
+func languageOrDefault(lang *word.Language) *word.Language {
+	if lang == nil {
+		return word.French
+	}
+	return lang
+}
+
+// validatePlacement vérifie l'alignement des lettres et leur placement sur le
+// plateau (case centrale de layout au premier coup, contact avec l'existant
+// ensuite).
+func validatePlacement(layout *Layout, board Board, letters []request.PlacedLetter) error {
+	center := layoutOrDefault(layout).Center
+	sameRow := true
+	sameCol := true
+	firstX := letters[0].X
+	firstY := letters[0].Y
+	for _, l := range letters {
+		if l.X != firstX {
+			sameCol = false
+		}
+		if l.Y != firstY {
+			sameRow = false
+		}
+	}
+	if !sameRow && !sameCol {
+		return ErrNotAligned
+	}
+
+	if IsBoardEmpty(board) {
+		for _, l := range letters {
+			if l.X == center && l.Y == center {
+				return nil
+			}
+		}
+		return ErrFirstMoveCenter
+	}
+
+	if !IsConnected(board, letters) {
+		return ErrNotConnected
+	}
+	return nil
+}
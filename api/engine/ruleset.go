@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// Gestion des lettres restantes en fin de partie.
+const (
+	// LeftoverStandard : chaque joueur perd la valeur de son rack et celui qui
+	// a fini gagne la somme des racks adverses (règle officielle).
+	LeftoverStandard = "standard"
+	// LeftoverSubtract : chaque joueur perd la valeur de son rack, sans bonus.
+	LeftoverSubtract = "subtract"
+	// LeftoverNone : les lettres restantes ne comptent pas.
+	LeftoverNone = "none"
+)
+
+var ErrInvalidRuleset = errors.New("invalid ruleset")
+
+// Ruleset regroupe les règles configurables d'une partie. Il est choisi à la
+// création et stocké avec la partie.
+type Ruleset struct {
+	BingoBonus int `json:"bingo_bonus"` // bonus quand tout le rack est posé
+	// ScorelessTurnLimit est le nombre de tours consécutifs sans score
+	// (passes, échanges, coups retirés) qui termine la partie ; 0 = 2 par joueur.
+	ScorelessTurnLimit int    `json:"scoreless_turn_limit"`
+	RackSize           int    `json:"rack_size"`
+	MaxPlayers         int    `json:"max_players"`
+	SmallBagExchange   bool   `json:"small_bag_exchange"` // échange permis avec moins de RackSize lettres dans le sac
+	Leftover           string `json:"leftover"`           // LeftoverStandard, LeftoverSubtract ou LeftoverNone
+}
+
+// DefaultRuleset retourne les règles classiques du Scrabble.
+func DefaultRuleset() Ruleset {
+	return Ruleset{
+		BingoBonus:         BingoBonus,
+		ScorelessTurnLimit: 0,
+		RackSize:           RackSize,
+		MaxPlayers:         4,
+		SmallBagExchange:   false,
+		Leftover:           LeftoverStandard,
+	}
+}
+
+// Validate vérifie que les règles sont jouables avec le sac de lang
+// (français si nil).
+func (r Ruleset) Validate(lang *word.Language) error {
+	switch {
+	case r.BingoBonus < 0, r.ScorelessTurnLimit < 0:
+		return ErrInvalidRuleset
+	case r.RackSize < 1 || r.RackSize > 12:
+		return ErrInvalidRuleset
+	case r.MaxPlayers < 1 || r.MaxPlayers > 8:
+		return ErrInvalidRuleset
+	case r.MaxPlayers*r.RackSize > len(languageOrDefault(lang).Bag):
+		return ErrInvalidRuleset
+	}
+	switch r.Leftover {
+	case LeftoverStandard, LeftoverSubtract, LeftoverNone:
+		return nil
+	}
+	return ErrInvalidRuleset
+}
+
+// MoveScore calcule le score total d'un coup, bonus de scrabble compris.
+// Les valeurs des lettres sont celles de lang (français si nil) et les cases
+// spéciales celles de layout (plateau standard si nil).
+func (r Ruleset) MoveScore(lang *word.Language, layout *Layout, board Board, placed []request.PlacedLetter, boardBlank map[Pos]bool) int {
+	total := 0
+	for _, sw := range ScoreWords(lang, layout, board, placed, boardBlank) {
+		total += sw.Score
+	}
+	if len(placed) == r.RackSize {
+		total += r.BingoBonus
+	}
+	return total
+}
+
+// scorelessLimit retourne le nombre de tours sans score qui termine une partie
+// à nbPlayers joueurs.
+func (r Ruleset) scorelessLimit(nbPlayers int) int {
+	if r.ScorelessTurnLimit > 0 {
+		return r.ScorelessTurnLimit
+	}
+	return nbPlayers * 2
+}
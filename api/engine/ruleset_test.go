@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestRuleset_Validate(t *testing.T) {
+	if err := DefaultRuleset().Validate(nil); err != nil {
+		t.Fatalf("default ruleset should be valid: %v", err)
+	}
+	invalid := []func(r *Ruleset){
+		func(r *Ruleset) { r.BingoBonus = -1 },
+		func(r *Ruleset) { r.RackSize = 0 },
+		func(r *Ruleset) { r.MaxPlayers = 0 },
+		func(r *Ruleset) { r.RackSize, r.MaxPlayers = 12, 8 },
+		func(r *Ruleset) { r.Leftover = "double" },
+	}
+	for i, mutate := range invalid {
+		r := DefaultRuleset()
+		mutate(&r)
+		if err := r.Validate(nil); !errors.Is(err, ErrInvalidRuleset) {
+			t.Fatalf("case %d: expected ErrInvalidRuleset, got %v", i, err)
+		}
+	}
+}
+
+func TestRuleset_RackSizeAndBingoBonus(t *testing.T) {
+	rules := DefaultRuleset()
+	rules.RackSize = 4
+	rules.BingoBonus = 20
+
+	s := NewGame([]int64{1, 2}, nil, tiles("CHATEEEEEEEEEE"), rules, rand.New(rand.NewSource(1)))
+	if len(s.Racks[1]) != 4 || len(s.Racks[2]) != 4 {
+		t.Fatalf("expected racks of 4 tiles, got %q and %q", s.Racks[1], s.Racks[2])
+	}
+
+	s.Racks[1] = tiles("CHAT")
+	next, res, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 18 points + bonus de 20 pour un rack de 4 lettres entièrement posé
+	if res.Score != 38 {
+		t.Fatalf("expected 38, got %d", res.Score)
+	}
+	if len(next.Racks[1]) != 4 {
+		t.Fatalf("expected rack refilled to 4 tiles, got %q", next.Racks[1])
+	}
+
+	five := append(chatAtCenter(), request.PlacedLetter{X: 9, Y: 7, Char: "E"})
+	s.Racks[1] = tiles("CHATE")
+	if _, _, err := s.ApplyMove(1, five); !errors.Is(err, ErrTooManyLetters) {
+		t.Fatalf("expected ErrTooManyLetters, got %v", err)
+	}
+}
+
+func TestRuleset_ScorelessTurnsIncludeExchanges(t *testing.T) {
+	s := newTestGame("ABCDEFG", "HIJKLMN")
+	s.Rules = DefaultRuleset()
+	s.Rules.ScorelessTurnLimit = 2
+
+	s, _, err := s.Pass(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, res, err := s.Exchange(2, tiles("H"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.GameOver {
+		t.Fatalf("expected pass + exchange to reach the scoreless turn limit")
+	}
+}
+
+func TestRuleset_SmallBagExchange(t *testing.T) {
+	s := newTestGame("ABCDEFG", "HIJKLMN")
+	s.Bag = tiles("EE")
+	if _, _, err := s.Exchange(1, tiles("A")); !errors.Is(err, ErrBagTooSmall) {
+		t.Fatalf("expected ErrBagTooSmall, got %v", err)
+	}
+
+	s.Rules = DefaultRuleset()
+	s.Rules.SmallBagExchange = true
+	if _, _, err := s.Exchange(1, tiles("A")); err != nil {
+		t.Fatalf("expected exchange to be allowed, got %v", err)
+	}
+	if _, _, err := s.Exchange(1, tiles("ABC")); !errors.Is(err, ErrBagTooSmall) {
+		t.Fatalf("expected ErrBagTooSmall when exchanging more tiles than the bag holds, got %v", err)
+	}
+}
+
+func TestRuleset_Leftover(t *testing.T) {
+	s := newTestGame("", "KZ")
+	s.Scores[1] = 10
+	s.Scores[2] = 25
+	s.Rules = DefaultRuleset()
+
+	s.Rules.Leftover = LeftoverSubtract
+	final, res := s.Finish(1)
+	if final.Scores[1] != 10 || final.Scores[2] != 5 || res.Bonus != 0 {
+		t.Fatalf("subtract: unexpected scores %+v", final.Scores)
+	}
+
+	s.Rules.Leftover = LeftoverNone
+	final, res = s.Finish(1)
+	if final.Scores[1] != 10 || final.Scores[2] != 25 || res.WinnerID != 2 {
+		t.Fatalf("none: unexpected scores %+v", final.Scores)
+	}
+}
+
+func TestRackPoints_Language(t *testing.T) {
+	// K vaut 10 en français mais 5 en anglais
+	if got := RackPoints(nil, tiles("KA?")); got != 11 {
+		t.Fatalf("expected 11 with French values, got %d", got)
+	}
+	if got := RackPoints(word.English, tiles("KA?")); got != 6 {
+		t.Fatalf("expected 6 with English values, got %d", got)
+	}
+}
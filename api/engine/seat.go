@@ -0,0 +1,33 @@
+package engine
+
+import "errors"
+
+// ErrGameFull est retournée quand la partie a déjà son nombre maximal de joueurs.
+var ErrGameFull = errors.New("game is full")
+
+// Seat installe playerID avant le début de la partie : il est ajouté à la fin
+// de l'ordre de passage s'il n'y figure pas encore, et reçoit un rack complet
+// tiré du sac s'il n'en a pas. Un joueur déjà servi n'est pas modifié. En
+// duplicate, aucun rack n'est tiré : le tirage commun l'est à chaque ronde
+// (voir DrawDuplicateRack).
+func (s *GameState) Seat(playerID int64) (*GameState, error) {
+	next := s.Clone()
+	seated := false
+	for _, pid := range next.Players {
+		if pid == playerID {
+			seated = true
+			break
+		}
+	}
+	if !seated {
+		if len(next.Players) >= next.rules().MaxPlayers {
+			return nil, ErrGameFull
+		}
+		next.Players = append(next.Players, playerID)
+		next.Scores[playerID] = 0
+	}
+	if len(next.Racks[playerID]) == 0 && !next.Duplicate() {
+		next.Racks[playerID] = next.draw(playerID, next.rules().RackSize)
+	}
+	return next, nil
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestSeat_MatchesDealingAtCreation(t *testing.T) {
+	seed := bytes.Repeat([]byte{7}, SeedSize)
+	want := NewSeededGame([]int64{1, 2, 3}, nil, word.French.Bag, DefaultRuleset(), seed)
+
+	s := NewSeededGame([]int64{1}, nil, word.French.Bag, DefaultRuleset(), seed)
+	var err error
+	for _, pid := range []int64{2, 3} {
+		if s, err = s.Seat(pid); err != nil {
+			t.Fatalf("seat %d: %v", pid, err)
+		}
+	}
+	if !reflect.DeepEqual(s.Players, want.Players) || !reflect.DeepEqual(s.Racks, want.Racks) || !reflect.DeepEqual(s.Bag, want.Bag) {
+		t.Fatalf("seating players one by one should deal the same racks as creating the game with them")
+	}
+	if err := VerifyDraws(seed, word.French.Bag, s.Draws, s.BagChanges); err != nil {
+		t.Fatalf("draws should verify: %v", err)
+	}
+
+	// un joueur déjà servi garde son rack
+	again, err := s.Seat(2)
+	if err != nil || !reflect.DeepEqual(again.Racks[2], s.Racks[2]) || again.DrawCount != s.DrawCount {
+		t.Fatalf("seating a served player should change nothing (err %v)", err)
+	}
+}
+
+func TestSeat_EmptyRackAndFullGame(t *testing.T) {
+	rules := DefaultRuleset()
+	rules.MaxPlayers = 2
+	s := NewSeededGame([]int64{1}, nil, word.French.Bag, rules, bytes.Repeat([]byte{3}, SeedSize))
+	s.Bag = s.Bag.Concat(s.Racks[1])
+	s.Racks[1] = word.Tiles{}
+
+	s, err := s.Seat(1)
+	if err != nil || len(s.Racks[1]) != RackSize || len(s.Players) != 1 {
+		t.Fatalf("seated player without rack should be dealt one in place (err %v)", err)
+	}
+	if s, err = s.Seat(2); err != nil {
+		t.Fatalf("seat 2: %v", err)
+	}
+	if _, err := s.Seat(3); !errors.Is(err, ErrGameFull) {
+		t.Fatalf("expected ErrGameFull, got %v", err)
+	}
+}
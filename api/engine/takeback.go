@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+var (
+	ErrNothingToTakeBack = errors.New("no move to take back")
+	ErrCannotTakeBack    = errors.New("move cannot be taken back")
+)
+
+// PlayedMove décrit un coup déjà appliqué (pose, échange ou passe), avec ce
+// qu'il faut pour l'annuler. Une pose a des Letters, un échange des Returned ;
+// un coup sans l'un ni l'autre est une passe.
+type PlayedMove struct {
+	PlayerID      int64
+	Letters       []request.PlacedLetter // lettres posées
+	Returned      word.Tiles             // tuiles remises dans le sac (échange)
+	Drawn         word.Tiles             // tuiles piochées après le coup
+	Score         int
+	PrevPassCount int // compteur de passes avant une pose
+}
+
+// Takeback annule moves, qui doivent être les derniers coups de la partie
+// dans l'ordre où ils ont été joués : plateau, racks, sac, scores et
+// compteur de passes reviennent à l'état d'avant le premier coup, et la main
+// revient à son auteur.
+func (s *GameState) Takeback(moves []PlayedMove) (*GameState, error) {
+	if s.Ended {
+		return nil, ErrGameEnded
+	}
+	if len(moves) == 0 {
+		return nil, ErrNothingToTakeBack
+	}
+
+	next := s.Clone()
+	for i := len(moves) - 1; i >= 0; i-- {
+		if err := next.undo(moves[i]); err != nil {
+			return nil, err
+		}
+	}
+	next.Turn = moves[0].PlayerID
+	return next, nil
+}
+
+// undo annule mv, dernier coup appliqué à l'état courant.
+func (s *GameState) undo(mv PlayedMove) error {
+	rack, ok := s.Racks[mv.PlayerID]
+	if !ok {
+		return ErrNotInGame
+	}
+	for _, t := range mv.Drawn {
+		if rack, ok = rack.Remove(t); !ok {
+			return ErrCannotTakeBack
+		}
+	}
+
+	for _, pl := range mv.Letters {
+		if !s.Board.InBounds(pl.X, pl.Y) || s.Board[pl.Y][pl.X] == "" {
+			return ErrCannotTakeBack
+		}
+		s.Board[pl.Y][pl.X] = ""
+		delete(s.Blanks, Pos{X: pl.X, Y: pl.Y})
+		rack = append(rack, placedTile(pl))
+	}
+
+	for _, t := range mv.Returned {
+		if s.Bag, ok = s.Bag.Remove(t); !ok {
+			return ErrCannotTakeBack
+		}
+		rack = append(rack, t)
+	}
+	s.logBagChange(BagChange{Removed: mv.Returned})
+
+	// Un coup enregistré sans ses tuiles piochées ne peut pas être annulé
+	if len(rack) > s.rules().RackSize {
+		return ErrCannotTakeBack
+	}
+
+	s.Racks[mv.PlayerID] = rack
+	s.returnToBag(mv.Drawn)
+	s.Scores[mv.PlayerID] -= mv.Score
+	if len(mv.Letters) > 0 {
+		s.PassCount = mv.PrevPassCount
+	} else if s.PassCount > 0 {
+		s.PassCount--
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTakeback_RestoresPreMoveState(t *testing.T) {
+	s := newTestGame("CHATXYZ", "ABCDEFG")
+	s.PassCount = 1
+	played, res, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	back, err := played.Takeback([]PlayedMove{{
+		PlayerID:      1,
+		Letters:       res.Letters,
+		Drawn:         res.Drawn,
+		Score:         res.Score,
+		PrevPassCount: res.PrevPassCount,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if back.Board[7][7] != "" || back.Scores[1] != 0 || back.Turn != 1 || back.PassCount != 1 {
+		t.Fatalf("expected pre-move state, got board %q score %d turn %d passes %d", back.Board[7][7], back.Scores[1], back.Turn, back.PassCount)
+	}
+	if back.Racks[1].Count()["C"] != 1 || len(back.Racks[1]) != RackSize {
+		t.Fatalf("expected CHATXYZ back in the rack, got %q", back.Racks[1])
+	}
+	if len(back.Bag) != len(s.Bag) {
+		t.Fatalf("expected drawn tiles back in the bag, got %d tiles", len(back.Bag))
+	}
+	if played.Board[7][7] != "A" {
+		t.Fatalf("takeback must not modify the original state")
+	}
+}
+
+func TestTakeback_PlayThenExchangeAndPass(t *testing.T) {
+	s := newTestGame("CHATXYZ", "ABCDEFG", "HIJKLMN")
+	s.Bag = tiles("EEEEEEEEEEOOOO")
+	origRacks := map[int64]string{1: s.Racks[1].String(), 2: s.Racks[2].String(), 3: s.Racks[3].String()}
+
+	s1, play, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s2, exch, err := s1.Exchange(2, tiles("AB"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s3, _, err := s2.Pass(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	back, err := s3.Takeback([]PlayedMove{
+		{PlayerID: 1, Letters: play.Letters, Drawn: play.Drawn, Score: play.Score, PrevPassCount: play.PrevPassCount},
+		{PlayerID: 2, Returned: exch.Returned, Drawn: exch.Drawn},
+		{PlayerID: 3},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for pid, rack := range origRacks {
+		if !reflect.DeepEqual(back.Racks[pid].Count(), tiles(rack).Count()) {
+			t.Fatalf("player %d: expected rack %q, got %q", pid, rack, back.Racks[pid])
+		}
+	}
+	if !reflect.DeepEqual(back.Bag.Count(), s.Bag.Count()) {
+		t.Fatalf("expected bag %q, got %q", s.Bag, back.Bag)
+	}
+	if back.Turn != 1 || back.PassCount != 0 || back.Scores[1] != 0 {
+		t.Fatalf("expected turn 1 with no passes nor score, got turn %d passes %d score %d", back.Turn, back.PassCount, back.Scores[1])
+	}
+}
+
+func TestTakeback_Errors(t *testing.T) {
+	s := newTestGame("CHATXYZ", "ABCDEFG")
+	if _, err := s.Takeback(nil); !errors.Is(err, ErrNothingToTakeBack) {
+		t.Fatalf("expected ErrNothingToTakeBack, got %v", err)
+	}
+
+	played, res, err := s.ApplyMove(1, chatAtCenter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// sans les tuiles piochées, le rack déborderait
+	if _, err := played.Takeback([]PlayedMove{{PlayerID: 1, Letters: res.Letters, Score: res.Score}}); !errors.Is(err, ErrCannotTakeBack) {
+		t.Fatalf("expected ErrCannotTakeBack, got %v", err)
+	}
+
+	played.Ended = true
+	if _, err := played.Takeback([]PlayedMove{{PlayerID: 1, Letters: res.Letters, Drawn: res.Drawn}}); !errors.Is(err, ErrGameEnded) {
+		t.Fatalf("expected ErrGameEnded, got %v", err)
+	}
+}
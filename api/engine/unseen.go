@@ -0,0 +1,42 @@
+package engine
+
+import "github.com/ZiplEix/scrabble/api/word"
+
+// InitialBag retourne la distribution complète des tuiles de la partie :
+// le sac de sa langue à la taille de sa disposition.
+func (s *GameState) InitialBag() word.Tiles {
+	return languageOrDefault(s.Language).BagOfSize(layoutOrDefault(s.Layout).BagSize)
+}
+
+// Unseen retourne les tuiles que playerID ne voit pas : la distribution
+// initiale moins les tuiles posées (les jokers posés comptent comme jokers)
+// et moins son rack, soit le sac plus les racks adverses. Les tuiles suivent
+// l'ordre de la distribution initiale.
+func (s *GameState) Unseen(playerID int64) word.Tiles {
+	initial := s.InitialBag()
+	counts := initial.Count()
+	for y, row := range s.Board {
+		for x, cell := range row {
+			if cell == "" {
+				continue
+			}
+			t := word.Tile(cell)
+			if s.Blanks[Pos{X: x, Y: y}] {
+				t = Blank
+			}
+			counts[t]--
+		}
+	}
+	for _, t := range s.Racks[playerID] {
+		counts[t]--
+	}
+
+	unseen := word.Tiles{}
+	for _, t := range initial {
+		if counts[t] > 0 {
+			unseen = append(unseen, t)
+			counts[t]--
+		}
+	}
+	return unseen
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestUnseen_IsBagPlusOpponentRacks(t *testing.T) {
+	s := NewSeededGame([]int64{1, 2, 3}, nil, word.French.Bag, DefaultRuleset(), bytes.Repeat([]byte{1}, SeedSize))
+	want := s.Bag.Concat(s.Racks[2]).Concat(s.Racks[3]).Count()
+	if got := s.Unseen(1).Count(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unseen %v, want bag plus opponent racks %v", got, want)
+	}
+}
+
+func TestUnseen_SubtractsBoardAndBlanks(t *testing.T) {
+	s := &GameState{
+		Board:  boardWithChat(),
+		Blanks: map[Pos]bool{{X: 7, Y: 7}: true}, // le A de CHAT est un joker
+		Racks:  map[int64]word.Tiles{1: tiles("EEES?XY")},
+	}
+	unseen := s.Unseen(1)
+	if len(unseen) != len(word.French.Bag)-4-7 {
+		t.Fatalf("expected %d unseen tiles, got %d", len(word.French.Bag)-11, len(unseen))
+	}
+	initial, got := word.French.Bag.Count(), unseen.Count()
+	for tile, delta := range map[word.Tile]int{"C": 1, "H": 1, "A": 0, "T": 1, "?": 2, "E": 3, "X": 1, "Y": 1} {
+		if got[tile] != initial[tile]-delta {
+			t.Errorf("%s: expected %d unseen, got %d", tile, initial[tile]-delta, got[tile])
+		}
+	}
+}
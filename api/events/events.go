@@ -0,0 +1,189 @@
+// Package events distribue aux utilisateurs connectés les événements des
+// parties (coups, fin de partie, chat, succès) en temps réel, ainsi qu'aux
+// spectateurs d'une partie. Les services publient par l'intermédiaire d'un
+// Hub ; le flux SSE de l'API s'y abonne.
+// Le hub par défaut vit en mémoire et ne sert donc que les clients connectés à
+// la même instance : un hub adossé à Postgres LISTEN/NOTIFY pourra le
+// remplacer via SetHub sans toucher aux émetteurs, les événements étant déjà
+// sérialisés en JSON.
+package events
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Types d'événements.
+const (
+	MovePlayed          = "move_played"
+	Pass                = "pass"
+	Exchange            = "exchange"
+	GameEnded           = "game_ended"
+	ChatMessage         = "chat_message"
+	MessageDeleted      = "message_deleted"
+	AchievementUnlocked = "achievement_unlocked"
+	GameInvitation      = "game_invitation"
+	InvitationAnswered  = "invitation_answered"
+	GameStarted         = "game_started"
+	MatchFound          = "match_found"
+	PlayerJoined        = "player_joined"
+	DuplicateSubmitted  = "duplicate_submitted"
+	DuplicateRound      = "duplicate_round"
+	PlayerResigned      = "player_resigned"
+	MoveChallenged      = "move_challenged"
+	TakebackRequested   = "takeback_requested"
+	TakebackAnswered    = "takeback_answered"
+)
+
+// Event est un événement adressé à un utilisateur.
+type Event struct {
+	Type   string          `json:"type"`
+	GameID string          `json:"game_id,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// New construit un événement ; data est sérialisé en JSON (ignoré s'il ne
+// peut pas l'être).
+func New(typ, gameID string, data any) Event {
+	ev := Event{Type: typ, GameID: gameID}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			ev.Data = raw
+		}
+	}
+	return ev
+}
+
+// Hub achemine les événements vers les abonnés.
+type Hub interface {
+	// Publish envoie ev à chacun des utilisateurs, sans bloquer.
+	Publish(userIDs []int64, ev Event)
+	// Subscribe abonne userID et retourne le canal des événements et la
+	// fonction de désabonnement, qui ferme le canal.
+	Subscribe(userID int64) (<-chan Event, func())
+	// PublishGame envoie ev aux spectateurs de la partie gameID, sans bloquer.
+	PublishGame(gameID string, ev Event)
+	// SubscribeGame abonne un spectateur aux événements de la partie gameID.
+	SubscribeGame(gameID string) (<-chan Event, func())
+}
+
+// subscriberBuffer est le nombre d'événements gardés pour un abonné lent ;
+// au-delà, les suivants sont perdus pour lui (le client se resynchronise en
+// rechargeant la partie).
+const subscriberBuffer = 32
+
+// MemoryHub est un Hub en mémoire, limité à une instance de l'API.
+type MemoryHub struct {
+	mu    sync.Mutex
+	subs  subscribers[int64]
+	games subscribers[string]
+}
+
+// NewMemoryHub crée un hub en mémoire vide.
+func NewMemoryHub() *MemoryHub {
+	return &MemoryHub{subs: subscribers[int64]{}, games: subscribers[string]{}}
+}
+
+func (h *MemoryHub) Publish(userIDs []int64, ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	seen := make(map[int64]bool, len(userIDs))
+	for _, uid := range userIDs {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		h.subs.send(uid, ev)
+	}
+}
+
+func (h *MemoryHub) Subscribe(userID int64) (<-chan Event, func()) {
+	return subscribe(&h.mu, h.subs, userID)
+}
+
+func (h *MemoryHub) PublishGame(gameID string, ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.games.send(gameID, ev)
+}
+
+func (h *MemoryHub) SubscribeGame(gameID string) (<-chan Event, func()) {
+	return subscribe(&h.mu, h.games, gameID)
+}
+
+// subscribers associe à chaque clé (utilisateur ou partie) les canaux abonnés.
+type subscribers[K comparable] map[K]map[chan Event]struct{}
+
+// send remet ev à chaque abonné de key ; un abonné dont le tampon est plein
+// le perd.
+func (s subscribers[K]) send(key K, ev Event) {
+	for ch := range s[key] {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// subscribe ajoute un canal à s[key] sous mu et retourne la fonction de
+// désabonnement, qui ferme le canal une seule fois.
+func subscribe[K comparable](mu *sync.Mutex, s subscribers[K], key K) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	mu.Lock()
+	if s[key] == nil {
+		s[key] = map[chan Event]struct{}{}
+	}
+	s[key][ch] = struct{}{}
+	mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			mu.Lock()
+			delete(s[key], ch)
+			if len(s[key]) == 0 {
+				delete(s, key)
+			}
+			mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+var (
+	hubMu sync.RWMutex
+	hub   Hub = NewMemoryHub()
+)
+
+// SetHub remplace le hub utilisé par Publish et Subscribe.
+func SetHub(h Hub) {
+	hubMu.Lock()
+	hub = h
+	hubMu.Unlock()
+}
+
+func current() Hub {
+	hubMu.RLock()
+	defer hubMu.RUnlock()
+	return hub
+}
+
+// Publish envoie un événement aux utilisateurs par le hub courant.
+func Publish(userIDs []int64, ev Event) {
+	current().Publish(userIDs, ev)
+}
+
+// Subscribe abonne userID au hub courant.
+func Subscribe(userID int64) (<-chan Event, func()) {
+	return current().Subscribe(userID)
+}
+
+// PublishGame envoie un événement aux spectateurs d'une partie par le hub courant.
+func PublishGame(gameID string, ev Event) {
+	current().PublishGame(gameID, ev)
+}
+
+// SubscribeGame abonne un spectateur à une partie sur le hub courant.
+func SubscribeGame(gameID string) (<-chan Event, func()) {
+	return current().SubscribeGame(gameID)
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMemoryHub_DeliversToSubscribedUsers(t *testing.T) {
+	h := NewMemoryHub()
+	alice, unsubAlice := h.Subscribe(1)
+	defer unsubAlice()
+	bob, unsubBob := h.Subscribe(2)
+	defer unsubBob()
+
+	h.Publish([]int64{1, 1, 3}, New(MovePlayed, "g1", map[string]int{"score": 18}))
+
+	select {
+	case ev := <-alice:
+		if ev.Type != MovePlayed || ev.GameID != "g1" || string(ev.Data) != `{"score":18}` {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	default:
+		t.Fatalf("expected an event for user 1")
+	}
+	if len(alice) != 0 {
+		t.Fatalf("expected a single delivery despite the duplicate id")
+	}
+	if len(bob) != 0 {
+		t.Fatalf("user 2 was not addressed")
+	}
+}
+
+func TestMemoryHub_UnsubscribeClosesAndDropsSlowSubscribers(t *testing.T) {
+	h := NewMemoryHub()
+	ch, unsub := h.Subscribe(1)
+	for i := 0; i < subscriberBuffer+5; i++ {
+		h.Publish([]int64{1}, New(Pass, "g1", nil))
+	}
+	if len(ch) != subscriberBuffer {
+		t.Fatalf("expected %d buffered events, got %d", subscriberBuffer, len(ch))
+	}
+
+	unsub()
+	unsub()
+	for range ch {
+	}
+	h.Publish([]int64{1}, New(Pass, "g1", nil))
+	if len(h.subs) != 0 {
+		t.Fatalf("expected no subscriber left")
+	}
+}
+
+func TestMemoryHub_GameSubscribersAreSeparateFromUsers(t *testing.T) {
+	h := NewMemoryHub()
+	spectator, unsubSpectator := h.SubscribeGame("g1")
+	other, unsubOther := h.SubscribeGame("g2")
+	defer unsubOther()
+	player, unsubPlayer := h.Subscribe(1)
+	defer unsubPlayer()
+
+	h.PublishGame("g1", New(MovePlayed, "g1", nil))
+	if len(spectator) != 1 || len(other) != 0 || len(player) != 0 {
+		t.Fatalf("expected a single delivery to the g1 spectator, got %d/%d/%d", len(spectator), len(other), len(player))
+	}
+
+	unsubSpectator()
+	if _, ok := h.games["g1"]; ok {
+		t.Fatalf("expected the g1 subscription to be removed")
+	}
+}
+
+func TestEvent_MarshalsData(t *testing.T) {
+	raw, err := json.Marshal(New(ChatMessage, "g1", map[string]string{"content": "salut"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(raw) != `{"type":"chat_message","game_id":"g1","data":{"content":"salut"}}` {
+		t.Fatalf("unexpected json: %s", raw)
+	}
+}
@@ -0,0 +1,333 @@
+// Package gcg lit et écrit des parties au format GCG, la notation utilisée par
+// les outils d'analyse de Scrabble (Quackle, Macondo...). Le paquet ne connaît
+// ni le plateau ni les règles : il ne fait que traduire le texte en événements
+// et inversement.
+package gcg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Types d'événements d'une partie GCG.
+const (
+	Play           = "play"             // >nick: RACK 8H WORD +24 24
+	Pass           = "pass"             // >nick: RACK - +0 24
+	Exchange       = "exchange"         // >nick: RACK -ABC +0 24 (ou -3 si les tuiles sont inconnues)
+	PhonyRemoved   = "phony_removed"    // >nick: RACK -- -24 0
+	ChallengeBonus = "challenge_bonus"  // >nick: RACK (challenge) +5 29
+	TimePenalty    = "time_penalty"     // >nick: RACK (time) -10 19
+	EndRackPoints  = "end_rack_points"  // >nick: (RACK) +12 312
+	EndRackPenalty = "end_rack_penalty" // >nick: RACK (RACK) -6 280
+	Note           = "note"             // #note texte libre
+)
+
+// ErrSyntax est renvoyée pour une ligne GCG mal formée.
+var ErrSyntax = errors.New("gcg syntax error")
+
+// Player est un joueur déclaré par une ligne #playerN.
+type Player struct {
+	Nick string // pseudonyme, sans espace, repris en tête de chaque coup
+	Name string
+}
+
+// Position est la case de départ d'un mot : "8H" pour un mot horizontal
+// (ligne puis colonne), "H8" pour un mot vertical. X et Y partent de 0.
+type Position struct {
+	X, Y     int
+	Vertical bool
+}
+
+func (p Position) String() string {
+	col := string(rune('A' + p.X))
+	if p.Vertical {
+		return col + strconv.Itoa(p.Y+1)
+	}
+	return strconv.Itoa(p.Y+1) + col
+}
+
+// ParsePosition décode une position GCG ("8H" ou "H8").
+func ParsePosition(s string) (Position, error) {
+	if s == "" {
+		return Position{}, fmt.Errorf("%w: empty position", ErrSyntax)
+	}
+	vertical := unicode.IsLetter(rune(s[0]))
+	digits := strings.TrimFunc(s, unicode.IsLetter)
+	letters := strings.TrimFunc(s, unicode.IsDigit)
+	row, err := strconv.Atoi(digits)
+	if err != nil || len(letters) != 1 || len(digits)+len(letters) != len(s) || row < 1 {
+		return Position{}, fmt.Errorf("%w: invalid position %q", ErrSyntax, s)
+	}
+	col := unicode.ToUpper(rune(letters[0]))
+	if col < 'A' || col > 'Z' {
+		return Position{}, fmt.Errorf("%w: invalid position %q", ErrSyntax, s)
+	}
+	return Position{X: int(col - 'A'), Y: row - 1, Vertical: vertical}, nil
+}
+
+// Event est une ligne de coup (ou une note) d'une partie GCG.
+type Event struct {
+	Type     string
+	Nick     string
+	Rack     string   // rack avant le coup, "?" pour un joker ; vide si inconnu
+	Position Position // pose uniquement
+	// Word est le mot principal d'une pose : jokers en minuscules, "." pour
+	// les lettres déjà sur le plateau.
+	Word      string
+	Exchanged string // tuiles échangées ; vide si seul leur nombre est connu
+	Count     int    // nombre de tuiles échangées
+	// LostRack est le rack décompté en fin de partie (EndRackPoints,
+	// EndRackPenalty).
+	LostRack string
+	Score    int // points de l'événement, négatifs pour une pénalité
+	Total    int // score cumulé du joueur après l'événement
+	Note     string
+	Line     int // ligne du fichier, renseignée par Parse
+}
+
+// Game est une partie GCG : en-têtes et événements dans l'ordre.
+type Game struct {
+	Players     []Player
+	Title       string
+	Description string
+	ID          string
+	Lexicon     string
+	Events      []Event
+}
+
+// SplitTiles découpe une suite de tuiles GCG en faces : une lettre, un
+// digramme entre crochets ("[CH]", rendu sans crochets), "?" ou ".". La casse
+// est conservée.
+func SplitTiles(s string) ([]string, error) {
+	var out []string
+	for i := 0; i < len(s); {
+		if s[i] == '[' {
+			end := strings.IndexByte(s[i:], ']')
+			if end < 2 {
+				return nil, fmt.Errorf("%w: unterminated tile in %q", ErrSyntax, s)
+			}
+			out = append(out, s[i+1:i+end])
+			i += end + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		out = append(out, string(r))
+		i += size
+	}
+	return out, nil
+}
+
+// JoinTiles est l'inverse de SplitTiles : les tuiles de plusieurs lettres
+// sont placées entre crochets.
+func JoinTiles(faces []string) string {
+	var b strings.Builder
+	for _, f := range faces {
+		if utf8.RuneCountInString(f) > 1 {
+			b.WriteString("[" + f + "]")
+		} else {
+			b.WriteString(f)
+		}
+	}
+	return b.String()
+}
+
+// Write écrit g au format GCG.
+func Write(w io.Writer, g *Game) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#character-encoding UTF-8")
+	for i, p := range g.Players {
+		fmt.Fprintf(bw, "#player%d %s %s\n", i+1, p.Nick, p.Name)
+	}
+	header := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(bw, "#%s %s\n", key, value)
+		}
+	}
+	header("title", g.Title)
+	header("description", g.Description)
+	header("id", g.ID)
+	header("lexicon", g.Lexicon)
+
+	for _, ev := range g.Events {
+		if ev.Type == Note {
+			fmt.Fprintf(bw, "#note %s\n", ev.Note)
+			continue
+		}
+		var fields []string
+		if ev.Rack != "" && ev.Type != EndRackPoints {
+			fields = append(fields, ev.Rack)
+		}
+		switch ev.Type {
+		case Play:
+			fields = append(fields, ev.Position.String(), ev.Word)
+		case Pass:
+			fields = append(fields, "-")
+		case Exchange:
+			if ev.Exchanged != "" {
+				fields = append(fields, "-"+ev.Exchanged)
+			} else {
+				fields = append(fields, "-"+strconv.Itoa(ev.Count))
+			}
+		case PhonyRemoved:
+			fields = append(fields, "--")
+		case ChallengeBonus:
+			fields = append(fields, "(challenge)")
+		case TimePenalty:
+			fields = append(fields, "(time)")
+		case EndRackPoints, EndRackPenalty:
+			fields = append(fields, "("+ev.LostRack+")")
+		default:
+			return fmt.Errorf("unknown gcg event type %q", ev.Type)
+		}
+		fmt.Fprintf(bw, ">%s: %s %+d %d\n", ev.Nick, strings.Join(fields, " "), ev.Score, ev.Total)
+	}
+	return bw.Flush()
+}
+
+// Parse lit une partie GCG. Les en-têtes inconnus sont ignorés ; les notes
+// deviennent des événements Note.
+func Parse(r io.Reader) (*Game, error) {
+	g := &Game{}
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#"):
+			if err := parseHeader(g, text, line); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(text, ">"):
+			ev, err := parseEvent(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			ev.Line = line
+			g.Events = append(g.Events, ev)
+		default:
+			return nil, fmt.Errorf("line %d: %w: unexpected %q", line, ErrSyntax, text)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(g.Players) < 2 {
+		return nil, fmt.Errorf("%w: at least two #player lines are required", ErrSyntax)
+	}
+	return g, nil
+}
+
+func parseHeader(g *Game, text string, line int) error {
+	key, value, _ := strings.Cut(text[1:], " ")
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(key, "player"):
+		n, err := strconv.Atoi(strings.TrimPrefix(key, "player"))
+		nick, name, _ := strings.Cut(value, " ")
+		if err != nil || n != len(g.Players)+1 || nick == "" {
+			return fmt.Errorf("line %d: %w: invalid player declaration", line, ErrSyntax)
+		}
+		g.Players = append(g.Players, Player{Nick: nick, Name: strings.TrimSpace(name)})
+	case key == "title":
+		g.Title = value
+	case key == "description":
+		g.Description = value
+	case key == "id":
+		g.ID = value
+	case key == "lexicon":
+		g.Lexicon = value
+	case key == "note":
+		g.Events = append(g.Events, Event{Type: Note, Note: value, Line: line})
+	}
+	return nil
+}
+
+func parseEvent(text string) (Event, error) {
+	var ev Event
+	nick, rest, ok := strings.Cut(text[1:], ":")
+	fields := strings.Fields(rest)
+	if !ok || nick == "" || len(fields) < 3 {
+		return ev, fmt.Errorf("%w: malformed move", ErrSyntax)
+	}
+	ev.Nick = nick
+
+	var err error
+	n := len(fields)
+	if ev.Score, err = strconv.Atoi(fields[n-2]); err != nil || !strings.ContainsAny(fields[n-2][:1], "+-") {
+		return ev, fmt.Errorf("%w: invalid score %q", ErrSyntax, fields[n-2])
+	}
+	if ev.Total, err = strconv.Atoi(fields[n-1]); err != nil {
+		return ev, fmt.Errorf("%w: invalid total %q", ErrSyntax, fields[n-1])
+	}
+	fields = fields[:n-2]
+	last := fields[len(fields)-1]
+	// le rack qui précède le coup est facultatif
+	rackGiven := func(moveFields int) error {
+		switch len(fields) {
+		case moveFields:
+		case moveFields + 1:
+			ev.Rack = fields[0]
+		default:
+			return fmt.Errorf("%w: malformed move", ErrSyntax)
+		}
+		return nil
+	}
+
+	switch {
+	case last == "(challenge)" || last == "(time)":
+		ev.Type = ChallengeBonus
+		if last == "(time)" {
+			ev.Type = TimePenalty
+		}
+		err = rackGiven(1)
+	case isParenthesized(last):
+		ev.LostRack = last[1 : len(last)-1]
+		ev.Type = EndRackPoints
+		if len(fields) == 2 {
+			ev.Type = EndRackPenalty
+			ev.Rack = fields[0]
+		} else if len(fields) != 1 {
+			err = fmt.Errorf("%w: malformed end of game line", ErrSyntax)
+		}
+	case last == "--":
+		ev.Type = PhonyRemoved
+		err = rackGiven(1)
+	case last == "-":
+		ev.Type = Pass
+		err = rackGiven(1)
+	case strings.HasPrefix(last, "-"):
+		ev.Type = Exchange
+		if count, convErr := strconv.Atoi(last[1:]); convErr == nil {
+			ev.Count = count
+		} else {
+			ev.Exchanged = last[1:]
+			tiles, splitErr := SplitTiles(ev.Exchanged)
+			if splitErr != nil {
+				return ev, splitErr
+			}
+			ev.Count = len(tiles)
+		}
+		err = rackGiven(1)
+	default:
+		ev.Type = Play
+		if err = rackGiven(2); err != nil {
+			return ev, err
+		}
+		ev.Word = last
+		ev.Position, err = ParsePosition(fields[len(fields)-2])
+	}
+	return ev, err
+}
+
+func isParenthesized(s string) bool {
+	return len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')'
+}
@@ -0,0 +1,150 @@
+package gcg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleGame = `#character-encoding UTF-8
+#player1 alice Alice
+#player2 bob Bob Martin
+#title Finale
+#lexicon CSW21
+>alice: ACEHRST 8D CHARTES +84 84
+#note scrabble d'ouverture
+>bob: EIOUUVW -UUVW +0 0
+>alice: ?ENORST F4 S.oRTENT +70 154
+>bob: DEEIOXY E5 OXY +30 30
+>bob: DEEIOXY -- -30 0
+>bob: DEEIOXY -3 +0 0
+>alice: ADEIIMN - +0 154
+>bob: (challenge) +5 5
+>alice: AEEIN (time) -10 144
+>bob: (AEEIN) +10 15
+>alice: AEEIN (AEEIN) -5 139
+`
+
+func TestParse_ReadsHeadersAndEvents(t *testing.T) {
+	g, err := Parse(strings.NewReader(sampleGame))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Players) != 2 || g.Players[1] != (Player{Nick: "bob", Name: "Bob Martin"}) {
+		t.Fatalf("unexpected players: %+v", g.Players)
+	}
+	if g.Title != "Finale" || g.Lexicon != "CSW21" {
+		t.Fatalf("unexpected headers: %q %q", g.Title, g.Lexicon)
+	}
+
+	want := []Event{
+		{Type: Play, Nick: "alice", Rack: "ACEHRST", Position: Position{X: 3, Y: 7}, Word: "CHARTES", Score: 84, Total: 84, Line: 6},
+		{Type: Note, Note: "scrabble d'ouverture", Line: 7},
+		{Type: Exchange, Nick: "bob", Rack: "EIOUUVW", Exchanged: "UUVW", Count: 4, Line: 8},
+		{Type: Play, Nick: "alice", Rack: "?ENORST", Position: Position{X: 5, Y: 3, Vertical: true}, Word: "S.oRTENT", Score: 70, Total: 154, Line: 9},
+		{Type: Play, Nick: "bob", Rack: "DEEIOXY", Position: Position{X: 4, Y: 4, Vertical: true}, Word: "OXY", Score: 30, Total: 30, Line: 10},
+		{Type: PhonyRemoved, Nick: "bob", Rack: "DEEIOXY", Score: -30, Line: 11},
+		{Type: Exchange, Nick: "bob", Rack: "DEEIOXY", Count: 3, Line: 12},
+		{Type: Pass, Nick: "alice", Rack: "ADEIIMN", Total: 154, Line: 13},
+		{Type: ChallengeBonus, Nick: "bob", Score: 5, Total: 5, Line: 14},
+		{Type: TimePenalty, Nick: "alice", Rack: "AEEIN", Score: -10, Total: 144, Line: 15},
+		{Type: EndRackPoints, Nick: "bob", LostRack: "AEEIN", Score: 10, Total: 15, Line: 16},
+		{Type: EndRackPenalty, Nick: "alice", Rack: "AEEIN", LostRack: "AEEIN", Score: -5, Total: 139, Line: 17},
+	}
+	if len(g.Events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(g.Events), g.Events)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(g.Events[i], want[i]) {
+			t.Errorf("event %d: expected %+v, got %+v", i, want[i], g.Events[i])
+		}
+	}
+}
+
+func TestWrite_RoundTrips(t *testing.T) {
+	g, err := Parse(strings.NewReader(sampleGame))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out strings.Builder
+	if err := Write(&out, g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != sampleGame {
+		t.Fatalf("expected the sample back, got:\n%s", out.String())
+	}
+}
+
+func TestWrite_OmitsUnknownRack(t *testing.T) {
+	g := &Game{
+		Players: []Player{{Nick: "a", Name: "A"}, {Nick: "b", Name: "B"}},
+		Events:  []Event{{Type: Play, Nick: "a", Position: Position{X: 7, Y: 7}, Word: "CHAT", Score: 20, Total: 20}},
+	}
+	var out strings.Builder
+	if err := Write(&out, g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), ">a: 8H CHAT +20 20\n") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	parsed, err := Parse(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev := parsed.Events[0]; ev.Rack != "" || ev.Word != "CHAT" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	cases := map[string]Position{
+		"8H":  {X: 7, Y: 7},
+		"H8":  {X: 7, Y: 7, Vertical: true},
+		"15a": {X: 0, Y: 14},
+		"O1":  {X: 14, Y: 0, Vertical: true},
+	}
+	for in, want := range cases {
+		got, err := ParsePosition(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePosition(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "8", "H", "8HH", "0H", "8-"} {
+		if _, err := ParsePosition(in); !errors.Is(err, ErrSyntax) {
+			t.Errorf("ParsePosition(%q): expected ErrSyntax, got %v", in, err)
+		}
+	}
+}
+
+func TestSplitTiles_HandlesDigraphs(t *testing.T) {
+	tiles, err := SplitTiles("[CH]a.?[ll]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"CH", "a", ".", "?", "ll"}; !reflect.DeepEqual(tiles, want) {
+		t.Fatalf("expected %q, got %q", want, tiles)
+	}
+	if JoinTiles(tiles) != "[CH]a.?[ll]" {
+		t.Fatalf("unexpected join: %q", JoinTiles(tiles))
+	}
+	if _, err := SplitTiles("A[CH"); !errors.Is(err, ErrSyntax) {
+		t.Fatalf("expected ErrSyntax, got %v", err)
+	}
+}
+
+func TestParse_RejectsMalformedInput(t *testing.T) {
+	cases := []string{
+		"#player1 a A\n",
+		"#player1 a A\n#player2 b B\n>a: ACEHRST 8D CHARTES 84 84\n",
+		"#player1 a A\n#player2 b B\n>a: ACEHRST Z CHARTES +84 84\n",
+		"#player1 a A\n#player2 b B\nCHARTES\n",
+		"#player2 b B\n#player1 a A\n",
+	}
+	for _, in := range cases {
+		if _, err := Parse(strings.NewReader(in)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("expected ErrSyntax for %q, got %v", in, err)
+		}
+	}
+}
@@ -58,6 +58,18 @@ func main() {
 	services.InitBot()
 	services.StartBotWorker(5) // poll toutes les 5 secondes
 
+	// Relances et expiration des délais de jeu par tour
+	services.StartDeadlineWorker(60)
+
+	// Appariement des joueurs de la file de matchmaking
+	services.StartMatchmakingWorker(10)
+
+	// Lancement des tournois à la clôture des inscriptions
+	services.StartTournamentWorker(30)
+
+	// Clôture des rondes de duplicate dont le temps est écoulé
+	services.StartDuplicateWorker(5)
+
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -37,50 +37,90 @@ func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
 		if !strings.HasPrefix(authHeader, "Bearer ") {
 			return echo.NewHTTPError(http.StatusUnauthorized, "unauthorized, no auth bearer token")
 		}
+		if err := authenticate(c, secret, strings.TrimPrefix(authHeader, "Bearer ")); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return secret, nil
-		})
+// OptionalAuth identifie l'utilisateur quand un jeton valide est fourni, sans
+// rejeter les requêtes anonymes (routes publiques personnalisées si connecté).
+func OptionalAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	secret := []byte(os.Getenv("JWT_SECRET"))
 
-		if err != nil || !token.Valid {
-			return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("unauthorized, non valid token: %v", err))
+	return func(c echo.Context) error {
+		authHeader := c.Request().Header.Get("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			_ = authenticate(c, secret, strings.TrimPrefix(authHeader, "Bearer "))
 		}
+		return next(c)
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims")
+// RequireStreamAuth est RequireAuth pour les flux d'événements : EventSource
+// ne pouvant pas envoyer d'en-tête, le même jeton JWT est aussi accepté dans
+// le paramètre de requête access_token.
+func RequireStreamAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	secret := []byte(os.Getenv("JWT_SECRET"))
+
+	return func(c echo.Context) error {
+		token := c.QueryParam("access_token")
+		if authHeader := c.Request().Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			token = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+		if token == "" {
+			return echo.NewHTTPError(http.StatusUnauthorized, "unauthorized, no auth bearer token")
+		}
+		if err := authenticate(c, secret, token); err != nil {
+			return err
 		}
+		return next(c)
+	}
+}
 
-		userIDFloat, ok := claims["user_id"].(float64)
-		if !ok {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token payload")
+// authenticate valide le jeton JWT et injecte l'utilisateur dans le contexte.
+func authenticate(c echo.Context, secret []byte, tokenString string) error {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
-		userID := int64(userIDFloat)
+		return secret, nil
+	})
 
-		username, _ := claims["username"].(string)
+	if err != nil || !token.Valid {
+		return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("unauthorized, non valid token: %v", err))
+	}
 
-		// Injecte l'ID utilisateur dans le contexte Echo
-		c.Set(UserIDKey, userID)
-		if username != "" {
-			c.Set("username", username)
-		}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token claims")
+	}
 
-		// Injecte dans le context.Context standard de la requête HTTP
-		req := c.Request()
-		ctx := req.Context()
-		ctx = context.WithValue(ctx, userIDKey, userID)
-		if username != "" {
-			ctx = context.WithValue(ctx, usernameKey, username)
-		}
-		c.SetRequest(req.WithContext(ctx))
+	userIDFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token payload")
+	}
+	userID := int64(userIDFloat)
 
-		return next(c)
+	username, _ := claims["username"].(string)
+
+	// Injecte l'ID utilisateur dans le contexte Echo
+	c.Set(UserIDKey, userID)
+	if username != "" {
+		c.Set("username", username)
+	}
+
+	// Injecte dans le context.Context standard de la requête HTTP
+	req := c.Request()
+	ctx := req.Context()
+	ctx = context.WithValue(ctx, userIDKey, userID)
+	if username != "" {
+		ctx = context.WithValue(ctx, usernameKey, username)
 	}
+	c.SetRequest(req.WithContext(ctx))
+
+	return nil
 }
 
 func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
@@ -117,6 +117,64 @@ func TestRequireAuth_ValidToken_SetsUserID_AndCallsNext(t *testing.T) {
 	assert.EqualValues(t, userID, gotUserID)
 }
 
+func TestOptionalAuth_AnonymousOrInvalid_CallsNextWithoutUser(t *testing.T) {
+	for _, header := range []string{"", "Bearer not_a_jwt"} {
+		c, _ := makeEchoCtx(http.MethodGet, "/x", "")
+		if header != "" {
+			c.Request().Header.Set("Authorization", header)
+		}
+		nextCalled := false
+		h := OptionalAuth(func(c echo.Context) error {
+			nextCalled = true
+			assert.Nil(t, c.Get(UserIDKey))
+			return nil
+		})
+
+		require.NoError(t, h(c))
+		assert.True(t, nextCalled)
+	}
+}
+
+func TestOptionalAuth_ValidToken_SetsUserID(t *testing.T) {
+	c, _ := makeEchoCtx(http.MethodGet, "/x", "")
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": float64(7)})
+	tokenStr, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	require.NoError(t, err)
+	c.Request().Header.Set("Authorization", "Bearer "+tokenStr)
+
+	var gotUserID any
+	h := OptionalAuth(func(c echo.Context) error {
+		gotUserID = c.Get(UserIDKey)
+		return nil
+	})
+
+	require.NoError(t, h(c))
+	assert.EqualValues(t, 7, gotUserID)
+}
+
+func TestRequireStreamAuth_AcceptsQueryToken(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": float64(9)})
+	tokenStr, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	require.NoError(t, err)
+
+	c, _ := makeEchoCtx(http.MethodGet, "/events?access_token="+tokenStr, "")
+	var gotUserID any
+	h := RequireStreamAuth(func(c echo.Context) error {
+		gotUserID = c.Get(UserIDKey)
+		return nil
+	})
+	require.NoError(t, h(c))
+	assert.EqualValues(t, 9, gotUserID)
+
+	for _, path := range []string{"/events", "/events?access_token=not_a_jwt"} {
+		c, _ := makeEchoCtx(http.MethodGet, path, "")
+		err := RequireStreamAuth(func(c echo.Context) error { return nil })(c)
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok, path)
+		assert.Equal(t, http.StatusUnauthorized, he.Code, path)
+	}
+}
+
 // ---------------- RequireAdmin ----------------
 
 func TestRequireAdmin_NoUserInContext(t *testing.T) {
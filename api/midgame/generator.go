@@ -11,7 +11,6 @@ import (
 
 const (
 	BoardSize   = 15
-	DefaultBag  = "AAAAAAAAAEEEEEEEEEEEEIIIIIIIIONNNNNNRRRRRRTTTTTTLLLLSSSSUDDDGGGMMMBBCCPPFFHHVVJQKWXYZ??"
 	centerCoord = 7
 )
 
@@ -60,6 +59,7 @@ type placementCandidate struct {
 }
 
 type Generator struct {
+	lang               *word.Language
 	rng                *rand.Rand
 	targetWords        int
 	maxAttemptsPerHook int
@@ -68,7 +68,17 @@ type Generator struct {
 	placed             []PlacedWord
 }
 
+// NewGenerator crée un générateur de milieu de partie en français.
 func NewGenerator(targetWords int, seed int64) *Generator {
+	return NewGeneratorForLanguage(word.French, targetWords, seed)
+}
+
+// NewGeneratorForLanguage crée un générateur utilisant le dictionnaire et le
+// sac de lang.
+func NewGeneratorForLanguage(lang *word.Language, targetWords int, seed int64) *Generator {
+	if lang == nil {
+		lang = word.French
+	}
 	if targetWords <= 0 {
 		targetWords = 18
 	}
@@ -76,10 +86,15 @@ func NewGenerator(targetWords int, seed int64) *Generator {
 		seed = time.Now().UnixNano()
 	}
 	r := rand.New(rand.NewSource(seed))
-	bag := []rune(DefaultBag)
+	// chaque tuile est représentée par une rune (voir word.Language.TileRune)
+	bag := make([]rune, len(lang.Bag))
+	for i, t := range lang.Bag {
+		bag[i] = lang.TileRune(t)
+	}
 	r.Shuffle(len(bag), func(i, j int) { bag[i], bag[j] = bag[j], bag[i] })
 
 	return &Generator{
+		lang:               lang,
 		rng:                r,
 		targetWords:        targetWords,
 		maxAttemptsPerHook: 250,
@@ -88,7 +103,7 @@ func NewGenerator(targetWords int, seed int64) *Generator {
 }
 
 func (g *Generator) Generate() (*Result, error) {
-	playerRack := string(g.drawLetters(7))
+	playerRack := g.tilesString(g.drawLetters(7))
 
 	if err := g.placeSeedWord(); err != nil {
 		return nil, err
@@ -122,18 +137,18 @@ func (g *Generator) Generate() (*Result, error) {
 	return &Result{
 		Board:        g.board,
 		PlayerRack:   playerRack,
-		RemainingBag: string(g.bag),
+		RemainingBag: g.tilesString(g.bag),
 		Words:        append([]PlacedWord(nil), g.placed...),
 	}, nil
 }
 
 func (g *Generator) placeSeedWord() error {
 	for attempt := 0; attempt < 800; attempt++ {
-		seedWord, ok := word.RandomWord(g.rng, 4, 7)
+		seedWord, ok := g.lang.RandomWord(g.rng, 4, 7)
 		if !ok {
 			return fmt.Errorf("dictionary has no seed word between 4 and 7 letters")
 		}
-		runes := []rune(seedWord)
+		runes := g.lang.WordRunes(seedWord)
 		hookIndex := g.rng.Intn(len(runes))
 
 		dirs := []Direction{Horizontal, Vertical}
@@ -158,14 +173,14 @@ func (g *Generator) placeSeedWord() error {
 }
 
 func (g *Generator) tryPlaceAtHook(hook Hook) bool {
-	candidates := word.WordsContainingLetter(hook.Letter, 2, 10)
+	candidates := g.lang.WordsContainingLetter([]rune(string(g.lang.RuneTile(hook.Letter)))[0], 2, 10)
 	if len(candidates) == 0 {
 		return false
 	}
 
 	for i := 0; i < g.maxAttemptsPerHook; i++ {
 		w := candidates[g.rng.Intn(len(candidates))]
-		runes := []rune(w)
+		runes := g.lang.WordRunes(w)
 		indices := make([]int, 0, len(runes))
 		for idx, r := range runes {
 			if r == hook.Letter {
@@ -208,7 +223,7 @@ func (g *Generator) buildCandidate(startX, startY int, dir Direction, runes []ru
 		StartY:  startY,
 		Dir:     dir,
 		Word:    append([]rune(nil), runes...),
-		WordStr: string(runes),
+		WordStr: g.tilesString(runes),
 	}
 
 	dx, dy := 1, 0
@@ -239,8 +254,7 @@ func (g *Generator) buildCandidate(startX, startY int, dir Direction, runes []ru
 			cand.Needed = append(cand.Needed, r)
 			continue
 		}
-		er := []rune(existing)[0]
-		if er != r {
+		if g.lang.TileRune(word.Tile(existing)) != r {
 			return placementCandidate{}, false
 		}
 		hasOverlap = true
@@ -256,7 +270,7 @@ func (g *Generator) buildCandidate(startX, startY int, dir Direction, runes []ru
 
 	for _, t := range cand.NewTiles {
 		pw := g.readWordWithVirtualTile(t.X, t.Y, opposite(dir), t.Letter)
-		if len(pw) > 1 && !word.WordExists(pw) {
+		if len(pw) > 1 && !g.lang.WordExists(pw) {
 			return placementCandidate{}, false
 		}
 	}
@@ -270,7 +284,7 @@ func (g *Generator) buildCandidate(startX, startY int, dir Direction, runes []ru
 
 func (g *Generator) applyCandidate(c placementCandidate) {
 	for _, t := range c.NewTiles {
-		g.board[t.Y][t.X] = string(t.Letter)
+		g.board[t.Y][t.X] = string(g.lang.RuneTile(t.Letter))
 	}
 	g.consume(c.Needed)
 	g.placed = append(g.placed, PlacedWord{
@@ -292,12 +306,21 @@ func (g *Generator) collectHooks() []Hook {
 			if !hasFreeNeighbor(g.board, x, y) {
 				continue
 			}
-			hooks = append(hooks, Hook{X: x, Y: y, Letter: []rune(g.board[y][x])[0]})
+			hooks = append(hooks, Hook{X: x, Y: y, Letter: g.lang.TileRune(word.Tile(g.board[y][x]))})
 		}
 	}
 	return hooks
 }
 
+// tilesString convertit des runes de tuiles en texte (digrammes développés).
+func (g *Generator) tilesString(runes []rune) string {
+	var b strings.Builder
+	for _, r := range runes {
+		b.WriteString(string(g.lang.RuneTile(r)))
+	}
+	return b.String()
+}
+
 func (g *Generator) drawLetters(n int) []rune {
 	if n <= 0 || len(g.bag) == 0 {
 		return nil
@@ -368,7 +391,7 @@ func (g *Generator) readWordWithVirtualTile(x, y int, dir Direction, letter rune
 	cx, cy := sx, sy
 	for inBounds(cx, cy) {
 		if cx == x && cy == y {
-			b.WriteRune(letter)
+			b.WriteString(string(g.lang.RuneTile(letter)))
 			cx += dx
 			cy += dy
 			continue
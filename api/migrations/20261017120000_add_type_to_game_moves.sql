@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Les coups joués étaient enregistrés sans discriminant : seuls les "pass" avaient un type.
+UPDATE game_moves SET move = move || '{"type": "play"}'::jsonb WHERE NOT (move ? 'type');
+CREATE INDEX IF NOT EXISTS idx_game_moves_type ON game_moves ((move->>'type'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_moves_type;
+UPDATE game_moves SET move = move - 'type' WHERE move->>'type' = 'play';
+-- +goose StatementEnd
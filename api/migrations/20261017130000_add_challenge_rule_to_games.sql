@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games ADD COLUMN challenge_rule VARCHAR(10) NOT NULL DEFAULT 'none' CHECK (challenge_rule IN ('none', 'free', 'single', 'double'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games DROP COLUMN IF EXISTS challenge_rule;
+-- +goose StatementEnd
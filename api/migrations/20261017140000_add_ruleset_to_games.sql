@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games ADD COLUMN ruleset JSONB NOT NULL DEFAULT '{"bingo_bonus": 50, "scoreless_turn_limit": 0, "rack_size": 7, "max_players": 4, "small_bag_exchange": false, "leftover": "standard"}'::jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games DROP COLUMN IF EXISTS ruleset;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Les racks et sacs deviennent des tableaux de tuiles (["C","H","?"]) pour
+-- permettre les tuiles de plusieurs lettres ; l'ancien texte est découpé en
+-- une tuile par caractère.
+ALTER TABLE game_players ALTER COLUMN rack TYPE JSONB
+    USING COALESCE(to_jsonb(regexp_split_to_array(NULLIF(rack, ''), '')), '[]'::jsonb);
+ALTER TABLE games ALTER COLUMN available_letters TYPE JSONB
+    USING COALESCE(to_jsonb(regexp_split_to_array(NULLIF(available_letters, ''), '')), '[]'::jsonb);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE game_players ALTER COLUMN rack TYPE TEXT
+    USING regexp_replace(rack::text, '[\[\]", ]', '', 'g');
+ALTER TABLE games ALTER COLUMN available_letters TYPE TEXT
+    USING regexp_replace(available_letters::text, '[\[\]", ]', '', 'g');
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games
+    ADD COLUMN turn_time_limit_hours INT NOT NULL DEFAULT 0,
+    ADD COLUMN timeout_action VARCHAR(10) NOT NULL DEFAULT 'pass',
+    ADD COLUMN turn_deadline TIMESTAMP,
+    ADD COLUMN deadline_reminded BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN forfeited_by INT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_games_turn_deadline ON games (turn_deadline) WHERE status = 'ongoing';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_games_turn_deadline;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS forfeited_by,
+    DROP COLUMN IF EXISTS deadline_reminded,
+    DROP COLUMN IF EXISTS turn_deadline,
+    DROP COLUMN IF EXISTS timeout_action,
+    DROP COLUMN IF EXISTS turn_time_limit_hours;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games
+    ADD COLUMN bag_seed BYTEA,
+    ADD COLUMN bag_seed_hash TEXT,
+    ADD COLUMN draw_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS game_draws (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    move_id INTEGER REFERENCES game_moves(id) ON DELETE SET NULL,
+    player_id INTEGER NOT NULL,
+    bag JSONB NOT NULL,
+    drawn JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (game_id, seq)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS game_draws;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS draw_count,
+    DROP COLUMN IF EXISTS bag_seed_hash,
+    DROP COLUMN IF EXISTS bag_seed;
+-- +goose StatementEnd
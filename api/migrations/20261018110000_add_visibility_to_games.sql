@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games
+    ADD COLUMN visibility TEXT NOT NULL DEFAULT 'private' CHECK (visibility IN ('private', 'friends', 'public')),
+    ADD COLUMN spectator_chat BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_games_public_ongoing ON games(created_at DESC) WHERE visibility = 'public' AND status = 'ongoing';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_games_public_ongoing;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS spectator_chat,
+    DROP COLUMN IF EXISTS visibility;
+-- +goose StatementEnd
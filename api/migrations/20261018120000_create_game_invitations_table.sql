@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS game_invitations (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'expired')),
+    position INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    responded_at TIMESTAMP,
+    PRIMARY KEY (game_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_invitations_user_pending ON game_invitations(user_id) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_invitations_user_pending;
+DROP TABLE IF EXISTS game_invitations;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS matchmaking_queue (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('duel', 'multi')),
+    language TEXT NOT NULL,
+    variant TEXT NOT NULL,
+    ruleset JSONB NOT NULL,
+    rating_window INT CHECK (rating_window >= 0),
+    joined_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_matchmaking_queue_joined_at ON matchmaking_queue(joined_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_matchmaking_queue_joined_at;
+DROP TABLE IF EXISTS matchmaking_queue;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games
+    ADD COLUMN lobby_seats INT CHECK (lobby_seats >= 2),
+    ADD COLUMN lobby_min_rating INT;
+
+CREATE INDEX IF NOT EXISTS idx_games_lobby ON games(created_at DESC) WHERE status = 'pending' AND lobby_seats IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_games_lobby;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS lobby_min_rating,
+    DROP COLUMN IF EXISTS lobby_seats;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS game_invite_links (
+    id UUID PRIMARY KEY,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    created_by INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    redeemed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    redeemed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_invite_links_game ON game_invite_links(game_id);
+
+-- journal des tentatives d'utilisation, conservé même si la partie est supprimée
+CREATE TABLE IF NOT EXISTS game_invite_link_redemptions (
+    id SERIAL PRIMARY KEY,
+    link_id UUID NOT NULL,
+    game_id UUID NOT NULL,
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    outcome TEXT NOT NULL,
+    ip TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_invite_link_redemptions_link ON game_invite_link_redemptions(link_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_game_invite_link_redemptions_link;
+DROP TABLE IF EXISTS game_invite_link_redemptions;
+DROP INDEX IF EXISTS idx_game_invite_links_game;
+DROP TABLE IF EXISTS game_invite_links;
+-- +goose StatementEnd
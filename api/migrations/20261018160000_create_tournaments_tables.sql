@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tournaments (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    format TEXT NOT NULL CHECK (format IN ('round_robin', 'swiss')),
+    rounds INT NOT NULL DEFAULT 0 CHECK (rounds >= 0), -- 0 : un tour complet pour un toutes-rondes
+    language TEXT NOT NULL DEFAULT 'fr',
+    variant TEXT NOT NULL DEFAULT 'standard',
+    ruleset JSONB NOT NULL,
+    registration_opens_at TIMESTAMP NOT NULL,
+    registration_closes_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL DEFAULT 'registration' CHECK (status IN ('registration', 'running', 'finished', 'cancelled')),
+    current_round INT NOT NULL DEFAULT 0,
+    created_by INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    started_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    CHECK (registration_closes_at > registration_opens_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_status ON tournaments(status, registration_closes_at);
+
+CREATE TABLE IF NOT EXISTS tournament_players (
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seed INT NOT NULL,
+    registered_at TIMESTAMP NOT NULL DEFAULT now(),
+    withdrawn_at TIMESTAMP,
+    PRIMARY KEY (tournament_id, user_id)
+);
+
+-- un appariement par joueur et par ronde ; player2_id NULL pour un bye
+CREATE TABLE IF NOT EXISTS tournament_pairings (
+    id SERIAL PRIMARY KEY,
+    tournament_id UUID NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INT NOT NULL CHECK (round >= 1),
+    player1_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    player2_id INT REFERENCES users(id) ON DELETE CASCADE,
+    game_id UUID REFERENCES games(id) ON DELETE SET NULL,
+    result TEXT NOT NULL DEFAULT 'pending' CHECK (result IN ('pending', 'played', 'bye', 'forfeit')),
+    score1 INT NOT NULL DEFAULT 0,
+    score2 INT NOT NULL DEFAULT 0,
+    winner_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (tournament_id, round, player1_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_pairings_round ON tournament_pairings(tournament_id, round);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tournament_pairings_game ON tournament_pairings(game_id) WHERE game_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tournament_pairings_game;
+DROP INDEX IF EXISTS idx_tournament_pairings_round;
+DROP TABLE IF EXISTS tournament_pairings;
+DROP TABLE IF EXISTS tournament_players;
+DROP INDEX IF EXISTS idx_tournaments_status;
+DROP TABLE IF EXISTS tournaments;
+-- +goose StatementEnd
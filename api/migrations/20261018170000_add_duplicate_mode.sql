@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games
+    ADD COLUMN mode TEXT NOT NULL DEFAULT 'classic' CHECK (mode IN ('classic', 'duplicate')),
+    ADD COLUMN round_time_limit_seconds INT NOT NULL DEFAULT 0; -- durée d'une ronde en duplicate
+
+-- une ronde par tirage commun ; le top est le meilleur coup possible, posé à la clôture
+CREATE TABLE IF NOT EXISTS duplicate_rounds (
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    round INT NOT NULL CHECK (round >= 1),
+    rack JSONB NOT NULL,
+    top_letters JSONB NOT NULL,
+    top_word TEXT NOT NULL,
+    top_score INT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT now(),
+    deadline TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    PRIMARY KEY (game_id, round)
+);
+
+CREATE INDEX IF NOT EXISTS idx_duplicate_rounds_deadline ON duplicate_rounds(deadline) WHERE closed_at IS NULL;
+
+-- coup proposé par chaque joueur pendant une ronde ; letters vide pour une passe
+CREATE TABLE IF NOT EXISTS duplicate_submissions (
+    game_id UUID NOT NULL,
+    round INT NOT NULL,
+    player_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    letters JSONB NOT NULL,
+    word TEXT NOT NULL DEFAULT '',
+    score INT NOT NULL DEFAULT 0,
+    submitted_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (game_id, round, player_id),
+    FOREIGN KEY (game_id, round) REFERENCES duplicate_rounds(game_id, round) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS duplicate_submissions;
+DROP INDEX IF EXISTS idx_duplicate_rounds_deadline;
+DROP TABLE IF EXISTS duplicate_rounds;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS round_time_limit_seconds,
+    DROP COLUMN IF EXISTS mode;
+-- +goose StatementEnd
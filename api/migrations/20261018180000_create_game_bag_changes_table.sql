@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- tuiles remises dans le sac ou reprises hors tirage, pour reconstituer le sac
+-- à chaque tirage ; seq est le numéro du tirage qui suit
+CREATE TABLE IF NOT EXISTS game_bag_changes (
+    id SERIAL PRIMARY KEY,
+    game_id UUID NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    returned JSONB NOT NULL,
+    removed JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_bag_changes_game ON game_bag_changes(game_id, seq, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS game_bag_changes;
+-- +goose StatementEnd
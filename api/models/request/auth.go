@@ -3,11 +3,15 @@ package request
 type RegisterRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// InviteToken est le jeton d'un lien d'invitation, utilisé une fois le compte créé
+	InviteToken string `json:"invite_token,omitempty"`
 }
 
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// InviteToken est le jeton d'un lien d'invitation, utilisé une fois connecté
+	InviteToken string `json:"invite_token,omitempty"`
 }
 
 type ChangePasswordRequest struct {
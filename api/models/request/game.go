@@ -1,10 +1,64 @@
 package request
 
 type CreateGameRequest struct {
-	Name        string   `json:"name"`
-	Players     []string `json:"players"` // liste des usernames, ex: ["Alice", "Bob"]
-	RevangeFrom *string  `json:"revange_from,omitempty"`
-	Difficulty  string   `json:"difficulty,omitempty"`
+	Name          string     `json:"name"`
+	Players       []string   `json:"players"` // liste des usernames, ex: ["Alice", "Bob"]
+	RevangeFrom   *string    `json:"revange_from,omitempty"`
+	Difficulty    string     `json:"difficulty,omitempty"`
+	ChallengeRule string     `json:"challenge_rule,omitempty"` // "none", "free", "single" ou "double"
+	Rules         *GameRules `json:"rules,omitempty"`          // règles personnalisées, valeurs par défaut si absent
+	Language      string     `json:"language,omitempty"`       // pack de langue : "fr" (défaut) ou "en"
+	Variant       string     `json:"variant,omitempty"`        // plateau : "standard" (15x15, défaut) ou "super" (21x21)
+	// délai de jeu par tour en heures (ex : 24, 48, 72 ; 0 = illimité) et action
+	// à son expiration : "pass" (défaut) ou "forfeit"
+	TurnTimeLimitHours int    `json:"turn_time_limit_hours,omitempty"`
+	TimeoutAction      string `json:"timeout_action,omitempty"`
+	// Training accorde les reprises de coup sans approbation (partie contre le bot uniquement)
+	Training bool `json:"training,omitempty"`
+	// Visibility ouvre la partie aux spectateurs : "private" (défaut), "friends" ou "public" ;
+	// SpectatorChat leur laisse lire le chat
+	Visibility    string `json:"visibility,omitempty"`
+	SpectatorChat bool   `json:"spectator_chat,omitempty"`
+	// Seats publie la partie dans le lobby avec ce nombre total de places
+	// (créateur et invités compris) ; MinRating réserve les places libres aux
+	// joueurs d'au moins ce classement
+	Seats     int  `json:"seats,omitempty"`
+	MinRating *int `json:"min_rating,omitempty"`
+	// InviteLinks réserve ce nombre de places à des liens d'invitation
+	// (GET /game/:id/invite-links), pour des joueurs qui n'ont pas encore de compte
+	InviteLinks int `json:"invite_links,omitempty"`
+	// Mode : "classic" (défaut) ou "duplicate" (même tirage pour tous à chaque
+	// ronde) ; RoundTimeLimitSeconds est la durée d'une ronde en duplicate
+	// (180 s par défaut)
+	Mode                  string `json:"mode,omitempty"`
+	RoundTimeLimitSeconds int    `json:"round_time_limit_seconds,omitempty"`
+}
+
+// SetVisibilityRequest change l'accès des spectateurs à une partie.
+type SetVisibilityRequest struct {
+	Visibility    string `json:"visibility"`
+	SpectatorChat *bool  `json:"spectator_chat,omitempty"` // inchangé si absent
+}
+
+// GameRules surcharge les règles par défaut d'une partie ; les champs absents
+// gardent leur valeur par défaut.
+type GameRules struct {
+	BingoBonus         *int    `json:"bingo_bonus,omitempty"`
+	ScorelessTurnLimit *int    `json:"scoreless_turn_limit,omitempty"` // 0 = 2 tours par joueur
+	RackSize           *int    `json:"rack_size,omitempty"`
+	MaxPlayers         *int    `json:"max_players,omitempty"`
+	SmallBagExchange   *bool   `json:"small_bag_exchange,omitempty"`
+	Leftover           *string `json:"leftover,omitempty"` // "standard", "subtract" ou "none"
+}
+
+// CreateInviteLinkRequest crée un lien d'invitation à une place de la partie.
+type CreateInviteLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours,omitempty"` // 72 h par défaut, 30 jours au plus
+}
+
+// RedeemInviteLinkRequest utilise un lien d'invitation reçu.
+type RedeemInviteLinkRequest struct {
+	Token string `json:"token"`
 }
 
 type RenameGameRequest struct {
@@ -18,6 +72,13 @@ type PlayMoveRequest struct {
 	Direction string         `json:"dir"`     // "H" ou "V"
 	Letters   []PlacedLetter `json:"letters"` // lettres posées ce tour
 	Score     int            `json:"score"`   // score du coup
+	// Notation remplace Letters : "H8 CHAT" (vertical) ou "8H cHAT"
+	// (horizontal, minuscule = joker), les lettres du plateau étant sautées.
+	Notation string `json:"notation,omitempty"`
+}
+
+type ExchangeTilesRequest struct {
+	Tiles []string `json:"tiles"` // tuiles à remettre dans le sac, "?" pour un joker
 }
 
 type PlacedLetter struct {
@@ -26,3 +87,11 @@ type PlacedLetter struct {
 	Char  string `json:"char"`            // toujours en majuscules
 	Blank bool   `json:"blank,omitempty"` // true si la tuile posée est un joker (valeur 0)
 }
+
+// ImportGameRequest est le fichier GCG d'une partie à archiver (import admin).
+type ImportGameRequest struct {
+	GCG      string `json:"gcg"`                // contenu du fichier GCG
+	Name     string `json:"name,omitempty"`     // nom de la partie, #title du fichier par défaut
+	Language string `json:"language,omitempty"` // déduite de #lexicon si absente, "fr" par défaut
+	Variant  string `json:"variant,omitempty"`  // plateau : "standard" (défaut) ou "super"
+}
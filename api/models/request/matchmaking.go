@@ -0,0 +1,12 @@
+package request
+
+// JoinMatchmakingRequest décrit la partie recherchée dans la file de matchmaking.
+type JoinMatchmakingRequest struct {
+	Mode     string     `json:"mode,omitempty"`     // "duel" (1 contre 1, défaut) ou "multi" (3 ou 4 joueurs)
+	Language string     `json:"language,omitempty"` // pack de langue : "fr" (défaut) ou "en"
+	Variant  string     `json:"variant,omitempty"`  // plateau : "standard" (défaut) ou "super"
+	Rules    *GameRules `json:"rules,omitempty"`    // max_players est ignoré : le mode fixe le nombre de joueurs
+	// RatingWindow est l'écart de classement maximal accepté avec chaque
+	// adversaire (aucune limite si absent)
+	RatingWindow *int `json:"rating_window,omitempty"`
+}
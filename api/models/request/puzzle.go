@@ -4,6 +4,7 @@ type SubmitPuzzleAttemptRequest struct {
 	PuzzleID    string                `json:"puzzle_id"`
 	WordsPlayed []PuzzleWordForSubmit `json:"words_played"`
 	Letters     []PlacedLetter        `json:"letters,omitempty"`
+	Notation    string                `json:"notation,omitempty"` // remplace Letters, voir PlayMoveRequest
 	// time_used n'est plus envoyé par le client — calculé côté serveur depuis started_at
 }
 
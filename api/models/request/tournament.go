@@ -0,0 +1,30 @@
+package request
+
+import "time"
+
+// CreateTournamentRequest crée un tournoi.
+type CreateTournamentRequest struct {
+	Name   string `json:"name"`
+	Format string `json:"format"` // "round_robin" (toutes-rondes) ou "swiss"
+	// Rounds est le nombre de rondes : obligatoire en suisse ; en toutes-rondes,
+	// 0 (défaut) joue un tour complet
+	Rounds   int        `json:"rounds,omitempty"`
+	Language string     `json:"language,omitempty"` // pack de langue : "fr" (défaut) ou "en"
+	Variant  string     `json:"variant,omitempty"`  // plateau : "standard" (défaut) ou "super"
+	Rules    *GameRules `json:"rules,omitempty"`    // max_players est ignoré : chaque partie oppose deux joueurs
+	// fenêtre d'inscription : ouverte dès la création si RegistrationOpensAt est absent
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at,omitempty"`
+	RegistrationClosesAt time.Time  `json:"registration_closes_at"`
+}
+
+// TournamentPlayerRequest désigne un joueur du tournoi (retrait, forfait) ;
+// sans UserID, l'utilisateur connecté.
+type TournamentPlayerRequest struct {
+	UserID int64 `json:"user_id,omitempty"`
+}
+
+// TournamentByeRequest exempte un joueur d'une ronde à venir.
+type TournamentByeRequest struct {
+	UserID int64 `json:"user_id"`
+	Round  int   `json:"round"`
+}
@@ -2,4 +2,8 @@ package response
 
 type AuthResponse struct {
 	Token string `json:"token"`
+	// partie rejointe grâce au lien d'invitation fourni à la connexion, ou
+	// raison pour laquelle le lien n'a pas pu être utilisé
+	GameID      string `json:"game_id,omitempty"`
+	InviteError string `json:"invite_error,omitempty"`
 }
@@ -0,0 +1,26 @@
+package response
+
+// GameDraws est l'audit des tirages du sac d'une partie. SeedHash est publié
+// dès la création ; Seed et Draws ne sont révélés qu'à la fin de la partie,
+// ce qui permet de rejouer chaque tirage (voir engine.DrawRand).
+type GameDraws struct {
+	GameID    string     `json:"game_id"`
+	Status    string     `json:"status"`
+	SeedHash  string     `json:"seed_hash"`          // SHA-256 de la graine, en hexadécimal
+	Seed      string     `json:"seed,omitempty"`     // graine en hexadécimal
+	DrawCount int        `json:"draw_count"`         // nombre de tirages effectués
+	Draws     []DrawInfo `json:"draws,omitempty"`    // journal des tirages
+	Verified  bool       `json:"verified,omitempty"` // graine et tirages vérifiés par le serveur
+}
+
+// DrawInfo est un tirage : le sac juste avant et les tuiles tirées.
+type DrawInfo struct {
+	Seq      int      `json:"seq"`
+	MoveID   *int64   `json:"move_id,omitempty"` // coup qui a provoqué le tirage, absent pour les racks de départ
+	PlayerID int64    `json:"player_id"`
+	Bag      []string `json:"bag"`
+	Drawn    []string `json:"drawn"`
+	// tuiles remises dans le sac ou reprises depuis le tirage précédent
+	Returned []string `json:"returned,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+}
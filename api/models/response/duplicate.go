@@ -0,0 +1,59 @@
+package response
+
+import "time"
+
+// DuplicateGame est l'état d'une partie en duplicate vu par un joueur : ronde
+// en cours, rondes closes avec leur top et classement par rapport au top.
+type DuplicateGame struct {
+	GameID                string                 `json:"game_id"`
+	Status                string                 `json:"status"`
+	RoundTimeLimitSeconds int                    `json:"round_time_limit_seconds"`
+	CurrentRound          *DuplicateCurrentRound `json:"current_round,omitempty"` // absente une fois la partie terminée
+	Rounds                []DuplicateRound       `json:"rounds"`                  // rondes closes
+	TopTotal              int                    `json:"top_total"`               // somme des tops des rondes closes
+	Ranking               []DuplicateRanking     `json:"ranking"`
+}
+
+// DuplicateCurrentRound est la ronde ouverte : le tirage commun, l'échéance
+// et le coup déjà proposé par le joueur. Le top reste caché jusqu'à la clôture.
+type DuplicateCurrentRound struct {
+	Round        int                  `json:"round"`
+	Rack         []string             `json:"rack"`
+	Deadline     time.Time            `json:"deadline"`
+	Submitted    int                  `json:"submitted"` // joueurs ayant déjà proposé un coup
+	Players      int                  `json:"players"`
+	MySubmission *DuplicateSubmission `json:"my_submission,omitempty"`
+}
+
+// DuplicateRound est une ronde close : le top posé sur le plateau et le coup
+// de chaque joueur.
+type DuplicateRound struct {
+	Round       int                   `json:"round"`
+	Rack        []string              `json:"rack"`
+	TopWord     string                `json:"top_word"`
+	TopLetters  []ReplayTile          `json:"top_letters"`
+	TopScore    int                   `json:"top_score"`
+	Submissions []DuplicateSubmission `json:"submissions"`
+}
+
+// DuplicateSubmission est le coup proposé par un joueur pendant une ronde ;
+// sans lettres, le joueur a passé (0 point).
+type DuplicateSubmission struct {
+	PlayerID    int64        `json:"player_id"`
+	Username    string       `json:"username"`
+	Word        string       `json:"word,omitempty"`
+	Letters     []ReplayTile `json:"letters,omitempty"`
+	Score       int          `json:"score"`
+	SubmittedAt time.Time    `json:"submitted_at"`
+}
+
+// DuplicateRanking est la place d'un joueur : son total et sa part du total
+// des tops, en pourcentage.
+type DuplicateRanking struct {
+	Rank     int     `json:"rank"`
+	PlayerID int64   `json:"player_id"`
+	Username string  `json:"username"`
+	Score    int     `json:"score"`
+	Percent  float64 `json:"percent"`
+	Resigned bool    `json:"resigned,omitempty"`
+}
@@ -9,6 +9,7 @@ type GameInfo struct {
 	Name             string       `json:"name"`
 	Board            any          `json:"board"`
 	YourRack         string       `json:"your_rack"`
+	YourTiles        []string     `json:"your_tiles,omitempty"` // une entrée par tuile, digrammes compris
 	Players          []PlayerInfo `json:"players"`
 	Moves            []MoveInfo   `json:"moves"`
 	CurrentTurn      int64        `json:"current_turn"`
@@ -22,6 +23,43 @@ type GameInfo struct {
 	BlankTiles       []BoardBlank `json:"blank_tiles,omitempty"`
 	PassCount        int          `json:"pass_count"`
 	Difficulty       string       `json:"difficulty,omitempty"`
+	ChallengeRule    string       `json:"challenge_rule,omitempty"`
+	Rules            *GameRules   `json:"rules,omitempty"`
+	Language         string       `json:"language,omitempty"`
+	Variant          string       `json:"variant,omitempty"`
+	BoardSize        int          `json:"board_size"`
+	Premiums         []Premium    `json:"premiums,omitempty"` // cases spéciales du plateau
+	// délai de jeu par tour (0 = illimité), action à son expiration et échéance du tour courant
+	TurnTimeLimitHours int        `json:"turn_time_limit_hours,omitempty"`
+	TimeoutAction      string     `json:"timeout_action,omitempty"`
+	TurnDeadline       *time.Time `json:"turn_deadline,omitempty"`
+	ForfeitedBy        *int64     `json:"forfeited_by,omitempty"` // joueur ayant perdu par forfait
+	Training           bool       `json:"training,omitempty"`     // reprises de coup accordées sans approbation
+	// empreinte SHA-256 de la graine du sac, publiée à la création, et graine
+	// révélée à la fin de la partie (voir GET /game/:id/draws)
+	BagSeedHash string `json:"bag_seed_hash,omitempty"`
+	BagSeed     string `json:"bag_seed,omitempty"`
+	// accès des spectateurs : "private", "friends" ou "public", et lecture du chat
+	Visibility    string `json:"visibility"`
+	SpectatorChat bool   `json:"spectator_chat"`
+	// réponses des invités tant que la partie n'a pas commencé (statut "pending")
+	Invitations []InvitationInfo `json:"invitations,omitempty"`
+	// places proposées dans le lobby et classement minimal pour en prendre une
+	LobbySeats     int  `json:"lobby_seats,omitempty"`
+	LobbyMinRating *int `json:"lobby_min_rating,omitempty"`
+	// mode de jeu ("classic" ou "duplicate") et durée d'une ronde en duplicate
+	// (voir GET /game/:id/duplicate)
+	Mode                  string `json:"mode"`
+	RoundTimeLimitSeconds int    `json:"round_time_limit_seconds,omitempty"`
+}
+
+type GameRules struct {
+	BingoBonus         int    `json:"bingo_bonus"`
+	ScorelessTurnLimit int    `json:"scoreless_turn_limit"`
+	RackSize           int    `json:"rack_size"`
+	MaxPlayers         int    `json:"max_players"`
+	SmallBagExchange   bool   `json:"small_bag_exchange"`
+	Leftover           string `json:"leftover"`
 }
 
 type PlayerInfo struct {
@@ -31,14 +69,27 @@ type PlayerInfo struct {
 	Position int    `json:"position"`
 	Rack     string `json:"rack,omitempty"`
 	IsBot    bool   `json:"is_bot"`
+	Resigned bool   `json:"resigned,omitempty"` // a abandonné la partie
 }
 
 type MoveInfo struct {
 	PlayerID int64     `json:"player_id"`
+	Type     string    `json:"type"` // "play", "pass", "exchange", "challenge", "forfeit" ou "resign"
 	Move     any       `json:"move"` // JSONB brut
 	PlayedAt time.Time `json:"played_at"`
 }
 
+type ChallengeResponse struct {
+	Success      bool     `json:"success"`
+	InvalidWords []string `json:"invalid_words,omitempty"`
+	Penalty      int      `json:"penalty,omitempty"`
+	LostTurn     bool     `json:"lost_turn,omitempty"`
+}
+
+type TakebackResponse struct {
+	Applied bool `json:"applied"` // false : la demande attend l'accord des adversaires
+}
+
 type GameSummary struct {
 	ID                  string    `json:"id"`
 	Name                string    `json:"name"`
@@ -59,6 +110,14 @@ type BoardBlank struct {
 	Y int `json:"y"`
 }
 
+// Premium est une case spéciale du plateau ("DL", "TL", "QL", "DW", "TW",
+// "QW" ou "★" pour le centre).
+type Premium struct {
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	Type string `json:"type"`
+}
+
 // AdminGameSummary is a compact summary for admin listing of games
 type AdminGameSummary struct {
 	ID                  string     `json:"id"`
@@ -0,0 +1,22 @@
+package response
+
+import "time"
+
+// GameInvitation est une invitation en attente de réponse, vue par l'invité.
+type GameInvitation struct {
+	GameID    string           `json:"game_id"`
+	GameName  string           `json:"game_name"`
+	InvitedBy string           `json:"invited_by"`
+	Language  string           `json:"language"`
+	Variant   string           `json:"variant"`
+	Invitees  []InvitationInfo `json:"invitees"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// InvitationInfo est la réponse d'un invité à une partie en attente.
+type InvitationInfo struct {
+	UserID      int64      `json:"user_id"`
+	Username    string     `json:"username"`
+	Status      string     `json:"status"` // "pending", "accepted", "declined" ou "expired"
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+// InviteLink est un lien d'invitation réservant une place dans une partie,
+// vu par le créateur de la partie.
+type InviteLink struct {
+	ID         string     `json:"id"`
+	Token      string     `json:"token,omitempty"` // seulement tant que le lien est utilisable
+	Status     string     `json:"status"`          // "active", "expired", "revoked" ou "redeemed"
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RedeemedBy string     `json:"redeemed_by,omitempty"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty"`
+}
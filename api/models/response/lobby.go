@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+// LobbyGame est une partie en attente qui a encore des places ouvertes à tous.
+type LobbyGame struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	Language  string    `json:"language"`
+	Variant   string    `json:"variant"`
+	Seats     int       `json:"seats"` // places au total, créateur compris
+	Taken     int       `json:"taken"` // places prises ou réservées par une invitation
+	Open      int       `json:"open"`
+	MinRating *int      `json:"min_rating,omitempty"`
+	Players   []string  `json:"players"` // joueurs déjà installés
+	Joined    bool      `json:"joined"`  // le joueur a déjà une place ou une invitation
+	CanJoin   bool      `json:"can_join"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+// MatchmakingEntry est l'inscription d'un joueur dans la file de matchmaking.
+type MatchmakingEntry struct {
+	Mode         string     `json:"mode"`
+	Language     string     `json:"language"`
+	Variant      string     `json:"variant"`
+	Rules        *GameRules `json:"rules"`
+	RatingWindow *int       `json:"rating_window,omitempty"`
+	Rating       int        `json:"rating"`
+	JoinedAt     time.Time  `json:"joined_at"`
+}
@@ -0,0 +1,52 @@
+package response
+
+// GameReplay reconstruit une partie coup par coup à partir de son historique.
+type GameReplay struct {
+	GameID     string       `json:"game_id"`
+	Name       string       `json:"name"`
+	Status     string       `json:"status"`
+	Language   string       `json:"language"`
+	Variant    string       `json:"variant"`
+	BoardSize  int          `json:"board_size"`
+	Premiums   []Premium    `json:"premiums,omitempty"`
+	Players    []PlayerInfo `json:"players"`
+	TotalPlies int          `json:"total_plies"` // nombre de coups rejoués (hors état initial et fin de partie)
+	Plies      []ReplayPly  `json:"plies"`
+}
+
+// ReplayPly est l'état de la partie après un coup. Le coup 0 est l'état
+// initial ; une partie terminée se conclut par un coup "end" portant les
+// scores finaux (lettres restantes déduites).
+type ReplayPly struct {
+	Ply       int           `json:"ply"`
+	Type      string        `json:"type"` // "start", "play", "pass", "exchange", "challenge", "forfeit", "resign" ou "end"
+	PlayerID  int64         `json:"player_id,omitempty"`
+	Word      string        `json:"word,omitempty"`
+	Letters   []ReplayTile  `json:"letters,omitempty"` // lettres posées
+	Score     int           `json:"score,omitempty"`
+	Words     []WordScore   `json:"words,omitempty"`     // détail du score par mot formé
+	Bonus     int           `json:"bonus,omitempty"`     // prime pour avoir posé tout le rack
+	Withdrawn bool          `json:"withdrawn,omitempty"` // pose retirée ensuite par une contestation
+	Count     int           `json:"count,omitempty"`     // tuiles échangées
+	Success   bool          `json:"success,omitempty"`   // contestation réussie
+	Penalty   int           `json:"penalty,omitempty"`
+	LostTurn  bool          `json:"lost_turn,omitempty"` // contestation ratée : tour perdu
+	Board     [][]string    `json:"board"`
+	Blanks    []BoardBlank  `json:"blank_tiles,omitempty"`
+	Scores    map[int64]int `json:"scores"`
+	// Racks n'est renseigné que pour une partie terminée, ou pour le seul
+	// rack du joueur qui consulte une partie en cours.
+	Racks map[int64][]string `json:"racks,omitempty"`
+}
+
+type ReplayTile struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Char  string `json:"char"`
+	Blank bool   `json:"blank,omitempty"`
+}
+
+type WordScore struct {
+	Word  string `json:"word"`
+	Score int    `json:"score"`
+}
@@ -0,0 +1,30 @@
+package response
+
+import "time"
+
+// SpectatorView est une partie vue par un spectateur : aucun rack, et le chat
+// en lecture seule seulement si la partie l'ouvre aux spectateurs.
+type SpectatorView struct {
+	GameInfo
+	Messages []map[string]any `json:"messages,omitempty"`
+}
+
+// PublicGame résume une partie publique en cours pour la liste des parties à regarder.
+type PublicGame struct {
+	ID             string             `json:"id"`
+	Name           string             `json:"name"`
+	Language       string             `json:"language"`
+	Variant        string             `json:"variant"`
+	Players        []PublicGamePlayer `json:"players"`
+	CurrentTurn    int64              `json:"current_turn"`
+	MoveCount      int                `json:"move_count"`
+	CreatedAt      time.Time          `json:"created_at"`
+	LastActivityAt time.Time          `json:"last_activity_at"`
+}
+
+// PublicGamePlayer est un joueur d'une partie publique et son score.
+type PublicGamePlayer struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Score    int    `json:"score"`
+}
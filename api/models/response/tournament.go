@@ -0,0 +1,69 @@
+package response
+
+import "time"
+
+// Tournament résume un tournoi.
+type Tournament struct {
+	ID                   string     `json:"id"`
+	Name                 string     `json:"name"`
+	Format               string     `json:"format"` // "round_robin" ou "swiss"
+	Rounds               int        `json:"rounds"` // 0 tant qu'un toutes-rondes n'a pas commencé
+	CurrentRound         int        `json:"current_round"`
+	Language             string     `json:"language"`
+	Variant              string     `json:"variant"`
+	Rules                *GameRules `json:"rules,omitempty"`
+	Status               string     `json:"status"` // "registration", "running", "finished" ou "cancelled"
+	RegistrationOpensAt  time.Time  `json:"registration_opens_at"`
+	RegistrationClosesAt time.Time  `json:"registration_closes_at"`
+	Organizer            string     `json:"organizer"`
+	PlayerCount          int        `json:"player_count"`
+	Registered           bool       `json:"registered"` // l'utilisateur connecté est inscrit
+	CreatedAt            time.Time  `json:"created_at"`
+	StartedAt            *time.Time `json:"started_at,omitempty"`
+	EndedAt              *time.Time `json:"ended_at,omitempty"`
+}
+
+// TournamentDetails est la vue complète d'un tournoi : joueurs, appariements
+// de chaque ronde et classement.
+type TournamentDetails struct {
+	Tournament
+	CanManage bool                 `json:"can_manage"` // organisateur ou administrateur
+	Players   []TournamentPlayer   `json:"players"`
+	Pairings  []TournamentPairing  `json:"pairings"`
+	Standings []TournamentStanding `json:"standings"`
+}
+
+// TournamentPlayer est un inscrit, dans l'ordre des têtes de série.
+type TournamentPlayer struct {
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Seed      int    `json:"seed"`
+	Withdrawn bool   `json:"withdrawn"`
+}
+
+// TournamentPairing est un appariement d'une ronde.
+type TournamentPairing struct {
+	Round     int     `json:"round"`
+	Player1ID int64   `json:"player1_id"`
+	Player1   string  `json:"player1"`
+	Player2ID *int64  `json:"player2_id,omitempty"` // absent pour un bye
+	Player2   string  `json:"player2,omitempty"`
+	GameID    *string `json:"game_id,omitempty"`
+	Result    string  `json:"result"` // "pending", "played", "bye" ou "forfeit"
+	Score1    int     `json:"score1"`
+	Score2    int     `json:"score2"`
+	WinnerID  *int64  `json:"winner_id,omitempty"` // absent en cas d'égalité ou tant que la partie n'est pas finie
+}
+
+// TournamentStanding est la ligne d'un joueur au classement.
+type TournamentStanding struct {
+	Rank      int     `json:"rank"`
+	UserID    int64   `json:"user_id"`
+	Username  string  `json:"username"`
+	Wins      float64 `json:"wins"` // une égalité compte pour une demi-victoire
+	Losses    float64 `json:"losses"`
+	Spread    int     `json:"spread"`
+	Played    int     `json:"played"`
+	Byes      int     `json:"byes"`
+	Withdrawn bool    `json:"withdrawn"`
+}
@@ -0,0 +1,26 @@
+package response
+
+// UnseenTiles est le décompte des tuiles qu'un joueur ne voit pas : sac et
+// racks adverses confondus, calculé à partir de la distribution initiale.
+type UnseenTiles struct {
+	GameID     string         `json:"game_id"`
+	PlayerID   int64          `json:"player_id"`
+	Total      int            `json:"total"`
+	InBag      int            `json:"in_bag"` // dont tuiles encore dans le sac
+	Tiles      []string       `json:"tiles"`
+	Counts     map[string]int `json:"counts"` // nombre d'exemplaires de chaque tuile
+	Vowels     int            `json:"vowels"`
+	Consonants int            `json:"consonants"`
+	Blanks     int            `json:"blanks"`
+	VowelRatio float64        `json:"vowel_ratio"` // voyelles / (voyelles + consonnes), jokers exclus
+	// OpponentRack est le rack exact de l'adversaire quand le sac est vide en face à face.
+	OpponentRack []string `json:"opponent_rack,omitempty"`
+	// Split est la répartition réelle entre sac et racks, pour l'admin seulement.
+	Split *UnseenSplit `json:"split,omitempty"`
+}
+
+// UnseenSplit répartit les tuiles invisibles entre le sac et chaque rack adverse.
+type UnseenSplit struct {
+	Bag   []string           `json:"bag"`
+	Racks map[int64][]string `json:"racks"`
+}
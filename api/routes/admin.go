@@ -18,4 +18,6 @@ func setupAdminRoutes(e *echo.Echo) {
 	a.GET("/user/:id", controller.GetAdminUserByID)
 	a.GET("/games", controller.GetAdminGames)
 	a.GET("/game/:id", controller.GetAdminGameByID)
+	a.GET("/game/:id/unseen", controller.GetAdminUnseenTiles)
+	a.POST("/games/import", controller.ImportGameGCG)
 }
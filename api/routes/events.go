@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/ZiplEix/scrabble/api/controller"
+	"github.com/ZiplEix/scrabble/api/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func setupEventsRoutes(e *echo.Echo) {
+	// flux SSE : le jeton peut aussi passer en ?access_token= (EventSource)
+	e.GET("/events", controller.StreamEvents, middleware.RequireStreamAuth)
+}
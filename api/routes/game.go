@@ -18,9 +18,40 @@ func setupGameRoutes(e *echo.Echo) {
 	g.DELETE("/:id/messages/:msg_id", controller.DeleteMessage)
 	g.POST("/:id/play", controller.PlayMove)
 	g.GET("", controller.GetUserGames)
+	g.GET("/invitations", controller.GetInvitations)
+	g.POST("/:id/invitation/accept", controller.AcceptInvitation)
+	g.POST("/:id/invitation/decline", controller.DeclineInvitation)
+	g.POST("/:id/start", controller.StartGame)
+	g.GET("/lobby", controller.ListLobbyGames)
+	g.POST("/:id/join", controller.JoinLobbyGame)
+	g.POST("/:id/invite-links", controller.CreateInviteLink)
+	g.GET("/:id/invite-links", controller.ListInviteLinks)
+	g.DELETE("/:id/invite-links/:link_id", controller.RevokeInviteLink)
+	g.POST("/invite-links/redeem", controller.RedeemInviteLink)
 	g.PUT("/:id/rename", controller.RenameGame)
+	g.PUT("/:id/visibility", controller.SetGameVisibility)
 	g.GET("/:id/new_rack", controller.GetNewRack)
+	g.GET("/:id/unseen", controller.GetUnseenTiles)
+	g.POST("/:id/exchange", controller.ExchangeTiles)
 	g.POST("/:id/simulate_score", controller.SimulateScore)
 	g.POST("/:id/message", controller.CreateMessage)
 	g.POST("/:id/pass", controller.PassTurn)
+	g.POST("/:id/resign", controller.ResignGame)
+	g.POST("/:id/challenge", controller.ChallengeMove)
+	g.POST("/:id/takeback", controller.RequestTakeback)
+	g.POST("/:id/takeback/accept", controller.AcceptTakeback)
+	g.POST("/:id/takeback/decline", controller.DeclineTakeback)
+
+	// duplicate : ronde en cours, tops et classement, et coup proposé pour la ronde
+	g.GET("/:id/duplicate", controller.GetDuplicateGame)
+	g.POST("/:id/duplicate", controller.SubmitDuplicateMove)
+
+	// rejeu, export GCG et audit des tirages publics des parties terminées (réservés aux joueurs tant qu'elles sont en cours)
+	e.GET("/game/:id/replay", controller.GetGameReplay, middleware.OptionalAuth)
+	e.GET("/game/:id/export.gcg", controller.ExportGameGCG, middleware.OptionalAuth)
+	e.GET("/game/:id/draws", controller.GetGameDraws, middleware.OptionalAuth)
+
+	// spectateurs : parties publiques en cours et vue sans racks selon la visibilité de la partie
+	e.GET("/game/public", controller.ListPublicGames, middleware.OptionalAuth)
+	e.GET("/game/:id/spectate", controller.SpectateGame, middleware.OptionalAuth)
 }
@@ -13,6 +13,8 @@ var nonImplementedHandler = func(c echo.Context) error {
 func SetupRoutes(e *echo.Echo) {
 	setupAuthRoutes(e)
 	setupGameRoutes(e)
+	setupMatchmakingRoutes(e)
+	setupTournamentRoutes(e)
 	setupPuzzleRoutes(e)
 	setupReportRoutes(e)
 	setupUsersRoutes(e)
@@ -20,4 +22,5 @@ func SetupRoutes(e *echo.Echo) {
 	SetupMeRoutes(e)
 	setupAdminRoutes(e)
 	setupDictionaryRoutes(e)
+	setupEventsRoutes(e)
 }
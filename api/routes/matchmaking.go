@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/ZiplEix/scrabble/api/controller"
+	"github.com/ZiplEix/scrabble/api/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func setupMatchmakingRoutes(e *echo.Echo) {
+	m := e.Group("/matchmaking", middleware.RequireAuth)
+
+	// inscription (ou mise à jour des préférences), état et sortie de la file
+	m.POST("", controller.JoinMatchmaking)
+	m.GET("", controller.GetMatchmaking)
+	m.DELETE("", controller.LeaveMatchmaking)
+}
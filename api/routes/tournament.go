@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/ZiplEix/scrabble/api/controller"
+	"github.com/ZiplEix/scrabble/api/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func setupTournamentRoutes(e *echo.Echo) {
+	t := e.Group("/tournaments", middleware.RequireAuth)
+
+	t.POST("", controller.CreateTournament)
+	t.GET("", controller.ListTournaments)
+	t.GET("/:id", controller.GetTournament)
+
+	// inscriptions, pendant la fenêtre d'inscription
+	t.POST("/:id/register", controller.RegisterForTournament)
+	t.DELETE("/:id/register", controller.UnregisterFromTournament)
+
+	// retrait : le joueur lui-même, ou l'organisateur pour un autre joueur
+	t.POST("/:id/withdraw", controller.WithdrawFromTournament)
+
+	// organisateur ou administrateur
+	t.POST("/:id/start", controller.StartTournament)
+	t.POST("/:id/byes", controller.AssignTournamentBye)
+	t.DELETE("/:id/byes/:round/:user_id", controller.RemoveTournamentBye)
+	t.POST("/:id/forfeit", controller.ForfeitTournamentGame)
+}
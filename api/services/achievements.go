@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/events"
 	"github.com/ZiplEix/scrabble/api/models/request"
 	"github.com/ZiplEix/scrabble/api/pkg/logger"
 	"context"
@@ -12,7 +13,7 @@ import (
 
 // UnlockAchievement débloque un succès pour un utilisateur
 func UnlockAchievement(userID int64, achievementID string) error {
-	_, err := database.Exec(`
+	result, err := database.Exec(`
 		INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
 		VALUES ($1, $2, now())
 		ON CONFLICT (user_id, achievement_id) DO NOTHING
@@ -21,6 +22,10 @@ func UnlockAchievement(userID int64, achievementID string) error {
 		logger.Error(context.Background(), "Failed to unlock achievement", "user_id", userID, "achievement_id", achievementID, "error", err)
 		return err
 	}
+	// prévient le joueur en temps réel, seulement la première fois
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		events.Publish([]int64{userID}, events.New(events.AchievementUnlocked, "", map[string]string{"achievement_id": achievementID}))
+	}
 	return nil
 }
 
@@ -51,7 +56,7 @@ func CheckAndUnlockPlayMoveAchievements(userID int64, letters []request.PlacedLe
 
 	// 4. Premier Pas (placer son tout premier mot dans une partie)
 	var moveCount int
-	err := database.QueryRow(`SELECT COUNT(*) FROM game_moves WHERE player_id = $1`, userID).Scan(&moveCount)
+	err := database.QueryRow(`SELECT COUNT(*) FROM game_moves WHERE player_id = $1 AND move->>'type' = 'play' AND COALESCE(move->>'status', '') NOT IN ('withdrawn', 'retracted')`, userID).Scan(&moveCount)
 	if err == nil && moveCount == 1 {
 		_ = UnlockAchievement(userID, "first_step")
 	}
@@ -79,26 +84,24 @@ func CheckAndUnlockPlayMoveAchievements(userID int64, letters []request.PlacedLe
 	}
 }
 
-// CheckAndUnlockGameFinishedAchievements vérifie et débloque les succès liés à la fin de partie
-func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playerIDs []int64) {
+// CheckAndUnlockGameFinishedAchievements vérifie et débloque les succès liés à la fin de partie.
+// forfeitedID est le joueur ayant perdu par forfait (0 sinon) : une victoire par forfait ne
+// débloque aucun succès de victoire et le joueur forfait ne progresse sur aucun succès.
+func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playerIDs []int64, forfeitedID int64) {
 	// 1. Premier Sang (première victoire)
-	if winnerID != 0 {
+	if winnerID != 0 && forfeitedID == 0 {
 		var winCount int
 		err := database.QueryRow(`
 			SELECT COUNT(*) FROM games 
-			WHERE status = 'ended' AND winner_username = (SELECT username FROM users WHERE id = $1)
+			WHERE status = 'ended' AND forfeited_by IS NULL AND winner_username = (SELECT username FROM users WHERE id = $1)
 		`, winnerID).Scan(&winCount)
 		if err == nil && winCount == 1 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'first_blood', now())
-				ON CONFLICT DO NOTHING
-			`, winnerID)
+			_ = UnlockAchievement(winnerID, "first_blood")
 		}
 	}
 
 	// 11. Tueur de Géants (battre le bot Scrabby en 1vs1)
-	if BotUserID != -1 && len(playerIDs) == 2 && winnerID != 0 && winnerID != BotUserID {
+	if BotUserID != -1 && len(playerIDs) == 2 && winnerID != 0 && winnerID != BotUserID && forfeitedID == 0 {
 		hasBot := false
 		for _, pid := range playerIDs {
 			if pid == BotUserID {
@@ -107,24 +110,19 @@ func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playe
 			}
 		}
 		if hasBot {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'bot_slayer', now())
-				ON CONFLICT DO NOTHING
-			`, winnerID)
+			_ = UnlockAchievement(winnerID, "bot_slayer")
 		}
 	}
 
 	for _, pid := range playerIDs {
+		if pid == forfeitedID {
+			continue
+		}
 		// 2. Grand Maître (> 400 points dans la partie)
 		var score int
 		err := database.QueryRow(`SELECT score FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, pid).Scan(&score)
 		if err == nil && score > 400 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'scrabble_master', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "scrabble_master")
 		}
 
 		// 3. Marathonien (jouer 10 parties complètes)
@@ -135,20 +133,12 @@ func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playe
 			WHERE gp.player_id = $1 AND g.status = 'ended'
 		`, pid).Scan(&completeGames)
 		if err == nil && completeGames >= 10 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'marathoner', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "marathoner")
 		}
 
 		// 4. Vétéran (jouer 50 parties complètes)
 		if err == nil && completeGames >= 50 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'veteran', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "veteran")
 		}
 
 		// 5. Rivalité Amicale (jouer contre au moins 3 adversaires différents)
@@ -161,20 +151,12 @@ func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playe
 			WHERE gp1.player_id = $1 AND gp2.player_id != $1 AND g.status = 'ended'
 		`, pid).Scan(&opponentsCount)
 		if err == nil && opponentsCount >= 3 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'friendly_rivalry', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "friendly_rivalry")
 		}
 
 		// 6. Légende du Club (marquer plus de 500 points dans une seule partie)
 		if err == nil && score > 500 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'elite_player', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "elite_player")
 		}
 
 		// 7. Série Victorieuse (remporter 3 victoires consécutives)
@@ -193,32 +175,20 @@ func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playe
 			WHERE winner_username = username
 		`, pid).Scan(&winsInARow)
 		if err == nil && winsInARow == 3 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'serial_winner', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "serial_winner")
 		}
 
 		// 8. Oiseau de Nuit (terminer une partie entre 23h et 5h du matin)
 		hour := time.Now().Hour()
 		if hour >= 23 || hour < 5 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'night_owl', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "night_owl")
 		}
 
 		// 9. Stratège (atteindre un classement de 500 IPS ou plus)
 		var rating int
 		err = database.QueryRow(`SELECT rating FROM users WHERE id = $1`, pid).Scan(&rating)
 		if err == nil && rating >= 500 {
-			_, _ = database.Exec(`
-				INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-				VALUES ($1, 'ips_master', now())
-				ON CONFLICT DO NOTHING
-			`, pid)
+			_ = UnlockAchievement(pid, "ips_master")
 		}
 	}
 
@@ -241,11 +211,7 @@ func CheckAndUnlockGameFinishedAchievements(gameID string, winnerID int64, playe
 			rows.Close()
 
 			if len(scores) == 2 && (scores[0]-scores[1]) < 10 {
-				_, _ = database.Exec(`
-					INSERT INTO user_achievements (user_id, achievement_id, unlocked_at)
-					VALUES ($1, 'comeback_kid', now())
-					ON CONFLICT DO NOTHING
-				`, winnerID)
+				_ = UnlockAchievement(winnerID, "comeback_kid")
 			}
 		}
 	}
@@ -6,10 +6,13 @@ import (
 	"math/rand"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
 	"github.com/ZiplEix/scrabble/api/models/request"
 	"github.com/ZiplEix/scrabble/api/pkg/logger"
 	"github.com/ZiplEix/scrabble/api/word"
@@ -39,7 +42,7 @@ func InitBot() {
 }
 
 // TriggerBotIfNeeded vérifie si le prochain joueur est le bot et le déclenche en goroutine.
-// Doit être appelé après chaque changement de tour (PlayMove, PassTurn, GetNewRack).
+// Doit être appelé après chaque changement de tour (PlayMove, PassTurn, ExchangeTiles).
 func TriggerBotIfNeeded(gameID string, currentTurnUserID int64) {
 	if BotUserID == -1 || currentTurnUserID != BotUserID {
 		return
@@ -104,36 +107,42 @@ func playBotTurn(gameID string) error {
 	defer activeBotGames.Delete(gameID)
 
 	// Recharger l'état complet : on vérifie que c'est bien au bot
-	var currentTurn int64
-	var status string
-	var rackStr string
 	var difficulty string
 	err := database.QueryRow(
-		`SELECT current_turn, status, difficulty FROM games WHERE id = $1`, gameID,
-	).Scan(&currentTurn, &status, &difficulty)
+		`SELECT difficulty FROM games WHERE id = $1`, gameID,
+	).Scan(&difficulty)
 	if err != nil {
 		return fmt.Errorf("bot: failed to load game state: %w", err)
 	}
-	if status != "ongoing" || currentTurn != BotUserID {
-		return nil // plus notre tour ou partie terminée
-	}
-
-	// Charger le rack du bot
-	err = database.QueryRow(
-		`SELECT rack FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, BotUserID,
-	).Scan(&rackStr)
+	state, err := loadGameState(database.DB, gameID)
 	if err != nil {
-		return fmt.Errorf("bot: failed to load bot rack: %w", err)
+		return fmt.Errorf("bot: failed to load game state: %w", err)
+	}
+	if state.Ended || state.Turn != BotUserID {
+		return nil // plus notre tour ou partie terminée
 	}
 
-	// Charger le plateau
-	board, err := LoadBoard(gameID)
-	if err != nil {
-		return fmt.Errorf("bot: failed to load board: %w", err)
+	// Partie avec contestation : Scrabby conteste les mots invalides du coup précédent
+	if _, _, pending, err := lastPendingMove(database.DB, gameID); err == nil {
+		if len(engine.InvalidWords(state.Language, state.Board, pending.Letters)) > 0 {
+			logger.Info(context.Background(), "bot: challenging previous move", "game_id", gameID, "word", pending.Word)
+			res, err := ChallengeMove(BotUserID, gameID)
+			if err != nil {
+				return fmt.Errorf("bot: failed to challenge move: %w", err)
+			}
+			if res.GameOver || res.NextTurn != BotUserID {
+				return nil
+			}
+			// Contestation réussie : le bot garde la main sur le plateau restauré
+			state, err = loadGameState(database.DB, gameID)
+			if err != nil {
+				return fmt.Errorf("bot: failed to load game state: %w", err)
+			}
+		}
 	}
 
 	// Chercher le meilleur coup
-	bestMove := findBestMove(board, rackStr, gameID, difficulty)
+	bestMove := findBestMove(state.Language, state.Layout, state.Rules, state.Board, state.Racks[BotUserID], state.Blanks, difficulty)
 
 	if bestMove != nil {
 		logger.Info(context.Background(), "bot: playing move", "game_id", gameID, "word", bestMove.Word, "score", bestMove.Score)
@@ -144,15 +153,15 @@ func playBotTurn(gameID string) error {
 		return err
 	}
 
-	// Aucun coup trouvé → tenter l'échange
+	// Aucun coup trouvé → échanger les mauvaises lettres en gardant un bon reliquat
 	logger.Info(context.Background(), "bot: no valid move found, trying rack exchange", "game_id", gameID)
-	_, err = GetNewRack(BotUserID, gameID)
+	_, err = exchangeTiles(BotUserID, gameID, "", chooseExchange)
 	if err == nil {
 		go maybeSendBotTaunt(gameID, 0, true)
 		return nil
 	}
 
-	// Échange impossible (sac vide) → passer
+	// Échange impossible (moins de 7 lettres dans le sac) → passer
 	logger.Info(context.Background(), "bot: rack exchange failed, passing turn", "game_id", gameID)
 	err = PassTurn(BotUserID, gameID)
 	if err == nil {
@@ -161,6 +170,77 @@ func playBotTurn(gameID string) error {
 	return err
 }
 
+// leaveValues donne la valeur approximative d'une lettre conservée sur le rack
+// (joker et S en tête, lettres lourdes et difficiles à placer en queue).
+var leaveValues = map[word.Tile]float64{
+	"?": 25, "S": 8, "E": 4, "R": 3, "N": 2, "A": 1.5, "I": 1, "T": 1, "L": 1,
+	"U": 0, "O": 0, "D": 0, "M": 0, "C": 0, "P": 0, "X": 0,
+	"H": -2, "G": -2, "B": -2, "F": -2, "Z": -2, "Y": -3, "J": -3,
+	"V": -4, "Q": -6, "K": -7, "W": -8,
+}
+
+// leaveScore évalue la qualité d'un reliquat : valeur des lettres, pénalité
+// pour les doublons et pour le déséquilibre voyelles/consonnes.
+func leaveScore(leave word.Tiles) float64 {
+	score := 0.0
+	seen := map[word.Tile]int{}
+	vowels, consonants := 0, 0
+	for _, t := range leave {
+		score += leaveValues[t]
+		if t != word.BlankTile && seen[t] > 0 {
+			score -= 3 * float64(seen[t])
+		}
+		seen[t]++
+		switch {
+		case t == word.BlankTile:
+		case t.IsVowel():
+			vowels++
+		default:
+			consonants++
+		}
+	}
+	diff := vowels - consonants
+	if diff < 0 {
+		diff = -diff
+	}
+	return score - 2*float64(diff)
+}
+
+// chooseExchange retourne les tuiles que le bot doit remettre dans le sac :
+// on teste tous les sous-ensembles du rack et on conserve celui qui a le
+// meilleur leaveScore (au moins une tuile est toujours échangée).
+func chooseExchange(rack word.Tiles) word.Tiles {
+	tiles := rack
+	n := len(tiles)
+	if n == 0 {
+		return word.Tiles{}
+	}
+
+	bestKeep := 0
+	bestScore := 0.0
+	first := true
+	full := 1<<n - 1
+	for mask := 0; mask < full; mask++ {
+		var leave word.Tiles
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 {
+				leave = append(leave, tiles[i])
+			}
+		}
+		if sc := leaveScore(leave); first || sc > bestScore {
+			bestKeep, bestScore, first = mask, sc, false
+		}
+	}
+
+	var out word.Tiles
+	for i := 0; i < n; i++ {
+		if bestKeep&(1<<i) == 0 {
+			out = append(out, tiles[i])
+		}
+	}
+	return out
+}
+
 // candidate représente un coup candidat avec son score.
 type candidate struct {
 	move  request.PlayMoveRequest
@@ -172,32 +252,20 @@ type anchorCell struct {
 	letter rune // '?' if empty (adjacent to occupied)
 }
 
-func getAnchorCells(board [15][15]string) []anchorCell {
+func getAnchorCells(lang *word.Language, layout *engine.Layout, board engine.Board) []anchorCell {
 	var anchors []anchorCell
 
-	isEmpty := true
-	for y := 0; y < 15; y++ {
-		for x := 0; x < 15; x++ {
-			if board[y][x] != "" {
-				isEmpty = false
-				break
-			}
-		}
-		if !isEmpty {
-			break
-		}
+	if engine.IsBoardEmpty(board) {
+		return []anchorCell{{x: layout.Center, y: layout.Center, letter: '?'}}
 	}
 
-	if isEmpty {
-		return []anchorCell{{x: 7, y: 7, letter: '?'}}
-	}
-
-	var emptyAdded [15][15]bool
-	for y := 0; y < 15; y++ {
-		for x := 0; x < 15; x++ {
+	size := board.Size()
+	emptyAdded := make(map[Pos]bool)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
 			if board[y][x] != "" {
 				// Case occupée
-				anchors = append(anchors, anchorCell{x: x, y: y, letter: rune(board[y][x][0])})
+				anchors = append(anchors, anchorCell{x: x, y: y, letter: lang.TileRune(word.Tile(board[y][x]))})
 			} else {
 				// Case vide, vérifier si adjacente à une case occupée
 				neighbors := [][2]int{
@@ -207,14 +275,14 @@ func getAnchorCells(board [15][15]string) []anchorCell {
 				isAdjacent := false
 				for _, n := range neighbors {
 					nx, ny := n[0], n[1]
-					if nx >= 0 && nx < 15 && ny >= 0 && ny < 15 && board[ny][nx] != "" {
+					if board.InBounds(nx, ny) && board[ny][nx] != "" {
 						isAdjacent = true
 						break
 					}
 				}
-				if isAdjacent && !emptyAdded[y][x] {
+				if isAdjacent && !emptyAdded[Pos{X: x, Y: y}] {
 					anchors = append(anchors, anchorCell{x: x, y: y, letter: '?'})
-					emptyAdded[y][x] = true
+					emptyAdded[Pos{X: x, Y: y}] = true
 				}
 			}
 		}
@@ -247,7 +315,7 @@ func canFormWord(w string, rackCounts map[rune]int, wildcards int, boardLetters
 	}
 
 	// Un coup valide doit poser au moins 1 lettre du rack
-	if len(w) <= usedBoardLetters {
+	if utf8.RuneCountInString(w) <= usedBoardLetters {
 		return false
 	}
 
@@ -256,17 +324,51 @@ func canFormWord(w string, rackCounts map[rune]int, wildcards int, boardLetters
 
 // findBestMove explore exhaustivement tous les placements légaux et retourne celui avec le score maximum.
 // Utilise un algorithme ultra-rapide basé sur le pré-filtrage du dictionnaire.
+// boardBlanks indique les positions des jokers déjà posés, pour un calcul de score exact.
+// Le dictionnaire et les valeurs des lettres sont ceux de lang (français si nil),
+// la géométrie et les cases spéciales celles de layout (plateau standard si nil),
+// et les coups sont classés selon le barème de rules (DefaultRuleset si non renseignées).
+// Chaque tuile est manipulée sous la forme d'une rune (voir word.Language.TileRune),
+// ce qui couvre aussi les tuiles de plusieurs lettres.
 // Retourne nil si aucun coup valide n'est trouvé.
-func findBestMove(board [15][15]string, rack string, gameID string, difficulty string) *request.PlayMoveRequest {
-	boardBlanks := BuildBoardBlanks(gameID)
-	boardIsEmpty := isBoardEmpty(board)
+func findBestMove(lang *word.Language, layout *engine.Layout, rules engine.Ruleset, board engine.Board, tiles word.Tiles, boardBlanks map[Pos]bool, difficulty string) *request.PlayMoveRequest {
+	if lang == nil {
+		lang = word.French
+	}
+	if layout == nil {
+		layout = engine.StandardLayout
+	}
+	if rules == (engine.Ruleset{}) {
+		rules = engine.DefaultRuleset()
+	}
+	boardIsEmpty := engine.IsBoardEmpty(board)
+
+	rackRunes := make([]rune, len(tiles))
+	for i, t := range tiles {
+		rackRunes[i] = lang.TileRune(t)
+	}
+	rack := string(rackRunes)
+
+	// Les mots du dictionnaire sont découpés en tuiles pour les langues à digrammes
+	encode := func(w string) string { return w }
+	decode := encode
+	if len(lang.Digraphs) > 0 {
+		encode = func(w string) string { return string(lang.WordRunes(w)) }
+		decode = func(w string) string {
+			var b strings.Builder
+			for _, r := range w {
+				b.WriteString(string(lang.RuneTile(r)))
+			}
+			return b.String()
+		}
+	}
 
 	// Collecter les lettres uniques présentes sur le plateau
 	boardLetters := make(map[rune]bool)
-	for y := 0; y < 15; y++ {
-		for x := 0; x < 15; x++ {
-			if board[y][x] != "" {
-				boardLetters[rune(board[y][x][0])] = true
+	for _, row := range board {
+		for _, cell := range row {
+			if cell != "" {
+				boardLetters[lang.TileRune(word.Tile(cell))] = true
 			}
 		}
 	}
@@ -284,9 +386,10 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 
 	// 1. Filtrer tout le dictionnaire en < 5ms
 	var candidates []string
-	for _, w := range word.AllWords() {
+	for _, w := range lang.AllWords() {
+		w = encode(w)
 		// Pas la peine de tester les mots trop courts ou trop longs pour le plateau
-		if len(w) < 2 || len(w) > 15 {
+		if n := utf8.RuneCountInString(w); n < 2 || n > board.Size() {
 			continue
 		}
 		if canFormWord(w, rackCounts, wildcards, boardLetters) {
@@ -304,7 +407,7 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 		name   string
 	}
 	directions := []dir{{1, 0, "H"}, {0, 1, "V"}}
-	anchors := getAnchorCells(board)
+	anchors := getAnchorCells(lang, layout, board)
 
 	// 2. Paralléliser l'évaluation des candidats sur les CPU disponibles
 	numWorkers := runtime.NumCPU()
@@ -357,7 +460,7 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 								// Vérifier les limites du plateau
 								endX := startX + (wLen-1)*d.dx
 								endY := startY + (wLen-1)*d.dy
-								if startX < 0 || startY < 0 || endX >= 15 || endY >= 15 {
+								if !board.InBounds(startX, startY) || !board.InBounds(endX, endY) {
 									continue
 								}
 
@@ -367,27 +470,27 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 								}
 								seen[key] = true
 
-								placed, valid := buildPlacement(board, wRunes, startX, startY, d.dx, d.dy, rack, ac.letter, posInWord)
+								placed, valid := buildPlacement(lang, board, wRunes, startX, startY, d.dx, d.dy, rack, ac.letter, posInWord)
 								if !valid || len(placed) == 0 {
 									continue
 								}
 
-								if !isConnected(board, placed) {
+								if !engine.IsConnected(board, placed) {
 									continue
 								}
 
 								// Valider les mots formés
-								boardCopy := board
-								if err := ApplyLetters(&boardCopy, placed); err != nil {
+								boardCopy := board.Clone()
+								if err := engine.ApplyLetters(boardCopy, placed); err != nil {
 									continue
 								}
-								formedWords := extractFormedWords(boardCopy, placed)
+								formedWords := engine.ExtractFormedWords(boardCopy, placed)
 								if len(formedWords) == 0 {
 									continue
 								}
 								allValid := true
 								for _, fw := range formedWords {
-									if !word.WordExists(fw.Word) {
+									if !lang.WordExists(fw.Word) {
 										allValid = false
 										break
 									}
@@ -396,9 +499,9 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 									continue
 								}
 
-								score := ComputeMoveScore(boardCopy, placed, boardBlanks)
+								score := rules.MoveScore(lang, layout, boardCopy, placed, boardBlanks)
 								move := request.PlayMoveRequest{
-									Word:      w,
+									Word:      decode(w),
 									StartX:    startX,
 									StartY:    startY,
 									Direction: d.name,
@@ -420,7 +523,7 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 
 								endX := startX + (wLen-1)*d.dx
 								endY := startY + (wLen-1)*d.dy
-								if startX < 0 || startY < 0 || endX >= 15 || endY >= 15 {
+								if !board.InBounds(startX, startY) || !board.InBounds(endX, endY) {
 									continue
 								}
 
@@ -430,7 +533,7 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 								}
 								seen[key] = true
 
-								placed, valid := buildPlacement(board, wRunes, startX, startY, d.dx, d.dy, rack, '?', posInWord)
+								placed, valid := buildPlacement(lang, board, wRunes, startX, startY, d.dx, d.dy, rack, '?', posInWord)
 								if !valid || len(placed) == 0 {
 									continue
 								}
@@ -438,7 +541,7 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 								if boardIsEmpty {
 									touchesCenter := false
 									for _, pl := range placed {
-										if pl.X == 7 && pl.Y == 7 {
+										if pl.X == layout.Center && pl.Y == layout.Center {
 											touchesCenter = true
 											break
 										}
@@ -447,22 +550,22 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 										continue
 									}
 								} else {
-									if !isConnected(board, placed) {
+									if !engine.IsConnected(board, placed) {
 										continue
 									}
 								}
 
-								boardCopy := board
-								if err := ApplyLetters(&boardCopy, placed); err != nil {
+								boardCopy := board.Clone()
+								if err := engine.ApplyLetters(boardCopy, placed); err != nil {
 									continue
 								}
-								formedWords := extractFormedWords(boardCopy, placed)
+								formedWords := engine.ExtractFormedWords(boardCopy, placed)
 								if len(formedWords) == 0 {
 									continue
 								}
 								allValid := true
 								for _, fw := range formedWords {
-									if !word.WordExists(fw.Word) {
+									if !lang.WordExists(fw.Word) {
 										allValid = false
 										break
 									}
@@ -471,9 +574,9 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 									continue
 								}
 
-								score := ComputeMoveScore(boardCopy, placed, boardBlanks)
+								score := rules.MoveScore(lang, layout, boardCopy, placed, boardBlanks)
 								move := request.PlayMoveRequest{
-									Word:      w,
+									Word:      decode(w),
 									StartX:    startX,
 									StartY:    startY,
 									Direction: d.name,
@@ -529,7 +632,8 @@ func findBestMove(board [15][15]string, rack string, gameID string, difficulty s
 // en vérifiant la compatibilité avec les cases déjà occupées et le rack disponible.
 // Retourne les lettres à poser et un booléen de validité.
 func buildPlacement(
-	board [15][15]string,
+	lang *word.Language,
+	board engine.Board,
 	wordRunes []rune,
 	startX, startY, dx, dy int,
 	rack string,
@@ -551,7 +655,7 @@ func buildPlacement(
 
 		if existing != "" {
 			// Case occupée : doit correspondre exactement à la lettre du mot
-			if existing != string(letter) {
+			if lang.TileRune(word.Tile(existing)) != letter {
 				return nil, false
 			}
 			// La lettre vient du plateau, pas du rack
@@ -574,7 +678,7 @@ func buildPlacement(
 		placed = append(placed, request.PlacedLetter{
 			X:     x,
 			Y:     y,
-			Char:  string(letter),
+			Char:  string(lang.RuneTile(letter)),
 			Blank: isBlank,
 		})
 	}
@@ -588,14 +692,14 @@ func buildPlacement(
 	// (sinon le mot serait en réalité plus long)
 	beforeX := startX - dx
 	beforeY := startY - dy
-	if beforeX >= 0 && beforeX < 15 && beforeY >= 0 && beforeY < 15 {
+	if board.InBounds(beforeX, beforeY) {
 		if board[beforeY][beforeX] != "" {
 			return nil, false
 		}
 	}
 	endX := startX + len(wordRunes)*dx
 	endY := startY + len(wordRunes)*dy
-	if endX >= 0 && endX < 15 && endY >= 0 && endY < 15 {
+	if board.InBounds(endX, endY) {
 		if board[endY][endX] != "" {
 			return nil, false
 		}
@@ -604,35 +708,6 @@ func buildPlacement(
 	return placed, true
 }
 
-// isConnected vérifie qu'au moins une des lettres posées est adjacente à une tuile existante du plateau.
-func isConnected(board [15][15]string, placed []request.PlacedLetter) bool {
-	for _, pl := range placed {
-		neighbors := [][2]int{
-			{pl.X - 1, pl.Y}, {pl.X + 1, pl.Y},
-			{pl.X, pl.Y - 1}, {pl.X, pl.Y + 1},
-		}
-		for _, n := range neighbors {
-			nx, ny := n[0], n[1]
-			if nx >= 0 && nx < 15 && ny >= 0 && ny < 15 && board[ny][nx] != "" {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-// isBoardEmpty retourne true si le plateau est entièrement vide.
-func isBoardEmpty(board [15][15]string) bool {
-	for y := 0; y < 15; y++ {
-		for x := 0; x < 15; x++ {
-			if board[y][x] != "" {
-				return false
-			}
-		}
-	}
-	return true
-}
-
 // uniqueRunes retourne les runes uniques présentes dans la tranche.
 func uniqueRunes(runes []rune) []rune {
 	seen := map[rune]bool{}
@@ -659,10 +734,10 @@ func IsBotGame(gameID string) bool {
 	return count > 0
 }
 
-// FindBestMoveStandalone explore tous les placements légaux sur un plateau donné avec un rack donné,
+// FindBestMoveStandalone explore tous les placements légaux sur un plateau standard donné avec un rack donné,
 // sans nécessiter de connexion à la base de données.
-func FindBestMoveStandalone(board [15][15]string, rack string) *request.PlayMoveRequest {
-	return findBestMove(board, rack, "", "hard")
+func FindBestMoveStandalone(board engine.Board, rack string) *request.PlayMoveRequest {
+	return findBestMove(word.French, engine.StandardLayout, engine.DefaultRuleset(), board, word.ParseTiles(rack), map[Pos]bool{}, "hard")
 }
 
 // maybeSendBotTaunt choisit et envoie aléatoirement une réplique amusante dans le chat de la partie
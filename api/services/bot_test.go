@@ -0,0 +1,32 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestChooseExchange_KeepsGoodLeave(t *testing.T) {
+	out := chooseExchange(word.ParseTiles("SE?KWQA")).String()
+	for _, r := range "KWQ" {
+		if !strings.ContainsRune(out, r) {
+			t.Fatalf("expected %c to be exchanged, got %q", r, out)
+		}
+	}
+	for _, r := range "S?" {
+		if strings.ContainsRune(out, r) {
+			t.Fatalf("expected %c to be kept, got %q", r, out)
+		}
+	}
+}
+
+func TestChooseExchange_AlwaysExchangesAtLeastOneTile(t *testing.T) {
+	out := chooseExchange(word.ParseTiles("??SSERA")).String()
+	if out == "" {
+		t.Fatalf("expected at least one tile to be exchanged")
+	}
+	if len(out) > 6 {
+		t.Fatalf("expected a partial exchange, got %q", out)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/events"
 	"github.com/ZiplEix/scrabble/api/utils"
 	"github.com/ZiplEix/scrabble/api/pkg/logger"
 	"context"
@@ -142,6 +143,7 @@ func CreateMessage(userID int64, gameID, content string, meta map[string]any) (m
 		"meta":       meta,
 		"created_at": createdAt,
 	}
+	publishChatEvent(gameID, events.ChatMessage, msg)
 	return msg, nil
 }
 
@@ -155,7 +157,11 @@ func GetMessages(userID int64, gameID string) ([]map[string]any, error) {
 	if !inGame {
 		return nil, fmt.Errorf("user not in game")
 	}
+	return loadMessages(gameID)
+}
 
+// loadMessages retourne les messages non supprimés de la partie, du plus ancien au plus récent.
+func loadMessages(gameID string) ([]map[string]any, error) {
 	rows, err := database.Query(`SELECT id, user_id, content, meta, created_at FROM messages WHERE game_id = $1 AND deleted_at IS NULL ORDER BY created_at ASC`, gameID)
 	if err != nil {
 		return nil, err
@@ -202,9 +208,9 @@ func DeleteMessage(userID int64, gameID, msgID string) error {
 	}
 
 	// check ownership and existence
-	var ownerID int64
+	var id, ownerID int64
 	var dbGameID string
-	err = database.QueryRow(`SELECT user_id, game_id FROM messages WHERE id = $1 AND deleted_at IS NULL`, msgID).Scan(&ownerID, &dbGameID)
+	err = database.QueryRow(`SELECT id, user_id, game_id FROM messages WHERE id = $1 AND deleted_at IS NULL`, msgID).Scan(&id, &ownerID, &dbGameID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("not found")
@@ -223,6 +229,7 @@ func DeleteMessage(userID int64, gameID, msgID string) error {
 	if err != nil {
 		return err
 	}
+	publishChatEvent(gameID, events.MessageDeleted, map[string]any{"id": id})
 	return nil
 }
 
@@ -35,11 +35,11 @@ func createGameWithPlayers(t *testing.T, uids ...int64) string {
 	_, err := database.Exec(`
         INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, created_at)
         VALUES ($1, $2, $3, 'ongoing', NULL, $4::jsonb, $5, now())
-    `, gid, "chat-game", uids[0], "[]", "")
+    `, gid, "chat-game", uids[0], "[]", "[]")
 	require.NoError(t, err)
 	pos := 1
 	for _, uid := range uids {
-		_, err := database.Exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ($1, $2, '', $3, 0)`, gid, uid, pos)
+		_, err := database.Exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ($1, $2, '[]', $3, 0)`, gid, uid, pos)
 		require.NoError(t, err)
 		pos++
 	}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/utils"
+)
+
+// Actions appliquées quand le délai de jeu d'un tour est écoulé.
+const (
+	TimeoutPass    = "pass"    // le tour est passé automatiquement
+	TimeoutForfeit = "forfeit" // le joueur perd la partie par forfait
+)
+
+// MaxTurnTimeLimitHours borne le délai de jeu configurable (une semaine).
+const MaxTurnTimeLimitHours = 7 * 24
+
+// deadlineReminderBefore est le délai restant à partir duquel le joueur est
+// relancé (ou le quart du délai de jeu s'il est plus court).
+const deadlineReminderBefore = 6 * time.Hour
+
+var (
+	// ErrInvalidTurnTimeLimit est renvoyée quand le délai de jeu demandé est hors bornes.
+	ErrInvalidTurnTimeLimit = errors.New("invalid turn time limit")
+	// ErrInvalidTimeoutAction est renvoyée quand l'action à l'expiration n'existe pas.
+	ErrInvalidTimeoutAction = errors.New("invalid timeout action")
+)
+
+// timeoutMoveRecord est la forme stockée d'un tour passé automatiquement ou
+// d'un forfait, avec sa cause.
+type timeoutMoveRecord struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// timeoutReason est la cause enregistrée pour un délai de jeu dépassé.
+const timeoutReason = "timeout"
+
+// validateTurnTimeLimit vérifie le délai de jeu (en heures, 0 = illimité) et
+// l'action appliquée à son expiration (TimeoutPass par défaut).
+func validateTurnTimeLimit(hours int, action string) (int, string, error) {
+	if hours < 0 || hours > MaxTurnTimeLimitHours {
+		return 0, "", ErrInvalidTurnTimeLimit
+	}
+	switch action {
+	case "":
+		action = TimeoutPass
+	case TimeoutPass, TimeoutForfeit:
+	default:
+		return 0, "", ErrInvalidTimeoutAction
+	}
+	return hours, action, nil
+}
+
+// turnDeadlineSQL retourne l'expression SQL de l'échéance d'un tour commençant
+// maintenant, pour un délai en heures donné par hoursExpr (NULL si illimité).
+func turnDeadlineSQL(hoursExpr string) string {
+	return fmt.Sprintf("CASE WHEN %[1]s > 0 THEN now() + make_interval(hours => %[1]s) END", hoursExpr)
+}
+
+// StartDeadlineWorker lance la goroutine qui relance les joueurs dont le délai
+// de jeu arrive à échéance et applique l'action de la partie (passe ou
+// forfait) quand il est dépassé. Intervalle en secondes.
+func StartDeadlineWorker(intervalSeconds int) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := sendDeadlineReminders(); err != nil {
+				logger.Error(context.Background(), "deadline: reminder query failed", "error", err)
+			}
+			if err := expireOverdueTurns(); err != nil {
+				logger.Error(context.Background(), "deadline: poll query failed", "error", err)
+			}
+		}
+	}()
+	logger.Info(context.Background(), "deadline: worker started", "interval_seconds", intervalSeconds)
+}
+
+// sendDeadlineReminders envoie une notification aux joueurs dont le délai de
+// jeu expire bientôt. Chaque tour n'est rappelé qu'une fois.
+func sendDeadlineReminders() error {
+	rows, err := database.Query(`
+		UPDATE games
+		   SET deadline_reminded = TRUE
+		 WHERE status = 'ongoing'
+		   AND NOT deadline_reminded
+		   AND turn_deadline > now()
+		   AND turn_deadline <= now() + LEAST(make_interval(hours => turn_time_limit_hours) / 4, $1::interval)
+		   AND current_turn <> $2
+		RETURNING id, name, current_turn, timeout_action, EXTRACT(EPOCH FROM turn_deadline - now())::int
+	`, fmt.Sprintf("%d seconds", int(deadlineReminderBefore.Seconds())), BotUserID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			gameID, name, action string
+			playerID             int64
+			remaining            int
+		)
+		if err := rows.Scan(&gameID, &name, &playerID, &action, &remaining); err != nil {
+			return err
+		}
+		consequence := "ton tour sera passé"
+		if action == TimeoutForfeit {
+			consequence = "tu perdras la partie par forfait"
+		}
+		hours := (remaining + 3599) / 3600
+		payload := utils.NotificationPayload{
+			Title: "Ton tour expire bientôt !",
+			Body:  fmt.Sprintf("Il te reste moins de %dh pour jouer dans %s, sinon %s.", hours, name, consequence),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+		}
+		if err := utils.SendNotificationToUserByID(playerID, payload); err != nil {
+			logger.Info(context.Background(), "deadline: failed to send reminder", "error", err, "game_id", gameID, "user_id", playerID)
+		}
+	}
+	return rows.Err()
+}
+
+// expireOverdueTurns applique l'action d'expiration à toutes les parties dont
+// le délai de jeu est dépassé (hors tours du bot, qui joue de lui-même).
+func expireOverdueTurns() error {
+	rows, err := database.Query(`
+		SELECT id FROM games
+		WHERE status = 'ongoing' AND turn_deadline <= now() AND current_turn <> $1
+	`, BotUserID)
+	if err != nil {
+		return err
+	}
+	var gameIDs []string
+	for rows.Next() {
+		var gid string
+		if err := rows.Scan(&gid); err == nil {
+			gameIDs = append(gameIDs, gid)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, gid := range gameIDs {
+		if _, err := expireTurn(gid); err != nil {
+			logger.Error(context.Background(), "deadline: failed to expire turn", "error", err, "game_id", gid)
+		}
+	}
+	return nil
+}
+
+// expireTurn passe le tour du joueur courant ou lui fait perdre la partie par
+// forfait, selon la partie, si son délai de jeu est dépassé. À plus de deux
+// joueurs, le forfait est un abandon : la partie continue sans lui. Retourne false
+// si rien n'a été fait (partie terminée ou joueur ayant joué entre-temps).
+func expireTurn(gameID string) (bool, error) {
+	ctx := context.Background()
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			logger.Error(ctx, "rollback failed", "error", rbErr, "game_id", gameID)
+		}
+	}()
+
+	// Verrouille la partie et revérifie l'échéance sous verrou
+	var (
+		action  string
+		expired bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT timeout_action, COALESCE(turn_deadline <= now(), FALSE)
+		FROM games WHERE id = $1 AND status = 'ongoing'
+		FOR UPDATE
+	`, gameID).Scan(&action, &expired)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !expired) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return false, err
+	}
+	playerID := state.Turn
+
+	accepted, err := acceptPendingMoves(tx, gameID)
+	if err != nil {
+		return false, fmt.Errorf("failed to accept pending moves: %w", err)
+	}
+
+	// À plusieurs, le joueur absent abandonne et la partie continue sans lui
+	if action == TimeoutForfeit && len(state.Players) > 2 {
+		res, ended, err := resignLocked(tx, gameID, state, playerID, timeoutReason)
+		if err != nil {
+			return false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return false, err
+		}
+		if res.Finish == nil {
+			publishGameEvent(gameID, events.PlayerResigned, moveEvent{PlayerID: playerID, Timeout: true, NextTurn: res.NextTurn})
+		}
+		publishGameEnded(gameID, ended)
+		unlockAcceptedPlaysAchievements(accepted)
+		logger.Info(ctx, "deadline: player resigned on timeout", "game_id", gameID, "user_id", playerID)
+		if res.Finish == nil {
+			notifyTurnAfterTimeout(gameID, res.NextTurn)
+		}
+		return true, nil
+	}
+
+	if action == TimeoutForfeit {
+		ended, err := forfeitLocked(tx, gameID, state, playerID, timeoutReason)
+		if err != nil {
+			return false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return false, err
+		}
+		publishGameEnded(gameID, ended)
+		unlockAcceptedPlaysAchievements(accepted)
+		logger.Info(ctx, "deadline: player forfeited", "game_id", gameID, "user_id", playerID)
+		return true, nil
+	}
+
+	next, res, err := state.Pass(playerID)
+	if err != nil {
+		return false, err
+	}
+	if err := insertGameMove(tx, gameID, playerID, timeoutMoveRecord{Type: MoveTypePass, Reason: timeoutReason}); err != nil {
+		return false, fmt.Errorf("failed to record pass: %w", err)
+	}
+	if err := saveGameState(tx, gameID, next); err != nil {
+		return false, err
+	}
+	var ended *gameEnded
+	if res.GameOver {
+		if ended, err = finishGame(tx, gameID, next, 0); err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	publishGameEvent(gameID, events.Pass, moveEvent{PlayerID: playerID, Timeout: true, NextTurn: res.NextTurn})
+	publishGameEnded(gameID, ended)
+	unlockAcceptedPlaysAchievements(accepted)
+	logger.Info(ctx, "deadline: turn passed on timeout", "game_id", gameID, "user_id", playerID)
+
+	if !res.GameOver {
+		notifyTurnAfterTimeout(gameID, res.NextTurn)
+	}
+	return true, nil
+}
+
+// notifyTurnAfterTimeout prévient nextTurn que la main lui revient après le
+// délai de jeu dépassé de son adversaire, et fait jouer le bot si besoin.
+func notifyTurnAfterTimeout(gameID string, nextTurn int64) {
+	var gameName string
+	if err := database.QueryRow(`SELECT name FROM games WHERE id = $1`, gameID).Scan(&gameName); err != nil {
+		gameName = "une partie"
+	}
+	_ = utils.SendNotificationToUserByID(nextTurn, utils.NotificationPayload{
+		Title: "C'est à toi de jouer !",
+		Body:  fmt.Sprintf("Le délai de jeu de ton adversaire est écoulé dans %s", gameName),
+		Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+	})
+	TriggerBotIfNeeded(gameID, nextTurn)
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+)
+
+// expireDeadline place l'échéance du tour courant dans le passé.
+func expireDeadline(t *testing.T, gameID string) {
+	t.Helper()
+	_, err := database.Exec(`UPDATE games SET turn_deadline = now() - interval '1 minute' WHERE id = $1`, gameID)
+	require.NoError(t, err)
+}
+
+func TestValidateTurnTimeLimit(t *testing.T) {
+	hours, action, err := validateTurnTimeLimit(48, "")
+	require.NoError(t, err)
+	assert.Equal(t, 48, hours)
+	assert.Equal(t, TimeoutPass, action)
+
+	_, _, err = validateTurnTimeLimit(MaxTurnTimeLimitHours+1, TimeoutPass)
+	assert.ErrorIs(t, err, ErrInvalidTurnTimeLimit)
+	_, _, err = validateTurnTimeLimit(24, "sleep")
+	assert.ErrorIs(t, err, ErrInvalidTimeoutAction)
+}
+
+func TestTurnDeadline_SetAndReset(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "deadline_owner")
+	u2 := mustCreateUser(t, "deadline_p2")
+
+	gid, err := createStartedGameWithOptions(t, u1, "timed", []string{"deadline_p2"}, nil, GameOptions{TurnTimeLimitHours: 24})
+	require.NoError(t, err)
+	info, err := GetGameDetails(u1, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, 24, info.TurnTimeLimitHours)
+	assert.Equal(t, TimeoutPass, info.TimeoutAction)
+	require.NotNil(t, info.TurnDeadline)
+
+	// l'échéance n'est pas dépassée : rien ne se passe
+	done, err := expireTurn(gid.String())
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	// jouer relance le délai
+	expireDeadline(t, gid.String())
+	require.NoError(t, PassTurn(u1, gid.String()))
+	done, err = expireTurn(gid.String())
+	require.NoError(t, err)
+	assert.False(t, done)
+	info, err = GetGameDetails(u2, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, u2, info.CurrentTurn)
+
+	untimed, err := createStartedGameWithOptions(t, u1, "untimed", []string{"deadline_p2"}, nil, GameOptions{})
+	require.NoError(t, err)
+	info, err = GetGameDetails(u1, untimed.String())
+	require.NoError(t, err)
+	assert.Nil(t, info.TurnDeadline)
+}
+
+func TestExpireTurn_AutoPass(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "timeout_pass_owner")
+	u2 := mustCreateUser(t, "timeout_pass_p2")
+
+	gid, err := createStartedGameWithOptions(t, u1, "timed", []string{"timeout_pass_p2"}, nil, GameOptions{TurnTimeLimitHours: 48})
+	require.NoError(t, err)
+	expireDeadline(t, gid.String())
+
+	done, err := expireTurn(gid.String())
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	info, err := GetGameDetails(u2, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Equal(t, u2, info.CurrentTurn)
+	assert.Equal(t, 1, info.PassCount)
+	require.NotNil(t, info.TurnDeadline)
+	require.Len(t, info.Moves, 1)
+	assert.Equal(t, MoveTypePass, info.Moves[0].Type)
+	mv := info.Moves[0].Move.(map[string]any)
+	assert.Equal(t, timeoutReason, mv["reason"])
+}
+
+func TestExpireTurn_Forfeit(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "timeout_forfeit_owner")
+	u2 := mustCreateUser(t, "timeout_forfeit_p2")
+
+	gid, err := createStartedGameWithOptions(t, u1, "timed", []string{"timeout_forfeit_p2"}, nil, GameOptions{
+		TurnTimeLimitHours: 72,
+		TimeoutAction:      TimeoutForfeit,
+	})
+	require.NoError(t, err)
+	// le joueur forfait mène au score mais perd quand même
+	_, err = database.Exec(`UPDATE game_players SET score = 120 WHERE game_id = $1 AND player_id = $2`, gid.String(), u1)
+	require.NoError(t, err)
+	expireDeadline(t, gid.String())
+
+	done, err := expireTurn(gid.String())
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	info, err := GetGameDetails(u2, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, "ended", info.Status)
+	assert.Equal(t, "timeout_forfeit_p2", info.WinnerUsername)
+	require.NotNil(t, info.ForfeitedBy)
+	assert.Equal(t, u1, *info.ForfeitedBy)
+	assert.Nil(t, info.TurnDeadline)
+	require.Len(t, info.Moves, 1)
+	assert.Equal(t, MoveTypeForfeit, info.Moves[0].Type)
+
+	// la partie perdue par forfait compte pour 0 point dans l'IPS
+	var rating int
+	require.NoError(t, database.QueryRow(`SELECT rating FROM users WHERE id = $1`, u1).Scan(&rating))
+	assert.Equal(t, 0, rating)
+}
+
+func TestExpireTurn_ForfeitMultiplayer(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "timeout_multi_owner")
+	u2 := mustCreateUser(t, "timeout_multi_p2")
+	u3 := mustCreateUser(t, "timeout_multi_p3")
+
+	gid, err := createStartedGameWithOptions(t, u1, "timed", []string{"timeout_multi_p2", "timeout_multi_p3"}, nil, GameOptions{
+		TurnTimeLimitHours: 24,
+		TimeoutAction:      TimeoutForfeit,
+	})
+	require.NoError(t, err)
+	g := gid.String()
+	setGameTurnAndBag(t, g, u2, "EEEE")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	expireDeadline(t, g)
+
+	// à trois, le joueur absent abandonne et la partie continue
+	done, err := expireTurn(g)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Len(t, getGameBag(t, g), 11)
+
+	info, err := GetGameDetails(u3, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Nil(t, info.ForfeitedBy)
+	assert.Equal(t, u3, info.CurrentTurn)
+	require.NotNil(t, info.TurnDeadline)
+	for _, p := range info.Players {
+		assert.Equal(t, p.ID == u2, p.Resigned, "player %d", p.ID)
+	}
+	require.Len(t, info.Moves, 1)
+	assert.Equal(t, MoveTypeResign, info.Moves[0].Type)
+	mv := info.Moves[0].Move.(map[string]any)
+	assert.Equal(t, timeoutReason, mv["reason"])
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// saveDraws journalise les tirages du sac de state, et ses autres
+// modifications, dans la transaction du coup. Les tirages sont rattachés au
+// dernier coup enregistré de la partie (aucun pour les racks de départ).
+func saveDraws(tx *sql.Tx, gameID string, state *engine.GameState) error {
+	for _, d := range state.Draws {
+		_, err := tx.Exec(`
+			INSERT INTO game_draws (game_id, seq, move_id, player_id, bag, drawn)
+			VALUES ($1, $2, (SELECT MAX(id) FROM game_moves WHERE game_id = $1), $3, $4, $5)
+		`, gameID, d.Seq, d.PlayerID, d.Bag, d.Tiles)
+		if err != nil {
+			return fmt.Errorf("failed to log draw %d: %w", d.Seq, err)
+		}
+	}
+	for _, c := range state.BagChanges {
+		_, err := tx.Exec(`
+			INSERT INTO game_bag_changes (game_id, seq, returned, removed) VALUES ($1, $2, $3, $4)
+		`, gameID, c.Seq, c.Returned, c.Removed)
+		if err != nil {
+			return fmt.Errorf("failed to log bag change before draw %d: %w", c.Seq, err)
+		}
+	}
+	return nil
+}
+
+// GetGameDraws retourne l'engagement sur la graine du sac d'une partie. Une
+// fois la partie terminée, la graine et le journal complet des tirages sont
+// révélés et vérifiés, avec les règles de visibilité du rejeu ; avant, seuls
+// les joueurs y ont accès et le journal reste caché, car il trahirait les
+// racks adverses.
+func GetGameDraws(viewerID int64, gameID string) (*response.GameDraws, error) {
+	var (
+		res               response.GameDraws
+		seed              []byte
+		seedHash          sql.NullString
+		drawCount         int
+		language, variant string
+	)
+	err := database.QueryRow(`
+		SELECT id, status, bag_seed, bag_seed_hash, draw_count, language, variant FROM games WHERE id = $1
+	`, gameID).Scan(&res.GameID, &res.Status, &seed, &seedHash, &drawCount, &language, &variant)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("game not found")
+		}
+		return nil, err
+	}
+	ended := gameFinished(res.Status)
+	if err := validatePlayerInGame(database.DB, gameID, viewerID); err != nil {
+		if !ended {
+			return nil, errors.New("game not found")
+		}
+		if err := canReadEndedGame(viewerID, gameID); err != nil {
+			return nil, err
+		}
+	}
+	if !seedHash.Valid {
+		return nil, errors.New("game has no seeded bag")
+	}
+	res.SeedHash = seedHash.String
+	res.DrawCount = drawCount
+	if !ended {
+		return &res, nil
+	}
+
+	changes, err := loadBagChanges(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Query(`
+		SELECT seq, move_id, player_id, bag, drawn
+		FROM game_draws
+		WHERE game_id = $1
+		ORDER BY seq
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var draws []engine.Draw
+	for rows.Next() {
+		var (
+			d      engine.Draw
+			moveID sql.NullInt64
+		)
+		if err := rows.Scan(&d.Seq, &moveID, &d.PlayerID, &d.Bag, &d.Tiles); err != nil {
+			return nil, err
+		}
+		draws = append(draws, d)
+		info := response.DrawInfo{
+			Seq:      d.Seq,
+			PlayerID: d.PlayerID,
+			Bag:      d.Bag.Strings(),
+			Drawn:    d.Tiles.Strings(),
+		}
+		if moveID.Valid {
+			info.MoveID = &moveID.Int64
+		}
+		for _, c := range changes {
+			if c.Seq == d.Seq {
+				info.Returned = append(info.Returned, c.Returned.Strings()...)
+				info.Removed = append(info.Removed, c.Removed.Strings()...)
+			}
+		}
+		res.Draws = append(res.Draws, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// le sac est reconstitué depuis le sac de départ de la partie, sans se
+	// fier aux sacs journalisés
+	layout := layoutOrStandard(variant)
+	initial := word.Lang(language).BagOfSize(layout.BagSize)
+	res.Seed = bagSeedHex(seed)
+	res.Verified = engine.SeedHash(seed) == res.SeedHash && len(draws) == drawCount &&
+		engine.VerifyDraws(seed, initial, draws, changes) == nil
+	return &res, nil
+}
+
+// loadBagChanges retourne les modifications du sac hors tirage d'une partie,
+// dans l'ordre où elles ont eu lieu.
+func loadBagChanges(gameID string) ([]engine.BagChange, error) {
+	rows, err := database.Query(`
+		SELECT seq, returned, removed FROM game_bag_changes
+		WHERE game_id = $1
+		ORDER BY seq, id
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var changes []engine.BagChange
+	for rows.Next() {
+		var c engine.BagChange
+		if err := rows.Scan(&c.Seq, &c.Returned, &c.Removed); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// bagSeedHex encode la graine du sac en hexadécimal (vide si la partie n'en a pas).
+func bagSeedHex(seed []byte) string {
+	return hex.EncodeToString(seed)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/hex"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestGameDraws_CommittedThenRevealed(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "draw1")
+	mustCreateUser(t, "draw2")
+	outsider := mustCreateUser(t, "draw3")
+	gid, err := createStartedGame(t, u1, "draws", []string{"draw2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	// en cours : seule l'empreinte est publiée, et seulement aux joueurs
+	draws, err := GetGameDraws(u1, g)
+	require.NoError(t, err)
+	assert.Len(t, draws.SeedHash, 64)
+	assert.Equal(t, 2, draws.DrawCount)
+	assert.Empty(t, draws.Seed)
+	assert.Empty(t, draws.Draws)
+	_, err = GetGameDraws(outsider, g)
+	assert.ErrorContains(t, err, "not found")
+
+	details, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, draws.SeedHash, details.BagSeedHash)
+	assert.Empty(t, details.BagSeed)
+
+	_, err = ExchangeTiles(u1, g, details.YourTiles[:2])
+	require.NoError(t, err)
+	require.NoError(t, ResignGame(u1, g))
+
+	// terminée : graine et tirages révélés à ceux qui peuvent regarder la
+	// partie, et vérifiables
+	_, err = GetGameDraws(outsider, g)
+	assert.ErrorContains(t, err, "unauthorized")
+	require.NoError(t, SetGameVisibility(u1, g, VisibilityPublic, nil))
+	draws, err = GetGameDraws(0, g)
+	require.NoError(t, err)
+	assert.True(t, draws.Verified)
+	require.Len(t, draws.Draws, 3)
+	assert.Nil(t, draws.Draws[0].MoveID)
+	assert.NotNil(t, draws.Draws[2].MoveID)
+	assert.Equal(t, u1, draws.Draws[2].PlayerID)
+	assert.Len(t, draws.Draws[2].Drawn, 2)
+
+	seed, err := hex.DecodeString(draws.Seed)
+	require.NoError(t, err)
+	assert.Equal(t, draws.SeedHash, engine.SeedHash(seed))
+
+	// un sac journalisé remanié n'est plus vérifié : le sac est reconstitué
+	bag := word.TilesFromStrings(draws.Draws[2].Bag)
+	slices.Reverse(bag)
+	_, err = database.Exec(`UPDATE game_draws SET bag = $1 WHERE game_id = $2 AND seq = 2`, bag, g)
+	require.NoError(t, err)
+	draws, err = GetGameDraws(0, g)
+	require.NoError(t, err)
+	assert.False(t, draws.Verified)
+}
@@ -0,0 +1,655 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// Durée d'une ronde en duplicate, en secondes.
+const (
+	DefaultRoundTimeLimitSeconds = 180
+	MinRoundTimeLimitSeconds     = 30
+	MaxRoundTimeLimitSeconds     = 24 * 60 * 60
+)
+
+// duplicateMaxRedraws borne les tirages d'affilée sans aucun coup possible ;
+// au-delà, la partie se termine.
+const duplicateMaxRedraws = 3
+
+var (
+	// ErrInvalidDuplicateOptions est renvoyée quand une partie en duplicate
+	// demande un délai de jeu par tour, une règle de contestation ou le mode
+	// entraînement, qui n'ont pas de sens sans tour de jeu.
+	ErrInvalidDuplicateOptions = errors.New("invalid duplicate options: no turn time limit, challenge rule or training")
+	// ErrInvalidRoundTimeLimit est renvoyée quand la durée d'une ronde est hors bornes.
+	ErrInvalidRoundTimeLimit = errors.New("invalid round time limit")
+	// ErrDuplicateAgainstBot est renvoyée quand le bot est invité à une partie en duplicate.
+	ErrDuplicateAgainstBot = errors.New("duplicate mode is not available against the bot")
+	// ErrNoOpenRound est renvoyée quand un coup est proposé hors d'une ronde ouverte.
+	ErrNoOpenRound = errors.New("no open duplicate round")
+)
+
+// duplicateRoundOpened décrit une ronde qui commence.
+type duplicateRoundOpened struct {
+	Round    int       `json:"round"`
+	Rack     []string  `json:"rack"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// duplicateRoundClosed est la charge de l'événement de clôture d'une ronde :
+// le top posé, les totaux et la ronde suivante, s'il y en a une.
+type duplicateRoundClosed struct {
+	Round      int                    `json:"round"`
+	TopWord    string                 `json:"top_word"`
+	TopLetters []request.PlacedLetter `json:"top_letters"`
+	TopScore   int                    `json:"top_score"`
+	Scores     map[int64]int          `json:"scores"`
+	NextRound  *duplicateRoundOpened  `json:"next_round,omitempty"`
+}
+
+// duplicateSubmitted est la charge de l'événement publié quand un joueur
+// propose un coup ; le coup lui-même reste caché jusqu'à la clôture.
+type duplicateSubmitted struct {
+	PlayerID  int64 `json:"player_id"`
+	Round     int   `json:"round"`
+	Submitted int   `json:"submitted"`
+	Players   int   `json:"players"`
+}
+
+// validateGameMode vérifie le mode de jeu et, en duplicate, la durée d'une
+// ronde (DefaultRoundTimeLimitSeconds si 0).
+func validateGameMode(opts GameOptions, challengeRule string) (string, int, error) {
+	switch opts.Mode {
+	case "", engine.ModeClassic:
+		return engine.ModeClassic, 0, nil
+	case engine.ModeDuplicate:
+	default:
+		return "", 0, engine.ErrInvalidMode
+	}
+	if opts.TurnTimeLimitHours != 0 || challengeRule != engine.ChallengeNone || opts.Training {
+		return "", 0, ErrInvalidDuplicateOptions
+	}
+	seconds := opts.RoundTimeLimitSeconds
+	if seconds == 0 {
+		seconds = DefaultRoundTimeLimitSeconds
+	}
+	if seconds < MinRoundTimeLimitSeconds || seconds > MaxRoundTimeLimitSeconds {
+		return "", 0, ErrInvalidRoundTimeLimit
+	}
+	return engine.ModeDuplicate, seconds, nil
+}
+
+// openDuplicateRound tire le rack commun de la ronde round, calcule son top
+// avec la recherche du bot et ouvre la ronde pour la durée prévue par la
+// partie. Un tirage sans aucun coup possible retourne dans le sac et est
+// refait. La ronde retournée est nil si aucune ne peut plus être ouverte :
+// la partie est alors terminée. L'état retourné, tirages compris, reste à
+// enregistrer par l'appelant.
+func openDuplicateRound(tx *sql.Tx, gameID string, state *engine.GameState, round int) (*engine.GameState, *duplicateRoundOpened, error) {
+	next := state
+	for range duplicateMaxRedraws {
+		drawn, err := next.DrawDuplicateRack()
+		if errors.Is(err, engine.ErrRackExhausted) {
+			return next, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rack := drawn.SharedRack()
+		top := findBestMove(drawn.Language, drawn.Layout, drawn.Rules, drawn.Board, rack, drawn.Blanks, "hard")
+		if top == nil {
+			next = drawn.ReturnSharedRack()
+			continue
+		}
+		res, err := drawn.ScoreDuplicate(top.Letters)
+		if err != nil {
+			logger.Warn(context.Background(), "duplicate: top rejected by the engine", "error", err, "game_id", gameID, "round", round)
+			next = drawn.ReturnSharedRack()
+			continue
+		}
+		topWord := top.Word
+		if topWord == "" && len(res.Words) > 0 {
+			topWord = res.Words[0].Word
+		}
+		lettersJSON, err := json.Marshal(res.Letters)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		opened := &duplicateRoundOpened{Round: round, Rack: rack.Strings()}
+		err = tx.QueryRow(`
+			INSERT INTO duplicate_rounds (game_id, round, rack, top_letters, top_word, top_score, deadline)
+			SELECT id, $2, $3, $4, $5, $6, now() + make_interval(secs => round_time_limit_seconds)
+			FROM games WHERE id = $1
+			RETURNING deadline
+		`, gameID, round, rack, lettersJSON, topWord, res.Score).Scan(&opened.Deadline)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open duplicate round: %w", err)
+		}
+		return drawn, opened, nil
+	}
+	return next, nil, nil
+}
+
+// closeDuplicateRound clôt la ronde round : le top est posé sur le plateau,
+// chaque joueur marque les points du coup qu'il a proposé (0 sans coup) et la
+// ronde suivante est ouverte, ou la partie terminée s'il n'y en a plus. La
+// partie doit être verrouillée par tx ; les événements retournés sont à
+// publier après validation.
+func closeDuplicateRound(tx *sql.Tx, gameID string, state *engine.GameState, round int) (*duplicateRoundClosed, *gameEnded, error) {
+	var (
+		topRaw  []byte
+		topWord string
+	)
+	err := tx.QueryRow(`
+		SELECT top_letters, top_word FROM duplicate_rounds
+		WHERE game_id = $1 AND round = $2 AND closed_at IS NULL
+	`, gameID, round).Scan(&topRaw, &topWord)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrNoOpenRound
+		}
+		return nil, nil, err
+	}
+	var top []request.PlacedLetter
+	if err := json.Unmarshal(topRaw, &top); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal duplicate top: %w", err)
+	}
+
+	scores := map[int64]int{}
+	rows, err := tx.Query(`
+		SELECT player_id, score FROM duplicate_submissions
+		WHERE game_id = $1 AND round = $2
+	`, gameID, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	for rows.Next() {
+		var (
+			pid   int64
+			score int
+		)
+		if err := rows.Scan(&pid, &score); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		scores[pid] = score
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	next, res, err := state.PlayDuplicateTop(top, scores)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE duplicate_rounds SET closed_at = now()
+		WHERE game_id = $1 AND round = $2
+	`, gameID, round); err != nil {
+		return nil, nil, err
+	}
+
+	closed := &duplicateRoundClosed{
+		Round:      round,
+		TopWord:    topWord,
+		TopLetters: res.Letters,
+		TopScore:   res.Score,
+		Scores:     next.Scores,
+	}
+	if !res.GameOver {
+		if next, closed.NextRound, err = openDuplicateRound(tx, gameID, next, round+1); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := saveGameState(tx, gameID, next); err != nil {
+		return nil, nil, err
+	}
+	if closed.NextRound != nil {
+		return closed, nil, nil
+	}
+
+	final, fin := next.FinishDuplicate()
+	ended, err := endGame(tx, gameID, final, fin)
+	if err != nil {
+		return nil, nil, err
+	}
+	return closed, ended, nil
+}
+
+// SubmitDuplicateMove enregistre le coup proposé par userID pour la ronde en
+// cours d'une partie en duplicate ; un coup sans lettres est une passe (0
+// point). Le joueur peut changer de coup tant que la ronde est ouverte. La
+// ronde est close dès que tous les joueurs ont proposé un coup.
+func SubmitDuplicateMove(gameID string, userID int64, req request.PlayMoveRequest) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	if err := lockGame(tx, gameID); err != nil {
+		return err
+	}
+	if err := validatePlayerInGame(tx, gameID, userID); err != nil {
+		return err
+	}
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return fmt.Errorf("game not found: %v", err)
+	}
+	if !state.Duplicate() {
+		return engine.ErrNotDuplicate
+	}
+	if state.Ended {
+		return engine.ErrGameEnded
+	}
+	if _, ok := state.Racks[userID]; !ok {
+		return engine.ErrNotInGame
+	}
+
+	var round int
+	err = tx.QueryRow(`
+		SELECT round FROM duplicate_rounds
+		WHERE game_id = $1 AND closed_at IS NULL AND deadline > now()
+	`, gameID).Scan(&round)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoOpenRound
+		}
+		return err
+	}
+
+	if req.Notation != "" {
+		notation, err := engine.ParseNotation(state.Language, state.Board, req.Notation)
+		if err != nil {
+			return err
+		}
+		req.Word, req.Letters = notation.Word, notation.Letters
+	}
+	letters := []request.PlacedLetter{}
+	score := 0
+	if len(req.Letters) > 0 {
+		res, err := state.ScoreDuplicate(req.Letters)
+		if err != nil {
+			return err
+		}
+		letters, score = res.Letters, res.Score
+		if req.Word == "" && len(res.Words) > 0 {
+			req.Word = res.Words[0].Word
+		}
+	} else {
+		req.Word = ""
+	}
+	lettersJSON, err := json.Marshal(letters)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO duplicate_submissions (game_id, round, player_id, letters, word, score)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (game_id, round, player_id) DO UPDATE
+		SET letters = EXCLUDED.letters, word = EXCLUDED.word, score = EXCLUDED.score, submitted_at = now()
+	`, gameID, round, userID, lettersJSON, req.Word, score)
+	if err != nil {
+		return fmt.Errorf("failed to save duplicate submission: %w", err)
+	}
+
+	submitted, err := countDuplicateSubmissions(tx, gameID, round)
+	if err != nil {
+		return err
+	}
+	var (
+		closed *duplicateRoundClosed
+		ended  *gameEnded
+	)
+	if submitted >= len(state.Players) {
+		if closed, ended, err = closeDuplicateRound(tx, gameID, state, round); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	publishGameEvent(gameID, events.DuplicateSubmitted, duplicateSubmitted{
+		PlayerID:  userID,
+		Round:     round,
+		Submitted: submitted,
+		Players:   len(state.Players),
+	})
+	if closed != nil {
+		publishGameEvent(gameID, events.DuplicateRound, closed)
+	}
+	publishGameEnded(gameID, ended)
+	return nil
+}
+
+// countDuplicateSubmissions compte les joueurs encore en jeu qui ont proposé
+// un coup pour la ronde round.
+func countDuplicateSubmissions(q gameQuerier, gameID string, round int) (int, error) {
+	var n int
+	err := q.QueryRow(`
+		SELECT COUNT(*)
+		FROM duplicate_submissions s
+		JOIN game_players gp ON gp.game_id = s.game_id AND gp.player_id = s.player_id
+		WHERE s.game_id = $1 AND s.round = $2 AND NOT gp.resigned
+	`, gameID, round).Scan(&n)
+	return n, err
+}
+
+// GetDuplicateGame retourne l'état d'une partie en duplicate pour l'un de ses
+// joueurs. Le coup des autres joueurs et le top de la ronde en cours ne sont
+// révélés qu'à sa clôture.
+func GetDuplicateGame(viewerID int64, gameID string) (*response.DuplicateGame, error) {
+	res := response.DuplicateGame{
+		Rounds:  []response.DuplicateRound{},
+		Ranking: []response.DuplicateRanking{},
+	}
+	var mode string
+	err := database.QueryRow(`
+		SELECT id, status, mode, round_time_limit_seconds FROM games WHERE id = $1
+	`, gameID).Scan(&res.GameID, &res.Status, &mode, &res.RoundTimeLimitSeconds)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("game not found")
+		}
+		return nil, err
+	}
+	if err := validatePlayerInGame(database.DB, gameID, viewerID); err != nil {
+		return nil, errors.New("game not found")
+	}
+	if mode != engine.ModeDuplicate {
+		return nil, engine.ErrNotDuplicate
+	}
+
+	rows, err := database.Query(`
+		SELECT gp.player_id, u.username, gp.score, gp.resigned
+		FROM game_players gp
+		JOIN users u ON u.id = gp.player_id
+		WHERE gp.game_id = $1
+		ORDER BY gp.position
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for rows.Next() {
+		var r response.DuplicateRanking
+		if err := rows.Scan(&r.PlayerID, &r.Username, &r.Score, &r.Resigned); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !r.Resigned {
+			active++
+		}
+		res.Ranking = append(res.Ranking, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = database.Query(`
+		SELECT round, rack, top_letters, top_word, top_score, deadline, closed_at IS NOT NULL
+		FROM duplicate_rounds
+		WHERE game_id = $1
+		ORDER BY round
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			r        response.DuplicateRound
+			rack     word.Tiles
+			topRaw   []byte
+			deadline time.Time
+			closed   bool
+		)
+		if err := rows.Scan(&r.Round, &rack, &topRaw, &r.TopWord, &r.TopScore, &deadline, &closed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		r.Rack = rack.Strings()
+		if !closed {
+			if res.Status == "ongoing" {
+				res.CurrentRound = &response.DuplicateCurrentRound{
+					Round:    r.Round,
+					Rack:     r.Rack,
+					Deadline: deadline,
+					Players:  active,
+				}
+			}
+			continue
+		}
+		r.TopLetters = duplicateTiles(topRaw)
+		r.Submissions = []response.DuplicateSubmission{}
+		res.TopTotal += r.TopScore
+		res.Rounds = append(res.Rounds, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = database.Query(`
+		SELECT s.round, s.player_id, u.username, s.word, s.letters, s.score, s.submitted_at
+		FROM duplicate_submissions s
+		JOIN users u ON u.id = s.player_id
+		WHERE s.game_id = $1
+		ORDER BY s.round, s.score DESC, s.submitted_at
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			round      int
+			sub        response.DuplicateSubmission
+			lettersRaw []byte
+		)
+		if err := rows.Scan(&round, &sub.PlayerID, &sub.Username, &sub.Word, &lettersRaw, &sub.Score, &sub.SubmittedAt); err != nil {
+			return nil, err
+		}
+		sub.Letters = duplicateTiles(lettersRaw)
+		if cur := res.CurrentRound; cur != nil && cur.Round == round {
+			cur.Submitted++
+			if sub.PlayerID == viewerID {
+				cur.MySubmission = &sub
+			}
+			continue
+		}
+		for i := range res.Rounds {
+			if res.Rounds[i].Round == round {
+				res.Rounds[i].Submissions = append(res.Rounds[i].Submissions, sub)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// classement par total, en pourcentage du total des tops
+	sort.SliceStable(res.Ranking, func(i, j int) bool {
+		return res.Ranking[i].Score > res.Ranking[j].Score
+	})
+	for i := range res.Ranking {
+		r := &res.Ranking[i]
+		r.Rank = i + 1
+		if i > 0 && r.Score == res.Ranking[i-1].Score {
+			r.Rank = res.Ranking[i-1].Rank
+		}
+		if res.TopTotal > 0 {
+			r.Percent = math.Round(float64(r.Score)*1000/float64(res.TopTotal)) / 10
+		}
+	}
+	return &res, nil
+}
+
+// duplicateTiles décode les lettres d'un top ou d'un coup proposé.
+func duplicateTiles(raw []byte) []response.ReplayTile {
+	var letters []request.PlacedLetter
+	if err := json.Unmarshal(raw, &letters); err != nil {
+		return nil
+	}
+	out := make([]response.ReplayTile, 0, len(letters))
+	for _, pl := range letters {
+		out = append(out, response.ReplayTile{X: pl.X, Y: pl.Y, Char: pl.Char, Blank: pl.Blank})
+	}
+	return out
+}
+
+// loadDuplicateBlanks ajoute à blanks les jokers des tops posés : en
+// duplicate, ils ne figurent pas dans game_moves.
+func loadDuplicateBlanks(q gameQuerier, gameID string, blanks map[Pos]bool) error {
+	rows, err := q.Query(`
+		SELECT top_letters FROM duplicate_rounds
+		WHERE game_id = $1 AND closed_at IS NOT NULL
+	`, gameID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return err
+		}
+		var letters []request.PlacedLetter
+		if err := json.Unmarshal(raw, &letters); err != nil {
+			continue
+		}
+		for _, pl := range letters {
+			if pl.Blank {
+				blanks[Pos{X: pl.X, Y: pl.Y}] = true
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// StartDuplicateWorker lance la goroutine qui clôt les rondes de duplicate
+// dont le temps est écoulé. Intervalle en secondes.
+func StartDuplicateWorker(intervalSeconds int) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := CloseExpiredDuplicateRounds(); err != nil {
+				logger.Error(context.Background(), "duplicate: poll query failed", "error", err)
+			}
+		}
+	}()
+	logger.Info(context.Background(), "duplicate: worker started", "interval_seconds", intervalSeconds)
+}
+
+// CloseExpiredDuplicateRounds clôt toutes les rondes dont le temps est écoulé ;
+// les joueurs qui n'ont rien proposé marquent 0 point.
+func CloseExpiredDuplicateRounds() error {
+	rows, err := database.Query(`
+		SELECT r.game_id, r.round
+		FROM duplicate_rounds r
+		JOIN games g ON g.id = r.game_id
+		WHERE r.closed_at IS NULL AND r.deadline <= now() AND g.status = 'ongoing'
+	`)
+	if err != nil {
+		return err
+	}
+	type expired struct {
+		gameID string
+		round  int
+	}
+	var due []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.gameID, &e.round); err == nil {
+			due = append(due, e)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range due {
+		if err := closeExpiredDuplicateRound(e.gameID, e.round); err != nil {
+			logger.Error(context.Background(), "duplicate: failed to close round", "error", err, "game_id", e.gameID, "round", e.round)
+		}
+	}
+	return nil
+}
+
+// closeExpiredDuplicateRound clôt la ronde round si elle est toujours ouverte
+// et échue une fois la partie verrouillée.
+func closeExpiredDuplicateRound(gameID string, round int) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	if err := lockGame(tx, gameID); err != nil {
+		return err
+	}
+	var expired bool
+	err = tx.QueryRow(`
+		SELECT deadline <= now() FROM duplicate_rounds
+		WHERE game_id = $1 AND round = $2 AND closed_at IS NULL
+	`, gameID, round).Scan(&expired)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !expired) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return err
+	}
+	if state.Ended {
+		return nil
+	}
+
+	closed, ended, err := closeDuplicateRound(tx, gameID, state, round)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	publishGameEvent(gameID, events.DuplicateRound, closed)
+	publishGameEnded(gameID, ended)
+	return nil
+}
+
+// hasBot indique si le bot fait partie de playerIDs.
+func hasBot(playerIDs []int64) bool {
+	return BotUserID != -1 && slices.Contains(playerIDs, BotUserID)
+}
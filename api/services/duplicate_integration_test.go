@@ -0,0 +1,122 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestDuplicate_Validation(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "dup_validator")
+
+	_, err := CreateGameWithOptions(u1, "g", nil, nil, GameOptions{Mode: "marathon"})
+	assert.ErrorIs(t, err, engine.ErrInvalidMode)
+	_, err = CreateGameWithOptions(u1, "g", nil, nil, GameOptions{Mode: engine.ModeDuplicate, ChallengeRule: engine.ChallengeDouble})
+	assert.ErrorIs(t, err, ErrInvalidDuplicateOptions)
+	_, err = CreateGameWithOptions(u1, "g", nil, nil, GameOptions{Mode: engine.ModeDuplicate, TurnTimeLimitHours: 24})
+	assert.ErrorIs(t, err, ErrInvalidDuplicateOptions)
+	_, err = CreateGameWithOptions(u1, "g", nil, nil, GameOptions{Mode: engine.ModeDuplicate, RoundTimeLimitSeconds: 10})
+	assert.ErrorIs(t, err, ErrInvalidRoundTimeLimit)
+
+	// partie classique : pas de vue duplicate
+	gid, err := CreateGameWithOptions(u1, "g", nil, nil, GameOptions{})
+	require.NoError(t, err)
+	_, err = GetDuplicateGame(u1, gid.String())
+	assert.ErrorIs(t, err, engine.ErrNotDuplicate)
+}
+
+func TestDuplicate_RoundsAndRanking(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "dup_alice")
+	u2 := mustCreateUser(t, "dup_bob")
+
+	gid, err := CreateGameWithOptions(u1, "duplicate", []string{"dup_bob"}, nil, GameOptions{Mode: engine.ModeDuplicate})
+	require.NoError(t, err)
+	g := gid.String()
+	acceptAllInvitations(t, g)
+
+	// ronde 1 : même tirage réglementaire pour tous
+	dup, err := GetDuplicateGame(u2, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", dup.Status)
+	assert.Equal(t, DefaultRoundTimeLimitSeconds, dup.RoundTimeLimitSeconds)
+	require.NotNil(t, dup.CurrentRound)
+	assert.Equal(t, 1, dup.CurrentRound.Round)
+	assert.Equal(t, 2, dup.CurrentRound.Players)
+	rack := make(word.Tiles, 0, len(dup.CurrentRound.Rack))
+	for _, tile := range dup.CurrentRound.Rack {
+		rack = append(rack, word.Tile(tile))
+	}
+	assert.Len(t, rack, 7)
+	assert.True(t, engine.ValidDuplicateRack(rack))
+
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, engine.ModeDuplicate, info.Mode)
+	assert.Equal(t, dup.CurrentRound.Rack, info.YourTiles)
+	assert.ErrorIs(t, PassTurn(u1, g), engine.ErrDuplicateMode)
+
+	// les deux joueurs passent : la ronde est close aussitôt
+	require.NoError(t, SubmitDuplicateMove(g, u1, request.PlayMoveRequest{}))
+	dup, err = GetDuplicateGame(u1, g)
+	require.NoError(t, err)
+	require.NotNil(t, dup.CurrentRound.MySubmission)
+	assert.Equal(t, 1, dup.CurrentRound.Submitted)
+	assert.Empty(t, dup.Rounds)
+	require.NoError(t, SubmitDuplicateMove(g, u2, request.PlayMoveRequest{}))
+
+	dup, err = GetDuplicateGame(u1, g)
+	require.NoError(t, err)
+	require.Len(t, dup.Rounds, 1)
+	top1 := dup.Rounds[0].TopScore
+	assert.Positive(t, top1)
+	assert.NotEmpty(t, dup.Rounds[0].TopLetters)
+	assert.Len(t, dup.Rounds[0].Submissions, 2)
+	require.NotNil(t, dup.CurrentRound)
+	assert.Equal(t, 2, dup.CurrentRound.Round)
+
+	// ronde 2 : u1 trouve le top, u2 laisse passer le temps
+	state, err := loadGameState(database.DB, g)
+	require.NoError(t, err)
+	top := findBestMove(state.Language, state.Layout, state.Rules, state.Board, state.SharedRack(), state.Blanks, "hard")
+	require.NotNil(t, top)
+	require.NoError(t, SubmitDuplicateMove(g, u1, request.PlayMoveRequest{Letters: top.Letters}))
+
+	_, err = database.Exec(`UPDATE duplicate_rounds SET deadline = now() - interval '1 second' WHERE game_id = $1 AND round = 2`, g)
+	require.NoError(t, err)
+	assert.ErrorIs(t, SubmitDuplicateMove(g, u2, request.PlayMoveRequest{}), ErrNoOpenRound)
+	require.NoError(t, CloseExpiredDuplicateRounds())
+
+	dup, err = GetDuplicateGame(u2, g)
+	require.NoError(t, err)
+	require.Len(t, dup.Rounds, 2)
+	top2 := dup.Rounds[1].TopScore
+	assert.Equal(t, top1+top2, dup.TopTotal)
+	require.Len(t, dup.Ranking, 2)
+	assert.Equal(t, u1, dup.Ranking[0].PlayerID)
+	assert.Equal(t, 1, dup.Ranking[0].Rank)
+	assert.Equal(t, top2, dup.Ranking[0].Score)
+	assert.Equal(t, math.Round(float64(top2)*1000/float64(top1+top2))/10, dup.Ranking[0].Percent)
+	assert.Equal(t, 0.0, dup.Ranking[1].Percent)
+
+	// le top est posé sur le plateau commun
+	board, err := LoadBoard(g)
+	require.NoError(t, err)
+	placed := 0
+	for _, row := range board {
+		for _, cell := range row {
+			if cell != "" {
+				placed++
+			}
+		}
+	}
+	assert.Equal(t, len(dup.Rounds[0].TopLetters)+len(dup.Rounds[1].TopLetters), placed)
+}
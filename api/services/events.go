@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+)
+
+// moveEvent est la charge d'un événement de coup (pose, passe, échange,
+// abandon à plusieurs).
+type moveEvent struct {
+	PlayerID int64                  `json:"player_id"`
+	Word     string                 `json:"word,omitempty"`
+	Score    int                    `json:"score,omitempty"`
+	Letters  []request.PlacedLetter `json:"letters,omitempty"`
+	Count    int                    `json:"count,omitempty"` // tuiles échangées
+	Pending  bool                   `json:"pending,omitempty"`
+	Timeout  bool                   `json:"timeout,omitempty"` // passe ou abandon imposé par le délai de jeu
+	NextTurn int64                  `json:"next_turn"`
+}
+
+// moveChallenged est la charge de l'événement de contestation.
+type moveChallenged struct {
+	PlayerID     int64    `json:"player_id"` // contestataire
+	AuthorID     int64    `json:"author_id"` // auteur du coup contesté
+	Success      bool     `json:"success"`
+	InvalidWords []string `json:"invalid_words,omitempty"`
+	Penalty      int      `json:"penalty,omitempty"`
+	LostTurn     bool     `json:"lost_turn,omitempty"`
+	NextTurn     int64    `json:"next_turn"`
+}
+
+// takebackEvent est la charge des événements de reprise. NextTurn n'est
+// renseigné que si le coup a été annulé (Applied).
+type takebackEvent struct {
+	PlayerID   int64  `json:"player_id"` // auteur du coup à reprendre
+	Status     string `json:"status"`
+	AnsweredBy int64  `json:"answered_by,omitempty"`
+	Applied    bool   `json:"applied,omitempty"`
+	NextTurn   int64  `json:"next_turn,omitempty"`
+}
+
+// gameEnded est la charge de l'événement de fin de partie. endGame la
+// calcule dans la transaction ; elle n'est publiée qu'après sa validation.
+type gameEnded struct {
+	WinnerID       int64         `json:"winner_id,omitempty"`
+	WinnerUsername string        `json:"winner_username,omitempty"`
+	ForfeitedBy    int64         `json:"forfeited_by,omitempty"`
+	Scores         map[int64]int `json:"scores"`
+	BagSeed        string        `json:"bag_seed,omitempty"` // graine du sac, révélée à la fin
+
+	tournamentID string // tournoi de la partie, à faire avancer après validation
+}
+
+// gamePlayerIDs retourne tous les joueurs de la partie, y compris ceux qui
+// ont abandonné.
+func gamePlayerIDs(gameID string) ([]int64, error) {
+	rows, err := database.Query(`SELECT player_id FROM game_players WHERE game_id = $1`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// publishGameEvent envoie un événement temps réel à tous les joueurs de la
+// partie et à ses spectateurs. À appeler une fois la transaction du coup
+// validée ; data ne doit révéler aucun rack.
+func publishGameEvent(gameID, typ string, data any) {
+	ev := events.New(typ, gameID, data)
+	publishToPlayers(gameID, ev)
+	events.PublishGame(gameID, ev)
+}
+
+// publishChatEvent est publishGameEvent pour le chat : les spectateurs ne le
+// reçoivent que si la partie leur ouvre le chat.
+func publishChatEvent(gameID, typ string, data any) {
+	ev := events.New(typ, gameID, data)
+	publishToPlayers(gameID, ev)
+	var spectatorChat bool
+	if err := database.QueryRow(`SELECT spectator_chat FROM games WHERE id = $1`, gameID).Scan(&spectatorChat); err != nil {
+		logger.Warn(context.Background(), "events: failed to fetch spectator chat setting", "error", err, "game_id", gameID)
+		return
+	}
+	if spectatorChat {
+		events.PublishGame(gameID, ev)
+	}
+}
+
+func publishToPlayers(gameID string, ev events.Event) {
+	ids, err := gamePlayerIDs(gameID)
+	if err != nil {
+		logger.Warn(context.Background(), "events: failed to fetch game players", "error", err, "game_id", gameID)
+		return
+	}
+	events.Publish(ids, ev)
+}
+
+// publishGameEnded publie la fin de partie calculée par endGame (rien si nil)
+// et, pour une partie de tournoi, fait avancer le tournoi.
+func publishGameEnded(gameID string, ended *gameEnded) {
+	if ended == nil {
+		return
+	}
+	publishGameEvent(gameID, events.GameEnded, ended)
+	if ended.tournamentID != "" {
+		if err := advanceTournament(ended.tournamentID); err != nil {
+			logger.Error(context.Background(), "tournaments: failed to advance tournament", "error", err, "tournament_id", ended.tournamentID, "game_id", gameID)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/events"
+)
+
+// nextEvent attend le prochain événement de type typ sur stream.
+func nextEvent(t *testing.T, stream <-chan events.Event, typ string) events.Event {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-stream:
+			if ev.Type == typ {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("no %s event received", typ)
+		}
+	}
+}
+
+func TestEvents_PublishedForMovesChatAndEnd(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "ev1")
+	u2 := mustCreateUser(t, "ev2")
+	gid, err := createStartedGame(t, u1, "events", []string{"ev2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+
+	stream, unsubscribe := events.Subscribe(u2)
+	defer unsubscribe()
+
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	ev := nextEvent(t, stream, events.MovePlayed)
+	assert.Equal(t, g, ev.GameID)
+	var played moveEvent
+	require.NoError(t, json.Unmarshal(ev.Data, &played))
+	assert.Equal(t, u1, played.PlayerID)
+	assert.Equal(t, 18, played.Score)
+	assert.Equal(t, u2, played.NextTurn)
+
+	_, err = ExchangeTiles(u2, g, []string{"A"})
+	require.NoError(t, err)
+	ev = nextEvent(t, stream, events.Exchange)
+	assert.JSONEq(t, `{"player_id":`+jsonInt(u2)+`,"count":1,"next_turn":`+jsonInt(u1)+`}`, string(ev.Data))
+
+	msg, err := CreateMessage(u1, g, "bien joué", nil)
+	require.NoError(t, err)
+	nextEvent(t, stream, events.ChatMessage)
+	require.NoError(t, DeleteMessage(u1, g, jsonInt(msg["id"].(int64))))
+	ev = nextEvent(t, stream, events.MessageDeleted)
+	assert.JSONEq(t, `{"id":`+jsonInt(msg["id"].(int64))+`}`, string(ev.Data))
+
+	require.NoError(t, ResignGame(u1, g))
+	ev = nextEvent(t, stream, events.GameEnded)
+	var ended gameEnded
+	require.NoError(t, json.Unmarshal(ev.Data, &ended))
+	assert.Equal(t, u2, ended.WinnerID)
+	assert.Equal(t, u1, ended.ForfeitedBy)
+}
+
+func TestEvents_PublishedForTakebackAndResign(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "ev_tb1")
+	u2 := mustCreateUser(t, "ev_tb2")
+	u3 := mustCreateUser(t, "ev_tb3")
+	gid, err := createStartedGame(t, u1, "events", []string{"ev_tb2", "ev_tb3"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+
+	stream, unsubscribe := events.Subscribe(u2)
+	defer unsubscribe()
+
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	_, err = RequestTakeback(u1, g)
+	require.NoError(t, err)
+	ev := nextEvent(t, stream, events.TakebackRequested)
+	assert.JSONEq(t, `{"player_id":`+jsonInt(u1)+`,"status":"requested"}`, string(ev.Data))
+
+	require.NoError(t, RespondTakeback(u2, g, true))
+	ev = nextEvent(t, stream, events.TakebackAnswered)
+	assert.JSONEq(t, `{"player_id":`+jsonInt(u1)+`,"status":"accepted","answered_by":`+jsonInt(u2)+`}`, string(ev.Data))
+	require.NoError(t, RespondTakeback(u3, g, true))
+	ev = nextEvent(t, stream, events.TakebackAnswered)
+	var answered takebackEvent
+	require.NoError(t, json.Unmarshal(ev.Data, &answered))
+	assert.True(t, answered.Applied)
+	assert.Equal(t, u1, answered.NextTurn)
+
+	// à plusieurs, l'abandon est publié sans fin de partie
+	require.NoError(t, ResignGame(u1, g))
+	ev = nextEvent(t, stream, events.PlayerResigned)
+	assert.JSONEq(t, `{"player_id":`+jsonInt(u1)+`,"next_turn":`+jsonInt(u2)+`}`, string(ev.Data))
+}
+
+func jsonInt(n int64) string {
+	raw, _ := json.Marshal(n)
+	return string(raw)
+}
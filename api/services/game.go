@@ -6,53 +6,163 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
 	"github.com/ZiplEix/scrabble/api/models/request"
 	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
 	"github.com/ZiplEix/scrabble/api/utils"
 	"github.com/ZiplEix/scrabble/api/word"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
-	"github.com/ZiplEix/scrabble/api/pkg/logger"
 )
 
-// Plateau vide 15x15
-func initEmptyBoard() [15][15]string {
-	return [15][15]string{}
+// GameOptions regroupe les réglages choisis à la création d'une partie.
+type GameOptions struct {
+	Difficulty    string             // difficulté du bot : "easy", "medium" ou "hard"
+	ChallengeRule string             // règle de contestation (engine.ChallengeNone par défaut)
+	Rules         *request.GameRules // surcharges des règles par défaut
+	Language      string             // code du pack de langue ("fr" par défaut)
+	Variant       string             // disposition du plateau (engine.LayoutStandard par défaut)
+	// TurnTimeLimitHours est le temps accordé pour jouer chaque tour (0 = illimité).
+	TurnTimeLimitHours int
+	// TimeoutAction est appliquée quand ce temps est écoulé (TimeoutPass par défaut).
+	TimeoutAction string
+	// Training accorde les reprises de coup sans approbation (parties contre le bot).
+	Training bool
+	// Visibility détermine qui peut regarder la partie (VisibilityPrivate par défaut).
+	Visibility string
+	// SpectatorChat laisse les spectateurs lire le chat de la partie.
+	SpectatorChat bool
+	// Seats publie la partie dans le lobby avec ce nombre total de places
+	// (0 = partie sur invitation uniquement).
+	Seats int
+	// MinRating est le classement minimal pour prendre une place du lobby.
+	MinRating *int
+	// InviteLinks réserve ce nombre de places à des liens d'invitation,
+	// partageables avec des joueurs sans compte.
+	InviteLinks int
+	// Mode est le mode de jeu (engine.ModeClassic par défaut).
+	Mode string
+	// RoundTimeLimitSeconds est la durée d'une ronde en duplicate
+	// (DefaultRoundTimeLimitSeconds si 0).
+	RoundTimeLimitSeconds int
 }
 
-// Lettres classiques du scrabble français + 2 jokers ('?')
-const initialLetters = "AAAAAAAAAEEEEEEEEEEEEIIIIIIIIONNNNNNRRRRRRTTTTTTLLLLSSSSUDDDGGGMMMBBCCPPFFHHVVJQKWXYZ??"
+// ErrUnsupportedLanguage est renvoyée quand la langue demandée n'existe pas ou
+// que son dictionnaire n'est pas disponible.
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// ErrInvalidVariant est renvoyée quand la variante de plateau demandée n'existe pas.
+var ErrInvalidVariant = errors.New("invalid variant")
 
 func CreateGame(userID int64, name string, usernames []string, revangeFrom *string, difficultyOpt ...string) (*uuid.UUID, error) {
-	difficulty := "hard"
-	if len(difficultyOpt) > 0 && difficultyOpt[0] != "" {
-		difficulty = difficultyOpt[0]
+	var opts GameOptions
+	if len(difficultyOpt) > 0 {
+		opts.Difficulty = difficultyOpt[0]
+	}
+	return CreateGameWithOptions(userID, name, usernames, revangeFrom, opts)
+}
+
+// CreateGameWithOptions crée une partie avec les réglages fournis et invite
+// les joueurs nommés par usernames. Une revanche reprend les réglages de la
+// partie d'origine.
+func CreateGameWithOptions(userID int64, name string, usernames []string, revangeFrom *string, opts GameOptions) (*uuid.UUID, error) {
+	invitees, err := resolveInvitees(database.DB, userID, usernames)
+	if err != nil {
+		return nil, err
+	}
+	return createGame(userID, name, invitees, revangeFrom, opts)
+}
+
+// createGame crée une partie en attente de ses invités. Elle commence
+// aussitôt si aucun d'eux n'a de réponse à donner (voir insertInvitations).
+func createGame(userID int64, name string, invitees []invitee, revangeFrom *string, opts GameOptions) (*uuid.UUID, error) {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "user_id", userID)
+		}
+	}()
+
+	gameID, err := createGameTx(tx, userID, name, invitees, revangeFrom, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
-	board := initEmptyBoard()
 
-	available := []rune(initialLetters)
-	utils.ShuffleRunes(available)
+	notifyInvitees(gameID.String(), name, userID, invitees)
 
-	rack := utils.DrawLetters(&available, 7)
+	return gameID, nil
+}
 
-	boardJSON, err := json.Marshal(board)
+// createGameTx crée la partie dans tx, sans valider la transaction ni
+// prévenir les invités (voir notifyInvitees) : l'appelant peut ainsi lier la
+// création à ses propres écritures.
+func createGameTx(tx *sql.Tx, userID int64, name string, invitees []invitee, revangeFrom *string, opts GameOptions) (*uuid.UUID, error) {
+	difficulty := "hard"
+	if opts.Difficulty != "" {
+		difficulty = opts.Difficulty
+	}
+	challengeRule := engine.ChallengeNone
+	if opts.ChallengeRule != "" {
+		if !engine.ValidChallengeRule(opts.ChallengeRule) {
+			return nil, engine.ErrInvalidChallengeRule
+		}
+		challengeRule = opts.ChallengeRule
+	}
+	lang := word.French
+	if opts.Language != "" {
+		l, ok := word.GetLanguage(opts.Language)
+		if !ok || !l.Available() {
+			return nil, ErrUnsupportedLanguage
+		}
+		lang = l
+	}
+	layout, ok := engine.LayoutByName(opts.Variant)
+	if !ok {
+		return nil, ErrInvalidVariant
+	}
+	turnTimeLimit, timeoutAction, err := validateTurnTimeLimit(opts.TurnTimeLimitHours, opts.TimeoutAction)
+	if err != nil {
+		return nil, err
+	}
+	training := opts.Training
+	visibility, err := validateVisibility(opts.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	spectatorChat := opts.SpectatorChat
+	rules, err := buildRuleset(opts.Rules, lang)
+	if err != nil {
+		return nil, err
+	}
+	mode, roundTimeLimit, err := validateGameMode(opts, challengeRule)
 	if err != nil {
 		return nil, err
 	}
 
-	availableStr := string(available)
 	gameID := uuid.New()
 
 	// Si une partie d'origine est fournie pour une revanche, vérifier que
 	// l'utilisateur courant est bien le créateur de cette partie.
 	if revangeFrom != nil {
 		var srcCreatedBy int64
-		var srcDifficulty string
-		err := database.QueryRow(`SELECT created_by, difficulty FROM games WHERE id = $1`, *revangeFrom).Scan(&srcCreatedBy, &srcDifficulty)
+		var srcDifficulty, srcChallengeRule, srcLanguage, srcVariant string
+		var srcRules []byte
+		err := tx.QueryRow(`
+			SELECT created_by, difficulty, challenge_rule, ruleset, language, variant, turn_time_limit_hours, timeout_action, training,
+				visibility, spectator_chat, mode, round_time_limit_seconds
+			FROM games WHERE id = $1
+		`, *revangeFrom).Scan(&srcCreatedBy, &srcDifficulty, &srcChallengeRule, &srcRules, &srcLanguage, &srcVariant, &turnTimeLimit, &timeoutAction, &training,
+			&visibility, &spectatorChat, &mode, &roundTimeLimit)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return nil, fmt.Errorf("source game not found")
@@ -63,89 +173,96 @@ func CreateGame(userID int64, name string, usernames []string, revangeFrom *stri
 			return nil, fmt.Errorf("only the creator of the original game can create a rematch")
 		}
 		difficulty = srcDifficulty
+		challengeRule = srcChallengeRule
+		lang = word.Lang(srcLanguage)
+		layout = layoutOrStandard(srcVariant)
+		if rules, err = parseRuleset(srcRules); err != nil {
+			return nil, err
+		}
 	}
 
-	tx, err := database.DB.BeginTx(context.Background(), nil)
+	// Le créateur joue en premier, suivi des invités qui acceptent
+	playerIDs := []int64{userID}
+	for _, inv := range invitees {
+		playerIDs = append(playerIDs, inv.ID)
+	}
+
+	if opts.InviteLinks < 0 {
+		return nil, fmt.Errorf("invalid invite links count")
+	}
+	if len(playerIDs)+opts.InviteLinks > rules.MaxPlayers {
+		return nil, fmt.Errorf("too many players: at most %d allowed", rules.MaxPlayers)
+	}
+	if training && opts.InviteLinks > 0 {
+		return nil, ErrTrainingRequiresBot
+	}
+	if training && !onlyBotOpponents(playerIDs, userID) {
+		return nil, ErrTrainingRequiresBot
+	}
+	lobbySeats, err := validateLobbySeats(opts.Seats, len(playerIDs)+opts.InviteLinks, rules)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID.String())
-		}
-	}()
+	if training && lobbySeats.Valid {
+		return nil, ErrTrainingRequiresBot
+	}
+	if mode == engine.ModeDuplicate && hasBot(playerIDs) {
+		return nil, ErrDuplicateAgainstBot
+	}
 
-	// Création du jeu
-	_, err = tx.Exec(`
-		INSERT INTO games (id, name, created_by, current_turn, board, available_letters, created_at, difficulty)
-		VALUES ($1, $2, $3, $3, $4, $5, $6, $7)
-	`, gameID, name, userID, boardJSON, availableStr, time.Now(), difficulty)
+	// Tous les tirages de la partie découleront d'une graine secrète dont
+	// seule l'empreinte est publiée, dès maintenant. Les racks ne sont tirés
+	// qu'au lancement de la partie (voir startGame).
+	seed, err := engine.NewSeed()
 	if err != nil {
 		return nil, err
 	}
-
-	// Ajouter le créateur en premier
-	rackStr := string(rack)
-	_, err = tx.Exec(`
-		INSERT INTO game_players (game_id, player_id, rack, position, score)
-		VALUES ($1, $2, $3, 0, 0)
-	`, gameID, userID, rackStr)
+	boardJSON, err := json.Marshal(layout.NewBoard())
 	if err != nil {
 		return nil, err
 	}
-
-	// Récupérer les IDs des autres joueurs
-	if len(usernames) > 0 {
-		query := `SELECT id, username FROM users WHERE LOWER(username) = ANY($1)`
-		rows, err := tx.Query(query, pq.Array(usernames))
-		if err != nil {
-			return nil, err
-		}
-		defer rows.Close()
-
-		type player struct {
-			id   int64
-			name string
-		}
-		var otherPlayers []player
-
-		for rows.Next() {
-			var otherID int64
-			var uname string
-			if err := rows.Scan(&otherID, &uname); err != nil {
-				return nil, err
-			}
-			otherPlayers = append(otherPlayers, player{otherID, uname})
-		}
-
-		// 💡 Ici seulement on fait les autres tx.Exec
-		position := 1
-		for _, p := range otherPlayers {
-			rack := drawLetters(&available, 7)
-			rackStr := string(rack)
-
-			_, err := tx.Exec(`
-				INSERT INTO game_players (game_id, player_id, rack, position, score)
-				VALUES ($1, $2, $3, $4, 0)
-			`, gameID, p.id, rackStr, position)
-			if err != nil {
-				return nil, err
-			}
-			position++
-		}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
 	}
 
-	// Mise à jour du sac de lettres
+	// Création du jeu, en attente des réponses des invités
 	_, err = tx.Exec(`
-		UPDATE games SET available_letters = $1 WHERE id = $2
-	`, string(available), gameID)
+		INSERT INTO games (id, name, created_by, current_turn, board, available_letters, created_at, difficulty, challenge_rule, ruleset, language, variant,
+			turn_time_limit_hours, timeout_action, training, bag_seed, bag_seed_hash, draw_count,
+			visibility, spectator_chat, status, lobby_seats, lobby_min_rating, mode, round_time_limit_seconds)
+		VALUES ($1, $2, $3, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+			$12, $13, $14, $15, $16, 0,
+			$17, $18, 'pending', $19, $20, $21, $22)
+	`, gameID, name, userID, boardJSON, lang.BagOfSize(layout.BagSize), time.Now(), difficulty, challengeRule, rulesJSON, lang.Code, layout.Name,
+		turnTimeLimit, timeoutAction, training, seed, engine.SeedHash(seed),
+		visibility, spectatorChat, lobbySeats, opts.MinRating, mode, roundTimeLimit)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := tx.Commit(); err != nil {
+	_, err = tx.Exec(`
+		INSERT INTO game_players (game_id, player_id, rack, position, score)
+		VALUES ($1, $2, $3, 0, 0)
+	`, gameID, userID, word.Tiles{})
+	if err != nil {
 		return nil, err
 	}
+	waiting, err := insertInvitations(tx, gameID.String(), invitees)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < opts.InviteLinks; i++ {
+		if _, err := insertInviteLink(tx, gameID.String(), userID, defaultInviteLinkHours); err != nil {
+			return nil, err
+		}
+		waiting = true
+	}
+	// sans invité humain (partie solo ou contre le bot) ni place ouverte, rien à attendre
+	if !waiting && !lobbySeats.Valid {
+		if err := startGame(tx, gameID.String()); err != nil {
+			return nil, err
+		}
+	}
 
 	return &gameID, nil
 }
@@ -174,6 +291,15 @@ func DeleteGame(userID int64, gameID string) error {
 		}
 	}()
 
+	if err := deleteGameTx(tx, gameID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteGameTx supprime une partie et son historique (coups, joueurs, chat).
+func deleteGameTx(tx *sql.Tx, gameID string) error {
 	if _, err := tx.Exec(`DELETE FROM game_moves WHERE game_id = $1`, gameID); err != nil {
 		return err
 	}
@@ -190,11 +316,8 @@ func DeleteGame(userID int64, gameID string) error {
 		return err
 	}
 
-	if _, err := tx.Exec(`DELETE FROM games WHERE id = $1`, gameID); err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	_, err := tx.Exec(`DELETE FROM games WHERE id = $1`, gameID)
+	return err
 }
 
 // GetAdminGameDetail retourne toutes les infos d'une partie pour l'admin (incluant racks et sac), hors chat
@@ -204,30 +327,45 @@ func GetAdminGameDetail(gameID string) (*response.GameInfo, error) {
 	   SELECT id, name, board, available_letters,
 			current_turn, status, created_by,
 			winner_username, ended_at, pass_count,
-			difficulty
+			difficulty, challenge_rule, ruleset, language, variant,
+			turn_time_limit_hours, timeout_action, turn_deadline, forfeited_by, training
 	   FROM games
 	   WHERE id = $1
 	`
 	var (
 		boardJSON      []byte
-		avail          string
+		avail          word.Tiles
 		game           response.GameInfo
 		createdBy      int64
 		winnerUsername sql.NullString
 		endedAt        sql.NullTime
+		rulesJSON      []byte
+		turnDeadline   sql.NullTime
+		forfeitedBy    sql.NullInt64
 	)
 	err := database.QueryRow(gameQuery, gameID).Scan(
 		&game.ID, &game.Name, &boardJSON, &avail,
 		&game.CurrentTurn, &game.Status, &createdBy,
 		&winnerUsername, &endedAt, &game.PassCount,
-		&game.Difficulty,
+		&game.Difficulty, &game.ChallengeRule, &rulesJSON, &game.Language, &game.Variant,
+		&game.TurnTimeLimitHours, &game.TimeoutAction, &turnDeadline, &forfeitedBy, &game.Training,
 	)
 	if err != nil {
 		return nil, err
 	}
-	game.AvailableLetters = avail
+	game.AvailableLetters = avail.String()
 	game.RemainingLetters = len(avail)
 	_ = json.Unmarshal(boardJSON, &game.Board)
+	if rules, err := parseRuleset(rulesJSON); err == nil {
+		game.Rules = gameRulesResponse(rules)
+	}
+	game.BoardSize, game.Premiums = layoutResponse(layoutOrStandard(game.Variant))
+	if turnDeadline.Valid {
+		game.TurnDeadline = &turnDeadline.Time
+	}
+	if forfeitedBy.Valid {
+		game.ForfeitedBy = &forfeitedBy.Int64
+	}
 
 	if winnerUsername.Valid {
 		game.WinnerUsername = winnerUsername.String
@@ -238,7 +376,7 @@ func GetAdminGameDetail(gameID string) (*response.GameInfo, error) {
 
 	// 2. Récupère les joueurs (avec racks)
 	playerRows, err := database.Query(`
-		SELECT gp.player_id, u.username, gp.score, gp.position, gp.rack, u.is_bot
+		SELECT gp.player_id, u.username, gp.score, gp.position, gp.rack, u.is_bot, gp.resigned
 		FROM game_players gp
 		JOIN users u ON gp.player_id = u.id
 		WHERE gp.game_id = $1
@@ -251,10 +389,12 @@ func GetAdminGameDetail(gameID string) (*response.GameInfo, error) {
 
 	for playerRows.Next() {
 		var p response.PlayerInfo
-		err := playerRows.Scan(&p.ID, &p.Username, &p.Score, &p.Position, &p.Rack, &p.IsBot)
+		var rack word.Tiles
+		err := playerRows.Scan(&p.ID, &p.Username, &p.Score, &p.Position, &rack, &p.IsBot, &p.Resigned)
 		if err != nil {
 			return nil, err
 		}
+		p.Rack = rack.String()
 		if p.ID == game.CurrentTurn {
 			game.CurrentTurnName = p.Username
 		}
@@ -266,7 +406,7 @@ func GetAdminGameDetail(gameID string) (*response.GameInfo, error) {
 		SELECT player_id, move, created_at
 		FROM game_moves
 		WHERE game_id = $1
-		ORDER BY created_at ASC
+		ORDER BY created_at ASC, id ASC
 	`, gameID)
 	if err != nil {
 		return nil, err
@@ -279,7 +419,10 @@ func GetAdminGameDetail(gameID string) (*response.GameInfo, error) {
 		if err := moveRows.Scan(&mv.PlayerID, &moveJSON, &mv.PlayedAt); err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal(moveJSON, &mv.Move)
+		var move map[string]any
+		_ = json.Unmarshal(moveJSON, &move)
+		mv.Type = moveType(move)
+		mv.Move = move
 		game.Moves = append(game.Moves, mv)
 	}
 
@@ -337,37 +480,96 @@ func GetGameDetails(userID int64, gameID string) (*response.GameInfo, error) {
 		return nil, errors.New("unauthorized or game not found")
 	}
 
+	game, err := loadGameInfo(gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Récupère ton rack
+	var rack word.Tiles
+	err = database.QueryRow(`SELECT rack FROM game_players WHERE game_id=$1 AND player_id=$2`,
+		gameID, userID).Scan(&rack)
+	if err != nil {
+		return nil, err
+	}
+	game.YourRack = rack.String()
+	game.YourTiles = rack.Strings()
+
+	return game, nil
+}
+
+// loadGameInfo charge la partie telle que la voit viewerID, sans aucun rack :
+// infos, joueurs, historique (échanges et abandons des autres masqués) et
+// jokers posés. viewerID vaut 0 pour un spectateur.
+func loadGameInfo(gameID string, viewerID int64) (*response.GameInfo, error) {
 	// 2. Récupère info partie
 	gameQuery := `
        SELECT id, name, board, available_letters,
 			 current_turn, status, created_by,
 			 winner_username, ended_at, pass_count,
-			 difficulty
+			 difficulty, challenge_rule, ruleset, language, variant,
+			 turn_time_limit_hours, timeout_action, turn_deadline, forfeited_by, training,
+			 bag_seed, bag_seed_hash, visibility, spectator_chat, lobby_seats, lobby_min_rating,
+			 mode, round_time_limit_seconds
        FROM games
        WHERE id = $1
     `
 	var (
 		boardJSON      []byte
-		avail          string
+		avail          word.Tiles
 		game           response.GameInfo
 		createdBy      int64
 		winnerUsername sql.NullString
 		endedAt        sql.NullTime
+		rulesJSON      []byte
+		turnDeadline   sql.NullTime
+		forfeitedBy    sql.NullInt64
+		bagSeed        []byte
+		bagSeedHash    sql.NullString
+		lobbySeats     sql.NullInt64
+		lobbyMinRating sql.NullInt64
 	)
-	err = database.QueryRow(gameQuery, gameID).Scan(
+	err := database.QueryRow(gameQuery, gameID).Scan(
 		&game.ID, &game.Name, &boardJSON, &avail,
 		&game.CurrentTurn, &game.Status, &createdBy,
 		&winnerUsername, &endedAt, &game.PassCount,
-		&game.Difficulty,
+		&game.Difficulty, &game.ChallengeRule, &rulesJSON, &game.Language, &game.Variant,
+		&game.TurnTimeLimitHours, &game.TimeoutAction, &turnDeadline, &forfeitedBy, &game.Training,
+		&bagSeed, &bagSeedHash, &game.Visibility, &game.SpectatorChat, &lobbySeats, &lobbyMinRating,
+		&game.Mode, &game.RoundTimeLimitSeconds,
 	)
 	if err != nil {
 		return nil, err
 	}
+	game.BagSeedHash = bagSeedHash.String
+	if gameFinished(game.Status) {
+		game.BagSeed = bagSeedHex(bagSeed)
+	}
+	if game.Status == "pending" {
+		if game.Invitations, err = loadInvitations(gameID); err != nil {
+			return nil, err
+		}
+	}
+	game.LobbySeats = int(lobbySeats.Int64)
+	if lobbyMinRating.Valid {
+		r := int(lobbyMinRating.Int64)
+		game.LobbyMinRating = &r
+	}
 	game.RemainingLetters = len(avail)
 	_ = json.Unmarshal(boardJSON, &game.Board)
+	if rules, err := parseRuleset(rulesJSON); err == nil {
+		game.Rules = gameRulesResponse(rules)
+	}
+	game.BoardSize, game.Premiums = layoutResponse(layoutOrStandard(game.Variant))
+	if turnDeadline.Valid {
+		game.TurnDeadline = &turnDeadline.Time
+	}
+	if forfeitedBy.Valid {
+		game.ForfeitedBy = &forfeitedBy.Int64
+	}
 
 	// transfert dans le DTO
-	game.IsYourGame = (createdBy == userID)
+	game.IsYourGame = (createdBy == viewerID)
 	if winnerUsername.Valid {
 		game.WinnerUsername = winnerUsername.String
 	}
@@ -375,16 +577,9 @@ func GetGameDetails(userID int64, gameID string) (*response.GameInfo, error) {
 		game.EndedAt = &endedAt.Time
 	}
 
-	// 3. Récupère ton rack
-	err = database.QueryRow(`SELECT rack FROM game_players WHERE game_id=$1 AND player_id=$2`,
-		gameID, userID).Scan(&game.YourRack)
-	if err != nil {
-		return nil, err
-	}
-
 	// 4. Récupère les joueurs
 	playerRows, err := database.Query(`
-		SELECT gp.player_id, u.username, gp.score, gp.position, u.is_bot
+		SELECT gp.player_id, u.username, gp.score, gp.position, u.is_bot, gp.resigned
 		FROM game_players gp
 		JOIN users u ON gp.player_id = u.id
 		WHERE gp.game_id = $1
@@ -397,7 +592,7 @@ func GetGameDetails(userID int64, gameID string) (*response.GameInfo, error) {
 
 	for playerRows.Next() {
 		var p response.PlayerInfo
-		err := playerRows.Scan(&p.ID, &p.Username, &p.Score, &p.Position, &p.IsBot)
+		err := playerRows.Scan(&p.ID, &p.Username, &p.Score, &p.Position, &p.IsBot, &p.Resigned)
 		if err != nil {
 			return nil, err
 		}
@@ -412,7 +607,7 @@ func GetGameDetails(userID int64, gameID string) (*response.GameInfo, error) {
 		SELECT player_id, move, created_at
 		FROM game_moves
 		WHERE game_id = $1
-		ORDER BY created_at ASC
+		ORDER BY created_at ASC, id ASC
 	`, gameID)
 	if err != nil {
 		return nil, err
@@ -425,7 +620,11 @@ func GetGameDetails(userID int64, gameID string) (*response.GameInfo, error) {
 		if err := moveRows.Scan(&mv.PlayerID, &moveJSON, &mv.PlayedAt); err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal(moveJSON, &mv.Move)
+		var move map[string]any
+		_ = json.Unmarshal(moveJSON, &move)
+		mv.Type = moveType(move)
+		redactMove(move, mv.PlayerID, viewerID)
+		mv.Move = move
 		game.Moves = append(game.Moves, mv)
 	}
 
@@ -441,202 +640,123 @@ func GetGameDetails(userID int64, gameID string) (*response.GameInfo, error) {
 	return &game, nil
 }
 
+// PlayMove joue le coup req pour userID (voir PlayMoveIdempotent).
 func PlayMove(gameID string, userID int64, req request.PlayMoveRequest) error {
-	// 1. Vérification de l'appartenance au jeu et du tour
-	if err := validatePlayerInGame(gameID, userID); err != nil {
-		return err
-	}
-	var currentTurn int64
-	err := database.QueryRow(`SELECT current_turn FROM games WHERE id = $1`, gameID).Scan(&currentTurn)
-	if err != nil {
-		return fmt.Errorf("game not found: %v", err)
-	}
-	if currentTurn != userID {
-		return fmt.Errorf("not your turn")
-	}
+	return PlayMoveIdempotent(gameID, userID, req, "")
+}
 
-	// 2. Récupération du rack
-	var rack string
-	err = database.QueryRow(`SELECT rack FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, userID).Scan(&rack)
+// PlayMoveIdempotent joue le coup req dans une seule transaction, partie
+// verrouillée, lectures de validation comprises. Une requête renvoyée avec la
+// même clé non vide après avoir abouti ne rejoue rien et réussit à nouveau.
+func PlayMoveIdempotent(gameID string, userID int64, req request.PlayMoveRequest, key string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
 	if err != nil {
-		return fmt.Errorf("player not in game: %v", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
-
-	// Déduction automatique des jokers si le client ne les a pas marqués
-	resolvedLetters, err := resolveBlanks(rack, req.Letters)
-	if err != nil {
-		return fmt.Errorf("invalid move: you don't have the required letters")
-	}
-	req.Letters = resolvedLetters
-	if !rackContains(rack, req.Letters) {
-		return fmt.Errorf("invalid move: you don't have the required letters")
-	}
-	if len(req.Letters) == 0 {
-		return fmt.Errorf("no letters provided")
-	} else if len(req.Letters) > 7 {
-		return fmt.Errorf("cannot place more than 7 letters in one move")
-	}
-
-	// 3. Vérification alignement
-	sameRow := true
-	sameCol := true
-	firstX := req.Letters[0].X
-	firstY := req.Letters[0].Y
-	for _, l := range req.Letters {
-		if l.X != firstX {
-			sameCol = false
-		}
-		if l.Y != firstY {
-			sameRow = false
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "failed to rollback transaction", "error", err, "game_id", gameID)
 		}
-	}
-	if !sameRow && !sameCol {
-		return fmt.Errorf("letters must be aligned in the same row or column")
-	}
+	}()
 
-	// 4. Chargement du plateau
-	board, err := loadBoard(gameID)
-	if err != nil {
+	// 1. Verrouillage de la partie et vérification de l'appartenance au jeu
+	if err := lockGame(tx, gameID); err != nil {
 		return err
 	}
-
-	// 5. Vérification placement (centre ou contact)
-	isFirstMove := true
-	for y := 0; y < 15 && isFirstMove; y++ {
-		for x := 0; x < 15 && isFirstMove; x++ {
-			if board[y][x] != "" {
-				isFirstMove = false
-			}
-		}
-	}
-
-	if isFirstMove {
-		found := false
-		for _, l := range req.Letters {
-			if l.X == 7 && l.Y == 7 {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return fmt.Errorf("first move must cover the center cell")
-		}
-	} else {
-		touchesExisting := false
-		for _, l := range req.Letters {
-			for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
-				x, y := l.X+d[0], l.Y+d[1]
-				if x >= 0 && x < 15 && y >= 0 && y < 15 && board[y][x] != "" {
-					touchesExisting = true
-					break
-				}
-			}
-			if touchesExisting {
-				break
-			}
-		}
-		if !touchesExisting {
-			return fmt.Errorf("word must connect to existing letters")
-		}
-	}
-
-	// 6. Appliquer les lettres
-	if err := applyLetters(&board, req.Letters); err != nil {
+	if done, err := replayIdempotent(tx, userID, key, gameID, idempotentPlay, nil); err != nil || done {
 		return err
 	}
-
-	// 7. Validation des mots
-	for _, fw := range extractFormedWords(board, req.Letters) {
-		if !word.WordExists(fw.Word) {
-			return fmt.Errorf("invalid word played: %s", fw.Word)
-		}
+	if err := validatePlayerInGame(tx, gameID, userID); err != nil {
+		return err
 	}
 
-	// 8. Recalcul rack et score
-	newRack, err := updatePlayerRack(gameID, userID, rack, req.Letters)
+	// 2. Chargement de l'état et application des règles par le moteur
+	state, err := loadGameState(tx, gameID)
 	if err != nil {
-		return fmt.Errorf("failed to update rack: %v", err)
-	}
-	// Reconstruire les positions de jokers depuis l'historique des coups
-	boardBlank := buildBoardBlanks(gameID)
-	// Ajouter aussi les jokers du coup courant
-	for _, pl := range req.Letters {
-		if pl.Blank {
-			boardBlank[Pos{pl.X, pl.Y}] = true
+		return fmt.Errorf("game not found: %v", err)
+	}
+	if req.Notation != "" {
+		notation, err := engine.ParseNotation(state.Language, state.Board, req.Notation)
+		if err != nil {
+			return err
 		}
+		req.Word, req.StartX, req.StartY, req.Direction = notation.Word, notation.X, notation.Y, notation.Direction()
+		req.Letters = notation.Letters
 	}
-	moveScore := computeMoveScore(board, req.Letters, boardBlank)
-
-	// 9. Enregistrement du coup
-	// enrichit la requête avec le score calculé pour faciliter les agrégations
-	req.Score = moveScore
-	moveJSON, _ := json.Marshal(req)
-	_, err = database.Exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ($1, $2, $3)`, gameID, userID, moveJSON)
+	next, res, err := state.ApplyMove(userID, req.Letters)
 	if err != nil {
-		return fmt.Errorf("failed to insert move: %v", err)
+		return err
 	}
 
-	// 10. Mise à jour transactionnelle et met le pass_count à 0
-	tx, err := database.DB.BeginTx(context.Background(), nil)
+	// Jouer sans contester ferme la fenêtre de contestation du coup précédent
+	accepted, err := acceptPendingMoves(tx, gameID)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to accept pending moves: %v", err)
 	}
-	defer func() {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			logger.Error(context.Background(), "failed to rollback transaction", "error", err, "game_id", gameID)
-		}
-	}()
 
-	newBoardJSON, _ := json.Marshal(board)
-	_, err = tx.Exec(`UPDATE games SET board = $1, pass_count = 0 WHERE id = $2`, newBoardJSON, gameID)
-	if err != nil {
-		return fmt.Errorf("failed to update game board: %v", err)
+	// 3. Enregistrement du coup
+	// enrichit la requête avec les jokers résolus et le score calculé pour faciliter les agrégations
+	req.Letters = res.Letters
+	req.Score = res.Score
+	record := playMoveRecord{
+		Type:            MoveTypePlay,
+		PlayMoveRequest: req,
+		Drawn:           res.Drawn,
+		PrevPassCount:   res.PrevPassCount,
 	}
-	_, err = tx.Exec(`UPDATE game_players SET rack = $1, score = score + $2 WHERE game_id = $3 AND player_id = $4`, newRack, moveScore, gameID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to update game player: %v", err)
+	if res.Pending {
+		record.Status = MoveStatusPending
+	}
+	if err := insertGameMove(tx, gameID, userID, record); err != nil {
+		return fmt.Errorf("failed to insert move: %v", err)
 	}
 
-	var currentPosition int
-	err = tx.QueryRow(`SELECT position FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, userID).Scan(&currentPosition)
-	if err != nil {
-		return fmt.Errorf("failed to get player position: %v", err)
+	// 4. Mise à jour du plateau, du rack, du score et du tour
+	if err := saveGameState(tx, gameID, next); err != nil {
+		return err
 	}
-	var nextPlayerID int64
-	err = tx.QueryRow(`SELECT player_id FROM game_players WHERE game_id = $1 AND position = (($2 + 1) % (SELECT COUNT(*) FROM game_players WHERE game_id = $1))`, gameID, currentPosition).Scan(&nextPlayerID)
-	if err != nil {
-		return fmt.Errorf("failed to get next player ID: %v", err)
+	if err := saveIdempotent(tx, userID, key, gameID, idempotentPlay, nil); err != nil {
+		return err
+	}
+
+	played := moveEvent{
+		PlayerID: userID,
+		Word:     req.Word,
+		Score:    res.Score,
+		Letters:  res.Letters,
+		Pending:  res.Pending,
+		NextTurn: res.NextTurn,
 	}
 
 	// Si le rack du joueur est vide ET que le sac est vide, on termine la partie
-	var bag string
-	if err := tx.QueryRow(
-		`SELECT available_letters FROM games WHERE id = $1`, gameID,
-	).Scan(&bag); err != nil {
-		return fmt.Errorf("failed to get available letters: %w", err)
-	}
-	if len(newRack) == 0 && len(bag) == 0 {
-		if err := finishGame(tx, gameID, userID); err != nil {
+	if res.GameOver {
+		ended, err := finishGame(tx, gameID, next, userID)
+		if err != nil {
 			return err
 		}
 		if err := tx.Commit(); err != nil {
 			return fmt.Errorf("failed to commit transaction: %w", err)
 		}
-		CheckAndUnlockPlayMoveAchievements(userID, req.Letters, moveScore, req.Word)
+		publishGameEvent(gameID, events.MovePlayed, played)
+		publishGameEnded(gameID, ended)
+		unlockAcceptedPlaysAchievements(accepted)
+		CheckAndUnlockPlayMoveAchievements(userID, req.Letters, res.Score, req.Word)
 		return nil
 	}
 
-	// Sinon, passe au joueur suivant
-	_, err = tx.Exec(`UPDATE games SET current_turn = $1 WHERE id = $2`, nextPlayerID, gameID)
-	if err != nil {
-		return fmt.Errorf("failed to update current turn: %w", err)
-	}
-
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	publishGameEvent(gameID, events.MovePlayed, played)
+
+	unlockAcceptedPlaysAchievements(accepted)
+	// Un coup provisoire ne débloque ses succès qu'une fois accepté
+	if !res.Pending {
+		CheckAndUnlockPlayMoveAchievements(userID, req.Letters, res.Score, req.Word)
+	}
 
-	CheckAndUnlockPlayMoveAchievements(userID, req.Letters, moveScore, req.Word)
+	nextPlayerID := res.NextTurn
+	moveScore := res.Score
 
 	var username, gameName string
 	err = database.QueryRow(`SELECT username FROM users WHERE id = $1`, userID).Scan(&username)
@@ -666,7 +786,49 @@ func PlayMove(gameID string, userID int64, req request.PlayMoveRequest) error {
 	return nil
 }
 
+// GetNewRack échange l'intégralité du rack du joueur et retourne le nouveau rack.
 func GetNewRack(userID int64, gameID string) ([]string, error) {
+	return GetNewRackIdempotent(userID, gameID, "")
+}
+
+// GetNewRackIdempotent est GetNewRack avec une clé d'idempotence : une requête
+// renvoyée avec la même clé retourne le rack obtenu la première fois.
+func GetNewRackIdempotent(userID int64, gameID, key string) ([]string, error) {
+	out, err := exchangeTiles(userID, gameID, key, func(rack word.Tiles) word.Tiles { return rack })
+	if err != nil {
+		return nil, err
+	}
+	return out.Rack, nil
+}
+
+// ExchangeTiles remet dans le sac les tuiles choisies par le joueur ('?' pour
+// un joker) et retourne exactement les tuiles piochées en remplacement.
+func ExchangeTiles(userID int64, gameID string, tiles []string) ([]string, error) {
+	return ExchangeTilesIdempotent(userID, gameID, tiles, "")
+}
+
+// ExchangeTilesIdempotent est ExchangeTiles avec une clé d'idempotence : une
+// requête renvoyée avec la même clé retourne les tuiles piochées la première fois.
+func ExchangeTilesIdempotent(userID int64, gameID string, tiles []string, key string) ([]string, error) {
+	returned := word.TilesFromStrings(tiles)
+	out, err := exchangeTiles(userID, gameID, key, func(word.Tiles) word.Tiles { return returned })
+	if err != nil {
+		return nil, err
+	}
+	return out.Drawn, nil
+}
+
+// exchangeOutcome est ce qu'un échange renvoie au joueur, conservé tel quel
+// pour les requêtes rejouées avec la même clé d'idempotence.
+type exchangeOutcome struct {
+	Rack  []string `json:"rack"`
+	Drawn []string `json:"drawn"`
+}
+
+// exchangeTiles effectue l'échange dans une transaction, partie verrouillée.
+// pick reçoit le rack courant du joueur et retourne les tuiles à remettre dans
+// le sac.
+func exchangeTiles(userID int64, gameID, key string, pick func(rack word.Tiles) word.Tiles) (*exchangeOutcome, error) {
 	tx, err := database.DB.BeginTx(context.Background(), nil)
 	if err != nil {
 		return nil, err
@@ -677,87 +839,70 @@ func GetNewRack(userID int64, gameID string) ([]string, error) {
 		}
 	}()
 
-	// Vérifier que c'est au tour du joueur
-	var currentTurn int64
-	err = tx.QueryRow(`SELECT current_turn FROM games WHERE id = $1`, gameID).Scan(&currentTurn)
-	if err != nil {
-		return nil, fmt.Errorf("game not found")
+	if err := lockGame(tx, gameID); err != nil {
+		return nil, err
 	}
-	if currentTurn != userID {
-		return nil, fmt.Errorf("not your turn")
+	var out exchangeOutcome
+	if done, err := replayIdempotent(tx, userID, key, gameID, idempotentExchange, &out); err != nil || done {
+		return &out, err
 	}
 
-	// Récupérer l'ancien rack du joueur
-	var oldRack string
-	err = tx.QueryRow(`SELECT rack FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, userID).Scan(&oldRack)
+	state, err := loadGameState(tx, gameID)
 	if err != nil {
-		return nil, fmt.Errorf("player not in game")
+		return nil, fmt.Errorf("game not found")
 	}
 
-	// Récupérer le sac actuel
-	var bag string
-	err = tx.QueryRow(`SELECT available_letters FROM games WHERE id = $1`, gameID).Scan(&bag)
+	next, res, err := state.Exchange(userID, pick(state.Racks[userID]))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load bag")
-	}
-
-	newRack, updatedBag := utils.DrawLettersFromString(bag, 7)
-	if len(newRack) == 0 {
-		return nil, fmt.Errorf("no letters left in the bag")
+		return nil, err
 	}
 
-	newBag := updatedBag + oldRack
-
-	// Update le rack du joueur
-	_, err = tx.Exec(`
-		UPDATE game_players SET rack = $1
-		WHERE game_id = $2 AND player_id = $3
-	`, strings.Join(newRack, ""), gameID, userID)
+	accepted, err := acceptPendingMoves(tx, gameID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update rack")
+		return nil, fmt.Errorf("failed to accept pending moves: %v", err)
 	}
 
-	// Update le sac
-	_, err = tx.Exec(`UPDATE games SET available_letters = $1 WHERE id = $2`, newBag, gameID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update bag")
+	exchangeMove := exchangeMoveRecord{
+		Type:     MoveTypeExchange,
+		Count:    len(res.Returned),
+		Returned: res.Returned,
+		Drawn:    res.Drawn,
+	}
+	if err := insertGameMove(tx, gameID, userID, exchangeMove); err != nil {
+		return nil, fmt.Errorf("failed to insert move: %v", err)
 	}
 
-	// Calculer le joueur suivant
-	var currentPosition int
-	err = tx.QueryRow(`
-		SELECT position FROM game_players
-		WHERE game_id = $1 AND player_id = $2
-	`, gameID, userID).Scan(&currentPosition)
-	if err != nil {
+	if err := saveGameState(tx, gameID, next); err != nil {
 		return nil, err
 	}
-
-	var nextPlayerID int64
-	err = tx.QueryRow(`
-		SELECT player_id FROM game_players
-		WHERE game_id = $1 AND position = (
-			($2 + 1) % (SELECT COUNT(*) FROM game_players WHERE game_id = $1)
-		)
-	`, gameID, currentPosition).Scan(&nextPlayerID)
-	if err != nil {
+	out = exchangeOutcome{Rack: next.Racks[userID].Strings(), Drawn: res.Drawn.Strings()}
+	if err := saveIdempotent(tx, userID, key, gameID, idempotentExchange, out); err != nil {
 		return nil, err
 	}
 
-	_, err = tx.Exec(`UPDATE games SET current_turn = $1 WHERE id = $2`, nextPlayerID, gameID)
-	if err != nil {
-		return nil, err
+	// Fin de partie ? (limite de tours sans score atteinte)
+	var ended *gameEnded
+	if res.GameOver {
+		if ended, err = finishGame(tx, gameID, next, 0); err != nil {
+			return nil, err
+		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	publishGameEvent(gameID, events.Exchange, moveEvent{PlayerID: userID, Count: len(res.Returned), NextTurn: res.NextTurn})
+	publishGameEnded(gameID, ended)
 
-	// Déclencher le bot en goroutine si c'est son tour
-	TriggerBotIfNeeded(gameID, nextPlayerID)
+	unlockAcceptedPlaysAchievements(accepted)
+
+	if !res.GameOver {
+		// Déclencher le bot en goroutine si c'est son tour
+		TriggerBotIfNeeded(gameID, res.NextTurn)
+	}
 
-	return newRack, nil
+	return &out, nil
 }
 
 func GetGamesByUserID(userID int64) ([]response.GameSummary, error) {
@@ -906,39 +1051,48 @@ func GetAllGamesAdmin() ([]response.AdminGameSummary, error) {
 	return out, nil
 }
 
-func SimulateScore(gameID string, userID int64, letters []request.PlacedLetter) (int, error) {
-	if len(letters) == 0 {
+// SimulateScore calcule le score d'un coup sans le jouer. Le coup est décrit
+// par letters ou, à défaut, par sa notation (voir engine.ParseNotation).
+func SimulateScore(gameID string, userID int64, letters []request.PlacedLetter, notation string) (int, error) {
+	if len(letters) == 0 && notation == "" {
 		return 0, nil
 	}
-	if err := validatePlayerInGame(gameID, userID); err != nil {
+	if err := validatePlayerInGame(database.DB, gameID, userID); err != nil {
 		return 0, err
 	}
-	board, err := loadBoard(gameID)
+	state, err := loadGameState(database.DB, gameID)
 	if err != nil {
 		return 0, err
 	}
-	// tente de déduire les jokers à partir du rack courant pour une simulation fidèle
-	var rack string
-	if err := database.QueryRow(`SELECT rack FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, userID).Scan(&rack); err == nil {
-		if rl, err := resolveBlanks(rack, letters); err == nil {
-			letters = rl
-		}
+	if letters, err = notationLetters(state.Language, state.Board, notation, letters); err != nil {
+		return 0, err
 	}
+	// les jokers sont déduits du rack courant pour une simulation fidèle
+	return state.ScoreMove(userID, letters)
+}
 
-	if err := applyLetters(&board, letters); err != nil {
-		return 0, err
+// notationLetters retourne les lettres décrites par notation sur board, ou
+// letters quand aucune notation n'est fournie.
+func notationLetters(lang *word.Language, board engine.Board, notation string, letters []request.PlacedLetter) ([]request.PlacedLetter, error) {
+	if notation == "" {
+		return letters, nil
 	}
-	// Reconstruit les positions de jokers déjà présentes
-	boardBlank := buildBoardBlanks(gameID)
-	for _, pl := range letters {
-		if pl.Blank {
-			boardBlank[Pos{pl.X, pl.Y}] = true
-		}
+	n, err := engine.ParseNotation(lang, board, notation)
+	if err != nil {
+		return nil, err
 	}
-	return computeMoveScore(board, letters, boardBlank), nil
+	return n.Letters, nil
 }
 
+// PassTurn fait passer son tour à userID (voir PassTurnIdempotent).
 func PassTurn(userID int64, gameID string) error {
+	return PassTurnIdempotent(userID, gameID, "")
+}
+
+// PassTurnIdempotent passe le tour dans une transaction, partie verrouillée.
+// Une requête renvoyée avec la même clé non vide après avoir abouti ne passe
+// pas une seconde fois le tour.
+func PassTurnIdempotent(userID int64, gameID, key string) error {
 	ctx := context.Background()
 
 	tx, err := database.DB.BeginTx(ctx, nil)
@@ -951,86 +1105,284 @@ func PassTurn(userID int64, gameID string) error {
 		}
 	}()
 
-	// Vérifie le tour (et verrouille la ligne game)
-	var currentTurn int64
-	if err := tx.QueryRowContext(ctx,
-		`SELECT current_turn FROM games WHERE id = $1 FOR UPDATE`, gameID,
-	).Scan(&currentTurn); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return errors.New("game not found")
-		}
+	// Verrouille la ligne game avant de charger l'état
+	if err := lockGame(tx, gameID); err != nil {
 		return err
 	}
-	if currentTurn != userID {
-		return errors.New("not your turn")
+	if done, err := replayIdempotent(tx, userID, key, gameID, idempotentPass, nil); err != nil || done {
+		return err
 	}
 
-	// Enregistre le "pass" DANS la transaction
-	passMove := map[string]any{"type": "pass"}
-	moveJSON, _ := json.Marshal(passMove)
-	if _, err := tx.ExecContext(ctx,
-		`INSERT INTO game_moves (game_id, player_id, move) VALUES ($1, $2, $3)`,
-		gameID, userID, moveJSON,
-	); err != nil {
-		return errors.New("failed to record pass")
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return err
 	}
-
-	// Récupère position du joueur
-	var position int
-	if err := tx.QueryRowContext(ctx,
-		`SELECT position FROM game_players WHERE game_id = $1 AND player_id = $2`,
-		gameID, userID,
-	).Scan(&position); err != nil {
+	next, res, err := state.Pass(userID)
+	if err != nil {
 		return err
 	}
 
-	// Détermine prochain joueur
-	var nextPlayer int64
-	if err := tx.QueryRowContext(ctx,
-		`SELECT player_id FROM game_players
-		  WHERE game_id = $1
-		    AND position = (($2 + 1) % (SELECT COUNT(*) FROM game_players WHERE game_id = $1))`,
-		gameID, position,
-	).Scan(&nextPlayer); err != nil {
+	accepted, err := acceptPendingMoves(tx, gameID)
+	if err != nil {
 		return err
 	}
 
-	// Incrémente pass_count ET met à jour le tour courant, en récupérant la valeur
-	var passCount int
-	if err := tx.QueryRowContext(ctx,
-		`UPDATE games
-		    SET pass_count = pass_count + 1,
-		        current_turn = $2
-		  WHERE id = $1
-		  RETURNING pass_count`,
-		gameID, nextPlayer,
-	).Scan(&passCount); err != nil {
-		return err
+	// Enregistre le "pass" DANS la transaction
+	passMove := map[string]any{"type": MoveTypePass}
+	if err := insertGameMove(tx, gameID, userID, passMove); err != nil {
+		return errors.New("failed to record pass")
 	}
 
-	// Nombre de joueurs
-	var playerCount int
-	if err := tx.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM game_players WHERE game_id = $1`, gameID,
-	).Scan(&playerCount); err != nil {
+	if err := saveGameState(tx, gameID, next); err != nil {
 		return err
 	}
+	if err := saveIdempotent(tx, userID, key, gameID, idempotentPass, nil); err != nil {
+		return err
+	}
+
+	passed := moveEvent{PlayerID: userID, NextTurn: res.NextTurn}
 
-	// Fin de partie ? (2 passes * nb joueurs)
-	if passCount >= playerCount*2 {
-		if err := finishGame(tx, gameID, 0); err != nil {
+	// Fin de partie ? (limite de tours sans score atteinte)
+	if res.GameOver {
+		ended, err := finishGame(tx, gameID, next, 0)
+		if err != nil {
 			return err
 		}
 		// IMPORTANT: commit après finishGame
-		return tx.Commit()
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		publishGameEvent(gameID, events.Pass, passed)
+		publishGameEnded(gameID, ended)
+		unlockAcceptedPlaysAchievements(accepted)
+		return nil
 	}
 
 	if err := tx.Commit(); err != nil {
 		return err
 	}
+	publishGameEvent(gameID, events.Pass, passed)
+
+	unlockAcceptedPlaysAchievements(accepted)
 
 	// Déclencher le bot en goroutine si c'est son tour
-	TriggerBotIfNeeded(gameID, nextPlayer)
+	TriggerBotIfNeeded(gameID, res.NextTurn)
+
+	return nil
+}
+
+// ResignGame fait abandonner userID, que ce soit son tour ou non. En face à
+// face, l'adversaire remporte la partie par forfait. À plusieurs, le joueur
+// quitte l'ordre de passage, ses tuiles retournent dans le sac et la partie
+// continue. L'abandon ferme la fenêtre de contestation des coups en attente.
+func ResignGame(userID int64, gameID string) error {
+	ctx := context.Background()
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			logger.Error(context.Background(), "rollback failed", "error", rbErr, "game_id", gameID)
+		}
+	}()
+
+	// Verrouille la ligne game avant de charger l'état
+	if err := lockGame(tx, gameID); err != nil {
+		return err
+	}
+
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return err
+	}
+
+	accepted, err := acceptPendingMoves(tx, gameID)
+	if err != nil {
+		return err
+	}
+
+	res, ended, err := resignLocked(tx, gameID, state, userID, "")
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	publishGameEnded(gameID, ended)
+	unlockAcceptedPlaysAchievements(accepted)
+
+	// Face à face : la partie s'est terminée par forfait
+	if res.Finish != nil {
+		return nil
+	}
+	publishGameEvent(gameID, events.PlayerResigned, moveEvent{PlayerID: userID, NextTurn: res.NextTurn})
+
+	// La main passe au joueur suivant si c'était le tour du joueur qui abandonne
+	if state.Turn == userID {
+		var gameName string
+		if err := database.QueryRow(`SELECT name FROM games WHERE id = $1`, gameID).Scan(&gameName); err != nil {
+			gameName = "une partie"
+		}
+		_ = utils.SendNotificationToUserByID(res.NextTurn, utils.NotificationPayload{
+			Title: "C'est à toi de jouer !",
+			Body:  fmt.Sprintf("Un joueur a abandonné %s", gameName),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+		})
+		TriggerBotIfNeeded(gameID, res.NextTurn)
+	}
 
 	return nil
 }
+
+// resignLocked fait abandonner userID dans la partie verrouillée par tx et
+// enregistre l'abandon, avec sa cause éventuelle (reason, vide pour un abandon
+// volontaire). En face à face, la partie se termine par forfait et l'événement
+// de fin est retourné pour être publié après validation ; à plusieurs, l'état
+// sans le joueur est sauvegardé.
+func resignLocked(tx *sql.Tx, gameID string, state *engine.GameState, userID int64, reason string) (*engine.ResignResult, *gameEnded, error) {
+	next, res, err := state.Resign(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := insertGameMove(tx, gameID, userID, resignMoveRecord{Type: MoveTypeResign, Reason: reason, Returned: res.Returned}); err != nil {
+		return nil, nil, errors.New("failed to record resignation")
+	}
+	// À plusieurs, le rack est vidé (tuiles remises dans le sac) ; en face à
+	// face, il est conservé pour le rejeu de la partie
+	if _, err := tx.Exec(
+		`UPDATE game_players SET resigned = TRUE, rack = $3 WHERE game_id = $1 AND player_id = $2`,
+		gameID, userID, next.Racks[userID],
+	); err != nil {
+		return nil, nil, fmt.Errorf("failed to mark player as resigned: %w", err)
+	}
+
+	if res.Finish != nil {
+		ended, err := endGame(tx, gameID, next, res.Finish)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, ended, nil
+	}
+	if err := saveGameState(tx, gameID, next); err != nil {
+		return nil, nil, err
+	}
+	return res, nil, nil
+}
+
+// forfeitLocked termine par le forfait de playerID la partie verrouillée par
+// tx et enregistre le forfait avec sa cause (reason). L'événement de fin est
+// retourné pour être publié après validation.
+func forfeitLocked(tx *sql.Tx, gameID string, state *engine.GameState, playerID int64, reason string) (*gameEnded, error) {
+	final, res, err := state.Forfeit(playerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := insertGameMove(tx, gameID, playerID, timeoutMoveRecord{Type: MoveTypeForfeit, Reason: reason}); err != nil {
+		return nil, fmt.Errorf("failed to record forfeit: %w", err)
+	}
+	return endGame(tx, gameID, final, res)
+}
+
+// ChallengeMove conteste le dernier coup de la partie, accepté provisoirement.
+// Seul le joueur dont c'est le tour peut contester. Une contestation réussie
+// retire le coup (plateau, rack, sac et score restaurés) ; une contestation
+// ratée applique la pénalité prévue par la règle de la partie.
+func ChallengeMove(userID int64, gameID string) (*engine.ChallengeResult, error) {
+	ctx := context.Background()
+
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			logger.Error(context.Background(), "rollback failed", "error", rbErr, "game_id", gameID)
+		}
+	}()
+
+	// Verrouille la ligne game avant de charger l'état
+	if err := lockGame(tx, gameID); err != nil {
+		return nil, err
+	}
+
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	moveID, authorID, mv, err := lastPendingMove(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, res, err := state.Challenge(userID, engine.PendingMove{
+		PlayerID:      authorID,
+		Letters:       mv.Letters,
+		Drawn:         mv.Drawn,
+		Score:         mv.Score,
+		PrevPassCount: mv.PrevPassCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	status := MoveStatusAccepted
+	if res.Success {
+		status = MoveStatusWithdrawn
+	}
+	if err := setMoveStatus(tx, moveID, status); err != nil {
+		return nil, fmt.Errorf("failed to update challenged move: %w", err)
+	}
+
+	challenge := challengeMoveRecord{
+		Type:         MoveTypeChallenge,
+		TargetMoveID: moveID,
+		Success:      res.Success,
+		InvalidWords: res.InvalidWords,
+		Penalty:      res.Penalty,
+		LostTurn:     res.LostTurn,
+	}
+	if err := insertGameMove(tx, gameID, userID, challenge); err != nil {
+		return nil, errors.New("failed to record challenge")
+	}
+
+	if err := saveGameState(tx, gameID, next); err != nil {
+		return nil, err
+	}
+
+	var ended *gameEnded
+	if res.GameOver {
+		if ended, err = finishGame(tx, gameID, next, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	publishGameEvent(gameID, events.MoveChallenged, moveChallenged{
+		PlayerID:     userID,
+		AuthorID:     authorID,
+		Success:      res.Success,
+		InvalidWords: res.InvalidWords,
+		Penalty:      res.Penalty,
+		LostTurn:     res.LostTurn,
+		NextTurn:     res.NextTurn,
+	})
+	publishGameEnded(gameID, ended)
+
+	if !res.Success {
+		CheckAndUnlockPlayMoveAchievements(authorID, mv.Letters, mv.Score, mv.Word)
+	}
+
+	if !res.GameOver {
+		// Déclencher le bot en goroutine si c'est son tour
+		TriggerBotIfNeeded(gameID, res.NextTurn)
+	}
+
+	return res, nil
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
 	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/word"
 )
 
 // resetAllGamesDeps supprime les données dans un ordre compatible avec les FKs
@@ -42,16 +45,71 @@ func mustCreateUser(t *testing.T, username string) int64 {
 	return u.ID
 }
 
+// createStartedGame creates a game and has every invitee accept it, so that
+// the game is ongoing with racks drawn.
+func createStartedGame(t *testing.T, userID int64, name string, usernames []string, revangeFrom *string, difficultyOpt ...string) (*uuid.UUID, error) {
+	t.Helper()
+	gid, err := CreateGame(userID, name, usernames, revangeFrom, difficultyOpt...)
+	if err != nil {
+		return nil, err
+	}
+	acceptAllInvitations(t, gid.String())
+	return gid, nil
+}
+
+// createStartedGameWithOptions is createStartedGame for CreateGameWithOptions.
+func createStartedGameWithOptions(t *testing.T, userID int64, name string, usernames []string, revangeFrom *string, opts GameOptions) (*uuid.UUID, error) {
+	t.Helper()
+	gid, err := CreateGameWithOptions(userID, name, usernames, revangeFrom, opts)
+	if err != nil {
+		return nil, err
+	}
+	acceptAllInvitations(t, gid.String())
+	return gid, nil
+}
+
+func acceptAllInvitations(t *testing.T, gameID string) {
+	t.Helper()
+	rows, err := database.Query(`SELECT user_id FROM game_invitations WHERE game_id = $1 AND status = 'pending'`, gameID)
+	require.NoError(t, err)
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	rows.Close()
+	for _, id := range ids {
+		require.NoError(t, AcceptInvitation(id, gameID))
+	}
+}
+
 func setPlayerRack(t *testing.T, gameID string, userID int64, rack string) {
 	t.Helper()
-	_, err := database.Exec(`UPDATE game_players SET rack = $1 WHERE game_id = $2 AND player_id = $3`, rack, gameID, userID)
+	_, err := database.Exec(`UPDATE game_players SET rack = $1 WHERE game_id = $2 AND player_id = $3`, word.ParseTiles(rack), gameID, userID)
 	require.NoError(t, err)
 }
 
 func setGameTurnAndBag(t *testing.T, gameID string, turnUserID int64, bag string) {
 	t.Helper()
-	_, err := database.Exec(`UPDATE games SET current_turn = $1, available_letters = $2 WHERE id = $3`, turnUserID, bag, gameID)
+	_, err := database.Exec(`UPDATE games SET current_turn = $1, available_letters = $2 WHERE id = $3`, turnUserID, word.ParseTiles(bag), gameID)
+	require.NoError(t, err)
+}
+
+func getPlayerRack(t *testing.T, gameID string, userID int64) string {
+	t.Helper()
+	var rack word.Tiles
+	err := database.QueryRow(`SELECT rack FROM game_players WHERE game_id = $1 AND player_id = $2`, gameID, userID).Scan(&rack)
 	require.NoError(t, err)
+	return rack.String()
+}
+
+func getGameBag(t *testing.T, gameID string) string {
+	t.Helper()
+	var bag word.Tiles
+	err := database.QueryRow(`SELECT available_letters FROM games WHERE id = $1`, gameID).Scan(&bag)
+	require.NoError(t, err)
+	return bag.String()
 }
 
 func getGameFieldString(t *testing.T, gameID, field string) string {
@@ -72,7 +130,7 @@ func TestCreateGame_Success(t *testing.T) {
 	_ = mustCreateUser(t, "p2")
 	_ = mustCreateUser(t, "p3")
 
-	gid, err := CreateGame(creator, "ma partie", []string{"p2", "p3"}, nil)
+	gid, err := createStartedGame(t, creator, "ma partie", []string{"p2", "p3"}, nil)
 	require.NoError(t, err)
 	require.NotNil(t, gid)
 
@@ -96,14 +154,14 @@ func TestCreateGame_RematchChecks(t *testing.T) {
 	_ = mustCreateUser(t, "bob")
 
 	// original game with non-default difficulty (easy)
-	gid, err := CreateGame(u1, "g1", []string{"bob"}, nil, "easy")
+	gid, err := createStartedGame(t, u1, "g1", []string{"bob"}, nil, "easy")
 	require.NoError(t, err)
 	require.NotNil(t, gid)
 
 	orig := gid.String()
 
 	// same creator can rematch, should inherit the difficulty
-	gid2, err := CreateGame(u1, "rev", []string{"bob"}, &orig)
+	gid2, err := createStartedGame(t, u1, "rev", []string{"bob"}, &orig)
 	require.NoError(t, err)
 	require.NotNil(t, gid2)
 
@@ -117,13 +175,13 @@ func TestCreateGame_RematchChecks(t *testing.T) {
 	userBob, err := GetUserByUsername("bob")
 	require.NoError(t, err)
 	u2 := userBob.ID
-	gid3, err := CreateGame(u2, "rev2", []string{"alice"}, &orig)
+	gid3, err := createStartedGame(t, u2, "rev2", []string{"alice"}, &orig)
 	require.Error(t, err)
 	assert.Nil(t, gid3)
 
 	// non-existent source
 	fake := uuid.New().String()
-	gid4, err := CreateGame(u1, "rev3", []string{"bob"}, &fake)
+	gid4, err := createStartedGame(t, u1, "rev3", []string{"bob"}, &fake)
 	require.Error(t, err)
 	assert.Nil(t, gid4)
 }
@@ -148,7 +206,7 @@ func TestDeleteGame_OnlyCreator(t *testing.T) {
 	u1 := mustCreateUser(t, "owner")
 	_ = mustCreateUser(t, "guest")
 
-	gid, err := CreateGame(u1, "to-delete", []string{"guest"}, nil)
+	gid, err := createStartedGame(t, u1, "to-delete", []string{"guest"}, nil)
 	require.NoError(t, err)
 
 	// non creator cannot delete
@@ -171,7 +229,7 @@ func TestRenameGame_OnlyCreator(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "boss")
 	_ = mustCreateUser(t, "other")
-	gid, err := CreateGame(u1, "old", []string{"other"}, nil)
+	gid, err := createStartedGame(t, u1, "old", []string{"other"}, nil)
 	require.NoError(t, err)
 
 	// ok as creator
@@ -191,7 +249,7 @@ func TestGetGameDetails_AfterMove(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "p1")
 	_ = mustCreateUser(t, "p2")
-	gid, err := CreateGame(u1, "details", []string{"p2"}, nil)
+	gid, err := createStartedGame(t, u1, "details", []string{"p2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -231,7 +289,7 @@ func TestGetGameDetails_Unauthorized_NotInGame(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "d1")
 	_ = mustCreateUser(t, "d2")
-	gid, err := CreateGame(u1, "nope", []string{"d2"}, nil)
+	gid, err := createStartedGame(t, u1, "nope", []string{"d2"}, nil)
 	require.NoError(t, err)
 	stranger := mustCreateUser(t, "str")
 
@@ -244,7 +302,7 @@ func TestGetNewRack_ChangesRackAndAdvancesTurn(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "rackman")
 	_ = mustCreateUser(t, "mate")
-	gid, err := CreateGame(u1, "getrack", []string{"mate"}, nil)
+	gid, err := createStartedGame(t, u1, "getrack", []string{"mate"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -267,7 +325,7 @@ func TestGetNewRack_Errors(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "rack_err1")
 	u2 := mustCreateUser(t, "rack_err2")
-	gid, err := CreateGame(u1, "rackerrs", []string{"rack_err2"}, nil)
+	gid, err := createStartedGame(t, u1, "rackerrs", []string{"rack_err2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -285,7 +343,7 @@ func TestGetGamesByUserID_Basic(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "gamer1")
 	_ = mustCreateUser(t, "gamer2")
-	gid, err := CreateGame(u1, "liste", []string{"gamer2"}, nil)
+	gid, err := createStartedGame(t, u1, "liste", []string{"gamer2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -316,11 +374,11 @@ func TestSimulateScore_Positive(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "simu1")
 	_ = mustCreateUser(t, "simu2")
-	gid, err := CreateGame(u1, "simu", []string{"simu2"}, nil)
+	gid, err := createStartedGame(t, u1, "simu", []string{"simu2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
-	score, err := SimulateScore(g, u1, []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}, {X: 8, Y: 7, Char: "B"}})
+	score, err := SimulateScore(g, u1, []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}, {X: 8, Y: 7, Char: "B"}}, "")
 	require.NoError(t, err)
 	assert.Greater(t, score, 0)
 }
@@ -329,30 +387,58 @@ func TestSimulateScore_Errors(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "sim_err1")
 	_ = mustCreateUser(t, "sim_err2")
-	gid, err := CreateGame(u1, "simerrs", []string{"sim_err2"}, nil)
+	gid, err := createStartedGame(t, u1, "simerrs", []string{"sim_err2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
 	// empty letters → 0, nil
-	sc, err := SimulateScore(g, u1, []request.PlacedLetter{})
+	sc, err := SimulateScore(g, u1, []request.PlacedLetter{}, "")
 	require.NoError(t, err)
 	assert.Equal(t, 0, sc)
 
 	// non-participant
 	stranger := mustCreateUser(t, "sim_str")
-	_, err = SimulateScore(g, stranger, []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}})
+	_, err = SimulateScore(g, stranger, []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}}, "")
 	require.Error(t, err)
 
 	// overlapping same cell in one request → applyLetters error
-	_, err = SimulateScore(g, u1, []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}, {X: 7, Y: 7, Char: "B"}})
+	_, err = SimulateScore(g, u1, []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}, {X: 7, Y: 7, Char: "B"}}, "")
 	require.Error(t, err)
 }
 
+func TestPlayMove_Notation(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "nota1")
+	u2 := mustCreateUser(t, "nota2")
+	gid, err := createStartedGame(t, u1, "notation", []string{"nota2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHAT?XY")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+
+	// la notation ne correspond pas au plateau vide
+	_, err = SimulateScore(g, u1, nil, "8F C.AT")
+	require.ErrorIs(t, err, engine.ErrInvalidNotation)
+
+	score, err := SimulateScore(g, u1, nil, "8F CHAT")
+	require.NoError(t, err)
+	assert.Equal(t, 18, score)
+
+	err = PlayMove(g, u1, request.PlayMoveRequest{Notation: "8F cHAT"})
+	require.NoError(t, err)
+	state, err := loadGameState(database.DB, g)
+	require.NoError(t, err)
+	assert.Equal(t, "C", state.Board[7][5])
+	assert.True(t, state.Blanks[engine.Pos{X: 5, Y: 7}], "minuscule = joker")
+	assert.Equal(t, 12, state.Scores[u1])
+}
+
 func TestPassTurn_EndsGameAfterDoubleRound(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pass1")
 	u2 := mustCreateUser(t, "pass2")
-	gid, err := CreateGame(u1, "passes", []string{"pass2"}, nil)
+	gid, err := createStartedGame(t, u1, "passes", []string{"pass2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -377,7 +463,7 @@ func TestPassTurn_Errors(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pt_err1")
 	u2 := mustCreateUser(t, "pt_err2")
-	gid, err := CreateGame(u1, "pterr", []string{"pt_err2"}, nil)
+	gid, err := createStartedGame(t, u1, "pterr", []string{"pt_err2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -396,7 +482,7 @@ func TestPlayMove_NotInGame(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pm_own")
 	u2 := mustCreateUser(t, "pm_str")
-	gid, err := CreateGame(u1, "pm", []string{}, nil)
+	gid, err := createStartedGame(t, u1, "pm", []string{}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -408,7 +494,7 @@ func TestPlayMove_NotYourTurn(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pm_t1")
 	u2 := mustCreateUser(t, "pm_t2")
-	gid, err := CreateGame(u1, "pm2", []string{"pm_t2"}, nil)
+	gid, err := createStartedGame(t, u1, "pm2", []string{"pm_t2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -421,7 +507,7 @@ func TestPlayMove_NotYourTurn(t *testing.T) {
 func TestPlayMove_NoLetters_TooMany_NotAligned(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pm_errs")
-	gid, err := CreateGame(u1, "pm3", []string{}, nil)
+	gid, err := createStartedGame(t, u1, "pm3", []string{}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 	setPlayerRack(t, g, u1, "ABCDEFG")
@@ -443,7 +529,7 @@ func TestPlayMove_NoLetters_TooMany_NotAligned(t *testing.T) {
 func TestPlayMove_FirstMoveNotCenter(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pm_center")
-	gid, err := CreateGame(u1, "pmc", []string{}, nil)
+	gid, err := createStartedGame(t, u1, "pmc", []string{}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 	setPlayerRack(t, g, u1, "AAAAAAA")
@@ -457,7 +543,7 @@ func TestPlayMove_NotConnectedAndOccupiedCellAndInvalidWord(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pm_chain")
 	u2 := mustCreateUser(t, "pm_chain2")
-	gid, err := CreateGame(u1, "pmchain", []string{"pm_chain2"}, nil)
+	gid, err := createStartedGame(t, u1, "pmchain", []string{"pm_chain2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -487,7 +573,7 @@ func TestPlayMove_FinishesGame_WhenRackAndBagEmpty(t *testing.T) {
 	resetAllGamesDeps(t)
 	u1 := mustCreateUser(t, "pm_end1")
 	_ = mustCreateUser(t, "pm_end2")
-	gid, err := CreateGame(u1, "pmend", []string{"pm_end2"}, nil)
+	gid, err := createStartedGame(t, u1, "pmend", []string{"pm_end2"}, nil)
 	require.NoError(t, err)
 	g := gid.String()
 
@@ -506,3 +592,320 @@ func TestPlayMove_FinishesGame_WhenRackAndBagEmpty(t *testing.T) {
 	assert.True(t, endedAt.Valid)
 	assert.True(t, winner.Valid)
 }
+
+func TestExchangeTiles_PartialExchange(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "swapper")
+	_ = mustCreateUser(t, "swapmate")
+	gid, err := createStartedGame(t, u1, "exchange", []string{"swapmate"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "AE?KWST")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEE")
+
+	drawn, err := ExchangeTiles(u1, g, []string{"K", "w"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"E", "E"}, drawn)
+
+	assert.Equal(t, "AE?STEE", getPlayerRack(t, g, u1))
+	assert.Len(t, getGameBag(t, g), 8)
+}
+
+func TestExchangeTiles_Errors(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "swap_err1")
+	_ = mustCreateUser(t, "swap_err2")
+	gid, err := createStartedGame(t, u1, "exchange errs", []string{"swap_err2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "ABCDEFG")
+
+	// tuile absente du rack
+	setGameTurnAndBag(t, g, u1, "EEEEEEEE")
+	_, err = ExchangeTiles(u1, g, []string{"?"})
+	require.Error(t, err)
+
+	// moins de 7 lettres dans le sac
+	setGameTurnAndBag(t, g, u1, "EEEEEE")
+	_, err = ExchangeTiles(u1, g, []string{"A"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not enough letters")
+}
+
+func TestExchangeTiles_RecordedInMoves(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "histo_swap")
+	u2 := mustCreateUser(t, "histo_mate")
+	gid, err := createStartedGame(t, u1, "exchange history", []string{"histo_mate"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEE")
+	_, err = ExchangeTiles(u1, g, []string{"A", "B"})
+	require.NoError(t, err)
+
+	// l'auteur voit les tuiles rendues et piochées
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	require.Len(t, info.Moves, 1)
+	assert.Equal(t, MoveTypeExchange, info.Moves[0].Type)
+	mv := info.Moves[0].Move.(map[string]any)
+	assert.EqualValues(t, 2, mv["count"])
+	assert.Equal(t, "AB", mv["returned"])
+	assert.Equal(t, "EE", mv["drawn"])
+
+	// l'adversaire ne voit que le nombre de tuiles échangées
+	info, err = GetGameDetails(u2, g)
+	require.NoError(t, err)
+	require.Len(t, info.Moves, 1)
+	mv = info.Moves[0].Move.(map[string]any)
+	assert.EqualValues(t, 2, mv["count"])
+	assert.NotContains(t, mv, "returned")
+	assert.NotContains(t, mv, "drawn")
+}
+
+func TestChallengeMove_WithdrawsPhony(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "phony_player")
+	u2 := mustCreateUser(t, "phony_judge")
+	gid, err := createStartedGameWithOptions(t, u1, "challenge", []string{"phony_judge"}, nil, GameOptions{ChallengeRule: "double"})
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "XYZABCD")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEE")
+
+	// le mot invalide est accepté provisoirement
+	err = PlayMove(g, u1, request.PlayMoveRequest{
+		Letters: []request.PlacedLetter{{X: 7, Y: 7, Char: "X"}, {X: 8, Y: 7, Char: "Y"}, {X: 9, Y: 7, Char: "Z"}},
+	})
+	require.NoError(t, err)
+
+	// l'auteur ne peut pas contester son propre coup
+	_, err = ChallengeMove(u1, g)
+	require.Error(t, err)
+
+	res, err := ChallengeMove(u2, g)
+	require.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, []string{"XYZ"}, res.InvalidWords)
+
+	var score int
+	err = database.QueryRow(`SELECT score FROM game_players WHERE game_id = $1 AND player_id = $2`, g, u1).Scan(&score)
+	require.NoError(t, err)
+	assert.Equal(t, "ABCDXYZ", getPlayerRack(t, g, u1))
+	assert.Equal(t, 0, score)
+	assert.Equal(t, "EEEEEEEE", getGameBag(t, g))
+
+	// le coup ne peut plus être contesté
+	_, err = ChallengeMove(u2, g)
+	require.Error(t, err)
+
+	info, err := GetGameDetails(u2, g)
+	require.NoError(t, err)
+	require.Len(t, info.Moves, 2)
+	assert.Equal(t, MoveStatusWithdrawn, info.Moves[0].Move.(map[string]any)["status"])
+	assert.NotContains(t, info.Moves[0].Move.(map[string]any), "drawn")
+	assert.Equal(t, MoveTypeChallenge, info.Moves[1].Type)
+}
+
+func TestCreateGameWithOptions_Ruleset(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "rules_owner")
+	_ = mustCreateUser(t, "rules_p2")
+	_ = mustCreateUser(t, "rules_p3")
+
+	rackSize, maxPlayers, leftover := 5, 2, "none"
+	rules := &request.GameRules{RackSize: &rackSize, MaxPlayers: &maxPlayers, Leftover: &leftover}
+
+	_, err := createStartedGameWithOptions(t, u1, "too many", []string{"rules_p2", "rules_p3"}, nil, GameOptions{Rules: rules})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many players")
+
+	gid, err := createStartedGameWithOptions(t, u1, "small racks", []string{"rules_p2"}, nil, GameOptions{Rules: rules})
+	require.NoError(t, err)
+
+	info, err := GetGameDetails(u1, gid.String())
+	require.NoError(t, err)
+	assert.Len(t, info.YourRack, 5)
+	require.NotNil(t, info.Rules)
+	assert.Equal(t, 5, info.Rules.RackSize)
+	assert.Equal(t, 50, info.Rules.BingoBonus)
+	assert.Equal(t, "none", info.Rules.Leftover)
+
+	// la revanche reprend les règles de la partie d'origine
+	src := gid.String()
+	rematch, err := createStartedGame(t, u1, "rematch", []string{"rules_p2"}, &src)
+	require.NoError(t, err)
+	info, err = GetGameDetails(u1, rematch.String())
+	require.NoError(t, err)
+	assert.Equal(t, 5, info.Rules.RackSize)
+
+	bad := -1
+	_, err = createStartedGameWithOptions(t, u1, "invalid", nil, nil, GameOptions{Rules: &request.GameRules{BingoBonus: &bad}})
+	require.Error(t, err)
+}
+
+func TestCreateGameWithOptions_Language(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "lang_owner")
+	_ = mustCreateUser(t, "lang_p2")
+
+	_, err := createStartedGameWithOptions(t, u1, "klingon", []string{"lang_p2"}, nil, GameOptions{Language: "tlh"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported language")
+
+	gid, err := createStartedGameWithOptions(t, u1, "default", []string{"lang_p2"}, nil, GameOptions{})
+	require.NoError(t, err)
+	info, err := GetGameDetails(u1, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, "fr", info.Language)
+	assert.Equal(t, len(word.French.Bag)-2*engine.RackSize, info.RemainingLetters)
+
+	if !word.English.Available() {
+		t.Skip("en.txt dictionary not embedded")
+	}
+	gid, err = createStartedGameWithOptions(t, u1, "english", []string{"lang_p2"}, nil, GameOptions{Language: "en"})
+	require.NoError(t, err)
+	info, err = GetGameDetails(u1, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, "en", info.Language)
+	assert.Equal(t, len(word.English.Bag)-2*engine.RackSize, info.RemainingLetters)
+
+	// la revanche reprend la langue de la partie d'origine
+	src := gid.String()
+	rematch, err := createStartedGame(t, u1, "rematch", []string{"lang_p2"}, &src)
+	require.NoError(t, err)
+	info, err = GetGameDetails(u1, rematch.String())
+	require.NoError(t, err)
+	assert.Equal(t, "en", info.Language)
+}
+
+func TestCreateGameWithOptions_SuperVariant(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "super_owner")
+	_ = mustCreateUser(t, "super_p2")
+
+	_, err := createStartedGameWithOptions(t, u1, "hexa", []string{"super_p2"}, nil, GameOptions{Variant: "hexagonal"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid variant")
+
+	gid, err := createStartedGameWithOptions(t, u1, "super", []string{"super_p2"}, nil, GameOptions{Variant: "super"})
+	require.NoError(t, err)
+	info, err := GetGameDetails(u1, gid.String())
+	require.NoError(t, err)
+	assert.Equal(t, engine.LayoutSuper, info.Variant)
+	assert.Equal(t, 21, info.BoardSize)
+	assert.Equal(t, 200-2*engine.RackSize, info.RemainingLetters)
+	assert.Contains(t, info.Premiums, response.Premium{X: 0, Y: 0, Type: engine.PremiumQW})
+
+	// premier coup sur la case centrale du plateau 21x21
+	setPlayerRack(t, gid.String(), u1, "CHATEEE")
+	err = PlayMove(gid.String(), u1, request.PlayMoveRequest{
+		Letters: []request.PlacedLetter{
+			{X: 8, Y: 10, Char: "C"},
+			{X: 9, Y: 10, Char: "H"},
+			{X: 10, Y: 10, Char: "A"},
+			{X: 11, Y: 10, Char: "T"},
+		},
+	})
+	require.NoError(t, err)
+	board, err := LoadBoard(gid.String())
+	require.NoError(t, err)
+	require.Equal(t, 21, board.Size())
+	assert.Equal(t, "A", board[10][10])
+
+	// la revanche reprend la variante de la partie d'origine
+	src := gid.String()
+	rematch, err := createStartedGame(t, u1, "rematch", []string{"super_p2"}, &src)
+	require.NoError(t, err)
+	info, err = GetGameDetails(u1, rematch.String())
+	require.NoError(t, err)
+	assert.Equal(t, engine.LayoutSuper, info.Variant)
+}
+
+func TestResignGame_HeadToHead(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "resign_quitter")
+	u2 := mustCreateUser(t, "resign_winner")
+	gid, err := createStartedGame(t, u1, "resign", []string{"resign_winner"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	// l'abandon est possible hors de son tour, même en menant au score
+	_, err = database.Exec(`UPDATE game_players SET score = 80 WHERE game_id = $1 AND player_id = $2`, g, u1)
+	require.NoError(t, err)
+	require.NoError(t, PassTurn(u1, g))
+	require.NoError(t, ResignGame(u1, g))
+
+	info, err := GetGameDetails(u2, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ended", info.Status)
+	assert.Equal(t, "resign_winner", info.WinnerUsername)
+	require.NotNil(t, info.ForfeitedBy)
+	assert.Equal(t, u1, *info.ForfeitedBy)
+	require.Len(t, info.Moves, 2)
+	assert.Equal(t, MoveTypeResign, info.Moves[1].Type)
+
+	var endedAt sql.NullTime
+	require.NoError(t, database.QueryRow(`SELECT ended_at FROM games WHERE id = $1`, g).Scan(&endedAt))
+	assert.True(t, endedAt.Valid)
+
+	// partie terminée : plus d'abandon possible
+	err = ResignGame(u2, g)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "game is not ongoing")
+}
+
+func TestResignGame_Multiplayer(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "resign_multi1")
+	u2 := mustCreateUser(t, "resign_multi2")
+	u3 := mustCreateUser(t, "resign_multi3")
+	gid, err := createStartedGame(t, u1, "resign multi", []string{"resign_multi2", "resign_multi3"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setGameTurnAndBag(t, g, u2, "EEEE")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+
+	// u2 abandonne pendant son tour : ses tuiles retournent dans le sac
+	require.NoError(t, ResignGame(u2, g))
+	assert.Len(t, getGameBag(t, g), 11)
+	assert.Equal(t, "", getPlayerRack(t, g, u2))
+
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Equal(t, u3, info.CurrentTurn)
+	for _, p := range info.Players {
+		assert.Equal(t, p.ID == u2, p.Resigned, "player %d", p.ID)
+	}
+	require.Len(t, info.Moves, 1)
+	mv := info.Moves[0].Move.(map[string]any)
+	assert.Equal(t, MoveTypeResign, mv["type"])
+	assert.NotContains(t, mv, "returned")
+
+	// le joueur ayant abandonné ne joue plus
+	err = PassTurn(u2, g)
+	require.Error(t, err)
+	err = ResignGame(u2, g)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "player not in game")
+
+	// la rotation saute le joueur parti
+	require.NoError(t, PassTurn(u3, g))
+	info, err = GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, u1, info.CurrentTurn)
+
+	// le dernier abandon termine la partie
+	require.NoError(t, ResignGame(u1, g))
+	info, err = GetGameDetails(u3, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ended", info.Status)
+	assert.Equal(t, "resign_multi3", info.WinnerUsername)
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// gameQuerier est satisfait à la fois par *sql.DB et *sql.Tx, ce qui permet de
+// charger l'état d'une partie dans ou hors d'une transaction.
+type gameQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// loadGameState charge l'état complet d'une partie (plateau, jokers, racks,
+// scores, sac, ordre de passage) pour le moteur de règles. Les joueurs ayant
+// abandonné n'en font plus partie.
+func loadGameState(q gameQuerier, gameID string) (*engine.GameState, error) {
+	var (
+		boardRaw    []byte
+		rulesRaw    []byte
+		language    string
+		variant     string
+		currentTurn sql.NullInt64
+		status      string
+	)
+	state := &engine.GameState{
+		Racks:  map[int64]word.Tiles{},
+		Scores: map[int64]int{},
+	}
+	err := q.QueryRow(`
+		SELECT board, available_letters, current_turn, pass_count, status, challenge_rule, ruleset, language, variant,
+			bag_seed, draw_count, mode
+		FROM games WHERE id = $1
+	`, gameID).Scan(&boardRaw, &state.Bag, &currentTurn, &state.PassCount, &status, &state.ChallengeRule, &rulesRaw, &language, &variant,
+		&state.Seed, &state.DrawCount, &state.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(boardRaw, &state.Board); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal game board: %w", err)
+	}
+	if state.Rules, err = parseRuleset(rulesRaw); err != nil {
+		return nil, err
+	}
+	state.Language = word.Lang(language)
+	state.Layout = layoutOrStandard(variant)
+	state.Turn = currentTurn.Int64
+	state.Ended = status != "ongoing"
+
+	rows, err := q.Query(`
+		SELECT player_id, rack, score
+		FROM game_players
+		WHERE game_id = $1 AND NOT resigned
+		ORDER BY position
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			pid   int64
+			rack  word.Tiles
+			score int
+		)
+		if err := rows.Scan(&pid, &rack, &score); err != nil {
+			return nil, err
+		}
+		state.Players = append(state.Players, pid)
+		state.Racks[pid] = rack
+		state.Scores[pid] = score
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	state.Blanks, err = loadBoardBlanks(q, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// parseRuleset décode les règles stockées avec une partie.
+func parseRuleset(raw []byte) (engine.Ruleset, error) {
+	rules := engine.DefaultRuleset()
+	if len(raw) == 0 {
+		return rules, nil
+	}
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return rules, fmt.Errorf("failed to unmarshal game ruleset: %w", err)
+	}
+	return rules, nil
+}
+
+// buildRuleset applique les surcharges demandées aux règles par défaut et
+// vérifie qu'elles sont jouables avec le sac de lang.
+func buildRuleset(overrides *request.GameRules, lang *word.Language) (engine.Ruleset, error) {
+	rules := engine.DefaultRuleset()
+	if overrides != nil {
+		if overrides.BingoBonus != nil {
+			rules.BingoBonus = *overrides.BingoBonus
+		}
+		if overrides.ScorelessTurnLimit != nil {
+			rules.ScorelessTurnLimit = *overrides.ScorelessTurnLimit
+		}
+		if overrides.RackSize != nil {
+			rules.RackSize = *overrides.RackSize
+		}
+		if overrides.MaxPlayers != nil {
+			rules.MaxPlayers = *overrides.MaxPlayers
+		}
+		if overrides.SmallBagExchange != nil {
+			rules.SmallBagExchange = *overrides.SmallBagExchange
+		}
+		if overrides.Leftover != nil {
+			rules.Leftover = *overrides.Leftover
+		}
+	}
+	return rules, rules.Validate(lang)
+}
+
+// gameRulesResponse convertit les règles d'une partie pour l'API.
+func gameRulesResponse(rules engine.Ruleset) *response.GameRules {
+	return &response.GameRules{
+		BingoBonus:         rules.BingoBonus,
+		ScorelessTurnLimit: rules.ScorelessTurnLimit,
+		RackSize:           rules.RackSize,
+		MaxPlayers:         rules.MaxPlayers,
+		SmallBagExchange:   rules.SmallBagExchange,
+		Leftover:           rules.Leftover,
+	}
+}
+
+// layoutOrStandard retourne la disposition de la variante stockée, ou le
+// plateau standard si elle est vide ou inconnue.
+func layoutOrStandard(variant string) *engine.Layout {
+	if l, ok := engine.LayoutByName(variant); ok {
+		return l
+	}
+	return engine.StandardLayout
+}
+
+// layoutResponse retourne la taille du plateau et ses cases spéciales, triées
+// par ligne puis par colonne, pour l'API.
+func layoutResponse(layout *engine.Layout) (int, []response.Premium) {
+	premiums := make([]response.Premium, 0, len(layout.Premiums))
+	for p, code := range layout.Premiums {
+		premiums = append(premiums, response.Premium{X: p.X, Y: p.Y, Type: code})
+	}
+	sort.Slice(premiums, func(i, j int) bool {
+		if premiums[i].Y != premiums[j].Y {
+			return premiums[i].Y < premiums[j].Y
+		}
+		return premiums[i].X < premiums[j].X
+	})
+	return layout.Size, premiums
+}
+
+// loadBoardBlanks reconstruit les positions des jokers posés depuis l'historique
+// des coups et, en duplicate, les tops posés.
+func loadBoardBlanks(q gameQuerier, gameID string) (map[Pos]bool, error) {
+	res := map[Pos]bool{}
+	rows, err := q.Query(`SELECT move FROM game_moves WHERE game_id = $1 ORDER BY created_at ASC, id ASC`, gameID)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var moveRaw []byte
+		if err := rows.Scan(&moveRaw); err != nil {
+			continue
+		}
+		var mv struct {
+			Letters []request.PlacedLetter `json:"letters"`
+			Status  string                 `json:"status"`
+		}
+		if err := json.Unmarshal(moveRaw, &mv); err != nil || mv.Status == MoveStatusWithdrawn || mv.Status == MoveStatusRetracted {
+			continue
+		}
+		for _, pl := range mv.Letters {
+			if pl.Blank {
+				res[Pos{X: pl.X, Y: pl.Y}] = true
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+	rows.Close()
+	return res, loadDuplicateBlanks(q, gameID, res)
+}
+
+// lockGame verrouille la ligne de la partie jusqu'à la fin de tx. Toute action
+// qui modifie l'état d'une partie commence par là : les envois concurrents
+// (double clic, bot, délai de jeu) sont sérialisés et chacun charge l'état
+// laissé par le précédent.
+func lockGame(tx *sql.Tx, gameID string) error {
+	var locked string
+	if err := tx.QueryRow(`SELECT id FROM games WHERE id = $1 FOR UPDATE`, gameID).Scan(&locked); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("game not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// saveGameState persiste l'état retourné par le moteur : plateau, sac, passes,
+// tour courant (et son délai), racks et scores des joueurs, ainsi que les
+// tirages du sac, rattachés au dernier coup enregistré.
+func saveGameState(tx *sql.Tx, gameID string, state *engine.GameState) error {
+	boardJSON, err := json.Marshal(state.Board)
+	if err != nil {
+		return err
+	}
+	// chaque action relance le délai de jeu du joueur dont c'est le tour
+	_, err = tx.Exec(`
+		UPDATE games
+		SET board = $1, available_letters = $2, pass_count = $3, current_turn = $4,
+		    turn_deadline = `+turnDeadlineSQL("turn_time_limit_hours")+`, deadline_reminded = FALSE,
+		    draw_count = $6
+		WHERE id = $5
+	`, boardJSON, state.Bag, state.PassCount, state.Turn, gameID, state.DrawCount)
+	if err != nil {
+		return fmt.Errorf("failed to update game: %w", err)
+	}
+	if err := saveDraws(tx, gameID, state); err != nil {
+		return err
+	}
+
+	for _, pid := range state.Players {
+		_, err := tx.Exec(`
+			UPDATE game_players SET rack = $1, score = $2
+			WHERE game_id = $3 AND player_id = $4
+		`, state.Racks[pid], state.Scores[pid], gameID, pid)
+		if err != nil {
+			return fmt.Errorf("failed to update game player %d: %w", pid, err)
+		}
+	}
+	return nil
+}
+
+// Types de coups enregistrés dans game_moves (champ "type" du JSON).
+const (
+	MoveTypePlay      = "play"
+	MoveTypePass      = "pass"
+	MoveTypeExchange  = "exchange"
+	MoveTypeChallenge = "challenge"
+	MoveTypeForfeit   = "forfeit"
+	MoveTypeResign    = "resign"
+)
+
+// Statuts d'un coup : contestation d'un mot posé ou reprise.
+const (
+	MoveStatusPending   = "pending"   // accepté provisoirement, contestable
+	MoveStatusAccepted  = "accepted"  // fenêtre de contestation close
+	MoveStatusWithdrawn = "withdrawn" // retiré suite à une contestation réussie
+	MoveStatusRetracted = "retracted" // annulé par une reprise de coup
+)
+
+// playMoveRecord est la forme stockée d'un mot posé. Drawn et PrevPassCount
+// permettent d'annuler le coup (contestation ou reprise) ; Status n'est
+// renseigné que dans les parties avec contestation ou après une reprise.
+type playMoveRecord struct {
+	Type string `json:"type"`
+	request.PlayMoveRequest
+	Status        string        `json:"status,omitempty"`
+	Drawn         word.Tiles    `json:"drawn,omitempty"`
+	PrevPassCount int           `json:"prev_pass_count,omitempty"`
+	Takeback      *takebackInfo `json:"takeback,omitempty"`
+}
+
+// challengeMoveRecord est la forme stockée d'une contestation.
+type challengeMoveRecord struct {
+	Type         string   `json:"type"`
+	TargetMoveID int64    `json:"target_move_id"`
+	Success      bool     `json:"success"`
+	InvalidWords []string `json:"invalid_words,omitempty"`
+	Penalty      int      `json:"penalty,omitempty"`
+	LostTurn     bool     `json:"lost_turn,omitempty"`
+}
+
+// acceptedPlay est un coup provisoire dont la fenêtre de contestation vient de se fermer.
+type acceptedPlay struct {
+	PlayerID int64
+	Move     playMoveRecord
+}
+
+// exchangeMoveRecord est la forme stockée d'un échange. Returned et Drawn ne
+// sont visibles que par le joueur qui a échangé (voir redactMove).
+type exchangeMoveRecord struct {
+	Type     string     `json:"type"`
+	Count    int        `json:"count"`
+	Returned word.Tiles `json:"returned,omitempty"`
+	Drawn    word.Tiles `json:"drawn,omitempty"`
+}
+
+// resignMoveRecord est la forme stockée d'un abandon. Reason vaut
+// timeoutReason quand le délai de jeu est dépassé. Returned (tuiles remises
+// dans le sac en partie à plusieurs) n'est visible que par le joueur qui abandonne.
+type resignMoveRecord struct {
+	Type     string     `json:"type"`
+	Reason   string     `json:"reason,omitempty"`
+	Returned word.Tiles `json:"returned,omitempty"`
+}
+
+// storedMove est un coup de l'historique, tous types confondus, avec ce qu'il
+// faut pour l'annuler (reprise) ou le rejouer.
+type storedMove struct {
+	ID       int64
+	PlayerID int64
+	Record   struct {
+		Type          string                 `json:"type"`
+		Word          string                 `json:"word"`
+		Letters       []request.PlacedLetter `json:"letters"`
+		Score         int                    `json:"score"`
+		Status        string                 `json:"status"`
+		Returned      word.Tiles             `json:"returned"`
+		Drawn         word.Tiles             `json:"drawn"`
+		PrevPassCount int                    `json:"prev_pass_count"`
+		Takeback      *takebackInfo          `json:"takeback"`
+		TargetMoveID  int64                  `json:"target_move_id"`
+		Success       bool                   `json:"success"`
+		Penalty       int                    `json:"penalty"`
+		LostTurn      bool                   `json:"lost_turn"`
+	}
+}
+
+// playedMove convertit le coup pour le moteur de règles.
+func (m storedMove) playedMove() engine.PlayedMove {
+	return engine.PlayedMove{
+		PlayerID:      m.PlayerID,
+		Letters:       m.Record.Letters,
+		Returned:      m.Record.Returned,
+		Drawn:         m.Record.Drawn,
+		Score:         m.Record.Score,
+		PrevPassCount: m.Record.PrevPassCount,
+	}
+}
+
+// redactMove retire d'un coup stocké les informations privées (tuiles
+// échangées ou piochées) quand il est consulté par un autre joueur que son auteur.
+func redactMove(move map[string]any, authorID, viewerID int64) {
+	if authorID != viewerID {
+		delete(move, "returned")
+		delete(move, "drawn")
+	}
+}
+
+// moveType retourne le discriminant d'un coup stocké (les anciens coups joués
+// n'avaient pas de type).
+func moveType(move map[string]any) string {
+	if t, ok := move["type"].(string); ok && t != "" {
+		return t
+	}
+	return MoveTypePlay
+}
+
+// insertGameMove enregistre un coup (quel que soit son type) dans l'historique.
+func insertGameMove(tx *sql.Tx, gameID string, playerID int64, move any) error {
+	moveJSON, err := json.Marshal(move)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ($1, $2, $3)`, gameID, playerID, moveJSON)
+	return err
+}
+
+// lastPendingMove retourne le dernier coup de la partie s'il est en attente de
+// contestation.
+func lastPendingMove(q gameQuerier, gameID string) (int64, int64, *playMoveRecord, error) {
+	var (
+		moveID   int64
+		playerID int64
+		moveRaw  []byte
+	)
+	err := q.QueryRow(`
+		SELECT id, player_id, move FROM game_moves
+		WHERE game_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, gameID).Scan(&moveID, &playerID, &moveRaw)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, nil, engine.ErrNothingToChallenge
+		}
+		return 0, 0, nil, err
+	}
+	var mv playMoveRecord
+	if err := json.Unmarshal(moveRaw, &mv); err != nil {
+		return 0, 0, nil, err
+	}
+	if mv.Type != MoveTypePlay || mv.Status != MoveStatusPending {
+		return 0, 0, nil, engine.ErrNothingToChallenge
+	}
+	return moveID, playerID, &mv, nil
+}
+
+// setMoveStatus met à jour le statut d'un mot posé.
+func setMoveStatus(tx *sql.Tx, moveID int64, status string) error {
+	_, err := tx.Exec(`
+		UPDATE game_moves SET move = jsonb_set(move, '{status}', to_jsonb($1::text))
+		WHERE id = $2
+	`, status, moveID)
+	return err
+}
+
+// acceptPendingMoves ferme la fenêtre de contestation des coups provisoires de
+// la partie. Appelée dès que le joueur suivant agit sans contester.
+func acceptPendingMoves(tx *sql.Tx, gameID string) ([]acceptedPlay, error) {
+	rows, err := tx.Query(`
+		UPDATE game_moves SET move = jsonb_set(move, '{status}', to_jsonb($2::text))
+		WHERE game_id = $1 AND move->>'status' = $3
+		RETURNING player_id, move
+	`, gameID, MoveStatusAccepted, MoveStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accepted []acceptedPlay
+	for rows.Next() {
+		var (
+			ap      acceptedPlay
+			moveRaw []byte
+		)
+		if err := rows.Scan(&ap.PlayerID, &moveRaw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(moveRaw, &ap.Move); err != nil {
+			return nil, err
+		}
+		accepted = append(accepted, ap)
+	}
+	return accepted, rows.Err()
+}
+
+// unlockAcceptedPlaysAchievements débloque les succès des coups dont la
+// contestation n'est plus possible.
+func unlockAcceptedPlaysAchievements(accepted []acceptedPlay) {
+	for _, ap := range accepted {
+		CheckAndUnlockPlayMoveAchievements(ap.PlayerID, ap.Move.Letters, ap.Move.Score, ap.Move.Word)
+	}
+}
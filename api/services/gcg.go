@@ -0,0 +1,597 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/gcg"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/word"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidGCG est renvoyée quand un fichier GCG importé est mal formé ou ne
+// correspond pas aux règles (placement, score ou total incohérent).
+var ErrInvalidGCG = errors.New("invalid gcg file")
+
+// ExportGameGCG exporte la partie au format GCG, avec les règles de
+// visibilité du rejeu : ouverte selon la visibilité de la partie une fois
+// terminée, réservée à ses joueurs tant qu'elle est en cours (les racks et
+// échanges adverses sont alors omis).
+func ExportGameGCG(viewerID int64, gameID string) ([]byte, error) {
+	replay, g, err := loadReplay(viewerID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := gcg.Write(&buf, gcgFromReplay(replay, g, viewerID)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gcgFromReplay traduit le rejeu d'une partie en événements GCG.
+func gcgFromReplay(replay *response.GameReplay, g replayGame, viewerID int64) *gcg.Game {
+	ended := gameFinished(replay.Status)
+
+	nicks := gcgNicks(replay.Players)
+	out := &gcg.Game{Title: replay.Name, ID: "scrabble " + replay.GameID}
+	for _, p := range replay.Players {
+		out.Players = append(out.Players, gcg.Player{Nick: nicks[p.ID], Name: p.Username})
+	}
+
+	plies := replay.Plies
+	// rack d'un joueur avant le coup ply, vide s'il n'est pas visible
+	rackBefore := func(ply int, pid int64) string {
+		return gcg.JoinTiles(plies[ply-1].Racks[pid])
+	}
+	byID := make(map[int64]int, len(g.moves))
+	for i, m := range g.moves {
+		byID[m.ID] = i
+		ply := plies[i+1]
+		rec := m.Record
+		ev := gcg.Event{Nick: nicks[m.PlayerID], Rack: rackBefore(i+1, m.PlayerID), Total: ply.Scores[m.PlayerID]}
+
+		switch rec.Type {
+		case MoveTypePlay:
+			if len(rec.Letters) == 0 {
+				continue
+			}
+			ev.Type = gcg.Play
+			ev.Position, ev.Word = gcgPlay(ply.Board, rec.Letters)
+			ev.Score = rec.Score
+			if ev.Rack == "" {
+				ev.Rack = gcg.JoinTiles(placedTiles(rec.Letters).Strings())
+			}
+		case MoveTypePass:
+			ev.Type = gcg.Pass
+		case MoveTypeExchange:
+			ev.Type = gcg.Exchange
+			ev.Count = ply.Count
+			if ended || m.PlayerID == viewerID {
+				ev.Exchanged = gcg.JoinTiles(rec.Returned.Strings())
+			}
+		case MoveTypeChallenge:
+			target, ok := byID[rec.TargetMoveID]
+			switch {
+			case rec.Success && ok:
+				// la pose contestée est retirée par son auteur : "--"
+				author := g.moves[target].PlayerID
+				ev = gcg.Event{
+					Type:  gcg.PhonyRemoved,
+					Nick:  nicks[author],
+					Rack:  rackBefore(target+1, author),
+					Score: -g.moves[target].Record.Score,
+					Total: ply.Scores[author],
+				}
+			case rec.Penalty > 0:
+				// GCG n'a pas de notation pour la pénalité du contestataire
+				out.Events = append(out.Events, gcg.Event{Type: gcg.Note, Note: fmt.Sprintf("%s perd %d points pour une contestation ratée", ev.Nick, rec.Penalty)})
+				ev.Type = gcg.TimePenalty
+				ev.Score = -rec.Penalty
+			case rec.LostTurn:
+				out.Events = append(out.Events, gcg.Event{Type: gcg.Note, Note: ev.Nick + " perd son tour pour une contestation ratée"})
+				ev.Type = gcg.Pass
+			default:
+				ev = gcg.Event{Type: gcg.Note, Note: ev.Nick + " conteste sans succès"}
+			}
+		case MoveTypeResign:
+			ev = gcg.Event{Type: gcg.Note, Note: ev.Nick + " abandonne la partie"}
+		case MoveTypeForfeit:
+			ev = gcg.Event{Type: gcg.Note, Note: ev.Nick + " perd la partie par forfait"}
+		default:
+			continue
+		}
+		out.Events = append(out.Events, ev)
+	}
+
+	if last := plies[len(plies)-1]; last.Type == replayEnd {
+		out.Events = append(out.Events, gcgEndOfGame(replay.Players, nicks, plies[len(plies)-2], last)...)
+	}
+	return out
+}
+
+// gcgNicks attribue à chaque joueur un pseudonyme GCG unique et sans espace.
+func gcgNicks(players []response.PlayerInfo) map[int64]string {
+	nicks := make(map[int64]string, len(players))
+	used := map[string]bool{}
+	for i, p := range players {
+		nick := strings.Join(strings.Fields(p.Username), "_")
+		if nick == "" || used[nick] {
+			nick = fmt.Sprintf("p%d", i+1)
+		}
+		used[nick] = true
+		nicks[p.ID] = nick
+	}
+	return nicks
+}
+
+// gcgPlay retourne la position et le mot principal d'une pose, lu sur le
+// plateau après le coup : jokers en minuscules, "." pour les lettres déjà posées.
+func gcgPlay(board engine.Board, letters []request.PlacedLetter) (gcg.Position, string) {
+	placed := make(map[engine.Pos]request.PlacedLetter, len(letters))
+	for _, pl := range letters {
+		placed[engine.Pos{X: pl.X, Y: pl.Y}] = pl
+	}
+	occupied := func(x, y int) bool { return board.InBounds(x, y) && board[y][x] != "" }
+
+	first := letters[0]
+	vertical := len(letters) > 1 && letters[0].X == letters[1].X
+	if len(letters) == 1 {
+		// une seule lettre : le mot principal est celui qu'elle prolonge
+		vertical = !occupied(first.X-1, first.Y) && !occupied(first.X+1, first.Y)
+	}
+	dx, dy := 1, 0
+	if vertical {
+		dx, dy = 0, 1
+	}
+	x, y := first.X, first.Y
+	for occupied(x-dx, y-dy) {
+		x, y = x-dx, y-dy
+	}
+
+	pos := gcg.Position{X: x, Y: y, Vertical: vertical}
+	var faces []string
+	for ; occupied(x, y); x, y = x+dx, y+dy {
+		pl, ok := placed[engine.Pos{X: x, Y: y}]
+		switch {
+		case !ok:
+			faces = append(faces, ".")
+		case pl.Blank:
+			faces = append(faces, strings.ToLower(pl.Char))
+		default:
+			faces = append(faces, pl.Char)
+		}
+	}
+	return pos, gcg.JoinTiles(faces)
+}
+
+// gcgEndOfGame traduit le décompte des racks en fin de partie : les points
+// gagnés par le joueur qui a fini ((RACKS) +N), puis les racks retirés aux
+// autres (RACK (RACK) -N).
+func gcgEndOfGame(players []response.PlayerInfo, nicks map[int64]string, before, end response.ReplayPly) []gcg.Event {
+	var gains, losses []gcg.Event
+	for _, p := range players {
+		diff := end.Scores[p.ID] - before.Scores[p.ID]
+		rack := gcg.JoinTiles(end.Racks[p.ID])
+		switch {
+		case diff > 0:
+			var others []string
+			for _, o := range players {
+				if o.ID != p.ID {
+					others = append(others, end.Racks[o.ID]...)
+				}
+			}
+			gains = append(gains, gcg.Event{Type: gcg.EndRackPoints, Nick: nicks[p.ID], LostRack: gcg.JoinTiles(others), Score: diff, Total: end.Scores[p.ID]})
+		case diff < 0:
+			losses = append(losses, gcg.Event{Type: gcg.EndRackPenalty, Nick: nicks[p.ID], Rack: rack, LostRack: rack, Score: diff, Total: end.Scores[p.ID]})
+		}
+	}
+	return append(gains, losses...)
+}
+
+// importedMove est un coup à enregistrer pour une partie importée. target
+// est l'indice du coup contesté pour une contestation, -1 sinon.
+type importedMove struct {
+	playerID int64
+	record   any
+	target   int
+}
+
+// importedGame est le résultat du rejeu d'un fichier GCG par le moteur.
+type importedGame struct {
+	state *engine.GameState
+	moves []importedMove
+}
+
+// pendingDraw attend le rack suivant d'un joueur pour en déduire les tuiles
+// piochées après son coup.
+type pendingDraw struct {
+	drawn *word.Tiles
+	leave word.Tiles
+}
+
+// ImportGameGCG crée, à partir d'un fichier GCG, une partie archivée en lecture
+// seule (statut "archived", exclue de l'IPS, des succès et des statistiques). Chaque joueur du
+// fichier doit correspondre à un utilisateur (pseudonyme ou nom). Les coups sont
+// rejoués par le moteur : placements, scores et totaux doivent concorder ; les
+// mots ne sont pas vérifiés, le lexique du fichier pouvant différer du nôtre.
+func ImportGameGCG(adminID int64, req request.ImportGameRequest) (*uuid.UUID, error) {
+	parsed, err := gcg.Parse(strings.NewReader(req.GCG))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGCG, err)
+	}
+
+	langCode := req.Language
+	if langCode == "" {
+		langCode = lexiconLanguage(parsed.Lexicon)
+	}
+	lang := word.French
+	if langCode != "" {
+		l, ok := word.GetLanguage(langCode)
+		if !ok {
+			return nil, ErrUnsupportedLanguage
+		}
+		lang = l
+	}
+	layout, ok := engine.LayoutByName(req.Variant)
+	if !ok {
+		return nil, ErrInvalidVariant
+	}
+	rules := engine.DefaultRuleset()
+	if len(parsed.Players) > rules.MaxPlayers {
+		return nil, fmt.Errorf("%w: too many players", ErrInvalidGCG)
+	}
+	name := req.Name
+	if name == "" {
+		name = parsed.Title
+	}
+	if name == "" {
+		name = "Partie importée"
+	}
+
+	playerIDs := make([]int64, len(parsed.Players))
+	for i, p := range parsed.Players {
+		err := database.QueryRow(`
+			SELECT id FROM users
+			WHERE LOWER(username) = LOWER($1) OR LOWER(username) = LOWER($2)
+			ORDER BY LOWER(username) = LOWER($1) DESC
+			LIMIT 1
+		`, p.Nick, p.Name).Scan(&playerIDs[i])
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: unknown player %s", ErrInvalidGCG, p.Nick)
+			}
+			return nil, err
+		}
+		for _, other := range playerIDs[:i] {
+			if other == playerIDs[i] {
+				return nil, fmt.Errorf("%w: player %s appears twice", ErrInvalidGCG, p.Nick)
+			}
+		}
+	}
+
+	imported, err := replayGCG(parsed, playerIDs, lang, layout, rules)
+	if err != nil {
+		return nil, err
+	}
+	return saveImportedGame(adminID, name, lang, layout, rules, imported)
+}
+
+// lexiconLanguage déduit la langue d'un lexique GCG courant ("" si inconnu).
+func lexiconLanguage(lexicon string) string {
+	lexicon = strings.ToUpper(lexicon)
+	for _, prefix := range []string{"ODS", "FRA"} {
+		if strings.HasPrefix(lexicon, prefix) {
+			return word.French.Code
+		}
+	}
+	for _, prefix := range []string{"NWL", "TWL", "CSW", "OWL", "ECWL", "CEL"} {
+		if strings.HasPrefix(lexicon, prefix) {
+			return word.English.Code
+		}
+	}
+	return ""
+}
+
+// replayGCG rejoue les événements du fichier avec le moteur. Le rack de chaque
+// ligne remplace celui du joueur (le sac, inconnu, est reconstitué à chaque
+// coup) ; les tuiles piochées sont déduites du rack suivant du joueur.
+func replayGCG(parsed *gcg.Game, playerIDs []int64, lang *word.Language, layout *engine.Layout, rules engine.Ruleset) (*importedGame, error) {
+	byNick := make(map[string]int64, len(playerIDs))
+	for i, p := range parsed.Players {
+		byNick[p.Nick] = playerIDs[i]
+	}
+	bag := lang.BagOfSize(layout.BagSize)
+	state := engine.NewGame(playerIDs, layout, nil, rules, nil)
+	state.Language = lang
+	// mots non vérifiés : les poses douteuses sont retirées par "--"
+	state.ChallengeRule = engine.ChallengeFree
+
+	var (
+		moves    []importedMove
+		pending  = map[int64]pendingDraw{}
+		lastRack = map[int64]word.Tiles{}
+		final    = map[int64]word.Tiles{}
+		// dernière pose, tant qu'elle peut encore être retirée par "--"
+		lastPlay    *engine.PlayedMove
+		lastPlayIdx int
+	)
+	settle := func(pid int64, rack word.Tiles) {
+		if p, ok := pending[pid]; ok {
+			if drawn, ok := unreturn(rack, p.leave, nil); ok {
+				*p.drawn = drawn
+			}
+			delete(pending, pid)
+		}
+	}
+
+	for _, ev := range parsed.Events {
+		if ev.Type == gcg.Note {
+			continue
+		}
+		fail := func(format string, args ...any) error {
+			return fmt.Errorf("%w: line %d: %s", ErrInvalidGCG, ev.Line, fmt.Sprintf(format, args...))
+		}
+		pid, ok := byNick[ev.Nick]
+		if !ok {
+			return nil, fail("unknown player %s", ev.Nick)
+		}
+		rack, err := gcgRack(ev.Rack)
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		if len(rack) > 0 {
+			settle(pid, rack)
+		}
+		state.Turn = pid
+		state.Bag = append(word.Tiles(nil), bag...)
+		played := lastPlay
+		lastPlay = nil
+
+		switch ev.Type {
+		case gcg.Play:
+			notation, err := engine.ParseNotation(lang, state.Board, ev.Position.String()+" "+ev.Word)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			letters := notation.Letters
+			if len(rack) == 0 {
+				rack = placedTiles(letters)
+			}
+			state.Racks[pid] = rack
+			next, res, err := state.ApplyMove(pid, letters)
+			if err != nil {
+				return nil, fail("%s: %v", ev.Word, err)
+			}
+			if res.Score != ev.Score {
+				return nil, fail("score mismatch for %s: expected %d, computed %d", ev.Word, ev.Score, res.Score)
+			}
+			rec := &playMoveRecord{
+				Type: MoveTypePlay,
+				PlayMoveRequest: request.PlayMoveRequest{
+					Word: notation.Word, StartX: notation.X, StartY: notation.Y, Direction: notation.Direction(),
+					Letters: res.Letters, Score: res.Score,
+				},
+				PrevPassCount: res.PrevPassCount,
+			}
+			leave, _ := engine.RemoveFromRack(rack, res.Letters)
+			pending[pid] = pendingDraw{drawn: &rec.Drawn, leave: leave}
+			lastRack[pid] = leave
+			lastPlay = &engine.PlayedMove{PlayerID: pid, Letters: res.Letters, Drawn: res.Drawn, Score: res.Score, PrevPassCount: res.PrevPassCount}
+			lastPlayIdx = len(moves)
+			moves = append(moves, importedMove{playerID: pid, record: rec, target: -1})
+			state = next
+		case gcg.PhonyRemoved:
+			if played == nil || played.PlayerID != pid {
+				return nil, fail("no play to withdraw")
+			}
+			if ev.Score != -played.Score {
+				return nil, fail("score mismatch for the withdrawn play: expected %d, computed %d", ev.Score, -played.Score)
+			}
+			next, err := state.Takeback([]engine.PlayedMove{*played})
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			// la pose retirée ne pioche rien : le rack redevient celui d'avant
+			rec := moves[lastPlayIdx].record.(*playMoveRecord)
+			rec.Status = MoveStatusWithdrawn
+			rec.Drawn = nil
+			delete(pending, pid)
+			lastRack[pid] = next.Racks[pid]
+			challenger := state.NextPlayer(pid)
+			moves = append(moves, importedMove{
+				playerID: challenger,
+				record:   &challengeMoveRecord{Type: MoveTypeChallenge, Success: true},
+				target:   lastPlayIdx,
+			})
+			next.Turn = challenger
+			state = next
+		case gcg.Pass:
+			if ev.Score != 0 {
+				return nil, fail("a pass scores 0")
+			}
+			if len(rack) > 0 {
+				lastRack[pid] = rack
+			}
+			next, _, err := state.Pass(pid)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			moves = append(moves, importedMove{playerID: pid, record: map[string]any{"type": MoveTypePass}, target: -1})
+			state = next
+		case gcg.Exchange:
+			if ev.Score != 0 {
+				return nil, fail("an exchange scores 0")
+			}
+			rec := &exchangeMoveRecord{Type: MoveTypeExchange, Count: ev.Count}
+			if ev.Exchanged == "" {
+				// tuiles inconnues : seul le tour sans score compte
+				next, _, err := state.Pass(pid)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				state = next
+			} else {
+				tiles, err := gcgRack(ev.Exchanged)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				if len(rack) == 0 {
+					rack = tiles
+				}
+				state.Racks[pid] = rack
+				next, _, err := state.Exchange(pid, tiles)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				rec.Returned = tiles
+				leave, _ := unreturn(rack, tiles, nil)
+				pending[pid] = pendingDraw{drawn: &rec.Drawn, leave: leave}
+				lastRack[pid] = leave
+				state = next
+			}
+			moves = append(moves, importedMove{playerID: pid, record: rec, target: -1})
+		case gcg.ChallengeBonus, gcg.TimePenalty:
+			if (ev.Type == gcg.ChallengeBonus) != (ev.Score >= 0) {
+				return nil, fail("unexpected score %+d", ev.Score)
+			}
+			state.Scores[pid] += ev.Score
+		case gcg.EndRackPoints:
+			lost, err := gcgRack(ev.LostRack)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			points := engine.RackPoints(lang, lost)
+			if ev.Score != points && ev.Score != 2*points {
+				return nil, fail("end of game points mismatch: expected %d or %d, got %d", points, 2*points, ev.Score)
+			}
+			final[pid] = word.Tiles{}
+			state.Scores[pid] += ev.Score
+		case gcg.EndRackPenalty:
+			lost, err := gcgRack(ev.LostRack)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			if points := engine.RackPoints(lang, lost); ev.Score != -points {
+				return nil, fail("end of game penalty mismatch: expected %d, got %d", -points, ev.Score)
+			}
+			final[pid] = lost
+			state.Scores[pid] += ev.Score
+		}
+
+		if state.Scores[pid] != ev.Total {
+			return nil, fail("total mismatch for %s: expected %d, computed %d", ev.Nick, ev.Total, state.Scores[pid])
+		}
+	}
+
+	// racks finaux : décomptés en fin de partie, sinon le dernier rack connu
+	for _, pid := range playerIDs {
+		rack, ok := final[pid]
+		if !ok {
+			rack = lastRack[pid]
+		}
+		if ok {
+			settle(pid, rack)
+		}
+		state.Racks[pid] = append(word.Tiles{}, rack...)
+	}
+	state.Bag = word.Tiles{}
+	state.Ended = true
+	return &importedGame{state: state, moves: moves}, nil
+}
+
+// gcgRack convertit un rack GCG en tuiles ("?" pour un joker).
+func gcgRack(s string) (word.Tiles, error) {
+	faces, err := gcg.SplitTiles(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range faces {
+		if f == "." {
+			return nil, fmt.Errorf("invalid tile in rack %s", s)
+		}
+	}
+	return word.TilesFromStrings(faces), nil
+}
+
+// saveImportedGame enregistre une partie importée avec le statut "archived".
+func saveImportedGame(adminID int64, name string, lang *word.Language, layout *engine.Layout, rules engine.Ruleset, imported *importedGame) (*uuid.UUID, error) {
+	state := imported.state
+	boardJSON, err := json.Marshal(state.Board)
+	if err != nil {
+		return nil, err
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	var winnerID int64
+	for i, pid := range state.Players {
+		if i == 0 || state.Scores[pid] > state.Scores[winnerID] {
+			winnerID = pid
+		}
+	}
+
+	gameID := uuid.New()
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID.String())
+		}
+	}()
+
+	_, err = tx.Exec(`
+		INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, pass_count,
+			challenge_rule, ruleset, language, variant, winner_username, ended_at)
+		VALUES ($1, $2, $3, 'archived', $4, $5, $6, $7,
+			$8, $9, $10, $11, (SELECT username FROM users WHERE id = $12), now())
+	`, gameID, name, adminID, state.Turn, boardJSON, state.Bag, state.PassCount,
+		engine.ChallengeFree, rulesJSON, lang.Code, layout.Name, winnerID)
+	if err != nil {
+		return nil, err
+	}
+	for position, pid := range state.Players {
+		_, err := tx.Exec(`
+			INSERT INTO game_players (game_id, player_id, rack, position, score)
+			VALUES ($1, $2, $3, $4, $5)
+		`, gameID, pid, state.Racks[pid], position, state.Scores[pid])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int64, len(imported.moves))
+	for i, m := range imported.moves {
+		if c, ok := m.record.(*challengeMoveRecord); ok && m.target >= 0 {
+			c.TargetMoveID = ids[m.target]
+		}
+		moveJSON, err := json.Marshal(m.record)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.QueryRow(
+			`INSERT INTO game_moves (game_id, player_id, move) VALUES ($1, $2, $3) RETURNING id`,
+			gameID, m.playerID, moveJSON,
+		).Scan(&ids[i]); err != nil {
+			return nil, fmt.Errorf("failed to insert move: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &gameID, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/gcg"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestExportGameGCG_RoundTripsThroughImport(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "gcg_p1")
+	u2 := mustCreateUser(t, "gcg_p2")
+	admin := mustCreateUser(t, "gcg_admin")
+	gid, err := createStartedGame(t, u1, "gcg", []string{"gcg_p2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	_, err = ExchangeTiles(u2, g, []string{"A", "B"})
+	require.NoError(t, err)
+	require.NoError(t, PassTurn(u1, g))
+	require.NoError(t, ResignGame(u2, g))
+
+	// partie privée terminée : exportable par ses joueurs seulement
+	_, err = ExportGameGCG(0, g)
+	assert.ErrorContains(t, err, "unauthorized")
+	data, err := ExportGameGCG(u1, g)
+	require.NoError(t, err)
+	out := string(data)
+	assert.Contains(t, out, "#player1 gcg_p1 gcg_p1\n#player2 gcg_p2 gcg_p2\n#title gcg\n")
+	assert.Contains(t, out, " 8F CHAT +18 18\n")
+	assert.Contains(t, out, " -AB +0 0\n")
+	assert.Contains(t, out, "#note gcg_p2 abandonne la partie\n")
+
+	gameID, err := ImportGameGCG(admin, request.ImportGameRequest{GCG: out})
+	require.NoError(t, err)
+
+	var status, name string
+	require.NoError(t, database.DB.QueryRow(`SELECT status, name FROM games WHERE id = $1`, gameID.String()).Scan(&status, &name))
+	assert.Equal(t, "archived", status)
+	assert.Equal(t, "gcg", name)
+
+	state, err := loadGameState(database.DB, gameID.String())
+	require.NoError(t, err)
+	assert.True(t, state.Ended)
+	assert.Equal(t, 18, state.Scores[u1])
+	assert.Equal(t, "A", state.Board[7][7])
+
+	// partie archivée : en lecture seule
+	err = PassTurn(u1, gameID.String())
+	assert.ErrorIs(t, err, engine.ErrGameEnded)
+
+	replay, err := GetGameReplay(u2, gameID.String(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, replay.TotalPlies)
+	assert.Equal(t, 18, replay.Plies[1].Score)
+
+	// les coups importés partagent leur date : l'historique suit leur ordre d'insertion
+	info, err := GetGameDetails(u1, gameID.String())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(info.Moves), 3)
+	assert.Equal(t, MoveTypePlay, info.Moves[0].Type)
+	assert.Equal(t, MoveTypeExchange, info.Moves[1].Type)
+	assert.Equal(t, MoveTypePass, info.Moves[2].Type)
+}
+
+func TestImportGameGCG_RejectsInconsistentFiles(t *testing.T) {
+	resetAllGamesDeps(t)
+	mustCreateUser(t, "gcg_alice")
+	mustCreateUser(t, "gcg_bob")
+	admin := mustCreateUser(t, "gcg_admin2")
+
+	header := "#player1 gcg_alice Alice\n#player2 gcg_bob Bob\n"
+	cases := map[string]string{
+		"bad score":      header + ">gcg_alice: CHATXYZ 8F CHAT +20 20\n",
+		"bad total":      header + ">gcg_alice: CHATXYZ 8F CHAT +18 20\n",
+		"off center":     header + ">gcg_alice: CHATXYZ 1A CHAT +10 10\n",
+		"missing tiles":  header + ">gcg_alice: XYZ 8F CHAT +18 18\n",
+		"unknown player": "#player1 gcg_alice Alice\n#player2 gcg_nobody Nobody\n",
+		"syntax":         header + ">gcg_alice CHAT\n",
+	}
+	for name, file := range cases {
+		_, err := ImportGameGCG(admin, request.ImportGameRequest{GCG: file})
+		assert.ErrorIs(t, err, ErrInvalidGCG, name)
+	}
+}
+
+func TestReplayGCG_PhonyAndEndOfGame(t *testing.T) {
+	parsed, err := gcg.Parse(strings.NewReader(`#player1 a A
+#player2 b B
+>a: CHATXYZ 8F CHAT +18 18
+>b: EEEEEEE G8 .E +6 6
+>b: EEEEEEE -- -6 0
+>a: XYZEEEE - +0 18
+>a: (EEEEEEE) +7 25
+>b: EEEEEEE (EEEEEEE) -7 -7
+`))
+	require.NoError(t, err)
+
+	imported, err := replayGCG(parsed, []int64{1, 2}, word.French, engine.StandardLayout, engine.DefaultRuleset())
+	require.NoError(t, err)
+	state := imported.state
+	assert.Equal(t, map[int64]int{1: 25, 2: -7}, state.Scores)
+	assert.Equal(t, "", state.Board[8][6], "la pose retirée quitte le plateau")
+	assert.Empty(t, state.Racks[1])
+	assert.Equal(t, word.ParseTiles("EEEEEEE"), state.Racks[2])
+
+	require.Len(t, imported.moves, 4)
+	play := imported.moves[0].record.(*playMoveRecord)
+	assert.Equal(t, "CHAT", play.Word)
+	assert.Equal(t, word.ParseTiles("EEEE"), play.Drawn, "tuiles piochées déduites du rack suivant")
+	phony := imported.moves[1].record.(*playMoveRecord)
+	assert.Equal(t, MoveStatusWithdrawn, phony.Status)
+	challenge := imported.moves[2]
+	assert.Equal(t, int64(1), challenge.playerID)
+	assert.Equal(t, 1, challenge.target)
+	assert.Equal(t, map[string]any{"type": MoveTypePass}, imported.moves[3].record)
+}
+
+func TestGCGFromReplay_RoundTrips(t *testing.T) {
+	move := func(id, pid int64, typ string) storedMove {
+		m := storedMove{ID: id, PlayerID: pid}
+		m.Record.Type = typ
+		return m
+	}
+	chat := move(1, 1, MoveTypePlay)
+	chat.Record.Letters = chatAtCenterMove().Letters
+	chat.Record.Score = 18
+	chat.Record.Drawn = word.ParseTiles("EEEE")
+	phony := move(2, 2, MoveTypePlay)
+	phony.Record.Letters = []request.PlacedLetter{{X: 6, Y: 8, Char: "E", Blank: true}}
+	phony.Record.Score = 4
+	phony.Record.Status = MoveStatusWithdrawn
+	phony.Record.Drawn = word.ParseTiles("E")
+	challenge := move(3, 1, MoveTypeChallenge)
+	challenge.Record.TargetMoveID = 2
+	challenge.Record.Success = true
+	pass := move(4, 1, MoveTypePass)
+
+	g := replayGame{
+		lang:        word.French,
+		layout:      engine.StandardLayout,
+		rackSize:    engine.RackSize,
+		players:     []int64{1, 2},
+		moves:       []storedMove{chat, phony, challenge, pass},
+		racks:       map[int64]word.Tiles{1: word.ParseTiles("XYZEEEE"), 2: word.ParseTiles("EEEEEE?")},
+		finalScores: map[int64]int{1: 24, 2: -6},
+	}
+	replay := &response.GameReplay{
+		GameID:  "g1",
+		Name:    "export",
+		Status:  "ended",
+		Players: []response.PlayerInfo{{ID: 1, Username: "Alice B"}, {ID: 2, Username: "bob"}},
+		Plies:   buildReplay(g),
+	}
+
+	var out strings.Builder
+	require.NoError(t, gcg.Write(&out, gcgFromReplay(replay, g, 0)))
+	assert.Equal(t, `#character-encoding UTF-8
+#player1 Alice_B Alice B
+#player2 bob bob
+#title export
+#id scrabble g1
+>Alice_B: XYZCHAT 8F CHAT +18 18
+>bob: EEEEEE? G8 .e +4 4
+>bob: EEEEEE? -- -4 0
+>Alice_B: XYZEEEE - +0 18
+>Alice_B: (EEEEEE?) +6 24
+>bob: EEEEEE? (EEEEEE?) -6 -6
+`, out.String())
+
+	parsed, err := gcg.Parse(strings.NewReader(out.String()))
+	require.NoError(t, err)
+	imported, err := replayGCG(parsed, []int64{1, 2}, word.French, engine.StandardLayout, engine.DefaultRuleset())
+	require.NoError(t, err)
+	assert.Equal(t, g.finalScores, imported.state.Scores)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIdempotencyKeyReused est renvoyée quand une clé d'idempotence déjà
+// utilisée accompagne une autre action ou une autre partie.
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used for another request")
+
+// idempotencyTTL est la durée pendant laquelle une requête rejouée avec la
+// même clé renvoie le résultat d'origine.
+const idempotencyTTL = 24 * time.Hour
+
+// Actions protégées par une clé d'idempotence.
+const (
+	idempotentPlay     = "play"
+	idempotentPass     = "pass"
+	idempotentExchange = "exchange"
+)
+
+// replayIdempotent cherche le résultat d'une requête déjà traitée avec key et
+// le décode dans out (ignoré si nil). Elle est appelée dans la transaction du
+// coup, une fois la partie verrouillée : deux envois simultanés sont donc
+// sérialisés et le second voit le résultat du premier. Retourne false si key
+// est vide ou inconnue.
+func replayIdempotent(tx *sql.Tx, userID int64, key, gameID, action string, out any) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+	var (
+		same   bool
+		result []byte
+	)
+	err := tx.QueryRow(`
+		SELECT game_id = $3::uuid AND action = $4, result
+		FROM move_idempotency_keys
+		WHERE user_id = $1 AND key = $2 AND created_at > now() - $5::interval
+	`, userID, key, gameID, action, fmt.Sprintf("%d seconds", int(idempotencyTTL.Seconds()))).Scan(&same, &result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	if !same {
+		return false, ErrIdempotencyKeyReused
+	}
+	if out != nil && len(result) > 0 {
+		if err := json.Unmarshal(result, out); err != nil {
+			return false, fmt.Errorf("failed to decode idempotent result: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// saveIdempotent enregistre dans la transaction du coup le résultat renvoyé
+// pour key, et purge les clés expirées du joueur.
+func saveIdempotent(tx *sql.Tx, userID int64, key, gameID, action string, result any) error {
+	if key == "" {
+		return nil
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM move_idempotency_keys
+		WHERE user_id = $1 AND created_at <= now() - $2::interval
+	`, userID, fmt.Sprintf("%d seconds", int(idempotencyTTL.Seconds()))); err != nil {
+		return fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO move_idempotency_keys (user_id, key, game_id, action, result)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, key, gameID, action, resultJSON); err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
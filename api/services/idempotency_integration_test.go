@@ -0,0 +1,91 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func countGameMoves(t *testing.T, gameID string) int {
+	t.Helper()
+	var n int
+	require.NoError(t, database.QueryRow(`SELECT COUNT(*) FROM game_moves WHERE game_id = $1`, gameID).Scan(&n))
+	return n
+}
+
+func TestPlayMoveIdempotent_RetryReturnsOriginalResult(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "idem1")
+	u2 := mustCreateUser(t, "idem2")
+	gid, err := createStartedGame(t, u1, "idem", []string{"idem2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+
+	require.NoError(t, PlayMoveIdempotent(g, u1, chatAtCenterMove(), "k-play"))
+	// le renvoi ne rejoue pas le coup et ne répond pas "not your turn"
+	require.NoError(t, PlayMoveIdempotent(g, u1, chatAtCenterMove(), "k-play"))
+	assert.Equal(t, 1, countGameMoves(t, g))
+	assert.Equal(t, "XYZEEEE", getPlayerRack(t, g, u1))
+
+	// même clé pour une autre action : refusé
+	err = PassTurnIdempotent(u1, g, "k-play")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	drawn, err := ExchangeTilesIdempotent(u2, g, []string{"A", "B"}, "k-exchange")
+	require.NoError(t, err)
+	again, err := ExchangeTilesIdempotent(u2, g, []string{"A", "B"}, "k-exchange")
+	require.NoError(t, err)
+	assert.Equal(t, drawn, again)
+	assert.Equal(t, 2, countGameMoves(t, g))
+
+	require.NoError(t, PassTurnIdempotent(u1, g, "k-pass"))
+	require.NoError(t, PassTurnIdempotent(u1, g, "k-pass"))
+	assert.Equal(t, 3, countGameMoves(t, g))
+}
+
+func TestPlayMove_ConcurrentSubmitsDrawOnce(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "race1")
+	u2 := mustCreateUser(t, "race2")
+	gid, err := createStartedGame(t, u1, "race", []string{"race2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+
+	const submits = 4
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, submits)
+	)
+	for i := 0; i < submits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = PlayMove(g, u1, chatAtCenterMove())
+		}(i)
+	}
+	wg.Wait()
+
+	ok := 0
+	for _, err := range errs {
+		if err == nil {
+			ok++
+		}
+	}
+	assert.Equal(t, 1, ok, "un seul envoi doit aboutir")
+	assert.Equal(t, 1, countGameMoves(t, g))
+
+	var bag word.Tiles
+	require.NoError(t, database.QueryRow(`SELECT available_letters FROM games WHERE id = $1`, g).Scan(&bag))
+	assert.Len(t, bag, 6, "quatre tuiles piochées une seule fois")
+}
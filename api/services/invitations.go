@@ -0,0 +1,531 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/lib/pq"
+)
+
+// Statuts d'une invitation à une partie.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationExpired  = "expired" // sans réponse quand le créateur a lancé la partie
+)
+
+// ErrInvitationNotFound est renvoyée quand l'utilisateur n'a pas d'invitation
+// en attente pour la partie.
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// ErrGameNotPending est renvoyée quand la partie a déjà commencé.
+var ErrGameNotPending = errors.New("game is not waiting for players")
+
+// ErrNotEnoughPlayers est renvoyée quand le créateur lance une partie que
+// personne n'a encore rejointe.
+var ErrNotEnoughPlayers = errors.New("not enough players to start the game")
+
+// UnknownUsersError liste les pseudos invités qui ne correspondent à aucun compte.
+type UnknownUsersError struct {
+	Usernames []string
+}
+
+func (e *UnknownUsersError) Error() string {
+	return "unknown users: " + strings.Join(e.Usernames, ", ")
+}
+
+// invitee est un utilisateur invité à une partie. Accepted vaut pour une
+// invitation acceptée d'office (le bot, ou un joueur apparié par le
+// matchmaking).
+type invitee struct {
+	ID       int64
+	Accepted bool
+}
+
+// gameFinished indique si une partie est terminée : finie ou importée. Une
+// partie en attente des invités n'a pas encore commencé.
+func gameFinished(status string) bool {
+	return status == "ended" || status == "archived"
+}
+
+// resolveInvitees retrouve les comptes des pseudos invités, dans l'ordre de
+// la demande, sans doublon ni le créateur lui-même. Tout pseudo inconnu fait
+// échouer la création avec une UnknownUsersError.
+func resolveInvitees(q gameQuerier, creatorID int64, usernames []string) ([]invitee, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, u := range usernames {
+		u = strings.ToLower(strings.TrimSpace(u))
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		names = append(names, u)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	rows, err := q.Query(`SELECT id, LOWER(username), is_bot FROM users WHERE LOWER(username) = ANY($1)`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]invitee{}
+	for rows.Next() {
+		var (
+			name string
+			inv  invitee
+		)
+		if err := rows.Scan(&inv.ID, &name, &inv.Accepted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found[name] = inv
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var (
+		invitees []invitee
+		unknown  []string
+	)
+	for _, name := range names {
+		inv, ok := found[name]
+		switch {
+		case !ok:
+			unknown = append(unknown, name)
+		case inv.ID != creatorID:
+			invitees = append(invitees, inv)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownUsersError{Usernames: unknown}
+	}
+	return invitees, nil
+}
+
+// insertInvitations enregistre les invitations d'une partie qui vient d'être
+// créée ; la valeur retournée indique s'il reste des réponses à attendre.
+func insertInvitations(tx *sql.Tx, gameID string, invitees []invitee) (bool, error) {
+	waiting := false
+	for i, inv := range invitees {
+		status := InvitationPending
+		if inv.Accepted {
+			status = InvitationAccepted
+		} else {
+			waiting = true
+		}
+		_, err := tx.Exec(`
+			INSERT INTO game_invitations (game_id, user_id, status, position, responded_at)
+			VALUES ($1, $2, $3, $4, CASE WHEN $3 = 'pending' THEN NULL ELSE now() END)
+		`, gameID, inv.ID, status, i+1)
+		if err != nil {
+			return false, err
+		}
+	}
+	return waiting, nil
+}
+
+// startGame fait commencer une partie en attente. Le créateur puis les
+// invités ayant accepté, dans l'ordre de l'invitation, rejoignent les joueurs
+// déjà installés (places ouvertes du lobby) ; ceux qui n'ont pas encore de
+// rack le tirent maintenant, à partir de la graine engagée à la création ; en
+// duplicate, la première ronde s'ouvre avec le tirage commun. Les invitations
+// restées sans réponse et les liens d'invitation inutilisés expirent. La partie doit être verrouillée par tx.
+func startGame(tx *sql.Tx, gameID string) error {
+	var createdBy int64
+	if err := tx.QueryRow(`SELECT created_by FROM games WHERE id = $1`, gameID).Scan(&createdBy); err != nil {
+		return err
+	}
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return err
+	}
+
+	playerIDs := []int64{createdBy}
+	rows, err := tx.Query(`
+		SELECT user_id FROM game_invitations
+		WHERE game_id = $1 AND status = 'accepted'
+		ORDER BY position
+	`, gameID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var pid int64
+		if err := rows.Scan(&pid); err != nil {
+			rows.Close()
+			return err
+		}
+		playerIDs = append(playerIDs, pid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, pid := range playerIDs {
+		if state, err = state.Seat(pid); err != nil {
+			return err
+		}
+	}
+	if state.Duplicate() {
+		var round *duplicateRoundOpened
+		if state, round, err = openDuplicateRound(tx, gameID, state, 1); err != nil {
+			return err
+		}
+		if round == nil {
+			return engine.ErrRackExhausted
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE games
+		SET status = 'ongoing', available_letters = $1, current_turn = created_by, draw_count = $2,
+			turn_deadline = `+turnDeadlineSQL("turn_time_limit_hours")+`
+		WHERE id = $3
+	`, state.Bag, state.DrawCount, gameID)
+	if err != nil {
+		return err
+	}
+	if err := seatPlayers(tx, gameID, state); err != nil {
+		return err
+	}
+	if err := saveDraws(tx, gameID, state); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE game_invitations SET status = 'expired', responded_at = now()
+		WHERE game_id = $1 AND status = 'pending'
+	`, gameID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE game_invite_links SET expires_at = now()
+		WHERE game_id = $1 AND redeemed_at IS NULL AND revoked_at IS NULL AND expires_at > now()
+	`, gameID)
+	return err
+}
+
+// seatPlayers enregistre les joueurs installés par engine.GameState.Seat,
+// avec leur rack et leur place dans l'ordre de passage.
+func seatPlayers(tx *sql.Tx, gameID string, state *engine.GameState) error {
+	for position, pid := range state.Players {
+		_, err := tx.Exec(`
+			INSERT INTO game_players (game_id, player_id, rack, position, score)
+			VALUES ($1, $2, $3, $4, 0)
+			ON CONFLICT (game_id, player_id) DO UPDATE SET rack = EXCLUDED.rack, position = EXCLUDED.position
+		`, gameID, pid, state.Racks[pid], position)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockPendingGame verrouille une partie et vérifie qu'elle attend encore ses
+// invités. Elle retourne son nom et son créateur.
+func lockPendingGame(tx *sql.Tx, gameID string) (string, int64, error) {
+	var (
+		name      string
+		createdBy int64
+		status    string
+	)
+	err := tx.QueryRow(`SELECT name, created_by, status FROM games WHERE id = $1 FOR UPDATE`, gameID).Scan(&name, &createdBy, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", 0, errors.New("game not found")
+		}
+		return "", 0, err
+	}
+	if status != "pending" {
+		return "", 0, ErrGameNotPending
+	}
+	return name, createdBy, nil
+}
+
+// seatCount décompte les places d'une partie en attente.
+type seatCount struct {
+	Seated   int           // joueurs installés : le créateur et les places prises dans le lobby
+	Accepted int           // invités ayant accepté
+	Pending  int           // invités sans réponse, dont la place reste réservée
+	Links    int           // liens d'invitation encore utilisables, qui réservent aussi une place
+	Seats    sql.NullInt64 // places proposées dans le lobby (NULL hors lobby)
+}
+
+// players retourne le nombre de joueurs de la partie si elle commençait maintenant.
+func (c seatCount) players() int {
+	return c.Seated + c.Accepted
+}
+
+// taken retourne le nombre de places prises ou réservées.
+func (c seatCount) taken() int {
+	return c.Seated + c.Accepted + c.Pending + c.Links
+}
+
+// open retourne le nombre de places du lobby encore libres.
+func (c seatCount) open() int {
+	if !c.Seats.Valid {
+		return 0
+	}
+	return max(0, int(c.Seats.Int64)-c.taken())
+}
+
+// ready indique si la partie peut commencer d'elle-même : plus aucune réponse
+// ni aucun lien d'invitation n'est attendu et toutes les places du lobby sont
+// prises, ou, hors lobby, au moins un invité a accepté.
+func (c seatCount) ready() bool {
+	if c.Pending > 0 || c.Links > 0 {
+		return false
+	}
+	if c.Seats.Valid {
+		return c.players() >= int(c.Seats.Int64)
+	}
+	return c.Accepted > 0
+}
+
+// loadSeatCount décompte les places d'une partie en attente.
+func loadSeatCount(q gameQuerier, gameID string) (seatCount, error) {
+	var c seatCount
+	err := q.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM game_players WHERE game_id = g.id),
+			(SELECT COUNT(*) FROM game_invitations WHERE game_id = g.id AND status = 'accepted'),
+			(SELECT COUNT(*) FROM game_invitations WHERE game_id = g.id AND status = 'pending'),
+			(SELECT COUNT(*) FROM game_invite_links
+				WHERE game_id = g.id AND redeemed_at IS NULL AND revoked_at IS NULL AND expires_at > now()),
+			g.lobby_seats
+		FROM games g WHERE g.id = $1
+	`, gameID).Scan(&c.Seated, &c.Accepted, &c.Pending, &c.Links, &c.Seats)
+	return c, err
+}
+
+// invitationAnswered est la charge de l'événement envoyé au créateur quand un
+// invité répond.
+type invitationAnswered struct {
+	UserID  int64  `json:"user_id"`
+	Status  string `json:"status"`
+	Started bool   `json:"started"`
+}
+
+// AcceptInvitation enregistre l'acceptation de userID. La partie commence dès
+// qu'elle est complète (voir seatCount.ready).
+func AcceptInvitation(userID int64, gameID string) error {
+	return answerInvitation(userID, gameID, InvitationAccepted)
+}
+
+// DeclineInvitation enregistre le refus de userID. Si la partie est alors
+// complète sans lui, elle commence.
+func DeclineInvitation(userID int64, gameID string) error {
+	return answerInvitation(userID, gameID, InvitationDeclined)
+}
+
+func answerInvitation(userID int64, gameID, answer string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	name, createdBy, err := lockPendingGame(tx, gameID)
+	if err != nil {
+		if errors.Is(err, ErrGameNotPending) {
+			// une invitation expirée ou déjà traitée n'attend plus de réponse
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+	res, err := tx.Exec(`
+		UPDATE game_invitations SET status = $1, responded_at = now()
+		WHERE game_id = $2 AND user_id = $3 AND status = 'pending'
+	`, answer, gameID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrInvitationNotFound
+	}
+
+	seats, err := loadSeatCount(tx, gameID)
+	if err != nil {
+		return err
+	}
+	started := seats.ready()
+	if started {
+		if err := startGame(tx, gameID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	events.Publish([]int64{createdBy}, events.New(events.InvitationAnswered, gameID, invitationAnswered{
+		UserID: userID, Status: answer, Started: started,
+	}))
+	if started {
+		notifyGameStarted(gameID, name)
+	}
+	return nil
+}
+
+// StartPendingGame lance, à la demande de son créateur, une partie en attente
+// avec les joueurs installés et les invités qui ont déjà accepté.
+func StartPendingGame(userID int64, gameID string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	name, createdBy, err := lockPendingGame(tx, gameID)
+	if err != nil {
+		return err
+	}
+	if createdBy != userID {
+		return errors.New("unauthorized: you are not the creator of the game")
+	}
+	seats, err := loadSeatCount(tx, gameID)
+	if err != nil {
+		return err
+	}
+	if seats.players() < 2 {
+		return ErrNotEnoughPlayers
+	}
+	if err := startGame(tx, gameID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	notifyGameStarted(gameID, name)
+	return nil
+}
+
+// notifyGameStarted prévient les joueurs que la partie commence.
+func notifyGameStarted(gameID, name string) {
+	publishGameEvent(gameID, events.GameStarted, nil)
+	ids, err := gamePlayerIDs(gameID)
+	if err != nil {
+		logger.Warn(context.Background(), "invitations: failed to fetch game players", "error", err, "game_id", gameID)
+		return
+	}
+	for _, uid := range ids {
+		_ = utils.SendNotificationToUserByID(uid, utils.NotificationPayload{
+			Title: "La partie commence",
+			Body:  fmt.Sprintf("Tous les joueurs sont prêts dans « %s ».", name),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+		})
+	}
+}
+
+// notifyInvitees prévient les invités d'une nouvelle partie qui attendent sa
+// réponse.
+func notifyInvitees(gameID, name string, creatorID int64, invitees []invitee) {
+	var creator string
+	if err := database.QueryRow(`SELECT username FROM users WHERE id = $1`, creatorID).Scan(&creator); err != nil {
+		logger.Warn(context.Background(), "invitations: failed to fetch creator", "error", err, "game_id", gameID)
+	}
+	for _, inv := range invitees {
+		if inv.Accepted {
+			continue
+		}
+		events.Publish([]int64{inv.ID}, events.New(events.GameInvitation, gameID, map[string]string{
+			"game_name":  name,
+			"invited_by": creator,
+		}))
+		_ = utils.SendNotificationToUserByID(inv.ID, utils.NotificationPayload{
+			Title: "Nouvelle invitation",
+			Body:  fmt.Sprintf("%s vous invite à jouer à « %s ».", creator, name),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+		})
+	}
+}
+
+// GetInvitations retourne les invitations en attente de userID, des plus
+// récentes aux plus anciennes.
+func GetInvitations(userID int64) ([]response.GameInvitation, error) {
+	rows, err := database.Query(`
+		SELECT g.id, g.name, u.username, g.language, g.variant, i.created_at
+		FROM game_invitations i
+		JOIN games g ON g.id = i.game_id
+		JOIN users u ON u.id = g.created_by
+		WHERE i.user_id = $1 AND i.status = 'pending' AND g.status = 'pending'
+		ORDER BY i.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []response.GameInvitation{}
+	for rows.Next() {
+		var inv response.GameInvitation
+		if err := rows.Scan(&inv.GameID, &inv.GameName, &inv.InvitedBy, &inv.Language, &inv.Variant, &inv.CreatedAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range invitations {
+		if invitations[i].Invitees, err = loadInvitations(invitations[i].GameID); err != nil {
+			return nil, err
+		}
+	}
+	return invitations, nil
+}
+
+// loadInvitations retourne les réponses des invités d'une partie.
+func loadInvitations(gameID string) ([]response.InvitationInfo, error) {
+	rows, err := database.Query(`
+		SELECT i.user_id, u.username, i.status, i.responded_at
+		FROM game_invitations i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.game_id = $1
+		ORDER BY i.position
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []response.InvitationInfo
+	for rows.Next() {
+		var (
+			inv         response.InvitationInfo
+			respondedAt sql.NullTime
+		)
+		if err := rows.Scan(&inv.UserID, &inv.Username, &inv.Status, &respondedAt); err != nil {
+			return nil, err
+		}
+		if respondedAt.Valid {
+			inv.RespondedAt = &respondedAt.Time
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, rows.Err()
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestInvitations_UnknownUsers(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "inv_owner")
+	mustCreateUser(t, "inv_known")
+
+	_, err := CreateGame(u1, "typo", []string{"inv_known", "inv_ghost", "Inv_Phantom"}, nil)
+	var unknown *UnknownUsersError
+	require.ErrorAs(t, err, &unknown)
+	assert.Equal(t, []string{"inv_ghost", "inv_phantom"}, unknown.Usernames)
+
+	var cnt int
+	require.NoError(t, database.QueryRow(`SELECT COUNT(*) FROM games`).Scan(&cnt))
+	assert.Equal(t, 0, cnt)
+}
+
+func TestInvitations_AcceptStartsGame(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "inv_host")
+	u2 := mustCreateUser(t, "inv_guest1")
+	u3 := mustCreateUser(t, "inv_guest2")
+
+	stream, unsubscribe := events.Subscribe(u2)
+	defer unsubscribe()
+	owner, unsubscribeOwner := events.Subscribe(u1)
+	defer unsubscribeOwner()
+
+	gid, err := CreateGame(u1, "invites", []string{"inv_guest1", "INV_GUEST2", "inv_guest1", "inv_host"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	nextEvent(t, stream, events.GameInvitation)
+
+	// en attente : aucun rack tiré, seuls le créateur et ses invitations existent
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", info.Status)
+	assert.Empty(t, info.YourRack)
+	require.Len(t, info.Players, 1)
+	require.Len(t, info.Invitations, 2)
+	assert.Equal(t, u2, info.Invitations[0].UserID)
+	assert.Equal(t, InvitationPending, info.Invitations[0].Status)
+	assert.NotEmpty(t, info.BagSeedHash)
+	_, err = GetGameDetails(u2, g)
+	assert.Error(t, err)
+	assert.ErrorIs(t, PassTurn(u1, g), engine.ErrGameEnded)
+
+	invitations, err := GetInvitations(u2)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, "inv_host", invitations[0].InvitedBy)
+	assert.Len(t, invitations[0].Invitees, 2)
+
+	assert.ErrorIs(t, AcceptInvitation(u1, g), ErrInvitationNotFound)
+	require.NoError(t, AcceptInvitation(u2, g))
+	ev := nextEvent(t, owner, events.InvitationAnswered)
+	assert.Contains(t, string(ev.Data), `"started":false`)
+	assert.ErrorIs(t, AcceptInvitation(u2, g), ErrInvitationNotFound)
+
+	require.NoError(t, AcceptInvitation(u3, g))
+	nextEvent(t, stream, events.GameStarted)
+
+	info, err = GetGameDetails(u3, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Equal(t, u1, info.CurrentTurn)
+	assert.Len(t, info.YourTiles, 7)
+	require.Len(t, info.Players, 3)
+	assert.Equal(t, []int64{u1, u2, u3}, []int64{info.Players[0].ID, info.Players[1].ID, info.Players[2].ID})
+	assert.Empty(t, info.Invitations)
+	assert.Equal(t, len(word.French.Bag)-21, info.RemainingLetters)
+
+	draws, err := GetGameDraws(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, info.BagSeedHash, draws.SeedHash)
+	assert.Equal(t, 3, draws.DrawCount)
+
+	invitations, err = GetInvitations(u2)
+	require.NoError(t, err)
+	assert.Empty(t, invitations)
+}
+
+func TestInvitations_DeclineAndCreatorStart(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "inv_boss")
+	u2 := mustCreateUser(t, "inv_yes")
+	u3 := mustCreateUser(t, "inv_no")
+	u4 := mustCreateUser(t, "inv_late")
+
+	gid, err := CreateGame(u1, "partial", []string{"inv_yes", "inv_no", "inv_late"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	assert.ErrorIs(t, StartPendingGame(u1, g), ErrNotEnoughPlayers)
+	require.NoError(t, DeclineInvitation(u3, g))
+	require.NoError(t, AcceptInvitation(u2, g))
+	assert.ErrorContains(t, StartPendingGame(u2, g), "not the creator")
+
+	require.NoError(t, StartPendingGame(u1, g))
+	assert.ErrorIs(t, StartPendingGame(u1, g), ErrGameNotPending)
+	assert.ErrorIs(t, AcceptInvitation(u4, g), ErrInvitationNotFound)
+
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	require.Len(t, info.Players, 2)
+	assert.Equal(t, u2, info.Players[1].ID)
+	_, err = GetGameDetails(u3, g)
+	assert.Error(t, err)
+
+	var status string
+	require.NoError(t, database.QueryRow(`SELECT status FROM game_invitations WHERE game_id = $1 AND user_id = $2`, g, u4).Scan(&status))
+	assert.Equal(t, InvitationExpired, status)
+}
+
+func TestInvitations_LastAnswerStartsGame(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "inv_chief")
+	u2 := mustCreateUser(t, "inv_ok")
+	u3 := mustCreateUser(t, "inv_nope")
+
+	gid, err := CreateGame(u1, "last answer", []string{"inv_ok", "inv_nope"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	require.NoError(t, AcceptInvitation(u2, g))
+	require.NoError(t, DeclineInvitation(u3, g))
+
+	info, err := GetGameDetails(u2, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Len(t, info.Players, 2)
+}
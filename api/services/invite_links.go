@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/google/uuid"
+)
+
+// Statuts d'un lien d'invitation.
+const (
+	InviteLinkActive   = "active"
+	InviteLinkExpired  = "expired"
+	InviteLinkRevoked  = "revoked"
+	InviteLinkRedeemed = "redeemed"
+)
+
+// Durée de validité d'un lien d'invitation, en heures.
+const (
+	defaultInviteLinkHours = 72
+	maxInviteLinkHours     = 30 * 24
+)
+
+// ErrInviteLinkNotFound est renvoyée quand le lien n'existe pas (ou plus).
+var ErrInviteLinkNotFound = errors.New("invite link not found")
+
+// ErrInviteLinkExpired est renvoyée pour un lien dont la validité est passée.
+var ErrInviteLinkExpired = errors.New("invite link expired")
+
+// ErrInviteLinkRevoked est renvoyée pour un lien révoqué par le créateur.
+var ErrInviteLinkRevoked = errors.New("invite link revoked")
+
+// ErrInviteLinkUsed est renvoyée pour un lien déjà utilisé : chaque lien ne
+// donne qu'une place.
+var ErrInviteLinkUsed = errors.New("invite link already used")
+
+// ErrInvalidInviteLinkExpiry est renvoyée quand la durée de validité demandée
+// est négative ou dépasse maxInviteLinkHours.
+var ErrInvalidInviteLinkExpiry = errors.New("invalid invite link expiry")
+
+// insertInviteLink crée un lien d'invitation réservant une place dans la
+// partie, valable hours heures.
+func insertInviteLink(tx *sql.Tx, gameID string, createdBy int64, hours int) (*response.InviteLink, error) {
+	link := response.InviteLink{ID: uuid.New().String(), Status: InviteLinkActive}
+	err := tx.QueryRow(`
+		INSERT INTO game_invite_links (id, game_id, created_by, expires_at)
+		VALUES ($1, $2, $3, now() + make_interval(hours => $4))
+		RETURNING created_at, expires_at
+	`, link.ID, gameID, createdBy, hours).Scan(&link.CreatedAt, &link.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if link.Token, err = utils.GenerateInviteToken(link.ID, gameID, link.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// requireGameCreator vérifie que userID a créé la partie et retourne son statut.
+func requireGameCreator(q gameQuerier, userID int64, gameID string) (string, error) {
+	var (
+		createdBy int64
+		status    string
+	)
+	err := q.QueryRow(`SELECT created_by, status FROM games WHERE id = $1`, gameID).Scan(&createdBy, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errors.New("game not found")
+		}
+		return "", err
+	}
+	if createdBy != userID {
+		return "", errors.New("unauthorized: you are not the creator of the game")
+	}
+	return status, nil
+}
+
+// CreateInviteLink crée, pour le créateur d'une partie en attente, un lien
+// d'invitation valable hours heures (defaultInviteLinkHours si 0). Le lien
+// réserve une place jusqu'à ce qu'il soit utilisé, révoqué ou expiré.
+func CreateInviteLink(userID int64, gameID string, hours int) (*response.InviteLink, error) {
+	if hours == 0 {
+		hours = defaultInviteLinkHours
+	}
+	if hours < 0 || hours > maxInviteLinkHours {
+		return nil, ErrInvalidInviteLinkExpiry
+	}
+
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	_, createdBy, err := lockPendingGame(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy != userID {
+		return nil, errors.New("unauthorized: you are not the creator of the game")
+	}
+	var rawRules []byte
+	if err := tx.QueryRow(`SELECT ruleset FROM games WHERE id = $1`, gameID).Scan(&rawRules); err != nil {
+		return nil, err
+	}
+	rules, err := parseRuleset(rawRules)
+	if err != nil {
+		return nil, err
+	}
+	seats, err := loadSeatCount(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	capacity := rules.MaxPlayers
+	if seats.Seats.Valid {
+		capacity = int(seats.Seats.Int64)
+	}
+	if seats.taken() >= capacity {
+		return nil, ErrNoOpenSeat
+	}
+
+	link, err := insertInviteLink(tx, gameID, userID, hours)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListInviteLinks retourne au créateur les liens d'invitation de sa partie,
+// du plus ancien au plus récent. Le jeton n'est fourni que pour les liens
+// encore utilisables.
+func ListInviteLinks(userID int64, gameID string) ([]response.InviteLink, error) {
+	status, err := requireGameCreator(database.DB, userID, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Query(`
+		SELECT l.id, l.created_at, l.expires_at, l.expires_at <= now(), l.revoked_at, COALESCE(u.username, ''), l.redeemed_at
+		FROM game_invite_links l
+		LEFT JOIN users u ON u.id = l.redeemed_by
+		WHERE l.game_id = $1
+		ORDER BY l.created_at, l.id
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []response.InviteLink{}
+	for rows.Next() {
+		var (
+			link                  response.InviteLink
+			expired               bool
+			revokedAt, redeemedAt sql.NullTime
+		)
+		if err := rows.Scan(&link.ID, &link.CreatedAt, &link.ExpiresAt, &expired, &revokedAt, &link.RedeemedBy, &redeemedAt); err != nil {
+			return nil, err
+		}
+		switch {
+		case redeemedAt.Valid:
+			link.Status = InviteLinkRedeemed
+			link.RedeemedAt = &redeemedAt.Time
+		case revokedAt.Valid:
+			link.Status = InviteLinkRevoked
+			link.RevokedAt = &revokedAt.Time
+		case expired:
+			link.Status = InviteLinkExpired
+		default:
+			link.Status = InviteLinkActive
+		}
+		if link.Status == InviteLinkActive && status == "pending" {
+			if link.Token, err = utils.GenerateInviteToken(link.ID, gameID, link.ExpiresAt); err != nil {
+				return nil, err
+			}
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// RevokeInviteLink révoque un lien d'invitation encore inutilisé. Sa place
+// n'étant plus réservée, une partie en attente qui est alors complète
+// commence.
+func RevokeInviteLink(userID int64, gameID, linkID string) error {
+	if _, err := uuid.Parse(linkID); err != nil {
+		return ErrInviteLinkNotFound
+	}
+
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	if err := lockGame(tx, gameID); err != nil {
+		return err
+	}
+	status, err := requireGameCreator(tx, userID, gameID)
+	if err != nil {
+		return err
+	}
+	var revokedAt, redeemedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT revoked_at, redeemed_at FROM game_invite_links WHERE id = $1 AND game_id = $2
+	`, linkID, gameID).Scan(&revokedAt, &redeemedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInviteLinkNotFound
+		}
+		return err
+	}
+	switch {
+	case redeemedAt.Valid:
+		return ErrInviteLinkUsed
+	case revokedAt.Valid:
+		return ErrInviteLinkRevoked
+	}
+	if _, err := tx.Exec(`UPDATE game_invite_links SET revoked_at = now() WHERE id = $1`, linkID); err != nil {
+		return err
+	}
+
+	started := false
+	var name string
+	if status == "pending" {
+		seats, err := loadSeatCount(tx, gameID)
+		if err != nil {
+			return err
+		}
+		if started = seats.ready(); started {
+			if err := tx.QueryRow(`SELECT name FROM games WHERE id = $1`, gameID).Scan(&name); err != nil {
+				return err
+			}
+			if err := startGame(tx, gameID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if started {
+		notifyGameStarted(gameID, name)
+	}
+	return nil
+}
+
+// RedeemInviteLink fait prendre à userID la place réservée par un jeton de
+// lien d'invitation et retourne la partie rejointe. Le joueur compte comme un
+// invité ayant accepté : son rack est tiré au lancement de la partie, qui
+// commence dès qu'elle est complète. Chaque tentative sur un jeton
+// correctement signé est journalisée dans game_invite_link_redemptions, avec
+// son résultat.
+func RedeemInviteLink(userID int64, token, ip string) (string, error) {
+	linkID, gameID, err := utils.ParseInviteToken(token)
+	if err == nil || errors.Is(err, utils.ErrInviteTokenExpired) {
+		if _, e := uuid.Parse(linkID); e != nil {
+			err = utils.ErrInvalidInviteToken
+		} else if _, e := uuid.Parse(gameID); e != nil {
+			err = utils.ErrInvalidInviteToken
+		}
+	}
+	if errors.Is(err, utils.ErrInvalidInviteToken) {
+		logger.Warn(context.Background(), "invite links: invalid token", "user_id", userID, "ip", ip)
+		return "", err
+	}
+
+	if errors.Is(err, utils.ErrInviteTokenExpired) {
+		err = ErrInviteLinkExpired
+	} else {
+		err = redeemInviteLink(userID, linkID, gameID)
+	}
+	auditInviteRedemption(linkID, gameID, userID, ip, err)
+	if err != nil {
+		return "", err
+	}
+	return gameID, nil
+}
+
+func redeemInviteLink(userID int64, linkID, gameID string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	name, createdBy, err := lockPendingGame(tx, gameID)
+	if err != nil {
+		if err.Error() == "game not found" {
+			return ErrInviteLinkNotFound
+		}
+		return err
+	}
+	var (
+		expired               bool
+		revokedAt, redeemedAt sql.NullTime
+	)
+	err = tx.QueryRow(`
+		SELECT expires_at <= now(), revoked_at, redeemed_at
+		FROM game_invite_links WHERE id = $1 AND game_id = $2
+		FOR UPDATE
+	`, linkID, gameID).Scan(&expired, &revokedAt, &redeemedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInviteLinkNotFound
+		}
+		return err
+	}
+	switch {
+	case revokedAt.Valid:
+		return ErrInviteLinkRevoked
+	case redeemedAt.Valid:
+		return ErrInviteLinkUsed
+	case expired:
+		return ErrInviteLinkExpired
+	}
+
+	var joined bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM game_players WHERE game_id = $1 AND player_id = $2)
+			OR EXISTS (SELECT 1 FROM game_invitations WHERE game_id = $1 AND user_id = $2 AND status IN ('pending', 'accepted'))
+	`, gameID, userID).Scan(&joined)
+	if err != nil {
+		return err
+	}
+	if joined {
+		return ErrAlreadyInGame
+	}
+
+	// un joueur qui avait refusé une invitation à la partie peut revenir par un lien
+	_, err = tx.Exec(`
+		INSERT INTO game_invitations (game_id, user_id, status, position, responded_at)
+		VALUES ($1, $2, 'accepted', (SELECT COALESCE(MAX(position), 0) + 1 FROM game_invitations WHERE game_id = $1), now())
+		ON CONFLICT (game_id, user_id) DO UPDATE
+		SET status = EXCLUDED.status, position = EXCLUDED.position, responded_at = EXCLUDED.responded_at
+	`, gameID, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE game_invite_links SET redeemed_by = $1, redeemed_at = now() WHERE id = $2
+	`, userID, linkID); err != nil {
+		return err
+	}
+
+	seats, err := loadSeatCount(tx, gameID)
+	if err != nil {
+		return err
+	}
+	started := seats.ready()
+	if started {
+		if err := startGame(tx, gameID); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	events.Publish([]int64{createdBy}, events.New(events.InvitationAnswered, gameID, invitationAnswered{
+		UserID: userID, Status: InvitationAccepted, Started: started,
+	}))
+	if started {
+		notifyGameStarted(gameID, name)
+	}
+	return nil
+}
+
+// redemptionOutcome résume le résultat d'une tentative d'utilisation d'un lien
+// pour le journal.
+func redemptionOutcome(err error) string {
+	switch {
+	case err == nil:
+		return InviteLinkRedeemed
+	case errors.Is(err, ErrInviteLinkExpired):
+		return InviteLinkExpired
+	case errors.Is(err, ErrInviteLinkRevoked):
+		return InviteLinkRevoked
+	case errors.Is(err, ErrInviteLinkUsed):
+		return "used"
+	case errors.Is(err, ErrInviteLinkNotFound):
+		return "not_found"
+	case errors.Is(err, ErrAlreadyInGame):
+		return "already_in_game"
+	case errors.Is(err, ErrGameNotPending):
+		return "game_started"
+	default:
+		return "error"
+	}
+}
+
+// auditInviteRedemption journalise une tentative d'utilisation d'un lien
+// d'invitation, en dehors de la transaction pour garder aussi les échecs.
+func auditInviteRedemption(linkID, gameID string, userID int64, ip string, err error) {
+	outcome := redemptionOutcome(err)
+	_, dbErr := database.Exec(`
+		INSERT INTO game_invite_link_redemptions (link_id, game_id, user_id, outcome, ip)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`, linkID, gameID, userID, outcome, ip)
+	if dbErr != nil {
+		logger.Error(context.Background(), "invite links: failed to audit redemption", "error", dbErr, "link_id", linkID, "game_id", gameID)
+	}
+	logger.Info(context.Background(), "invite links: redemption", "link_id", linkID, "game_id", gameID, "user_id", userID, "outcome", outcome)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/utils"
+)
+
+func redemptionOutcomes(t *testing.T, linkID string) []string {
+	t.Helper()
+	rows, err := database.Query(`SELECT outcome FROM game_invite_link_redemptions WHERE link_id = $1 ORDER BY id`, linkID)
+	require.NoError(t, err)
+	defer rows.Close()
+	var outcomes []string
+	for rows.Next() {
+		var o string
+		require.NoError(t, rows.Scan(&o))
+		outcomes = append(outcomes, o)
+	}
+	require.NoError(t, rows.Err())
+	return outcomes
+}
+
+func TestInviteLinks_RedeemIsSingleUse(t *testing.T) {
+	t.Setenv("JWT_SECRET", "testsecret")
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "link_host")
+
+	gid, err := CreateGameWithOptions(u1, "entre amis", nil, nil, GameOptions{InviteLinks: 2})
+	require.NoError(t, err)
+	g := gid.String()
+
+	_, err = ListInviteLinks(mustCreateUser(t, "link_snoop"), g)
+	assert.ErrorContains(t, err, "not the creator")
+	links, err := ListInviteLinks(u1, g)
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	for _, l := range links {
+		assert.Equal(t, InviteLinkActive, l.Status)
+		assert.NotEmpty(t, l.Token)
+	}
+
+	// les invités créent leur compte après la création de la partie
+	u2 := mustCreateUser(t, "link_newcomer")
+	u3 := mustCreateUser(t, "link_latecomer")
+
+	joined, err := RedeemInviteLink(u2, links[0].Token, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, g, joined)
+	_, err = RedeemInviteLink(u3, links[0].Token, "")
+	assert.ErrorIs(t, err, ErrInviteLinkUsed)
+	_, err = RedeemInviteLink(u2, links[1].Token, "")
+	assert.ErrorIs(t, err, ErrAlreadyInGame)
+
+	// le second lien réserve encore une place
+	info, err := GetGameDetails(u2, g)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", info.Status)
+
+	_, err = RedeemInviteLink(u3, links[1].Token, "")
+	require.NoError(t, err)
+	info, err = GetGameDetails(u3, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	require.Len(t, info.Players, 3)
+	assert.Equal(t, []int64{u1, u2, u3}, []int64{info.Players[0].ID, info.Players[1].ID, info.Players[2].ID})
+	assert.Len(t, info.YourTiles, 7)
+
+	links, err = ListInviteLinks(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, InviteLinkRedeemed, links[0].Status)
+	assert.Equal(t, "link_newcomer", links[0].RedeemedBy)
+	assert.Empty(t, links[0].Token)
+
+	assert.Equal(t, []string{"redeemed", "used"}, redemptionOutcomes(t, links[0].ID))
+	assert.Equal(t, []string{"already_in_game", "redeemed"}, redemptionOutcomes(t, links[1].ID))
+	var ip string
+	require.NoError(t, database.QueryRow(`
+		SELECT ip FROM game_invite_link_redemptions WHERE link_id = $1 AND outcome = 'redeemed'
+	`, links[0].ID).Scan(&ip))
+	assert.Equal(t, "203.0.113.7", ip)
+}
+
+func TestInviteLinks_RevokeAndExpiry(t *testing.T) {
+	t.Setenv("JWT_SECRET", "testsecret")
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "link_owner")
+	u2 := mustCreateUser(t, "link_friend")
+	u3 := mustCreateUser(t, "link_stranger")
+
+	gid, err := CreateGameWithOptions(u1, "liens", []string{"link_friend"}, nil, GameOptions{})
+	require.NoError(t, err)
+	g := gid.String()
+
+	_, err = CreateInviteLink(u2, g, 0)
+	assert.ErrorContains(t, err, "not the creator")
+	_, err = CreateInviteLink(u1, g, -1)
+	assert.ErrorIs(t, err, ErrInvalidInviteLinkExpiry)
+
+	revoked, err := CreateInviteLink(u1, g, 0)
+	require.NoError(t, err)
+	require.NoError(t, RevokeInviteLink(u1, g, revoked.ID))
+	assert.ErrorIs(t, RevokeInviteLink(u1, g, revoked.ID), ErrInviteLinkRevoked)
+	_, err = RedeemInviteLink(u3, revoked.Token, "")
+	assert.ErrorIs(t, err, ErrInviteLinkRevoked)
+
+	expired, err := CreateInviteLink(u1, g, 1)
+	require.NoError(t, err)
+	_, err = database.Exec(`UPDATE game_invite_links SET expires_at = now() - interval '1 hour' WHERE id = $1`, expired.ID)
+	require.NoError(t, err)
+	_, err = RedeemInviteLink(u3, expired.Token, "")
+	assert.ErrorIs(t, err, ErrInviteLinkExpired)
+	_, err = RedeemInviteLink(u3, "not-a-token", "")
+	assert.ErrorIs(t, err, utils.ErrInvalidInviteToken)
+
+	// créateur, invité et deux liens : les quatre places sont réservées
+	first, err := CreateInviteLink(u1, g, 0)
+	require.NoError(t, err)
+	second, err := CreateInviteLink(u1, g, 0)
+	require.NoError(t, err)
+	_, err = CreateInviteLink(u1, g, 0)
+	assert.ErrorIs(t, err, ErrNoOpenSeat)
+
+	// la partie attend les liens encore utilisables, puis commence quand le dernier est révoqué
+	require.NoError(t, AcceptInvitation(u2, g))
+	require.NoError(t, RevokeInviteLink(u1, g, first.ID))
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", info.Status)
+	require.NoError(t, RevokeInviteLink(u1, g, second.ID))
+	info, err = GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Len(t, info.Players, 2)
+
+	assert.Equal(t, []string{"revoked"}, redemptionOutcomes(t, revoked.ID))
+	assert.Equal(t, []string{"expired"}, redemptionOutcomes(t, expired.ID))
+}
@@ -35,6 +35,7 @@ func CalculateIPS(recentMatches []MatchInfo) int {
 }
 
 // UpdateUserIPS récupère les 10 dernières parties et met à jour l'IPS de l'utilisateur, et ajoute une entrée d'historique.
+// Une partie perdue par forfait ou abandonnée compte pour 0 point.
 // Si la partie implique le bot (partie non classée), l'IPS n'est pas mis à jour.
 func UpdateUserIPS(tx *sql.Tx, userID int64, gameID string) error {
 	// Les parties contre le bot sont hors classement
@@ -44,7 +45,7 @@ func UpdateUserIPS(tx *sql.Tx, userID int64, gameID string) error {
 
 	rows, err := tx.Query(`
 		SELECT 
-			gp.score, 
+			CASE WHEN g.forfeited_by = gp.player_id OR gp.resigned THEN 0 ELSE gp.score END, 
 			(g.winner_username = u.username) as is_winner
 		FROM game_players gp
 		JOIN games g ON gp.game_id = g.id
@@ -134,7 +135,7 @@ func RegenerateUserRatingHistory(tx *sql.Tx, userID int64) error {
 	for _, gp := range games {
 		mRows, err := tx.Query(`
 			SELECT 
-				gp2.score, 
+				CASE WHEN g2.forfeited_by = gp2.player_id OR gp2.resigned THEN 0 ELSE gp2.score END, 
 				(g2.winner_username = $1) as is_winner
 			FROM game_players gp2
 			JOIN games g2 ON gp2.game_id = g2.id
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/lib/pq"
+)
+
+// ErrInvalidSeats est renvoyée quand le nombre de places du lobby ne laisse
+// aucune place libre ou dépasse le nombre de joueurs permis par les règles.
+var ErrInvalidSeats = errors.New("invalid seats")
+
+// ErrNotInLobby est renvoyée quand la partie n'a pas de places ouvertes à tous.
+var ErrNotInLobby = errors.New("game is not open to join")
+
+// ErrNoOpenSeat est renvoyée quand toutes les places du lobby sont prises.
+var ErrNoOpenSeat = errors.New("no open seat left")
+
+// ErrRatingTooLow est renvoyée quand le classement du joueur est inférieur au
+// minimum fixé par le créateur.
+var ErrRatingTooLow = errors.New("rating too low to join this game")
+
+// ErrAlreadyInGame est renvoyée quand le joueur a déjà une place ou une
+// invitation dans la partie.
+var ErrAlreadyInGame = errors.New("already in game")
+
+// maxLobbyGames borne la liste des parties du lobby.
+const maxLobbyGames = 50
+
+// validateLobbySeats vérifie le nombre de places demandé pour le lobby :
+// players places sont déjà prises (créateur et invités) et au moins une doit
+// rester libre. 0 laisse la partie hors du lobby (NULL).
+func validateLobbySeats(seats, players int, rules engine.Ruleset) (sql.NullInt64, error) {
+	if seats == 0 {
+		return sql.NullInt64{}, nil
+	}
+	if seats <= players || seats > rules.MaxPlayers {
+		return sql.NullInt64{}, ErrInvalidSeats
+	}
+	return sql.NullInt64{Int64: int64(seats), Valid: true}, nil
+}
+
+// ListLobbyGames retourne les parties en attente qui ont encore des places
+// libres, des plus récentes aux plus anciennes, en indiquant pour chacune si
+// viewerID peut la rejoindre.
+func ListLobbyGames(viewerID int64) ([]response.LobbyGame, error) {
+	var rating int
+	if err := database.QueryRow(`SELECT COALESCE(rating, $2) FROM users WHERE id = $1`, viewerID, defaultRating).Scan(&rating); err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Query(`
+		SELECT id, name, creator, language, variant, lobby_seats, lobby_min_rating, created_at, taken, joined
+		FROM (
+			SELECT g.id, g.name, u.username AS creator, g.language, g.variant, g.lobby_seats, g.lobby_min_rating, g.created_at,
+				(SELECT COUNT(*) FROM game_players gp WHERE gp.game_id = g.id)
+				+ (SELECT COUNT(*) FROM game_invitations i WHERE i.game_id = g.id AND i.status IN ('pending', 'accepted'))
+				+ (SELECT COUNT(*) FROM game_invite_links l
+					WHERE l.game_id = g.id AND l.redeemed_at IS NULL AND l.revoked_at IS NULL AND l.expires_at > now()) AS taken,
+				EXISTS (SELECT 1 FROM game_players gp WHERE gp.game_id = g.id AND gp.player_id = $1)
+				OR EXISTS (SELECT 1 FROM game_invitations i WHERE i.game_id = g.id AND i.user_id = $1) AS joined
+			FROM games g
+			JOIN users u ON u.id = g.created_by
+			WHERE g.status = 'pending' AND g.lobby_seats IS NOT NULL
+		) lobby
+		WHERE taken < lobby_seats
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, viewerID, maxLobbyGames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := []response.LobbyGame{}
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			g         response.LobbyGame
+			minRating sql.NullInt64
+		)
+		if err := rows.Scan(&g.ID, &g.Name, &g.CreatedBy, &g.Language, &g.Variant, &g.Seats, &minRating, &g.CreatedAt, &g.Taken, &g.Joined); err != nil {
+			return nil, err
+		}
+		g.Open = g.Seats - g.Taken
+		if minRating.Valid {
+			r := int(minRating.Int64)
+			g.MinRating = &r
+		}
+		g.CanJoin = !g.Joined && (g.MinRating == nil || rating >= *g.MinRating)
+		g.Players = []string{}
+		index[g.ID] = len(games)
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return games, nil
+	}
+
+	ids := make([]string, 0, len(games))
+	for _, g := range games {
+		ids = append(ids, g.ID)
+	}
+	playerRows, err := database.Query(`
+		SELECT gp.game_id, u.username
+		FROM game_players gp
+		JOIN users u ON u.id = gp.player_id
+		WHERE gp.game_id = ANY($1::uuid[])
+		ORDER BY gp.position
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer playerRows.Close()
+	for playerRows.Next() {
+		var gameID, username string
+		if err := playerRows.Scan(&gameID, &username); err != nil {
+			return nil, err
+		}
+		g := &games[index[gameID]]
+		g.Players = append(g.Players, username)
+	}
+	return games, playerRows.Err()
+}
+
+// playerJoined est la charge de l'événement publié quand un joueur prend une
+// place du lobby.
+type playerJoined struct {
+	PlayerID int64 `json:"player_id"`
+	Position int   `json:"position"`
+	Open     int   `json:"open"` // places encore libres
+	Started  bool  `json:"started"`
+}
+
+// JoinLobbyGame fait prendre à userID une place libre de la partie. Dans la
+// même transaction, la partie verrouillée, il tire son rack du sac de la
+// partie et s'installe à la suite des joueurs déjà assis. La partie commence
+// quand elle est complète.
+func JoinLobbyGame(userID int64, gameID string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	name, _, err := lockPendingGame(tx, gameID)
+	if err != nil {
+		if errors.Is(err, ErrGameNotPending) {
+			return ErrNoOpenSeat
+		}
+		return err
+	}
+	var (
+		minRating sql.NullInt64
+		rating    int
+		joined    bool
+	)
+	err = tx.QueryRow(`
+		SELECT g.lobby_min_rating, (SELECT COALESCE(rating, $3) FROM users WHERE id = $2),
+			EXISTS (SELECT 1 FROM game_players WHERE game_id = g.id AND player_id = $2)
+			OR EXISTS (SELECT 1 FROM game_invitations WHERE game_id = g.id AND user_id = $2)
+		FROM games g WHERE g.id = $1
+	`, gameID, userID, defaultRating).Scan(&minRating, &rating, &joined)
+	if err != nil {
+		return err
+	}
+	seats, err := loadSeatCount(tx, gameID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !seats.Seats.Valid:
+		return ErrNotInLobby
+	case joined:
+		return ErrAlreadyInGame
+	case seats.open() == 0:
+		return ErrNoOpenSeat
+	case minRating.Valid && int64(rating) < minRating.Int64:
+		return ErrRatingTooLow
+	}
+
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return err
+	}
+	if state, err = state.Seat(userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE games SET available_letters = $1, draw_count = $2 WHERE id = $3
+	`, state.Bag, state.DrawCount, gameID); err != nil {
+		return err
+	}
+	if err := seatPlayers(tx, gameID, state); err != nil {
+		return err
+	}
+	if err := saveDraws(tx, gameID, state); err != nil {
+		return err
+	}
+
+	seats.Seated++
+	started := seats.ready()
+	if started {
+		if err := startGame(tx, gameID); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	publishGameEvent(gameID, events.PlayerJoined, playerJoined{
+		PlayerID: userID,
+		Position: len(state.Players) - 1,
+		Open:     seats.open(),
+		Started:  started,
+	})
+	if started {
+		notifyGameStarted(gameID, name)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestLobby_SeatsValidation(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "lobby_owner")
+	mustCreateUser(t, "lobby_friend")
+
+	_, err := CreateGameWithOptions(u1, "full", []string{"lobby_friend"}, nil, GameOptions{Seats: 2})
+	assert.ErrorIs(t, err, ErrInvalidSeats)
+	_, err = CreateGameWithOptions(u1, "crowd", nil, nil, GameOptions{Seats: 5})
+	assert.ErrorIs(t, err, ErrInvalidSeats)
+
+	games, err := ListLobbyGames(u1)
+	require.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestLobby_JoinDrawsRackAndStartsWhenFull(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "lobby_host")
+	u2 := mustCreateUser(t, "lobby_invited")
+	u3 := mustCreateUser(t, "lobby_first")
+	u4 := mustCreateUser(t, "lobby_second")
+	u5 := mustCreateUser(t, "lobby_rookie")
+	setUserRating(t, u5, 1200)
+
+	minRating := 1500
+	gid, err := CreateGameWithOptions(u1, "open table", []string{"lobby_invited"}, nil, GameOptions{Seats: 4, MinRating: &minRating})
+	require.NoError(t, err)
+	g := gid.String()
+
+	games, err := ListLobbyGames(u3)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.Equal(t, 4, games[0].Seats)
+	assert.Equal(t, 2, games[0].Taken)
+	assert.Equal(t, 2, games[0].Open)
+	assert.Equal(t, []string{"lobby_host"}, games[0].Players)
+	assert.True(t, games[0].CanJoin)
+
+	games, err = ListLobbyGames(u5)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.False(t, games[0].CanJoin)
+	assert.ErrorIs(t, JoinLobbyGame(u5, g), ErrRatingTooLow)
+	assert.ErrorIs(t, JoinLobbyGame(u2, g), ErrAlreadyInGame)
+
+	stream, unsubscribe := events.Subscribe(u1)
+	defer unsubscribe()
+
+	// la place est prise et le rack tiré tout de suite, la partie reste en attente
+	require.NoError(t, JoinLobbyGame(u3, g))
+	nextEvent(t, stream, events.PlayerJoined)
+	assert.ErrorIs(t, JoinLobbyGame(u3, g), ErrAlreadyInGame)
+	info, err := GetGameDetails(u3, g)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", info.Status)
+	assert.Len(t, info.YourTiles, 7)
+	assert.Equal(t, len(word.French.Bag)-7, info.RemainingLetters)
+	require.Len(t, info.Players, 2)
+	assert.Equal(t, u3, info.Players[1].ID)
+	assert.Equal(t, 4, info.LobbySeats)
+
+	// la dernière place libre est prise ; celle de l'invité reste réservée
+	require.NoError(t, JoinLobbyGame(u4, g))
+	games, err = ListLobbyGames(u5)
+	require.NoError(t, err)
+	assert.Empty(t, games)
+
+	require.NoError(t, AcceptInvitation(u2, g))
+	nextEvent(t, stream, events.GameStarted)
+
+	info, err = GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Equal(t, u1, info.CurrentTurn)
+	assert.Len(t, info.YourTiles, 7)
+	require.Len(t, info.Players, 4)
+	assert.Equal(t, []int64{u1, u3, u4, u2}, []int64{info.Players[0].ID, info.Players[1].ID, info.Players[2].ID, info.Players[3].ID})
+	assert.Equal(t, len(word.French.Bag)-28, info.RemainingLetters)
+
+	draws, err := GetGameDraws(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, 4, draws.DrawCount)
+}
+
+func TestLobby_CreatorStartsNow(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "lobby_boss")
+	u2 := mustCreateUser(t, "lobby_guest")
+	u3 := mustCreateUser(t, "lobby_late")
+
+	gid, err := CreateGameWithOptions(u1, "quick", nil, nil, GameOptions{Seats: 3})
+	require.NoError(t, err)
+	g := gid.String()
+
+	assert.ErrorIs(t, StartPendingGame(u1, g), ErrNotEnoughPlayers)
+	require.NoError(t, JoinLobbyGame(u2, g))
+	assert.ErrorContains(t, StartPendingGame(u2, g), "not the creator")
+	require.NoError(t, StartPendingGame(u1, g))
+	assert.ErrorIs(t, JoinLobbyGame(u3, g), ErrNoOpenSeat)
+
+	info, err := GetGameDetails(u2, g)
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	assert.Len(t, info.Players, 2)
+	assert.Len(t, info.YourTiles, 7)
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/ZiplEix/scrabble/api/word"
+	"github.com/lib/pq"
+)
+
+// Modes de la file de matchmaking.
+const (
+	MatchmakingDuel  = "duel"  // 1 contre 1
+	MatchmakingMulti = "multi" // 3 ou 4 joueurs
+)
+
+// ErrInvalidMatchmakingMode est renvoyée pour un mode de matchmaking inconnu.
+var ErrInvalidMatchmakingMode = errors.New("invalid matchmaking mode")
+
+// ErrInvalidRatingWindow est renvoyée pour un écart de classement négatif.
+var ErrInvalidRatingWindow = errors.New("invalid rating window")
+
+// ErrNotInMatchmaking est renvoyée quand l'utilisateur n'est pas dans la file.
+var ErrNotInMatchmaking = errors.New("not in matchmaking queue")
+
+// defaultRating est le classement des utilisateurs qui n'en ont pas encore.
+const defaultRating = 1600
+
+// matchmakingGameName est le nom des parties créées par le matchmaking.
+const matchmakingGameName = "Partie rapide"
+
+// matchmakingPlayers retourne le nombre minimal et maximal de joueurs d'une
+// partie du mode donné.
+func matchmakingPlayers(mode string) (int, int) {
+	if mode == MatchmakingMulti {
+		return 3, 4
+	}
+	return 2, 2
+}
+
+// JoinMatchmaking inscrit userID dans la file, ou met à jour ses préférences
+// s'il y est déjà, sans lui faire perdre sa place.
+func JoinMatchmaking(userID int64, req request.JoinMatchmakingRequest) (*response.MatchmakingEntry, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = MatchmakingDuel
+	}
+	if mode != MatchmakingDuel && mode != MatchmakingMulti {
+		return nil, ErrInvalidMatchmakingMode
+	}
+	lang := word.French
+	if req.Language != "" {
+		l, ok := word.GetLanguage(req.Language)
+		if !ok || !l.Available() {
+			return nil, ErrUnsupportedLanguage
+		}
+		lang = l
+	}
+	layout, ok := engine.LayoutByName(req.Variant)
+	if !ok {
+		return nil, ErrInvalidVariant
+	}
+	if req.RatingWindow != nil && *req.RatingWindow < 0 {
+		return nil, ErrInvalidRatingWindow
+	}
+
+	// le nombre de joueurs découle du mode : deux joueurs aux mêmes règles se
+	// retrouvent ainsi quelle que soit la limite qu'ils ont demandée
+	var overrides request.GameRules
+	if req.Rules != nil {
+		overrides = *req.Rules
+	}
+	_, maxPlayers := matchmakingPlayers(mode)
+	overrides.MaxPlayers = &maxPlayers
+	rules, err := buildRuleset(&overrides, lang)
+	if err != nil {
+		return nil, err
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = database.Exec(`
+		INSERT INTO matchmaking_queue (user_id, mode, language, variant, ruleset, rating_window)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET mode = EXCLUDED.mode, language = EXCLUDED.language, variant = EXCLUDED.variant,
+			ruleset = EXCLUDED.ruleset, rating_window = EXCLUDED.rating_window
+	`, userID, mode, lang.Code, layout.Name, rulesJSON, req.RatingWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join matchmaking queue: %w", err)
+	}
+	return GetMatchmakingEntry(userID)
+}
+
+// GetMatchmakingEntry retourne l'inscription de userID dans la file.
+func GetMatchmakingEntry(userID int64) (*response.MatchmakingEntry, error) {
+	var (
+		entry        response.MatchmakingEntry
+		rulesRaw     []byte
+		ratingWindow sql.NullInt64
+	)
+	err := database.QueryRow(`
+		SELECT q.mode, q.language, q.variant, q.ruleset, q.rating_window, COALESCE(u.rating, $2), q.joined_at
+		FROM matchmaking_queue q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.user_id = $1
+	`, userID, defaultRating).Scan(&entry.Mode, &entry.Language, &entry.Variant, &rulesRaw, &ratingWindow, &entry.Rating, &entry.JoinedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotInMatchmaking
+		}
+		return nil, err
+	}
+	rules, err := parseRuleset(rulesRaw)
+	if err != nil {
+		return nil, err
+	}
+	entry.Rules = gameRulesResponse(rules)
+	if ratingWindow.Valid {
+		w := int(ratingWindow.Int64)
+		entry.RatingWindow = &w
+	}
+	return &entry, nil
+}
+
+// LeaveMatchmaking retire userID de la file.
+func LeaveMatchmaking(userID int64) error {
+	res, err := database.Exec(`DELETE FROM matchmaking_queue WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotInMatchmaking
+	}
+	return nil
+}
+
+// queueEntry est une inscription de la file, avec le classement courant du joueur.
+type queueEntry struct {
+	UserID       int64
+	Mode         string
+	Language     string
+	Variant      string
+	Ruleset      string // JSON normalisé, comparé tel quel
+	RatingWindow sql.NullInt64
+	Rating       int
+	JoinedAt     time.Time
+}
+
+// accepts indique si e accepte b comme adversaire au vu de son écart de classement.
+func (e queueEntry) accepts(b queueEntry) bool {
+	if !e.RatingWindow.Valid {
+		return true
+	}
+	diff := e.Rating - b.Rating
+	if diff < 0 {
+		diff = -diff
+	}
+	return int64(diff) <= e.RatingWindow.Int64
+}
+
+// compatible indique si a et b cherchent la même partie et s'acceptent l'un l'autre.
+func compatible(a, b queueEntry) bool {
+	return a.Mode == b.Mode && a.Language == b.Language && a.Variant == b.Variant && a.Ruleset == b.Ruleset &&
+		a.accepts(b) && b.accepts(a)
+}
+
+// findMatches regroupe les joueurs de la file, triée du plus ancien au plus
+// récent. Chaque groupe part du joueur qui attend depuis le plus longtemps et
+// lui adjoint, par ordre d'arrivée, ceux qui sont compatibles avec tous les
+// membres déjà retenus.
+func findMatches(queue []queueEntry) [][]queueEntry {
+	var matches [][]queueEntry
+	used := make([]bool, len(queue))
+	for i, anchor := range queue {
+		if used[i] {
+			continue
+		}
+		minPlayers, maxPlayers := matchmakingPlayers(anchor.Mode)
+		group := []queueEntry{anchor}
+		members := []int{i}
+		for j := i + 1; j < len(queue) && len(group) < maxPlayers; j++ {
+			if used[j] {
+				continue
+			}
+			fits := true
+			for _, m := range group {
+				if !compatible(m, queue[j]) {
+					fits = false
+					break
+				}
+			}
+			if fits {
+				group = append(group, queue[j])
+				members = append(members, j)
+			}
+		}
+		if len(group) < minPlayers {
+			continue
+		}
+		for _, m := range members {
+			used[m] = true
+		}
+		matches = append(matches, group)
+	}
+	return matches
+}
+
+// loadMatchmakingQueue charge la file, du plus ancien inscrit au plus récent.
+func loadMatchmakingQueue() ([]queueEntry, error) {
+	rows, err := database.Query(`
+		SELECT q.user_id, q.mode, q.language, q.variant, q.ruleset::text, q.rating_window, COALESCE(u.rating, $1), q.joined_at
+		FROM matchmaking_queue q
+		JOIN users u ON u.id = q.user_id
+		ORDER BY q.joined_at, q.user_id
+	`, defaultRating)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var queue []queueEntry
+	for rows.Next() {
+		var e queueEntry
+		if err := rows.Scan(&e.UserID, &e.Mode, &e.Language, &e.Variant, &e.Ruleset, &e.RatingWindow, &e.Rating, &e.JoinedAt); err != nil {
+			return nil, err
+		}
+		queue = append(queue, e)
+	}
+	return queue, rows.Err()
+}
+
+// MatchPlayers fait un passage du matcher : chaque groupe de joueurs
+// compatibles quitte la file et reçoit une partie. Elle retourne les parties
+// créées.
+func MatchPlayers() ([]string, error) {
+	queue, err := loadMatchmakingQueue()
+	if err != nil {
+		return nil, err
+	}
+	var games []string
+	for _, group := range findMatches(queue) {
+		gameID, err := startMatch(group)
+		if err != nil {
+			logger.Error(context.Background(), "matchmaking: failed to create game", "error", err)
+			continue
+		}
+		if gameID != "" {
+			games = append(games, gameID)
+		}
+	}
+	return games, nil
+}
+
+// startMatch retire le groupe de la file et lui crée une partie, dans une
+// même transaction, qui commence aussitôt : en s'inscrivant, chacun a déjà
+// accepté de jouer. Si l'un d'eux a quitté la file entre-temps (ou qu'une
+// autre instance de l'API l'a déjà apparié), ou si la partie ne peut être
+// créée, rien n'est fait et tous restent inscrits.
+func startMatch(group []queueEntry) (string, error) {
+	ids := make([]int64, 0, len(group))
+	for _, e := range group {
+		ids = append(ids, e.UserID)
+	}
+
+	anchor := group[0]
+	rules, err := parseRuleset([]byte(anchor.Ruleset))
+	if err != nil {
+		return "", err
+	}
+	invitees := make([]invitee, 0, len(group)-1)
+	for _, e := range group[1:] {
+		invitees = append(invitees, invitee{ID: e.UserID, Accepted: true})
+	}
+
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err)
+		}
+	}()
+	res, err := tx.Exec(`DELETE FROM matchmaking_queue WHERE user_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return "", err
+	}
+	if n, _ := res.RowsAffected(); n != int64(len(group)) {
+		return "", nil
+	}
+	gameID, err := createGameTx(tx, anchor.UserID, matchmakingGameName, invitees, nil, GameOptions{
+		Language: anchor.Language,
+		Variant:  anchor.Variant,
+		Rules:    gameRulesRequest(rules),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	notifyMatchFound(gameID.String(), ids)
+	return gameID.String(), nil
+}
+
+// notifyMatchFound prévient les joueurs appariés que leur partie a commencé.
+func notifyMatchFound(gameID string, ids []int64) {
+	names := map[int64]string{}
+	rows, err := database.Query(`SELECT id, username FROM users WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		logger.Warn(context.Background(), "matchmaking: failed to fetch players", "error", err, "game_id", gameID)
+	} else {
+		for rows.Next() {
+			var (
+				id   int64
+				name string
+			)
+			if err := rows.Scan(&id, &name); err == nil {
+				names[id] = name
+			}
+		}
+		rows.Close()
+	}
+
+	for _, uid := range ids {
+		var opponents []string
+		for _, other := range ids {
+			if other != uid {
+				opponents = append(opponents, names[other])
+			}
+		}
+		events.Publish([]int64{uid}, events.New(events.MatchFound, gameID, map[string]any{
+			"opponents": opponents,
+		}))
+		_ = utils.SendNotificationToUserByID(uid, utils.NotificationPayload{
+			Title: "Adversaire trouvé",
+			Body:  fmt.Sprintf("Votre partie contre %s commence.", strings.Join(opponents, ", ")),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+		})
+	}
+}
+
+// gameRulesRequest exprime des règles complètes sous forme de surcharges,
+// pour créer une partie qui les reprend à l'identique.
+func gameRulesRequest(rules engine.Ruleset) *request.GameRules {
+	return &request.GameRules{
+		BingoBonus:         &rules.BingoBonus,
+		ScorelessTurnLimit: &rules.ScorelessTurnLimit,
+		RackSize:           &rules.RackSize,
+		MaxPlayers:         &rules.MaxPlayers,
+		SmallBagExchange:   &rules.SmallBagExchange,
+		Leftover:           &rules.Leftover,
+	}
+}
+
+// StartMatchmakingWorker lance la goroutine qui apparie les joueurs de la
+// file. La file étant en base, elle survit aux redémarrages de l'API.
+// Intervalle en secondes.
+func StartMatchmakingWorker(intervalSeconds int) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := MatchPlayers(); err != nil {
+				logger.Error(context.Background(), "matchmaking: queue query failed", "error", err)
+			}
+		}
+	}()
+	logger.Info(context.Background(), "matchmaking: worker started", "interval_seconds", intervalSeconds)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/models/request"
+)
+
+func setUserRating(t *testing.T, userID int64, rating int) {
+	t.Helper()
+	_, err := database.Exec(`UPDATE users SET rating = $1 WHERE id = $2`, rating, userID)
+	require.NoError(t, err)
+}
+
+func TestMatchmaking_JoinAndLeave(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "mm_solo")
+
+	_, err := JoinMatchmaking(u1, request.JoinMatchmakingRequest{Mode: "trio"})
+	assert.ErrorIs(t, err, ErrInvalidMatchmakingMode)
+	window := -10
+	_, err = JoinMatchmaking(u1, request.JoinMatchmakingRequest{RatingWindow: &window})
+	assert.ErrorIs(t, err, ErrInvalidRatingWindow)
+	_, err = GetMatchmakingEntry(u1)
+	assert.ErrorIs(t, err, ErrNotInMatchmaking)
+
+	entry, err := JoinMatchmaking(u1, request.JoinMatchmakingRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, MatchmakingDuel, entry.Mode)
+	assert.Equal(t, "fr", entry.Language)
+	assert.Equal(t, 2, entry.Rules.MaxPlayers)
+	assert.Nil(t, entry.RatingWindow)
+
+	// une nouvelle inscription met à jour les préférences sans perdre sa place
+	window = 100
+	updated, err := JoinMatchmaking(u1, request.JoinMatchmakingRequest{Mode: MatchmakingMulti, RatingWindow: &window})
+	require.NoError(t, err)
+	assert.Equal(t, MatchmakingMulti, updated.Mode)
+	assert.Equal(t, 4, updated.Rules.MaxPlayers)
+	require.NotNil(t, updated.RatingWindow)
+	assert.Equal(t, 100, *updated.RatingWindow)
+	assert.Equal(t, entry.JoinedAt, updated.JoinedAt)
+
+	games, err := MatchPlayers()
+	require.NoError(t, err)
+	assert.Empty(t, games)
+
+	require.NoError(t, LeaveMatchmaking(u1))
+	assert.ErrorIs(t, LeaveMatchmaking(u1), ErrNotInMatchmaking)
+}
+
+func TestMatchmaking_DuelRespectsRatingWindow(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "mm_picky")
+	u2 := mustCreateUser(t, "mm_strong")
+	u3 := mustCreateUser(t, "mm_close")
+	u4 := mustCreateUser(t, "mm_super")
+	setUserRating(t, u1, 1600)
+	setUserRating(t, u2, 1800)
+	setUserRating(t, u3, 1640)
+
+	stream, unsubscribe := events.Subscribe(u3)
+	defer unsubscribe()
+
+	window := 50
+	_, err := JoinMatchmaking(u1, request.JoinMatchmakingRequest{RatingWindow: &window})
+	require.NoError(t, err)
+	_, err = JoinMatchmaking(u4, request.JoinMatchmakingRequest{Variant: "super"})
+	require.NoError(t, err)
+	_, err = JoinMatchmaking(u2, request.JoinMatchmakingRequest{})
+	require.NoError(t, err)
+
+	// u2 est hors de l'écart accepté par u1, u4 cherche une partie sur le plateau super
+	games, err := MatchPlayers()
+	require.NoError(t, err)
+	assert.Empty(t, games)
+
+	_, err = JoinMatchmaking(u3, request.JoinMatchmakingRequest{})
+	require.NoError(t, err)
+	games, err = MatchPlayers()
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	nextEvent(t, stream, events.MatchFound)
+
+	info, err := GetGameDetails(u3, games[0])
+	require.NoError(t, err)
+	assert.Equal(t, "ongoing", info.Status)
+	require.Len(t, info.Players, 2)
+	assert.Equal(t, u1, info.Players[0].ID)
+	assert.Equal(t, u1, info.CurrentTurn)
+	assert.Len(t, info.YourTiles, 7)
+	assert.Equal(t, 2, info.Rules.MaxPlayers)
+
+	_, err = GetMatchmakingEntry(u1)
+	assert.ErrorIs(t, err, ErrNotInMatchmaking)
+	_, err = GetMatchmakingEntry(u2)
+	assert.NoError(t, err)
+	_, err = GetMatchmakingEntry(u4)
+	assert.NoError(t, err)
+}
+
+func TestMatchmaking_MultiWaitsForThreePlayers(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "mm_multi1")
+	u2 := mustCreateUser(t, "mm_multi2")
+	u3 := mustCreateUser(t, "mm_multi3")
+	u4 := mustCreateUser(t, "mm_duelist")
+
+	for _, uid := range []int64{u1, u2} {
+		_, err := JoinMatchmaking(uid, request.JoinMatchmakingRequest{Mode: MatchmakingMulti})
+		require.NoError(t, err)
+	}
+	_, err := JoinMatchmaking(u4, request.JoinMatchmakingRequest{Mode: MatchmakingDuel})
+	require.NoError(t, err)
+
+	games, err := MatchPlayers()
+	require.NoError(t, err)
+	assert.Empty(t, games)
+
+	_, err = JoinMatchmaking(u3, request.JoinMatchmakingRequest{Mode: MatchmakingMulti})
+	require.NoError(t, err)
+	games, err = MatchPlayers()
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+
+	info, err := GetGameDetails(u2, games[0])
+	require.NoError(t, err)
+	require.Len(t, info.Players, 3)
+	assert.Equal(t, []int64{u1, u2, u3}, []int64{info.Players[0].ID, info.Players[1].ID, info.Players[2].ID})
+	_, err = GetGameDetails(u4, games[0])
+	assert.Error(t, err)
+}
@@ -73,7 +73,7 @@ func TestGetMeInfo_WithSubsAndStats(t *testing.T) {
 	_, err = database.Exec(`
         INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, created_at)
         VALUES ($1, $2, $3, $4, NULL, $5::jsonb, $6, now())
-    `, g1, "game ongoing", u.ID, "ongoing", boardJSON, "")
+    `, g1, "game ongoing", u.ID, "ongoing", boardJSON, "[]")
 	require.NoError(t, err)
 
 	// Ended game with winner_username = user
@@ -82,13 +82,13 @@ func TestGetMeInfo_WithSubsAndStats(t *testing.T) {
 	_, err = database.Exec(`
         INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, created_at, winner_username, ended_at)
         VALUES ($1, $2, $3, $4, NULL, $5::jsonb, $6, now(), $7, $8)
-    `, g2, "game ended", u.ID, "ended", boardJSON, "", u.Username, endedAt)
+    `, g2, "game ended", u.ID, "ended", boardJSON, "[]", u.Username, endedAt)
 	require.NoError(t, err)
 
 	// Link user to both games with different scores
-	_, err = database.Exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ($1, $2, $3, $4, $5)`, g1, u.ID, "", 1, 10)
+	_, err = database.Exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ($1, $2, $3, $4, $5)`, g1, u.ID, "[]", 1, 10)
 	require.NoError(t, err)
-	_, err = database.Exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ($1, $2, $3, $4, $5)`, g2, u.ID, "", 1, 20)
+	_, err = database.Exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ($1, $2, $3, $4, $5)`, g2, u.ID, "[]", 1, 20)
 	require.NoError(t, err)
 
 	me, err := GetMeInfo(u.ID)
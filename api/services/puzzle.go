@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
 	"github.com/ZiplEix/scrabble/api/midgame"
 	dbmodels "github.com/ZiplEix/scrabble/api/models/database"
 	"github.com/ZiplEix/scrabble/api/models/request"
@@ -400,51 +401,37 @@ func SubmitPuzzleAttempt(ctx context.Context, playerID int64, req *request.Submi
 		wordsPlayed []resp.PuzzleWordRecord
 	)
 
-	if len(req.Letters) > 0 {
-		var board [15][15]string
-		if err := json.Unmarshal(boardRaw, &board); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal puzzle board: %w", err)
-		}
-
-		if rl, err := resolveBlanks(availableLetters, req.Letters); err == nil {
-			req.Letters = rl
-		}
-
-		if err := applyLetters(&board, req.Letters); err != nil {
+	if len(req.Letters) > 0 || req.Notation != "" {
+		state, err := newPuzzleState(boardRaw, playerID, availableLetters)
+		if err != nil {
 			return nil, err
 		}
-
-		formedWords := extractFormedWords(board, req.Letters)
-		for _, fw := range formedWords {
-			if !word.WordExists(fw.Word) {
-				return nil, fmt.Errorf("mot invalide: %s", fw.Word)
-			}
+		letters, err := notationLetters(nil, state.Board, req.Notation, req.Letters)
+		if err != nil {
+			return nil, err
 		}
 
-		isNew := make(map[Pos]bool, len(req.Letters))
-		isBlank := make(map[Pos]bool, len(req.Letters))
-		boardBlank := map[Pos]bool{}
-		for _, pl := range req.Letters {
-			pos := Pos{pl.X, pl.Y}
-			isNew[pos] = true
-			if pl.Blank {
-				isBlank[pos] = true
-				boardBlank[pos] = true
+		_, res, err := state.ApplyMove(playerID, letters)
+		if err != nil {
+			var invalidWord *engine.InvalidWordError
+			if errors.As(err, &invalidWord) {
+				return nil, fmt.Errorf("mot invalide: %s", invalidWord.Word)
 			}
+			return nil, err
 		}
 
-		score = computeMoveScore(board, req.Letters, boardBlank)
-		wordsPlayed = make([]resp.PuzzleWordRecord, 0, len(formedWords))
-		for _, fw := range formedWords {
+		score = res.Score
+		wordsPlayed = make([]resp.PuzzleWordRecord, 0, len(res.Words))
+		for _, sw := range res.Words {
 			direction := "horizontal"
-			if fw.DY != 0 {
+			if sw.DY != 0 {
 				direction = "vertical"
 			}
 			wordsPlayed = append(wordsPlayed, resp.PuzzleWordRecord{
-				Word:      fw.Word,
-				Position:  fmt.Sprintf("%d,%d", fw.StartX, fw.StartY),
+				Word:      sw.Word,
+				Position:  fmt.Sprintf("%d,%d", sw.StartX, sw.StartY),
 				Direction: direction,
-				Score:     computeWordScore(board, fw, isNew, isBlank),
+				Score:     sw.Score,
 			})
 		}
 	} else {
@@ -590,8 +577,8 @@ func StartPuzzle(ctx context.Context, playerID int64, puzzleID string) (*resp.Pu
 }
 
 // SimulatePuzzleScore simule le score d'un coup sans soumettre la tentative.
-func SimulatePuzzleScore(ctx context.Context, playerID int64, puzzleID string, letters []request.PlacedLetter) (int, error) {
-	if len(letters) == 0 {
+func SimulatePuzzleScore(ctx context.Context, playerID int64, puzzleID string, letters []request.PlacedLetter, notation string) (int, error) {
+	if len(letters) == 0 && notation == "" {
 		return 0, nil
 	}
 
@@ -634,27 +621,15 @@ func SimulatePuzzleScore(ctx context.Context, playerID int64, puzzleID string, l
 		return 0, errors.New("le temps imparti a été dépassé")
 	}
 
-	var board [15][15]string
-	if err := json.Unmarshal(boardRaw, &board); err != nil {
-		return 0, fmt.Errorf("failed to unmarshal puzzle board: %w", err)
-	}
-
-	if rl, err := resolveBlanks(availableLetters, letters); err == nil {
-		letters = rl
-	}
-
-	if err := applyLetters(&board, letters); err != nil {
+	state, err := newPuzzleState(boardRaw, playerID, availableLetters)
+	if err != nil {
 		return 0, err
 	}
-
-	boardBlank := map[Pos]bool{}
-	for _, pl := range letters {
-		if pl.Blank {
-			boardBlank[Pos{pl.X, pl.Y}] = true
-		}
+	if letters, err = notationLetters(nil, state.Board, notation, letters); err != nil {
+		return 0, err
 	}
 
-	return computeMoveScore(board, letters, boardBlank), nil
+	return state.ScoreMove(playerID, letters)
 }
 
 // GetPuzzleLeaderboard retourne le classement du jour pour un puzzle
@@ -774,6 +749,22 @@ func GetPlayerPuzzleStats(ctx context.Context, playerID int64) (*resp.PuzzleStat
 
 // ============= Private helpers =============
 
+// newPuzzleState construit l'état de jeu d'un puzzle : plateau figé, un seul
+// joueur dont c'est le tour, le tirage du puzzle comme rack et un sac vide.
+func newPuzzleState(boardRaw []byte, playerID int64, rack string) (*engine.GameState, error) {
+	state := &engine.GameState{
+		Blanks:  map[Pos]bool{},
+		Racks:   map[int64]word.Tiles{playerID: word.ParseTiles(rack)},
+		Scores:  map[int64]int{playerID: 0},
+		Players: []int64{playerID},
+		Turn:    playerID,
+	}
+	if err := json.Unmarshal(boardRaw, &state.Board); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal puzzle board: %w", err)
+	}
+	return state, nil
+}
+
 // validateAndScorePuzzleAttempt valide les mots et calcule le score total
 func validateAndScorePuzzleAttempt(wordsForSubmit []request.PuzzleWordForSubmit) (int, []resp.PuzzleWordRecord, error) {
 	totalScore := 0
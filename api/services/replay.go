@@ -0,0 +1,380 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// ErrInvalidPly est renvoyée quand le coup demandé n'existe pas dans le rejeu.
+var ErrInvalidPly = errors.New("invalid ply")
+
+// Types de coups propres au rejeu, en plus des types de game_moves.
+const (
+	replayStart = "start"
+	replayEnd   = "end"
+)
+
+// replayGame regroupe ce qu'il faut pour rejouer une partie.
+type replayGame struct {
+	lang     *word.Language
+	layout   *engine.Layout
+	rackSize int
+	players  []int64
+	moves    []storedMove         // coups dans l'ordre, reprises exclues
+	racks    map[int64]word.Tiles // racks actuels (finaux si la partie est terminée)
+	// finalScores est renseigné pour une partie terminée
+	finalScores map[int64]int
+}
+
+// GetGameReplay rejoue la partie depuis son historique et retourne l'état du
+// plateau, des scores, des jokers et des racks après chaque coup (ou après le
+// seul coup ply s'il est fourni). Une partie terminée expose tous les racks,
+// à ses joueurs et à ceux que sa visibilité autorise à la regarder (voir
+// canReadEndedGame) ; une partie en cours n'est visible que de ses joueurs,
+// qui ne voient que leur propre rack.
+func GetGameReplay(viewerID int64, gameID string, ply *int) (*response.GameReplay, error) {
+	replay, _, err := loadReplay(viewerID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if ply != nil {
+		if *ply < 0 || *ply >= len(replay.Plies) {
+			return nil, ErrInvalidPly
+		}
+		replay.Plies = replay.Plies[*ply : *ply+1]
+	}
+	return replay, nil
+}
+
+// loadReplay charge la partie et la rejoue entièrement pour viewerID, avec
+// les règles de visibilité de GetGameReplay. Le coup i de g.moves correspond
+// au coup i+1 du rejeu.
+func loadReplay(viewerID int64, gameID string) (*response.GameReplay, replayGame, error) {
+	var (
+		replay   response.GameReplay
+		rulesRaw []byte
+		g        replayGame
+	)
+	err := database.QueryRow(`
+		SELECT id, name, status, language, variant, ruleset
+		FROM games WHERE id = $1
+	`, gameID).Scan(&replay.GameID, &replay.Name, &replay.Status, &replay.Language, &replay.Variant, &rulesRaw)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, g, errors.New("game not found")
+		}
+		return nil, g, err
+	}
+	ended := gameFinished(replay.Status)
+	rules, err := parseRuleset(rulesRaw)
+	if err != nil {
+		return nil, g, err
+	}
+	g = replayGame{
+		lang:     word.Lang(replay.Language),
+		layout:   layoutOrStandard(replay.Variant),
+		rackSize: rules.RackSize,
+		racks:    map[int64]word.Tiles{},
+	}
+	replay.BoardSize, replay.Premiums = layoutResponse(g.layout)
+
+	rows, err := database.Query(`
+		SELECT gp.player_id, u.username, gp.score, gp.position, gp.rack, u.is_bot, gp.resigned
+		FROM game_players gp
+		JOIN users u ON u.id = gp.player_id
+		WHERE gp.game_id = $1
+		ORDER BY gp.position
+	`, gameID)
+	if err != nil {
+		return nil, g, err
+	}
+	defer rows.Close()
+	scores := map[int64]int{}
+	isPlayer := false
+	for rows.Next() {
+		var (
+			p    response.PlayerInfo
+			rack word.Tiles
+		)
+		if err := rows.Scan(&p.ID, &p.Username, &p.Score, &p.Position, &rack, &p.IsBot, &p.Resigned); err != nil {
+			return nil, g, err
+		}
+		replay.Players = append(replay.Players, p)
+		g.players = append(g.players, p.ID)
+		g.racks[p.ID] = rack
+		scores[p.ID] = p.Score
+		isPlayer = isPlayer || p.ID == viewerID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, g, err
+	}
+	if !ended && !isPlayer {
+		return nil, g, errors.New("unauthorized or game not found")
+	}
+	if ended && !isPlayer {
+		if err := canReadEndedGame(viewerID, gameID); err != nil {
+			return nil, g, err
+		}
+	}
+	if ended {
+		g.finalScores = scores
+	}
+
+	if g.moves, err = loadReplayMoves(gameID); err != nil {
+		return nil, g, err
+	}
+
+	plies := buildReplay(g)
+	for i := range plies {
+		plies[i].Racks = visibleRacks(plies[i].Racks, viewerID, ended)
+	}
+	replay.TotalPlies = len(g.moves)
+	replay.Plies = plies
+	return &replay, g, nil
+}
+
+// loadReplayMoves charge l'historique de la partie, sans les coups annulés
+// par une reprise.
+func loadReplayMoves(gameID string) ([]storedMove, error) {
+	rows, err := database.Query(`
+		SELECT id, player_id, move FROM game_moves
+		WHERE game_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var moves []storedMove
+	for rows.Next() {
+		var (
+			m       storedMove
+			moveRaw []byte
+		)
+		if err := rows.Scan(&m.ID, &m.PlayerID, &moveRaw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(moveRaw, &m.Record); err != nil {
+			return nil, err
+		}
+		if m.Record.Type == "" {
+			m.Record.Type = MoveTypePlay
+		}
+		if m.Record.Status != MoveStatusRetracted {
+			moves = append(moves, m)
+		}
+	}
+	return moves, rows.Err()
+}
+
+// visibleRacks filtre les racks d'un coup : tous pour une partie terminée,
+// seulement celui de viewerID pour une partie en cours.
+func visibleRacks(racks map[int64][]string, viewerID int64, ended bool) map[int64][]string {
+	if ended || racks == nil {
+		return racks
+	}
+	rack, ok := racks[viewerID]
+	if !ok {
+		return nil
+	}
+	return map[int64][]string{viewerID: rack}
+}
+
+// buildReplay rejoue les coups de g et retourne l'état après chacun, précédé
+// de l'état initial et suivi, si la partie est terminée, des scores finaux.
+func buildReplay(g replayGame) []response.ReplayPly {
+	board := g.layout.NewBoard()
+	blanks := map[engine.Pos]bool{}
+	scores := make(map[int64]int, len(g.players))
+	for _, pid := range g.players {
+		scores[pid] = 0
+	}
+	byID := make(map[int64]storedMove, len(g.moves))
+	racks := rebuildRacks(g)
+
+	plies := make([]response.ReplayPly, 0, len(g.moves)+2)
+	snapshot := func(p response.ReplayPly) {
+		p.Ply = len(plies)
+		p.Board = board.Clone()
+		p.Blanks = blankList(blanks)
+		p.Scores = make(map[int64]int, len(scores))
+		for pid, s := range scores {
+			p.Scores[pid] = s
+		}
+		if racks != nil {
+			p.Racks = make(map[int64][]string, len(g.players))
+			for pid, rack := range racks[min(p.Ply, len(racks)-1)] {
+				p.Racks[pid] = rack.Strings()
+			}
+		}
+		plies = append(plies, p)
+	}
+
+	snapshot(response.ReplayPly{Type: replayStart})
+	for _, m := range g.moves {
+		byID[m.ID] = m
+		rec := m.Record
+		p := response.ReplayPly{Type: rec.Type, PlayerID: m.PlayerID}
+
+		switch rec.Type {
+		case MoveTypePlay:
+			before := make(map[engine.Pos]bool, len(blanks))
+			for pos := range blanks {
+				before[pos] = true
+			}
+			if err := engine.ApplyLetters(board, rec.Letters); err != nil {
+				break
+			}
+			p.Word = rec.Word
+			p.Score = rec.Score
+			p.Withdrawn = rec.Status == MoveStatusWithdrawn
+			p.Bonus = rec.Score
+			for _, sw := range engine.ScoreWords(g.lang, g.layout, board, rec.Letters, before) {
+				p.Words = append(p.Words, response.WordScore{Word: sw.Word, Score: sw.Score})
+				p.Bonus -= sw.Score
+			}
+			for _, pl := range rec.Letters {
+				p.Letters = append(p.Letters, response.ReplayTile{X: pl.X, Y: pl.Y, Char: pl.Char, Blank: pl.Blank})
+				if pl.Blank {
+					blanks[engine.Pos{X: pl.X, Y: pl.Y}] = true
+				}
+			}
+			if p.Bonus < 0 {
+				p.Bonus = 0
+			}
+			scores[m.PlayerID] += rec.Score
+		case MoveTypeExchange:
+			p.Count = len(rec.Returned)
+		case MoveTypeChallenge:
+			p.Success = rec.Success
+			p.Penalty = rec.Penalty
+			p.LostTurn = rec.LostTurn
+			if target, ok := byID[rec.TargetMoveID]; ok && rec.Success {
+				for _, pl := range target.Record.Letters {
+					if board.InBounds(pl.X, pl.Y) {
+						board[pl.Y][pl.X] = ""
+					}
+					delete(blanks, engine.Pos{X: pl.X, Y: pl.Y})
+				}
+				scores[target.PlayerID] -= target.Record.Score
+			}
+			scores[m.PlayerID] -= rec.Penalty
+		}
+		snapshot(p)
+	}
+
+	if g.finalScores != nil {
+		for pid, s := range g.finalScores {
+			scores[pid] = s
+		}
+		snapshot(response.ReplayPly{Type: replayEnd})
+	}
+	return plies
+}
+
+// rebuildRacks reconstitue les racks de chaque joueur après chaque coup, en
+// remontant l'historique depuis les racks actuels. Retourne nil si
+// l'historique ne le permet pas (coups enregistrés sans leurs tuiles piochées).
+func rebuildRacks(g replayGame) []map[int64]word.Tiles {
+	current := make(map[int64]word.Tiles, len(g.racks))
+	for pid, rack := range g.racks {
+		current[pid] = append(word.Tiles(nil), rack...)
+	}
+	byID := make(map[int64]storedMove, len(g.moves))
+	for _, m := range g.moves {
+		byID[m.ID] = m
+	}
+
+	racks := make([]map[int64]word.Tiles, len(g.moves)+1)
+	racks[len(g.moves)] = current
+	for i := len(g.moves) - 1; i >= 0; i-- {
+		m := g.moves[i]
+		prev := make(map[int64]word.Tiles, len(current))
+		for pid, rack := range current {
+			prev[pid] = rack
+		}
+		rec := m.Record
+
+		var (
+			rack word.Tiles
+			ok   = true
+		)
+		switch {
+		case rec.Type == MoveTypePlay:
+			rack, ok = unreturn(current[m.PlayerID], rec.Drawn, placedTiles(rec.Letters))
+		case rec.Type == MoveTypeExchange:
+			rack, ok = unreturn(current[m.PlayerID], rec.Drawn, rec.Returned)
+		case rec.Type == MoveTypeResign && len(rec.Returned) > 0:
+			rack = rec.Returned
+		case rec.Type == MoveTypeChallenge && rec.Success:
+			// la contestation avait rendu au joueur ses tuiles posées
+			target, found := byID[rec.TargetMoveID]
+			if !found {
+				return nil
+			}
+			m = target
+			rack, ok = unreturn(current[target.PlayerID], placedTiles(target.Record.Letters), target.Record.Drawn)
+		default:
+			racks[i] = prev
+			current = prev
+			continue
+		}
+		if !ok || len(rack) > g.rackSize {
+			return nil
+		}
+		prev[m.PlayerID] = rack
+		racks[i] = prev
+		current = prev
+	}
+	return racks
+}
+
+// unreturn retire removed du rack et y remet added : l'inverse d'un coup qui
+// a consommé added et pioché removed.
+func unreturn(rack, removed, added word.Tiles) (word.Tiles, bool) {
+	rack = append(word.Tiles(nil), rack...)
+	for _, t := range removed {
+		var ok bool
+		if rack, ok = rack.Remove(t); !ok {
+			return nil, false
+		}
+	}
+	return rack.Concat(added), true
+}
+
+// placedTiles retourne les tuiles du rack consommées par des lettres posées.
+func placedTiles(letters []request.PlacedLetter) word.Tiles {
+	tiles := make(word.Tiles, 0, len(letters))
+	for _, pl := range letters {
+		if pl.Blank {
+			tiles = append(tiles, engine.Blank)
+		} else {
+			tiles = append(tiles, word.Tile(pl.Char))
+		}
+	}
+	return tiles
+}
+
+// blankList retourne les positions des jokers, triées pour un rendu stable.
+func blankList(blanks map[engine.Pos]bool) []response.BoardBlank {
+	list := make([]response.BoardBlank, 0, len(blanks))
+	for pos := range blanks {
+		list = append(list, response.BoardBlank{X: pos.X, Y: pos.Y})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Y != list[j].Y {
+			return list[i].Y < list[j].Y
+		}
+		return list[i].X < list[j].X
+	})
+	return list
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/models/response"
+)
+
+func TestGetGameReplay_EndedGameIsPublic(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "replay_p1")
+	u2 := mustCreateUser(t, "replay_p2")
+	gid, err := createStartedGame(t, u1, "replay", []string{"replay_p2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	require.NoError(t, PassTurn(u2, g))
+	require.NoError(t, ResignGame(u1, g))
+
+	// partie privée terminée : réservée à ses joueurs
+	_, err = GetGameReplay(0, g, nil)
+	assert.ErrorContains(t, err, "unauthorized")
+	_, err = GetGameReplay(u2, g, nil)
+	require.NoError(t, err)
+	require.NoError(t, SetGameVisibility(u1, g, VisibilityPublic, nil))
+
+	// partie publique terminée : visible sans être connecté, racks compris
+	replay, err := GetGameReplay(0, g, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, replay.TotalPlies)
+	require.Len(t, replay.Plies, 5)
+	types := []string{}
+	for _, p := range replay.Plies {
+		types = append(types, p.Type)
+	}
+	assert.Equal(t, []string{"start", MoveTypePlay, MoveTypePass, MoveTypeResign, "end"}, types)
+
+	start := replay.Plies[0]
+	assert.Equal(t, "", start.Board[7][7])
+	assert.ElementsMatch(t, []string{"C", "H", "A", "T", "X", "Y", "Z"}, start.Racks[u1])
+	assert.ElementsMatch(t, []string{"A", "B", "C", "D", "E", "F", "G"}, start.Racks[u2])
+
+	play := replay.Plies[1]
+	assert.Equal(t, u1, play.PlayerID)
+	assert.Equal(t, "A", play.Board[7][7])
+	// C(3)+H(4)+A(1)+T(1) = 9, centre mot compte double
+	assert.Equal(t, 18, play.Score)
+	assert.Equal(t, []response.WordScore{{Word: "CHAT", Score: 18}}, play.Words)
+	assert.Equal(t, 18, play.Scores[u1])
+	assert.ElementsMatch(t, []string{"X", "Y", "Z", "E", "E", "E", "E"}, play.Racks[u1])
+
+	// un seul coup à la demande
+	n := 1
+	one, err := GetGameReplay(0, g, &n)
+	require.NoError(t, err)
+	require.Len(t, one.Plies, 1)
+	assert.Equal(t, 1, one.Plies[0].Ply)
+	n = 5
+	_, err = GetGameReplay(0, g, &n)
+	assert.ErrorIs(t, err, ErrInvalidPly)
+}
+
+func TestGetGameReplay_OngoingGameHidesOpponentRacks(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "replay_live1")
+	u2 := mustCreateUser(t, "replay_live2")
+	outsider := mustCreateUser(t, "replay_outsider")
+	gid, err := createStartedGame(t, u1, "replay", []string{"replay_live2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+
+	_, err = GetGameReplay(outsider, g, nil)
+	require.Error(t, err)
+
+	replay, err := GetGameReplay(u2, g, nil)
+	require.NoError(t, err)
+	require.Len(t, replay.Plies, 2)
+	for _, p := range replay.Plies {
+		assert.NotContains(t, p.Racks, u1)
+		assert.Contains(t, p.Racks, u2)
+	}
+	assert.Equal(t, "A", replay.Plies[1].Board[7][7])
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/lib/pq"
+)
+
+// Visibilités d'une partie pour les spectateurs.
+const (
+	VisibilityPrivate = "private" // joueurs uniquement
+	VisibilityFriends = "friends" // utilisateurs ajoutés en ami par l'un des joueurs
+	VisibilityPublic  = "public"  // tout le monde, connecté ou non
+)
+
+// ErrInvalidVisibility est renvoyée pour une visibilité inconnue.
+var ErrInvalidVisibility = errors.New("invalid visibility")
+
+// ErrNotSpectatable est renvoyée quand la partie n'est pas ouverte au spectateur.
+var ErrNotSpectatable = errors.New("game not found or not open to spectators")
+
+// maxPublicGames borne la liste des parties publiques en cours.
+const maxPublicGames = 50
+
+// validateVisibility vérifie la visibilité demandée (VisibilityPrivate par défaut).
+func validateVisibility(v string) (string, error) {
+	switch v {
+	case "":
+		return VisibilityPrivate, nil
+	case VisibilityPrivate, VisibilityFriends, VisibilityPublic:
+		return v, nil
+	}
+	return "", ErrInvalidVisibility
+}
+
+// canSpectate indique si viewerID (0 si non connecté) peut regarder la
+// partie, et si le chat lui est ouvert. Les joueurs peuvent toujours
+// regarder leur propre partie.
+func canSpectate(viewerID int64, gameID string) (bool, error) {
+	var spectatorChat, allowed bool
+	err := database.QueryRow(`
+		SELECT g.spectator_chat,
+			g.visibility = 'public'
+			OR EXISTS (SELECT 1 FROM game_players gp WHERE gp.game_id = g.id AND gp.player_id = $2)
+			OR (g.visibility = 'friends' AND EXISTS (
+				SELECT 1 FROM game_players gp
+				JOIN user_friends uf ON uf.user_id = gp.player_id
+				WHERE gp.game_id = g.id AND uf.friend_id = $2
+			))
+		FROM games g WHERE g.id = $1
+	`, gameID, viewerID).Scan(&spectatorChat, &allowed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, ErrNotSpectatable
+		}
+		return false, err
+	}
+	if !allowed {
+		return false, ErrNotSpectatable
+	}
+	return spectatorChat, nil
+}
+
+// canReadEndedGame vérifie qu'un non-joueur peut consulter le rejeu, l'export
+// GCG et les tirages d'une partie terminée : sa visibilité doit le lui
+// permettre, comme pour la suivre en direct, sauf pour un administrateur.
+func canReadEndedGame(viewerID int64, gameID string) error {
+	_, err := canSpectate(viewerID, gameID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrNotSpectatable) {
+		return err
+	}
+	var admin bool
+	if err := database.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND role = 'admin')
+	`, viewerID).Scan(&admin); err != nil {
+		return err
+	}
+	if !admin {
+		return errors.New("unauthorized or game not found")
+	}
+	return nil
+}
+
+// CanSpectate vérifie que viewerID peut suivre la partie en direct.
+func CanSpectate(viewerID int64, gameID string) error {
+	_, err := canSpectate(viewerID, gameID)
+	return err
+}
+
+// GetSpectatorView retourne la partie vue par un spectateur : plateau,
+// scores, historique et, si la partie l'autorise, chat en lecture seule.
+// Aucun rack n'est visible, pas même celui du spectateur s'il joue.
+func GetSpectatorView(viewerID int64, gameID string) (*response.SpectatorView, error) {
+	chat, err := canSpectate(viewerID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	game, err := loadGameInfo(gameID, 0)
+	if err != nil {
+		return nil, err
+	}
+	view := &response.SpectatorView{GameInfo: *game}
+	if chat {
+		if view.Messages, err = loadMessages(gameID); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+// ListPublicGames retourne les parties publiques en cours, de la plus
+// récemment jouée à la plus ancienne.
+func ListPublicGames() ([]response.PublicGame, error) {
+	rows, err := database.Query(`
+		SELECT g.id, g.name, g.language, g.variant, g.created_at, g.current_turn,
+			(SELECT COUNT(*) FROM game_moves m WHERE m.game_id = g.id),
+			COALESCE((SELECT MAX(m.created_at) FROM game_moves m WHERE m.game_id = g.id), g.created_at) AS last_activity
+		FROM games g
+		WHERE g.visibility = 'public' AND g.status = 'ongoing'
+		ORDER BY last_activity DESC
+		LIMIT $1
+	`, maxPublicGames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := []response.PublicGame{}
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			g           response.PublicGame
+			currentTurn sql.NullInt64
+		)
+		if err := rows.Scan(&g.ID, &g.Name, &g.Language, &g.Variant, &g.CreatedAt, &currentTurn, &g.MoveCount, &g.LastActivityAt); err != nil {
+			return nil, err
+		}
+		g.CurrentTurn = currentTurn.Int64
+		g.Players = []response.PublicGamePlayer{}
+		index[g.ID] = len(games)
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return games, nil
+	}
+
+	ids := make([]string, 0, len(games))
+	for _, g := range games {
+		ids = append(ids, g.ID)
+	}
+	playerRows, err := database.Query(`
+		SELECT gp.game_id, gp.player_id, u.username, gp.score
+		FROM game_players gp
+		JOIN users u ON u.id = gp.player_id
+		WHERE gp.game_id = ANY($1::uuid[]) AND NOT gp.resigned
+		ORDER BY gp.position
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer playerRows.Close()
+	for playerRows.Next() {
+		var (
+			gameID string
+			p      response.PublicGamePlayer
+		)
+		if err := playerRows.Scan(&gameID, &p.ID, &p.Username, &p.Score); err != nil {
+			return nil, err
+		}
+		g := &games[index[gameID]]
+		g.Players = append(g.Players, p)
+	}
+	return games, playerRows.Err()
+}
+
+// SetGameVisibility change l'accès des spectateurs ; seul le créateur de la
+// partie peut le faire. spectatorChat nil conserve le réglage du chat.
+func SetGameVisibility(userID int64, gameID, visibility string, spectatorChat *bool) error {
+	if visibility == "" {
+		return ErrInvalidVisibility
+	}
+	visibility, err := validateVisibility(visibility)
+	if err != nil {
+		return err
+	}
+	var createdBy int64
+	if err := database.QueryRow(`SELECT created_by FROM games WHERE id = $1`, gameID).Scan(&createdBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("game not found")
+		}
+		return err
+	}
+	if createdBy != userID {
+		return errors.New("unauthorized: you are not the creator of the game")
+	}
+	_, err = database.Exec(`
+		UPDATE games SET visibility = $1, spectator_chat = COALESCE($2, spectator_chat)
+		WHERE id = $3
+	`, visibility, spectatorChat, gameID)
+	if err != nil {
+		return fmt.Errorf("failed to update game visibility: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/events"
+)
+
+func TestSpectate_VisibilityRules(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "spec1")
+	mustCreateUser(t, "spec2")
+	watcher := mustCreateUser(t, "spec3")
+
+	priv, err := createStartedGame(t, u1, "private", []string{"spec2"}, nil)
+	require.NoError(t, err)
+	pub, err := createStartedGameWithOptions(t, u1, "public", []string{"spec2"}, nil, GameOptions{Visibility: VisibilityPublic})
+	require.NoError(t, err)
+	_, err = createStartedGameWithOptions(t, u1, "bad", []string{"spec2"}, nil, GameOptions{Visibility: "secret"})
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+
+	_, err = GetSpectatorView(watcher, priv.String())
+	assert.ErrorIs(t, err, ErrNotSpectatable)
+	view, err := GetSpectatorView(0, pub.String())
+	require.NoError(t, err)
+	assert.Equal(t, VisibilityPublic, view.Visibility)
+
+	games, err := ListPublicGames()
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.Equal(t, pub.String(), games[0].ID)
+	assert.Len(t, games[0].Players, 2)
+
+	// amis : ouvert à ceux qu'un joueur a ajoutés en ami
+	assert.Error(t, SetGameVisibility(watcher, priv.String(), VisibilityFriends, nil))
+	require.NoError(t, SetGameVisibility(u1, priv.String(), VisibilityFriends, nil))
+	_, err = GetSpectatorView(watcher, priv.String())
+	assert.ErrorIs(t, err, ErrNotSpectatable)
+	require.NoError(t, AddFriend(u1, watcher))
+	_, err = GetSpectatorView(watcher, priv.String())
+	assert.NoError(t, err)
+	_, err = GetSpectatorView(0, priv.String())
+	assert.ErrorIs(t, err, ErrNotSpectatable)
+}
+
+func TestSpectate_HidesRacksAndIsReadOnly(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "watch1")
+	u2 := mustCreateUser(t, "watch2")
+	watcher := mustCreateUser(t, "watch3")
+	gid, err := createStartedGameWithOptions(t, u1, "live", []string{"watch2"}, nil, GameOptions{Visibility: VisibilityPublic})
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+
+	stream, unsubscribe := events.SubscribeGame(g)
+	defer unsubscribe()
+
+	// aucune action de jeu n'est permise au spectateur
+	assert.Error(t, PlayMove(g, watcher, chatAtCenterMove()))
+	assert.Error(t, PassTurn(watcher, g))
+	_, err = ExchangeTiles(watcher, g, []string{"A"})
+	assert.Error(t, err)
+	_, err = CreateMessage(watcher, g, "allez !", nil)
+	assert.Error(t, err)
+	assert.Error(t, ResignGame(watcher, g))
+
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	nextEvent(t, stream, events.MovePlayed)
+	_, err = ExchangeTiles(u2, g, []string{"A"})
+	require.NoError(t, err)
+	nextEvent(t, stream, events.Exchange)
+
+	// chat fermé aux spectateurs par défaut
+	_, err = CreateMessage(u1, g, "bien joué", nil)
+	require.NoError(t, err)
+	view, err := GetSpectatorView(watcher, g)
+	require.NoError(t, err)
+	assert.Empty(t, view.Messages)
+	assert.Empty(t, view.YourRack)
+	for _, p := range view.Players {
+		assert.Empty(t, p.Rack)
+	}
+	for _, mv := range view.Moves {
+		assert.NotContains(t, mv.Move, "returned")
+		assert.NotContains(t, mv.Move, "drawn")
+	}
+	assert.Equal(t, 18, view.Players[0].Score)
+
+	open := true
+	require.NoError(t, SetGameVisibility(u1, g, VisibilityPublic, &open))
+	_, err = CreateMessage(u2, g, "merci", nil)
+	require.NoError(t, err)
+	ev := nextEvent(t, stream, events.ChatMessage)
+	assert.Contains(t, string(ev.Data), "merci")
+	view, err = GetSpectatorView(watcher, g)
+	require.NoError(t, err)
+	assert.Len(t, view.Messages, 2)
+}
@@ -30,7 +30,7 @@ func GetLeaderboard(limit int, offset int) (*response.LeaderboardResponse, error
 			u.rating,
 			COUNT(DISTINCT CASE WHEN gp.game_id IS NOT NULL THEN gp.game_id END) as games
 		FROM users u
-		LEFT JOIN game_players gp ON u.id = gp.player_id
+		LEFT JOIN (game_players gp JOIN games g ON gp.game_id = g.id AND g.status <> 'archived') ON u.id = gp.player_id
 		WHERE u.rating > 0
 		GROUP BY u.id, u.username, u.rating
 		ORDER BY u.rating DESC
@@ -87,8 +87,7 @@ func GetUserStats(userID int64) (*response.UserStatsResponse, error) {
 			COUNT(DISTINCT gp.game_id) as games,
 			SUM(CASE WHEN g.winner_username = u.username THEN 1 ELSE 0 END) as wins
 		FROM users u
-		LEFT JOIN game_players gp ON u.id = gp.player_id
-		LEFT JOIN games g ON gp.game_id = g.id
+		LEFT JOIN (game_players gp JOIN games g ON gp.game_id = g.id AND g.status <> 'archived') ON u.id = gp.player_id
 		WHERE u.id = $1
 		GROUP BY u.id, u.username, u.rating
 	`, userID).Scan(&username, &rating, &games, &wins)
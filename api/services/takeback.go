@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/events"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/utils"
+)
+
+// États d'une demande de reprise, stockés sur le mot posé concerné.
+const (
+	TakebackRequested = "requested"
+	TakebackAccepted  = "accepted"
+	TakebackDeclined  = "declined"
+)
+
+var (
+	// ErrTrainingRequiresBot est renvoyée quand le mode entraînement est
+	// demandé pour une partie contre d'autres joueurs que le bot.
+	ErrTrainingRequiresBot = errors.New("training mode requires a game against the bot only")
+	// ErrTakebackAlreadyRequested est renvoyée quand une reprise a déjà été
+	// demandée (et éventuellement refusée) pour ce coup.
+	ErrTakebackAlreadyRequested = errors.New("takeback already requested")
+	// ErrNoTakebackRequest est renvoyée quand aucune reprise n'attend de réponse.
+	ErrNoTakebackRequest = errors.New("no pending takeback request")
+	// ErrOwnTakeback est renvoyée quand l'auteur répond à sa propre demande.
+	ErrOwnTakeback = errors.New("cannot answer your own takeback request")
+)
+
+// takebackInfo est la demande de reprise attachée à un mot posé.
+type takebackInfo struct {
+	Status     string  `json:"status"`
+	ApprovedBy []int64 `json:"approved_by,omitempty"`
+}
+
+// onlyBotOpponents indique si tous les adversaires de userID sont le bot.
+func onlyBotOpponents(playerIDs []int64, userID int64) bool {
+	opponents := 0
+	for _, pid := range playerIDs {
+		if pid == userID {
+			continue
+		}
+		if BotUserID == -1 || pid != BotUserID {
+			return false
+		}
+		opponents++
+	}
+	return opponents > 0
+}
+
+// takebackMoves retourne, dans l'ordre chronologique, les coups à annuler
+// pour reprendre le dernier mot posé par userID. Hors entraînement, ce mot
+// doit être le dernier coup de la partie ; en entraînement, les coups joués
+// depuis par le bot (pose, échange ou passe) sont annulés avec lui.
+func takebackMoves(q gameQuerier, gameID string, userID int64, training bool) ([]storedMove, error) {
+	rows, err := q.Query(`
+		SELECT id, player_id, move FROM game_moves
+		WHERE game_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var moves []storedMove
+	for rows.Next() {
+		var (
+			m       storedMove
+			moveRaw []byte
+		)
+		if err := rows.Scan(&m.ID, &m.PlayerID, &moveRaw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(moveRaw, &m.Record); err != nil {
+			return nil, err
+		}
+		if m.Record.Type == "" {
+			m.Record.Type = MoveTypePlay
+		}
+		if m.Record.Status == MoveStatusWithdrawn || m.Record.Status == MoveStatusRetracted {
+			return nil, engine.ErrNothingToTakeBack
+		}
+
+		if m.PlayerID == userID {
+			if m.Record.Type != MoveTypePlay {
+				return nil, engine.ErrNothingToTakeBack
+			}
+			moves = append(moves, m)
+			slices.Reverse(moves)
+			return moves, rows.Err()
+		}
+		if !training || m.PlayerID != BotUserID {
+			return nil, engine.ErrNothingToTakeBack
+		}
+		switch m.Record.Type {
+		case MoveTypePlay, MoveTypePass, MoveTypeExchange:
+			moves = append(moves, m)
+		default:
+			return nil, engine.ErrCannotTakeBack
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return nil, engine.ErrNothingToTakeBack
+}
+
+// setTakeback enregistre l'état de la demande de reprise d'un mot posé.
+func setTakeback(tx *sql.Tx, moveID int64, tb takebackInfo) error {
+	tbJSON, err := json.Marshal(tb)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE game_moves SET move = jsonb_set(move, '{takeback}', $1::jsonb)
+		WHERE id = $2
+	`, tbJSON, moveID)
+	return err
+}
+
+// applyTakeback annule moves et marque les coups comme retirés par reprise.
+func applyTakeback(tx *sql.Tx, gameID string, state *engine.GameState, moves []storedMove) error {
+	played := make([]engine.PlayedMove, len(moves))
+	for i, m := range moves {
+		played[i] = m.playedMove()
+	}
+	next, err := state.Takeback(played)
+	if err != nil {
+		return err
+	}
+
+	tb := takebackInfo{Status: TakebackAccepted}
+	if moves[0].Record.Takeback != nil {
+		tb.ApprovedBy = moves[0].Record.Takeback.ApprovedBy
+	}
+	if err := setTakeback(tx, moves[0].ID, tb); err != nil {
+		return fmt.Errorf("failed to update takeback: %w", err)
+	}
+	for _, m := range moves {
+		if err := setMoveStatus(tx, m.ID, MoveStatusRetracted); err != nil {
+			return fmt.Errorf("failed to retract move: %w", err)
+		}
+	}
+	return saveGameState(tx, gameID, next)
+}
+
+// lockGameForTakeback verrouille la partie et charge son état.
+func lockGameForTakeback(tx *sql.Tx, gameID string) (*engine.GameState, bool, error) {
+	var training bool
+	if err := tx.QueryRow(
+		`SELECT training FROM games WHERE id = $1 FOR UPDATE`, gameID,
+	).Scan(&training); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, errors.New("game not found")
+		}
+		return nil, false, err
+	}
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return nil, false, err
+	}
+	if state.Ended {
+		return nil, false, engine.ErrGameEnded
+	}
+	return state, training, nil
+}
+
+// RequestTakeback demande l'annulation du dernier mot posé par userID, tant
+// que le joueur suivant n'a pas joué. Les adversaires doivent l'accepter ;
+// en mode entraînement, la reprise est accordée immédiatement, coups du bot
+// compris. Hors entraînement, le bot refuse les reprises : la demande est
+// aussitôt rejetée. Retourne true si le coup a été annulé.
+func RequestTakeback(userID int64, gameID string) (bool, error) {
+	ctx := context.Background()
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			logger.Error(context.Background(), "rollback failed", "error", rbErr, "game_id", gameID)
+		}
+	}()
+
+	state, training, err := lockGameForTakeback(tx, gameID)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(state.Players, userID) {
+		return false, engine.ErrNotInGame
+	}
+	training = training && onlyBotOpponents(state.Players, userID)
+
+	moves, err := takebackMoves(tx, gameID, userID, training)
+	if err != nil {
+		return false, err
+	}
+	if moves[0].Record.Takeback != nil {
+		return false, ErrTakebackAlreadyRequested
+	}
+
+	// Hors entraînement, le bot refuse les reprises
+	tb := takebackInfo{Status: TakebackRequested}
+	if !training && userID != BotUserID && slices.Contains(state.Players, BotUserID) {
+		tb.Status = TakebackDeclined
+	}
+	moves[0].Record.Takeback = &tb
+
+	applied := training
+	if applied {
+		err = applyTakeback(tx, gameID, state, moves)
+	} else {
+		err = setTakeback(tx, moves[0].ID, tb)
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	switch {
+	case applied:
+		publishGameEvent(gameID, events.TakebackAnswered, takebackEvent{PlayerID: userID, Status: TakebackAccepted, Applied: true, NextTurn: userID})
+	case tb.Status == TakebackDeclined:
+		publishGameEvent(gameID, events.TakebackAnswered, takebackEvent{PlayerID: userID, Status: TakebackDeclined, AnsweredBy: BotUserID})
+	default:
+		publishGameEvent(gameID, events.TakebackRequested, takebackEvent{PlayerID: userID, Status: TakebackRequested})
+	}
+	if !applied && tb.Status == TakebackRequested {
+		notifyTakeback(gameID, userID, state.Players, "Demande de reprise", "%s demande à reprendre son dernier coup dans %s")
+	}
+	return applied, nil
+}
+
+// RespondTakeback accepte ou refuse la demande de reprise en attente. Le coup
+// est annulé quand tous les adversaires de son auteur l'ont acceptée ; un
+// seul refus suffit à la rejeter.
+func RespondTakeback(userID int64, gameID string, accept bool) error {
+	ctx := context.Background()
+	tx, err := database.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			logger.Error(context.Background(), "rollback failed", "error", rbErr, "game_id", gameID)
+		}
+	}()
+
+	state, _, err := lockGameForTakeback(tx, gameID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(state.Players, userID) {
+		return engine.ErrNotInGame
+	}
+
+	var authorID int64
+	err = tx.QueryRow(`
+		SELECT player_id FROM game_moves
+		WHERE game_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, gameID).Scan(&authorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoTakebackRequest
+		}
+		return err
+	}
+	if authorID == userID {
+		return ErrOwnTakeback
+	}
+	moves, err := takebackMoves(tx, gameID, authorID, false)
+	if errors.Is(err, engine.ErrNothingToTakeBack) {
+		return ErrNoTakebackRequest
+	}
+	if err != nil {
+		return err
+	}
+	tb := moves[0].Record.Takeback
+	if tb == nil || tb.Status != TakebackRequested {
+		return ErrNoTakebackRequest
+	}
+
+	applied := false
+	if !accept {
+		tb.Status = TakebackDeclined
+		err = setTakeback(tx, moves[0].ID, *tb)
+	} else {
+		if !slices.Contains(tb.ApprovedBy, userID) {
+			tb.ApprovedBy = append(tb.ApprovedBy, userID)
+		}
+		if applied = takebackApproved(state.Players, authorID, tb.ApprovedBy); applied {
+			err = applyTakeback(tx, gameID, state, moves)
+		} else {
+			err = setTakeback(tx, moves[0].ID, *tb)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	answered := takebackEvent{PlayerID: authorID, Status: TakebackAccepted, AnsweredBy: userID, Applied: applied}
+	if !accept {
+		answered.Status = TakebackDeclined
+	}
+	if applied {
+		answered.NextTurn = authorID
+	}
+	publishGameEvent(gameID, events.TakebackAnswered, answered)
+
+	if !accept {
+		notifyTakeback(gameID, userID, []int64{authorID}, "Reprise refusée", "%s a refusé ta demande de reprise dans %s")
+	} else if applied {
+		notifyTakeback(gameID, userID, []int64{authorID}, "Reprise acceptée", "%s a accepté ta demande de reprise dans %s, c'est à toi de jouer !")
+	}
+	return nil
+}
+
+// takebackApproved indique si tous les adversaires de authorID ont accepté.
+func takebackApproved(players []int64, authorID int64, approvedBy []int64) bool {
+	for _, pid := range players {
+		if pid != authorID && !slices.Contains(approvedBy, pid) {
+			return false
+		}
+	}
+	return true
+}
+
+// notifyTakeback notifie recipients (hors fromID et le bot) d'un événement de
+// reprise. format reçoit le nom de fromID et celui de la partie.
+func notifyTakeback(gameID string, fromID int64, recipients []int64, title, format string) {
+	var username, gameName string
+	if err := database.QueryRow(`SELECT username FROM users WHERE id = $1`, fromID).Scan(&username); err != nil {
+		username = "Un joueur"
+	}
+	if err := database.QueryRow(`SELECT name FROM games WHERE id = $1`, gameID).Scan(&gameName); err != nil {
+		gameName = "une partie"
+	}
+	for _, pid := range recipients {
+		if pid == fromID || pid == BotUserID {
+			continue
+		}
+		_ = utils.SendNotificationToUserByID(pid, utils.NotificationPayload{
+			Title: title,
+			Body:  fmt.Sprintf(format, username, gameName),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID),
+		})
+	}
+}
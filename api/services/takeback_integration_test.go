@@ -0,0 +1,170 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/request"
+)
+
+func chatAtCenterMove() request.PlayMoveRequest {
+	return request.PlayMoveRequest{
+		Letters: []request.PlacedLetter{
+			{X: 5, Y: 7, Char: "C"},
+			{X: 6, Y: 7, Char: "H"},
+			{X: 7, Y: 7, Char: "A"},
+			{X: 8, Y: 7, Char: "T"},
+		},
+	}
+}
+
+func TestTakeback_AcceptedRestoresState(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "takeback_author")
+	u2 := mustCreateUser(t, "takeback_opponent")
+	gid, err := createStartedGame(t, u1, "takeback", []string{"takeback_opponent"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setGameTurnAndBag(t, g, u1, "EEEEEE")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+
+	applied, err := RequestTakeback(u1, g)
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	_, err = RequestTakeback(u1, g)
+	assert.ErrorIs(t, err, ErrTakebackAlreadyRequested)
+	assert.ErrorIs(t, RespondTakeback(u1, g, true), ErrOwnTakeback)
+
+	require.NoError(t, RespondTakeback(u2, g, true))
+
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, u1, info.CurrentTurn)
+	assert.Equal(t, 6, info.RemainingLetters)
+	assert.ElementsMatch(t, []string{"C", "H", "A", "T", "X", "Y", "Z"}, info.YourTiles)
+	board, err := LoadBoard(g)
+	require.NoError(t, err)
+	assert.Equal(t, "", board[7][7])
+	for _, p := range info.Players {
+		assert.Equal(t, 0, p.Score)
+	}
+	require.Len(t, info.Moves, 1)
+	mv := info.Moves[0].Move.(map[string]any)
+	assert.Equal(t, MoveStatusRetracted, mv["status"])
+	assert.Equal(t, TakebackAccepted, mv["takeback"].(map[string]any)["status"])
+
+	// le coup peut être rejoué
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+}
+
+func TestTakeback_DeclinedOrTooLate(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "takeback_decl1")
+	u2 := mustCreateUser(t, "takeback_decl2")
+	gid, err := createStartedGame(t, u1, "takeback", []string{"takeback_decl2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	_, err = RequestTakeback(u1, g)
+	require.NoError(t, err)
+	require.NoError(t, RespondTakeback(u2, g, false))
+	assert.ErrorIs(t, RespondTakeback(u2, g, true), ErrNoTakebackRequest)
+
+	info, err := GetGameDetails(u2, g)
+	require.NoError(t, err)
+	assert.Equal(t, u2, info.CurrentTurn)
+	board, err := LoadBoard(g)
+	require.NoError(t, err)
+	assert.Equal(t, "A", board[7][7])
+
+	// une fois le coup suivant joué, la reprise n'est plus possible
+	require.NoError(t, PassTurn(u2, g))
+	_, err = RequestTakeback(u1, g)
+	assert.ErrorIs(t, err, engine.ErrNothingToTakeBack)
+	_, err = RequestTakeback(u2, g)
+	assert.ErrorIs(t, err, engine.ErrNothingToTakeBack)
+}
+
+func TestTakeback_TrainingAgainstBot(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "takeback_trainee")
+	_ = mustCreateUser(t, "takeback_friend")
+	bot := mustCreateUser(t, "takeback_bot")
+	prevBot := BotUserID
+	t.Cleanup(func() { BotUserID = prevBot })
+	BotUserID = bot
+
+	_, err := createStartedGameWithOptions(t, u1, "training", []string{"takeback_friend"}, nil, GameOptions{Training: true})
+	assert.ErrorIs(t, err, ErrTrainingRequiresBot)
+
+	gid, err := createStartedGameWithOptions(t, u1, "training", []string{"takeback_bot"}, nil, GameOptions{Training: true})
+	require.NoError(t, err)
+	g := gid.String()
+
+	// le bot est désactivé le temps de jouer ses coups à la main
+	BotUserID = -1
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	require.NoError(t, PassTurn(bot, g))
+	BotUserID = bot
+
+	applied, err := RequestTakeback(u1, g)
+	require.NoError(t, err)
+	assert.True(t, applied)
+
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.True(t, info.Training)
+	assert.Equal(t, u1, info.CurrentTurn)
+	assert.Equal(t, 0, info.PassCount)
+	board, err := LoadBoard(g)
+	require.NoError(t, err)
+	assert.Equal(t, "", board[7][7])
+	require.Len(t, info.Moves, 2)
+	for _, m := range info.Moves {
+		assert.Equal(t, MoveStatusRetracted, m.Move.(map[string]any)["status"])
+	}
+}
+
+func TestTakeback_BotDeclinesOutsideTraining(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "takeback_rated")
+	bot := mustCreateUser(t, "takeback_rated_bot")
+	prevBot := BotUserID
+	t.Cleanup(func() { BotUserID = prevBot })
+	BotUserID = bot
+
+	gid, err := createStartedGame(t, u1, "no training", []string{"takeback_rated_bot"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+
+	// le bot est désactivé pour qu'il ne réponde pas au coup
+	BotUserID = -1
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+	BotUserID = bot
+
+	applied, err := RequestTakeback(u1, g)
+	require.NoError(t, err)
+	assert.False(t, applied)
+	_, err = RequestTakeback(u1, g)
+	assert.ErrorIs(t, err, ErrTakebackAlreadyRequested)
+
+	info, err := GetGameDetails(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, bot, info.CurrentTurn)
+	board, err := LoadBoard(g)
+	require.NoError(t, err)
+	assert.Equal(t, "A", board[7][7])
+	require.Len(t, info.Moves, 1)
+	mv := info.Moves[0].Move.(map[string]any)
+	assert.Equal(t, TakebackDeclined, mv["takeback"].(map[string]any)["status"])
+}
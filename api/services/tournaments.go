@@ -0,0 +1,1141 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/pkg/logger"
+	"github.com/ZiplEix/scrabble/api/tournament"
+	"github.com/ZiplEix/scrabble/api/utils"
+	"github.com/ZiplEix/scrabble/api/word"
+	"github.com/google/uuid"
+)
+
+// Statuts d'un tournoi.
+const (
+	TournamentRegistration = "registration"
+	TournamentRunning      = "running"
+	TournamentFinished     = "finished"
+	TournamentCancelled    = "cancelled" // fenêtre d'inscription close avec moins de deux inscrits
+)
+
+// Résultats d'un appariement de tournoi.
+const (
+	PairingPending = "pending"
+	PairingPlayed  = "played"
+	PairingBye     = "bye"
+	PairingForfeit = "forfeit"
+)
+
+// organizerForfeitReason est la cause enregistrée pour un forfait prononcé
+// par l'organisateur d'un tournoi.
+const organizerForfeitReason = "organizer"
+
+// maxTournamentRounds borne le nombre de rondes d'un tournoi.
+const maxTournamentRounds = 50
+
+var (
+	ErrInvalidTournamentFormat    = errors.New("invalid tournament format")
+	ErrInvalidTournamentRounds    = errors.New("invalid tournament rounds")
+	ErrInvalidRegistrationWindow  = errors.New("invalid registration window")
+	ErrTournamentNotFound         = errors.New("tournament not found")
+	ErrRegistrationClosed         = errors.New("tournament registration is closed")
+	ErrAlreadyRegistered          = errors.New("already registered in tournament")
+	ErrNotRegistered              = errors.New("not registered in tournament")
+	ErrTournamentNotRunning       = errors.New("tournament is not running")
+	ErrNotTournamentManager       = errors.New("unauthorized: only the organizer or an admin can manage this tournament")
+	ErrTournamentNotEnoughPlayers = errors.New("not enough players to start the tournament")
+	ErrInvalidByeRound            = errors.New("invalid bye round")
+	ErrAlreadyPaired              = errors.New("player already paired in this round")
+	ErrByeNotFound                = errors.New("bye not found")
+	ErrNoPendingPairing           = errors.New("no pending game for player in current round")
+)
+
+// CreateTournament crée un tournoi organisé par userID et ouvre sa fenêtre
+// d'inscription. Chaque partie oppose deux joueurs.
+func CreateTournament(userID int64, req request.CreateTournamentRequest) (*response.TournamentDetails, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("tournament name is required")
+	}
+	if !tournament.ValidFormat(req.Format) {
+		return nil, ErrInvalidTournamentFormat
+	}
+	if req.Rounds < 0 || req.Rounds > maxTournamentRounds || (req.Format == tournament.Swiss && req.Rounds == 0) {
+		return nil, ErrInvalidTournamentRounds
+	}
+	lang := word.French
+	if req.Language != "" {
+		l, ok := word.GetLanguage(req.Language)
+		if !ok || !l.Available() {
+			return nil, ErrUnsupportedLanguage
+		}
+		lang = l
+	}
+	layout, ok := engine.LayoutByName(req.Variant)
+	if !ok {
+		return nil, ErrInvalidVariant
+	}
+	opensAt := time.Now()
+	if req.RegistrationOpensAt != nil {
+		opensAt = *req.RegistrationOpensAt
+	}
+	if req.RegistrationClosesAt.IsZero() || !req.RegistrationClosesAt.After(opensAt) || !req.RegistrationClosesAt.After(time.Now()) {
+		return nil, ErrInvalidRegistrationWindow
+	}
+
+	var overrides request.GameRules
+	if req.Rules != nil {
+		overrides = *req.Rules
+	}
+	players := 2
+	overrides.MaxPlayers = &players
+	rules, err := buildRuleset(&overrides, lang)
+	if err != nil {
+		return nil, err
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	_, err = database.Exec(`
+		INSERT INTO tournaments (id, name, format, rounds, language, variant, ruleset, registration_opens_at, registration_closes_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, id, name, req.Format, req.Rounds, lang.Code, layout.Name, rulesJSON, opensAt.UTC(), req.RegistrationClosesAt.UTC(), userID)
+	if err != nil {
+		return nil, err
+	}
+	return GetTournament(userID, id.String())
+}
+
+// tournamentColumns sont les colonnes lues par scanTournament.
+const tournamentColumns = `
+	t.id, t.name, t.format, t.rounds, t.current_round, t.language, t.variant, t.ruleset, t.status,
+	t.registration_opens_at, t.registration_closes_at, u.username, t.created_at, t.started_at, t.ended_at,
+	(SELECT COUNT(*) FROM tournament_players tp WHERE tp.tournament_id = t.id AND tp.withdrawn_at IS NULL),
+	EXISTS (SELECT 1 FROM tournament_players tp WHERE tp.tournament_id = t.id AND tp.user_id = $1)`
+
+func scanTournament(row interface{ Scan(...any) error }) (*response.Tournament, error) {
+	var (
+		t                  response.Tournament
+		rawRules           []byte
+		startedAt, endedAt sql.NullTime
+	)
+	err := row.Scan(&t.ID, &t.Name, &t.Format, &t.Rounds, &t.CurrentRound, &t.Language, &t.Variant, &rawRules, &t.Status,
+		&t.RegistrationOpensAt, &t.RegistrationClosesAt, &t.Organizer, &t.CreatedAt, &startedAt, &endedAt,
+		&t.PlayerCount, &t.Registered)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseRuleset(rawRules)
+	if err != nil {
+		return nil, err
+	}
+	t.Rules = gameRulesResponse(rules)
+	if startedAt.Valid {
+		t.StartedAt = &startedAt.Time
+	}
+	if endedAt.Valid {
+		t.EndedAt = &endedAt.Time
+	}
+	return &t, nil
+}
+
+// ListTournaments retourne les tournois à venir, en cours et récents : ceux
+// qui acceptent des inscriptions d'abord, puis par date de création.
+func ListTournaments(viewerID int64) ([]response.Tournament, error) {
+	rows, err := database.Query(`
+		SELECT `+tournamentColumns+`
+		FROM tournaments t
+		JOIN users u ON u.id = t.created_by
+		ORDER BY t.status = 'registration' DESC, t.status = 'running' DESC, t.created_at DESC
+		LIMIT 50
+	`, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := []response.Tournament{}
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, *t)
+	}
+	return tournaments, rows.Err()
+}
+
+// GetTournament retourne un tournoi avec ses joueurs, ses appariements et son
+// classement.
+func GetTournament(viewerID int64, tournamentID string) (*response.TournamentDetails, error) {
+	if _, err := uuid.Parse(tournamentID); err != nil {
+		return nil, ErrTournamentNotFound
+	}
+	t, err := scanTournament(database.QueryRow(`
+		SELECT `+tournamentColumns+`
+		FROM tournaments t
+		JOIN users u ON u.id = t.created_by
+		WHERE t.id = $2
+	`, viewerID, tournamentID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, err
+	}
+	details := &response.TournamentDetails{Tournament: *t}
+	if details.CanManage, err = canManageTournament(database.DB, viewerID, tournamentID); err != nil {
+		return nil, err
+	}
+
+	roster, err := loadTournamentRoster(database.DB, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	names := map[int64]string{}
+	withdrawn := map[int64]bool{}
+	details.Players = make([]response.TournamentPlayer, 0, len(roster))
+	for _, p := range roster {
+		names[p.ID] = p.Username
+		withdrawn[p.ID] = p.Withdrawn
+		details.Players = append(details.Players, response.TournamentPlayer{
+			UserID: p.ID, Username: p.Username, Seed: p.Seed, Withdrawn: p.Withdrawn,
+		})
+	}
+
+	pairings, err := loadTournamentPairings(database.DB, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	details.Pairings = make([]response.TournamentPairing, 0, len(pairings))
+	var results []tournament.Result
+	for _, p := range pairings {
+		rp := response.TournamentPairing{
+			Round: p.Round, Player1ID: p.Player1, Player1: names[p.Player1],
+			Result: p.Result, Score1: p.Score1, Score2: p.Score2,
+		}
+		if p.Player2 != tournament.Bye {
+			rp.Player2ID = &p.Player2
+			rp.Player2 = names[p.Player2]
+		}
+		if p.GameID.Valid {
+			rp.GameID = &p.GameID.String
+		}
+		if p.Winner != 0 {
+			rp.WinnerID = &p.Winner
+		}
+		details.Pairings = append(details.Pairings, rp)
+		if p.Result != PairingPending {
+			results = append(results, p.result())
+		}
+	}
+
+	details.Standings = make([]response.TournamentStanding, 0, len(roster))
+	for i, s := range tournament.Standings(roster.ids(), results) {
+		details.Standings = append(details.Standings, response.TournamentStanding{
+			Rank: i + 1, UserID: s.PlayerID, Username: names[s.PlayerID],
+			Wins: s.Wins, Losses: s.Losses, Spread: s.Spread, Played: s.Played, Byes: s.Byes,
+			Withdrawn: withdrawn[s.PlayerID],
+		})
+	}
+	return details, nil
+}
+
+// tournamentPlayer est un inscrit d'un tournoi.
+type tournamentPlayer struct {
+	ID        int64
+	Username  string
+	Seed      int
+	Withdrawn bool
+}
+
+// tournamentRoster liste les inscrits dans l'ordre des têtes de série.
+type tournamentRoster []tournamentPlayer
+
+func (r tournamentRoster) ids() []int64 {
+	ids := make([]int64, 0, len(r))
+	for _, p := range r {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func loadTournamentRoster(q gameQuerier, tournamentID string) (tournamentRoster, error) {
+	rows, err := q.Query(`
+		SELECT tp.user_id, u.username, tp.seed, tp.withdrawn_at IS NOT NULL
+		FROM tournament_players tp
+		JOIN users u ON u.id = tp.user_id
+		WHERE tp.tournament_id = $1
+		ORDER BY tp.seed
+	`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roster tournamentRoster
+	for rows.Next() {
+		var p tournamentPlayer
+		if err := rows.Scan(&p.ID, &p.Username, &p.Seed, &p.Withdrawn); err != nil {
+			return nil, err
+		}
+		roster = append(roster, p)
+	}
+	return roster, rows.Err()
+}
+
+// tournamentPairing est un appariement stocké ; Player2 vaut tournament.Bye
+// pour un bye.
+type tournamentPairing struct {
+	ID               int64
+	Round            int
+	Player1, Player2 int64
+	GameID           sql.NullString
+	Result           string
+	Score1, Score2   int
+	Winner           int64
+}
+
+func (p tournamentPairing) result() tournament.Result {
+	return tournament.Result{
+		Player1: p.Player1, Player2: p.Player2,
+		Score1: p.Score1, Score2: p.Score2,
+		Winner:  p.Winner,
+		Forfeit: p.Result == PairingForfeit,
+	}
+}
+
+func loadTournamentPairings(q gameQuerier, tournamentID string) ([]tournamentPairing, error) {
+	rows, err := q.Query(`
+		SELECT id, round, player1_id, COALESCE(player2_id, 0), game_id, result, score1, score2, COALESCE(winner_id, 0)
+		FROM tournament_pairings
+		WHERE tournament_id = $1
+		ORDER BY round, id
+	`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairings []tournamentPairing
+	for rows.Next() {
+		var p tournamentPairing
+		if err := rows.Scan(&p.ID, &p.Round, &p.Player1, &p.Player2, &p.GameID, &p.Result, &p.Score1, &p.Score2, &p.Winner); err != nil {
+			return nil, err
+		}
+		pairings = append(pairings, p)
+	}
+	return pairings, rows.Err()
+}
+
+// canManageTournament indique si userID organise le tournoi ou est administrateur.
+func canManageTournament(q gameQuerier, userID int64, tournamentID string) (bool, error) {
+	var ok bool
+	err := q.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM tournaments WHERE id = $1 AND created_by = $2)
+			OR EXISTS (SELECT 1 FROM users WHERE id = $2 AND role = 'admin')
+	`, tournamentID, userID).Scan(&ok)
+	return ok, err
+}
+
+// lockTournament verrouille un tournoi et retourne son statut, sa ronde
+// courante et son nombre de rondes.
+func lockTournament(tx *sql.Tx, tournamentID string) (status string, current, rounds int, err error) {
+	if _, err := uuid.Parse(tournamentID); err != nil {
+		return "", 0, 0, ErrTournamentNotFound
+	}
+	err = tx.QueryRow(`
+		SELECT status, current_round, rounds FROM tournaments WHERE id = $1 FOR UPDATE
+	`, tournamentID).Scan(&status, &current, &rounds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, 0, ErrTournamentNotFound
+	}
+	return status, current, rounds, err
+}
+
+// RegisterForTournament inscrit userID pendant la fenêtre d'inscription.
+func RegisterForTournament(userID int64, tournamentID string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	status, _, _, err := lockTournament(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	var open bool
+	if err := tx.QueryRow(`
+		SELECT now() >= registration_opens_at AND now() < registration_closes_at FROM tournaments WHERE id = $1
+	`, tournamentID).Scan(&open); err != nil {
+		return err
+	}
+	if status != TournamentRegistration || !open {
+		return ErrRegistrationClosed
+	}
+	res, err := tx.Exec(`
+		INSERT INTO tournament_players (tournament_id, user_id, seed)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(seed), 0) + 1 FROM tournament_players WHERE tournament_id = $1))
+		ON CONFLICT (tournament_id, user_id) DO NOTHING
+	`, tournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlreadyRegistered
+	}
+	return tx.Commit()
+}
+
+// UnregisterFromTournament désinscrit userID tant que le tournoi n'a pas commencé.
+func UnregisterFromTournament(userID int64, tournamentID string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	status, _, _, err := lockTournament(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if status != TournamentRegistration {
+		return ErrRegistrationClosed
+	}
+	if err := unregister(tx, tournamentID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func unregister(tx *sql.Tx, tournamentID string, userID int64) error {
+	res, err := tx.Exec(`DELETE FROM tournament_players WHERE tournament_id = $1 AND user_id = $2`, tournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotRegistered
+	}
+	// les byes attribués d'avance n'ont plus lieu d'être
+	_, err = tx.Exec(`DELETE FROM tournament_pairings WHERE tournament_id = $1 AND player1_id = $2`, tournamentID, userID)
+	return err
+}
+
+// StartTournament clôt les inscriptions et lance la première ronde, à la
+// demande de l'organisateur ou d'un administrateur.
+func StartTournament(actorID int64, tournamentID string) error {
+	ok, err := canManageTournament(database.DB, actorID, tournamentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotTournamentManager
+	}
+	return startTournament(tournamentID)
+}
+
+func startTournament(tournamentID string) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	status, _, rounds, err := lockTournament(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if status != TournamentRegistration {
+		return ErrRegistrationClosed
+	}
+	var (
+		format  string
+		players int
+	)
+	if err := tx.QueryRow(`
+		SELECT t.format, (SELECT COUNT(*) FROM tournament_players WHERE tournament_id = t.id)
+		FROM tournaments t WHERE t.id = $1
+	`, tournamentID).Scan(&format, &players); err != nil {
+		return err
+	}
+	if players < 2 {
+		return ErrTournamentNotEnoughPlayers
+	}
+	if format == tournament.RoundRobin && rounds == 0 {
+		rounds = tournament.RoundRobinRounds(players)
+	}
+	if _, err := tx.Exec(`
+		UPDATE tournaments SET status = 'running', rounds = $1, started_at = now() WHERE id = $2
+	`, rounds, tournamentID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return advanceTournament(tournamentID)
+}
+
+// StartDueTournaments lance les tournois dont la fenêtre d'inscription est
+// close ; ceux qui ont moins de deux inscrits sont annulés. Elle retourne les
+// tournois lancés.
+func StartDueTournaments() ([]string, error) {
+	rows, err := database.Query(`
+		SELECT id FROM tournaments WHERE status = 'registration' AND registration_closes_at <= now()
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var due []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var started []string
+	for _, id := range due {
+		err := startTournament(id)
+		switch {
+		case err == nil:
+			started = append(started, id)
+		case errors.Is(err, ErrTournamentNotEnoughPlayers):
+			if _, err := database.Exec(`
+				UPDATE tournaments SET status = 'cancelled', ended_at = now() WHERE id = $1 AND status = 'registration'
+			`, id); err != nil {
+				logger.Error(context.Background(), "tournaments: failed to cancel tournament", "error", err, "tournament_id", id)
+			}
+		case !errors.Is(err, ErrRegistrationClosed):
+			logger.Error(context.Background(), "tournaments: failed to start tournament", "error", err, "tournament_id", id)
+		}
+	}
+	return started, nil
+}
+
+// advanceTournament passe aux rondes suivantes tant que la ronde courante est
+// terminée : elle apparie la ronde suivante puis crée ses parties, ou clôt
+// le tournoi après la dernière ronde. Elle peut être appelée à tout moment :
+// sans ronde terminée, elle se contente de créer les parties manquantes.
+func advanceTournament(tournamentID string) error {
+	for {
+		advanced, finished, err := pairNextRound(tournamentID)
+		if err != nil {
+			return err
+		}
+		if finished {
+			notifyTournamentFinished(tournamentID)
+			return nil
+		}
+		if err := createRoundGames(tournamentID); err != nil {
+			return err
+		}
+		if !advanced {
+			return nil
+		}
+	}
+}
+
+// pairNextRound apparie la ronde suivante si la ronde courante est terminée,
+// ou clôt le tournoi si c'était la dernière.
+func pairNextRound(tournamentID string) (advanced, finished bool, err error) {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return false, false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	status, current, rounds, err := lockTournament(tx, tournamentID)
+	if err != nil || status != TournamentRunning {
+		return false, false, err
+	}
+	var pending int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM tournament_pairings WHERE tournament_id = $1 AND round = $2 AND result = 'pending'
+	`, tournamentID, current).Scan(&pending); err != nil {
+		return false, false, err
+	}
+	if pending > 0 {
+		return false, false, nil
+	}
+
+	if current >= rounds {
+		if _, err := tx.Exec(`
+			UPDATE tournaments SET status = 'finished', ended_at = now() WHERE id = $1
+		`, tournamentID); err != nil {
+			return false, false, err
+		}
+		return false, true, tx.Commit()
+	}
+
+	next := current + 1
+	var format string
+	if err := tx.QueryRow(`SELECT format FROM tournaments WHERE id = $1`, tournamentID).Scan(&format); err != nil {
+		return false, false, err
+	}
+	roster, err := loadTournamentRoster(tx, tournamentID)
+	if err != nil {
+		return false, false, err
+	}
+	pairings, err := loadTournamentPairings(tx, tournamentID)
+	if err != nil {
+		return false, false, err
+	}
+
+	// joueurs disponibles : ni retirés, ni exemptés d'avance pour cette ronde
+	available := map[int64]bool{}
+	for _, p := range roster {
+		available[p.ID] = !p.Withdrawn
+	}
+	var results []tournament.Result
+	for _, p := range pairings {
+		if p.Round == next {
+			available[p.Player1] = false
+			available[p.Player2] = false
+		} else if p.Result != PairingPending {
+			results = append(results, p.result())
+		}
+	}
+
+	var round []tournament.Pairing
+	switch format {
+	case tournament.RoundRobin:
+		// le calendrier reste celui des inscrits de départ : l'adversaire d'un
+		// joueur retiré ou exempté est exempt
+		for _, p := range tournament.RoundRobinRound(roster.ids(), next) {
+			switch {
+			case available[p.Player1] && available[p.Player2]:
+				round = append(round, p)
+			case available[p.Player1]:
+				round = append(round, tournament.Pairing{Player1: p.Player1, Player2: tournament.Bye})
+			case available[p.Player2]:
+				round = append(round, tournament.Pairing{Player1: p.Player2, Player2: tournament.Bye})
+			}
+		}
+	default:
+		var standings []tournament.Standing
+		for _, s := range tournament.Standings(roster.ids(), results) {
+			if available[s.PlayerID] {
+				standings = append(standings, s)
+			}
+		}
+		round = tournament.SwissRound(standings, results)
+	}
+
+	for _, p := range round {
+		if p.Player2 == tournament.Bye {
+			_, err = tx.Exec(`
+				INSERT INTO tournament_pairings (tournament_id, round, player1_id, result, winner_id)
+				VALUES ($1, $2, $3, 'bye', $3)
+			`, tournamentID, next, p.Player1)
+		} else {
+			_, err = tx.Exec(`
+				INSERT INTO tournament_pairings (tournament_id, round, player1_id, player2_id)
+				VALUES ($1, $2, $3, $4)
+			`, tournamentID, next, p.Player1, p.Player2)
+		}
+		if err != nil {
+			return false, false, err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE tournaments SET current_round = $1 WHERE id = $2`, next, tournamentID); err != nil {
+		return false, false, err
+	}
+	return true, false, tx.Commit()
+}
+
+// createRoundGames crée, par le chemin habituel de création de partie, les
+// parties des appariements de la ronde courante qui n'en ont pas encore (ou
+// dont la partie a été supprimée). Chaque appariement est verrouillé le temps
+// de la création pour ne jamais créer deux parties.
+func createRoundGames(tournamentID string) error {
+	var (
+		name, language, variant string
+		rawRules                []byte
+		current                 int
+	)
+	err := database.QueryRow(`
+		SELECT name, language, variant, ruleset, current_round FROM tournaments WHERE id = $1
+	`, tournamentID).Scan(&name, &language, &variant, &rawRules, &current)
+	if err != nil {
+		return err
+	}
+	rules, err := parseRuleset(rawRules)
+	if err != nil {
+		return err
+	}
+
+	rows, err := database.Query(`
+		SELECT id FROM tournament_pairings
+		WHERE tournament_id = $1 AND round = $2 AND result = 'pending' AND game_id IS NULL
+		ORDER BY id
+	`, tournamentID, current)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	gameName := fmt.Sprintf("%s – ronde %d", name, current)
+	for _, id := range ids {
+		if err := createPairingGame(id, gameName, GameOptions{
+			Language: language,
+			Variant:  variant,
+			Rules:    gameRulesRequest(rules),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createPairingGame crée la partie d'un appariement encore sans partie et l'y
+// rattache, dans une même transaction.
+func createPairingGame(pairingID int64, name string, opts GameOptions) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "pairing_id", pairingID)
+		}
+	}()
+
+	var p1, p2 int64
+	err = tx.QueryRow(`
+		SELECT player1_id, player2_id FROM tournament_pairings
+		WHERE id = $1 AND result = 'pending' AND game_id IS NULL
+		FOR UPDATE SKIP LOCKED
+	`, pairingID).Scan(&p1, &p2)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // déjà prise en charge
+	}
+	if err != nil {
+		return err
+	}
+
+	// La partie est créée dans la transaction qui verrouille l'appariement :
+	// un échec n'en laisse aucune orpheline
+	gameID, err := createGameTx(tx, p1, name, []invitee{{ID: p2, Accepted: true}}, nil, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE tournament_pairings SET game_id = $1 WHERE id = $2`, gameID, pairingID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, uid := range []int64{p1, p2} {
+		_ = utils.SendNotificationToUserByID(uid, utils.NotificationPayload{
+			Title: "Nouvelle ronde de tournoi",
+			Body:  fmt.Sprintf("Votre partie « %s » vous attend.", name),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/games/%s", gameID.String()),
+		})
+	}
+	return nil
+}
+
+// recordTournamentResult enregistre, dans la transaction de fin de partie, le
+// résultat d'une partie de tournoi. Elle retourne le tournoi concerné ("" pour
+// une partie hors tournoi) : il reste à le faire avancer après validation.
+func recordTournamentResult(tx *sql.Tx, gameID string, final *engine.GameState, res *engine.FinishResult) (string, error) {
+	var (
+		pairingID, p1, p2 int64
+		tournamentID      string
+	)
+	err := tx.QueryRow(`
+		SELECT id, tournament_id, player1_id, player2_id FROM tournament_pairings
+		WHERE game_id = $1 AND result = 'pending'
+		FOR UPDATE
+	`, gameID).Scan(&pairingID, &tournamentID, &p1, &p2)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	score1, score2 := final.Scores[p1], final.Scores[p2]
+	result := PairingPlayed
+	winner := sql.NullInt64{Int64: res.WinnerID, Valid: score1 != score2}
+	if res.Forfeited != 0 {
+		result = PairingForfeit
+		winner = sql.NullInt64{Int64: p1, Valid: true}
+		if res.Forfeited == p1 {
+			winner.Int64 = p2
+		}
+	}
+	_, err = tx.Exec(`
+		UPDATE tournament_pairings SET result = $1, score1 = $2, score2 = $3, winner_id = $4 WHERE id = $5
+	`, result, score1, score2, winner, pairingID)
+	if err != nil {
+		return "", err
+	}
+	return tournamentID, nil
+}
+
+// WithdrawFromTournament retire userID du tournoi, à sa demande ou à celle de
+// l'organisateur. Avant le début, c'est une simple désinscription ; ensuite,
+// sa partie de la ronde courante est perdue par forfait et il n'est plus
+// apparié.
+func WithdrawFromTournament(actorID int64, tournamentID string, userID int64) error {
+	if actorID != userID {
+		ok, err := canManageTournament(database.DB, actorID, tournamentID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNotTournamentManager
+		}
+	}
+
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	status, current, _, err := lockTournament(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case TournamentRegistration:
+		if err := unregister(tx, tournamentID, userID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	case TournamentRunning:
+	default:
+		return ErrTournamentNotRunning
+	}
+
+	res, err := tx.Exec(`
+		UPDATE tournament_players SET withdrawn_at = now()
+		WHERE tournament_id = $1 AND user_id = $2 AND withdrawn_at IS NULL
+	`, tournamentID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotRegistered
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM tournament_pairings WHERE tournament_id = $1 AND round > $2 AND player1_id = $3
+	`, tournamentID, current, userID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if err := forfeitCurrentPairing(tournamentID, userID); err != nil && !errors.Is(err, ErrNoPendingPairing) {
+		return err
+	}
+	return advanceTournament(tournamentID)
+}
+
+// ForfeitTournamentGame déclare, pour l'organisateur, le forfait de userID
+// dans sa partie de la ronde courante.
+func ForfeitTournamentGame(actorID int64, tournamentID string, userID int64) error {
+	ok, err := canManageTournament(database.DB, actorID, tournamentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotTournamentManager
+	}
+	if err := forfeitCurrentPairing(tournamentID, userID); err != nil {
+		return err
+	}
+	return advanceTournament(tournamentID)
+}
+
+// forfeitCurrentPairing fait perdre par forfait à userID son appariement en
+// cours. Une partie en cours se termine par le forfait du joueur, comme à
+// l'expiration du délai de jeu ; sinon seul l'appariement est réglé, et une
+// partie pas encore commencée est supprimée.
+func forfeitCurrentPairing(tournamentID string, userID int64) error {
+	var (
+		pairingID, p1, p2  int64
+		gameID, gameStatus sql.NullString
+	)
+	err := database.QueryRow(`
+		SELECT p.id, p.player1_id, p.player2_id, p.game_id, g.status
+		FROM tournament_pairings p
+		JOIN tournaments t ON t.id = p.tournament_id AND t.current_round = p.round AND t.status = 'running'
+		LEFT JOIN games g ON g.id = p.game_id
+		WHERE p.tournament_id = $1 AND p.result = 'pending' AND $2 IN (p.player1_id, p.player2_id)
+	`, tournamentID, userID).Scan(&pairingID, &p1, &p2, &gameID, &gameStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoPendingPairing
+	}
+	if err != nil {
+		return err
+	}
+	if gameStatus.String == "ongoing" {
+		return forfeitGame(gameID.String, userID)
+	}
+
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "pairing_id", pairingID)
+		}
+	}()
+
+	winner := p1
+	if winner == userID {
+		winner = p2
+	}
+	if _, err := tx.Exec(`
+		UPDATE tournament_pairings SET result = 'forfeit', winner_id = $1 WHERE id = $2 AND result = 'pending'
+	`, winner, pairingID); err != nil {
+		return err
+	}
+	if gameStatus.String == "pending" {
+		if err := deleteGameTx(tx, gameID.String); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// forfeitGame termine une partie en cours par le forfait de playerID.
+func forfeitGame(gameID string, playerID int64) error {
+	tx, err := database.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "game_id", gameID)
+		}
+	}()
+
+	if err := lockGame(tx, gameID); err != nil {
+		return err
+	}
+	state, err := loadGameState(tx, gameID)
+	if err != nil {
+		return err
+	}
+	accepted, err := acceptPendingMoves(tx, gameID)
+	if err != nil {
+		return fmt.Errorf("failed to accept pending moves: %w", err)
+	}
+	ended, err := forfeitLocked(tx, gameID, state, playerID, organizerForfeitReason)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	publishGameEnded(gameID, ended)
+	unlockAcceptedPlaysAchievements(accepted)
+	return nil
+}
+
+// AssignTournamentBye exempte userID d'une ronde qui n'est pas encore
+// appariée ; le bye compte comme une victoire (voir tournament.Standings).
+func AssignTournamentBye(actorID int64, tournamentID string, userID int64, round int) error {
+	tx, err := beginTournamentManagement(actorID, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	status, current, rounds, err := lockTournament(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if status != TournamentRegistration && status != TournamentRunning {
+		return ErrTournamentNotRunning
+	}
+	if round <= current || (rounds > 0 && round > rounds) {
+		return ErrInvalidByeRound
+	}
+	var registered bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM tournament_players WHERE tournament_id = $1 AND user_id = $2 AND withdrawn_at IS NULL)
+	`, tournamentID, userID).Scan(&registered); err != nil {
+		return err
+	}
+	if !registered {
+		return ErrNotRegistered
+	}
+	res, err := tx.Exec(`
+		INSERT INTO tournament_pairings (tournament_id, round, player1_id, result, winner_id)
+		VALUES ($1, $2, $3, 'bye', $3)
+		ON CONFLICT (tournament_id, round, player1_id) DO NOTHING
+	`, tournamentID, round, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlreadyPaired
+	}
+	return tx.Commit()
+}
+
+// RemoveTournamentBye retire un bye attribué d'avance à userID.
+func RemoveTournamentBye(actorID int64, tournamentID string, userID int64, round int) error {
+	tx, err := beginTournamentManagement(actorID, tournamentID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.Error(context.Background(), "Failed to rollback transaction", "error", err, "tournament_id", tournamentID)
+		}
+	}()
+
+	_, current, _, err := lockTournament(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if round <= current {
+		return ErrInvalidByeRound
+	}
+	res, err := tx.Exec(`
+		DELETE FROM tournament_pairings
+		WHERE tournament_id = $1 AND round = $2 AND player1_id = $3 AND result = 'bye'
+	`, tournamentID, round, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrByeNotFound
+	}
+	return tx.Commit()
+}
+
+// beginTournamentManagement vérifie que actorID gère le tournoi et ouvre la
+// transaction de l'opération.
+func beginTournamentManagement(actorID int64, tournamentID string) (*sql.Tx, error) {
+	ok, err := canManageTournament(database.DB, actorID, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotTournamentManager
+	}
+	return database.DB.BeginTx(context.Background(), nil)
+}
+
+// notifyTournamentFinished annonce le vainqueur aux joueurs du tournoi.
+func notifyTournamentFinished(tournamentID string) {
+	details, err := GetTournament(0, tournamentID)
+	if err != nil || len(details.Standings) == 0 {
+		logger.Warn(context.Background(), "tournaments: failed to load final standings", "error", err, "tournament_id", tournamentID)
+		return
+	}
+	winner := details.Standings[0].Username
+	for _, p := range details.Players {
+		_ = utils.SendNotificationToUserByID(p.UserID, utils.NotificationPayload{
+			Title: "Tournoi terminé",
+			Body:  fmt.Sprintf("%s remporte le tournoi « %s ».", winner, details.Name),
+			Url:   fmt.Sprintf("https://scrabble.baptiste.zip/tournaments/%s", tournamentID),
+		})
+	}
+}
+
+// StartTournamentWorker lance la goroutine qui démarre les tournois dont la
+// fenêtre d'inscription est close et relance la création des parties
+// manquantes. Intervalle en secondes.
+func StartTournamentWorker(intervalSeconds int) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := StartDueTournaments(); err != nil {
+				logger.Error(context.Background(), "tournaments: due tournaments query failed", "error", err)
+			}
+			resumeRunningTournaments()
+		}
+	}()
+	logger.Info(context.Background(), "tournaments: worker started", "interval_seconds", intervalSeconds)
+}
+
+// resumeRunningTournaments fait avancer les tournois en cours, au cas où la
+// création d'une partie ou le passage à la ronde suivante aurait échoué.
+func resumeRunningTournaments() {
+	rows, err := database.Query(`SELECT id FROM tournaments WHERE status = 'running'`)
+	if err != nil {
+		logger.Error(context.Background(), "tournaments: running tournaments query failed", "error", err)
+		return
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	for _, id := range ids {
+		if err := advanceTournament(id); err != nil {
+			logger.Error(context.Background(), "tournaments: failed to advance tournament", "error", err, "tournament_id", id)
+		}
+	}
+}
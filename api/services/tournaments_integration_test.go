@@ -0,0 +1,227 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/models/request"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/tournament"
+)
+
+func mustCreateTournament(t *testing.T, userID int64, format string, rounds int) string {
+	t.Helper()
+	opens := time.Now().Add(-time.Minute)
+	details, err := CreateTournament(userID, request.CreateTournamentRequest{
+		Name:                 "open du club",
+		Format:               format,
+		Rounds:               rounds,
+		RegistrationOpensAt:  &opens,
+		RegistrationClosesAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	return details.ID
+}
+
+// roundGame retourne la partie de userID dans la ronde round.
+func roundGame(t *testing.T, details *response.TournamentDetails, round int, userID int64) string {
+	t.Helper()
+	for _, p := range details.Pairings {
+		if p.Round != round || p.GameID == nil {
+			continue
+		}
+		if p.Player1ID == userID || (p.Player2ID != nil && *p.Player2ID == userID) {
+			return *p.GameID
+		}
+	}
+	t.Fatalf("no game for player %d in round %d", userID, round)
+	return ""
+}
+
+func TestTournaments_Validation(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "tn_validator")
+	closes := time.Now().Add(time.Hour)
+
+	_, err := CreateTournament(u1, request.CreateTournamentRequest{Name: "x", Format: "knockout", RegistrationClosesAt: closes})
+	assert.ErrorIs(t, err, ErrInvalidTournamentFormat)
+	_, err = CreateTournament(u1, request.CreateTournamentRequest{Name: "x", Format: tournament.Swiss, RegistrationClosesAt: closes})
+	assert.ErrorIs(t, err, ErrInvalidTournamentRounds)
+	_, err = CreateTournament(u1, request.CreateTournamentRequest{Name: "x", Format: tournament.RoundRobin, RegistrationClosesAt: time.Now().Add(-time.Hour)})
+	assert.ErrorIs(t, err, ErrInvalidRegistrationWindow)
+
+	details, err := CreateTournament(u1, request.CreateTournamentRequest{Name: "x", Format: tournament.RoundRobin, RegistrationClosesAt: closes})
+	require.NoError(t, err)
+	assert.Equal(t, TournamentRegistration, details.Status)
+	assert.Equal(t, 2, details.Rules.MaxPlayers)
+	assert.True(t, details.CanManage)
+	assert.Empty(t, details.Players)
+
+	_, err = GetTournament(u1, "not-a-uuid")
+	assert.ErrorIs(t, err, ErrTournamentNotFound)
+}
+
+func TestTournaments_RoundRobinAdvancesWhenGamesEnd(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "tn_alice")
+	u2 := mustCreateUser(t, "tn_bob")
+	u3 := mustCreateUser(t, "tn_carol")
+	outsider := mustCreateUser(t, "tn_outsider")
+
+	tid := mustCreateTournament(t, u1, tournament.RoundRobin, 0)
+	for _, uid := range []int64{u1, u2, u3} {
+		require.NoError(t, RegisterForTournament(uid, tid))
+	}
+	assert.ErrorIs(t, RegisterForTournament(u1, tid), ErrAlreadyRegistered)
+	assert.ErrorIs(t, StartTournament(outsider, tid), ErrNotTournamentManager)
+
+	require.NoError(t, StartTournament(u1, tid))
+	assert.ErrorIs(t, RegisterForTournament(outsider, tid), ErrRegistrationClosed)
+
+	for round := 1; round <= 3; round++ {
+		details, err := GetTournament(u1, tid)
+		require.NoError(t, err)
+		require.Equal(t, TournamentRunning, details.Status)
+		require.Equal(t, 3, details.Rounds)
+		require.Equal(t, round, details.CurrentRound)
+
+		// à trois, chaque ronde compte une partie et un bye
+		var game *response.TournamentPairing
+		for i, p := range details.Pairings {
+			if p.Round != round {
+				continue
+			}
+			if p.Result == PairingBye {
+				assert.Nil(t, p.Player2ID)
+				continue
+			}
+			game = &details.Pairings[i]
+		}
+		require.NotNil(t, game)
+		require.NotNil(t, game.GameID)
+
+		info, err := GetGameDetails(game.Player1ID, *game.GameID)
+		require.NoError(t, err)
+		assert.Equal(t, "ongoing", info.Status)
+		assert.Len(t, info.Players, 2)
+
+		// le second joueur abandonne : la ronde suivante est appariée aussitôt
+		require.NoError(t, ResignGame(*game.Player2ID, *game.GameID))
+	}
+
+	details, err := GetTournament(u2, tid)
+	require.NoError(t, err)
+	assert.Equal(t, TournamentFinished, details.Status)
+	assert.NotNil(t, details.EndedAt)
+	assert.Len(t, details.Pairings, 6)
+	require.Len(t, details.Standings, 3)
+	var wins float64
+	for _, s := range details.Standings {
+		assert.Equal(t, 3, s.Played)
+		assert.Equal(t, 1, s.Byes)
+		wins += s.Wins
+	}
+	assert.Equal(t, 6.0, wins)
+	assert.Equal(t, 1, details.Standings[0].Rank)
+}
+
+func TestTournaments_SwissByesForfeitsAndWithdrawals(t *testing.T) {
+	resetAllGamesDeps(t)
+	org := mustCreateUser(t, "tn_organizer")
+	u1 := mustCreateUser(t, "tn_p1")
+	u2 := mustCreateUser(t, "tn_p2")
+	u3 := mustCreateUser(t, "tn_p3")
+	u4 := mustCreateUser(t, "tn_p4")
+
+	tid := mustCreateTournament(t, org, tournament.Swiss, 2)
+	for _, uid := range []int64{u1, u2, u3, u4} {
+		require.NoError(t, RegisterForTournament(uid, tid))
+	}
+
+	// bye attribué d'avance par l'organisateur
+	assert.ErrorIs(t, AssignTournamentBye(u1, tid, u4, 1), ErrNotTournamentManager)
+	assert.ErrorIs(t, AssignTournamentBye(org, tid, u4, 3), ErrInvalidByeRound)
+	assert.ErrorIs(t, AssignTournamentBye(org, tid, org, 1), ErrNotRegistered)
+	require.NoError(t, AssignTournamentBye(org, tid, u4, 1))
+	assert.ErrorIs(t, AssignTournamentBye(org, tid, u4, 1), ErrAlreadyPaired)
+	require.NoError(t, AssignTournamentBye(org, tid, u4, 2))
+	require.NoError(t, RemoveTournamentBye(org, tid, u4, 2))
+	assert.ErrorIs(t, RemoveTournamentBye(org, tid, u4, 2), ErrByeNotFound)
+
+	// ronde 1 : u1 contre u2, u3 exempt (nombre impair), u4 exempt d'avance
+	require.NoError(t, StartTournament(org, tid))
+	details, err := GetTournament(org, tid)
+	require.NoError(t, err)
+	assert.Len(t, details.Pairings, 3)
+	g1 := roundGame(t, details, 1, u1)
+	assert.Equal(t, g1, roundGame(t, details, 1, u2))
+
+	// forfait prononcé par l'organisateur : la partie se termine
+	assert.ErrorIs(t, ForfeitTournamentGame(u1, tid, u2), ErrNotTournamentManager)
+	require.NoError(t, ForfeitTournamentGame(org, tid, u2))
+	info, err := GetGameDetails(u1, g1)
+	require.NoError(t, err)
+	assert.Equal(t, "ended", info.Status)
+
+	// ronde 2 sans revanche : u1 contre u3, u4 contre u2
+	details, err = GetTournament(org, tid)
+	require.NoError(t, err)
+	require.Equal(t, 2, details.CurrentRound)
+	g2 := roundGame(t, details, 2, u1)
+	assert.Equal(t, g2, roundGame(t, details, 2, u3))
+	assert.Equal(t, roundGame(t, details, 2, u4), roundGame(t, details, 2, u2))
+	require.NoError(t, ResignGame(u3, g2))
+
+	// le retrait de u2 lui fait perdre sa partie en cours et clôt le tournoi
+	assert.ErrorIs(t, WithdrawFromTournament(u1, tid, u2), ErrNotTournamentManager)
+	require.NoError(t, WithdrawFromTournament(u2, tid, u2))
+	assert.ErrorIs(t, ForfeitTournamentGame(org, tid, u4), ErrNoPendingPairing)
+
+	details, err = GetTournament(org, tid)
+	require.NoError(t, err)
+	assert.Equal(t, TournamentFinished, details.Status)
+	require.Len(t, details.Standings, 4)
+	got := make([]int64, 0, 4)
+	for _, s := range details.Standings {
+		got = append(got, s.UserID)
+	}
+	assert.Equal(t, []int64{u1, u4, u3, u2}, got)
+	assert.Equal(t, 2.0, details.Standings[0].Wins)
+	assert.Equal(t, 2*tournament.ByeSpread, details.Standings[0].Spread)
+	assert.True(t, details.Standings[3].Withdrawn)
+	assert.Equal(t, -2*tournament.ByeSpread, details.Standings[3].Spread)
+}
+
+func TestTournaments_ForfeitDeletesPendingGame(t *testing.T) {
+	resetAllGamesDeps(t)
+	org := mustCreateUser(t, "tn_pending_org")
+	u1 := mustCreateUser(t, "tn_pending_p1")
+	u2 := mustCreateUser(t, "tn_pending_p2")
+
+	tid := mustCreateTournament(t, org, tournament.Swiss, 1)
+	for _, uid := range []int64{u1, u2} {
+		require.NoError(t, RegisterForTournament(uid, tid))
+	}
+	require.NoError(t, StartTournament(org, tid))
+	details, err := GetTournament(org, tid)
+	require.NoError(t, err)
+	g := roundGame(t, details, 1, u1)
+
+	// partie pas encore commencée : le forfait la supprime
+	_, err = database.Exec(`UPDATE games SET status = 'pending' WHERE id = $1`, g)
+	require.NoError(t, err)
+	require.NoError(t, ForfeitTournamentGame(org, tid, u2))
+
+	var exists bool
+	require.NoError(t, database.QueryRow(`SELECT EXISTS (SELECT 1 FROM games WHERE id = $1)`, g).Scan(&exists))
+	assert.False(t, exists)
+	details, err = GetTournament(org, tid)
+	require.NoError(t, err)
+	assert.Equal(t, TournamentFinished, details.Status)
+	require.Len(t, details.Standings, 2)
+	assert.Equal(t, u1, details.Standings[0].UserID)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/ZiplEix/scrabble/api/database"
+	"github.com/ZiplEix/scrabble/api/engine"
+	"github.com/ZiplEix/scrabble/api/models/response"
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+// GetUnseenTiles retourne les tuiles que userID ne voit pas dans la partie :
+// distribution initiale moins le plateau et son rack. Il ne s'appuie jamais
+// sur le contenu réel du sac ni des racks adverses.
+func GetUnseenTiles(userID int64, gameID string) (*response.UnseenTiles, error) {
+	if err := validatePlayerInGame(database.DB, gameID, userID); err != nil {
+		return nil, errors.New("game not found")
+	}
+	state, err := loadGameState(database.DB, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return unseenTiles(gameID, state, userID), nil
+}
+
+// GetAdminUnseenTiles est GetUnseenTiles vu par playerID, complété par la
+// répartition réelle des tuiles entre le sac et les racks adverses.
+func GetAdminUnseenTiles(gameID string, playerID int64) (*response.UnseenTiles, error) {
+	state, err := loadGameState(database.DB, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("game not found")
+		}
+		return nil, err
+	}
+	if err := validatePlayerInGame(database.DB, gameID, playerID); err != nil {
+		return nil, errors.New("player not in game")
+	}
+	res := unseenTiles(gameID, state, playerID)
+	res.Split = &response.UnseenSplit{Bag: state.Bag.Strings(), Racks: map[int64][]string{}}
+	for _, pid := range state.Players {
+		if pid != playerID {
+			res.Split.Racks[pid] = state.Racks[pid].Strings()
+		}
+	}
+	return res, nil
+}
+
+// unseenTiles construit le décompte des tuiles invisibles pour playerID.
+func unseenTiles(gameID string, state *engine.GameState, playerID int64) *response.UnseenTiles {
+	unseen := state.Unseen(playerID)
+	res := &response.UnseenTiles{
+		GameID:   gameID,
+		PlayerID: playerID,
+		Total:    len(unseen),
+		InBag:    len(state.Bag),
+		Tiles:    unseen.Strings(),
+		Counts:   map[string]int{},
+	}
+	for _, t := range unseen {
+		res.Counts[string(t)]++
+		switch {
+		case t == word.BlankTile:
+			res.Blanks++
+		case t.IsVowel():
+			res.Vowels++
+		default:
+			res.Consonants++
+		}
+	}
+	if n := res.Vowels + res.Consonants; n > 0 {
+		res.VowelRatio = float64(res.Vowels) / float64(n)
+	}
+	// sac vide en face à face : tout ce qui reste invisible est chez l'adversaire
+	if len(state.Bag) == 0 && len(state.Players) == 2 {
+		if _, ok := state.Racks[playerID]; ok {
+			res.OpponentRack = res.Tiles
+		}
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ZiplEix/scrabble/api/word"
+)
+
+func TestGetUnseenTiles(t *testing.T) {
+	resetAllGamesDeps(t)
+	u1 := mustCreateUser(t, "unseen1")
+	u2 := mustCreateUser(t, "unseen2")
+	outsider := mustCreateUser(t, "unseen3")
+	gid, err := createStartedGame(t, u1, "unseen", []string{"unseen2"}, nil)
+	require.NoError(t, err)
+	g := gid.String()
+	setPlayerRack(t, g, u1, "CHATXYZ")
+	setPlayerRack(t, g, u2, "ABCDEFG")
+	setGameTurnAndBag(t, g, u1, "EEEEEEEEEE")
+	require.NoError(t, PlayMove(g, u1, chatAtCenterMove()))
+
+	// CHAT est sur le plateau, XYZ + 4 E tirés sont dans le rack de u1
+	unseen, err := GetUnseenTiles(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, len(word.French.Bag)-4-7, unseen.Total)
+	assert.Equal(t, 6, unseen.InBag)
+	initial := word.French.Bag.Count()
+	assert.Equal(t, initial["C"]-1, unseen.Counts["C"])
+	assert.Equal(t, initial["E"]-4, unseen.Counts["E"])
+	assert.Equal(t, 0, unseen.Counts["Z"])
+	assert.Equal(t, 2, unseen.Blanks)
+	assert.Equal(t, unseen.Total, unseen.Vowels+unseen.Consonants+unseen.Blanks)
+	assert.InDelta(t, float64(unseen.Vowels)/float64(unseen.Vowels+unseen.Consonants), unseen.VowelRatio, 1e-9)
+	assert.Empty(t, unseen.OpponentRack)
+	assert.Nil(t, unseen.Split)
+
+	_, err = GetUnseenTiles(outsider, g)
+	assert.ErrorContains(t, err, "not found")
+
+	// sac vide : tout l'invisible est le rack adverse
+	setGameTurnAndBag(t, g, u2, "")
+	unseen, err = GetUnseenTiles(u1, g)
+	require.NoError(t, err)
+	assert.Equal(t, unseen.Tiles, unseen.OpponentRack)
+
+	admin, err := GetAdminUnseenTiles(g, u1)
+	require.NoError(t, err)
+	require.NotNil(t, admin.Split)
+	assert.Empty(t, admin.Split.Bag)
+	assert.Equal(t, []string{"A", "B", "C", "D", "E", "F", "G"}, admin.Split.Racks[u2])
+	_, err = GetAdminUnseenTiles(g, outsider)
+	assert.ErrorContains(t, err, "not in game")
+}
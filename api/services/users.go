@@ -275,7 +275,7 @@ func GetAllUsers() ([]response.AdminUserInfo, error) {
 	gpRows, err := database.Query(`
 		SELECT gp.player_id, COUNT(*) as games,
 			   SUM(CASE WHEN g.status = 'ongoing' THEN 1 ELSE 0 END) as ongoing,
-			   SUM(CASE WHEN g.status != 'ongoing' THEN 1 ELSE 0 END) as finished
+			   SUM(CASE WHEN g.status IN ('ended', 'archived') THEN 1 ELSE 0 END) as finished
 		FROM game_players gp
 		JOIN games g ON g.id = gp.game_id
 		GROUP BY gp.player_id
@@ -400,7 +400,7 @@ func GetAdminUserByID(userID int64) (*response.AdminUserInfo, error) {
 	if err := database.QueryRow(`
 		SELECT COUNT(*) as games,
 			   SUM(CASE WHEN g.status = 'ongoing' THEN 1 ELSE 0 END) as ongoing,
-			   SUM(CASE WHEN g.status != 'ongoing' THEN 1 ELSE 0 END) as finished
+			   SUM(CASE WHEN g.status IN ('ended', 'archived') THEN 1 ELSE 0 END) as finished
 		FROM game_players gp
 		JOIN games g ON g.id = gp.game_id
 		WHERE gp.player_id = $1
@@ -3,6 +3,7 @@ package services
 import (
 	"testing"
 
+	"github.com/ZiplEix/scrabble/api/engine"
 	"github.com/ZiplEix/scrabble/api/models/request"
 )
 
@@ -21,22 +22,22 @@ func TestRackContains(t *testing.T) {
 }
 
 func TestApplyLetters(t *testing.T) {
-	var board [15][15]string
+	board := engine.StandardLayout.NewBoard()
 	letters := []request.PlacedLetter{{X: 7, Y: 7, Char: "A"}, {X: 8, Y: 7, Char: "B"}}
-	if err := applyLetters(&board, letters); err != nil {
+	if err := applyLetters(board, letters); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if board[7][7] != "A" || board[7][8] != "B" {
 		t.Fatalf("letters not applied correctly")
 	}
 	// placing over existing cell should error
-	if err := applyLetters(&board, []request.PlacedLetter{{X: 7, Y: 7, Char: "C"}}); err == nil {
+	if err := applyLetters(board, []request.PlacedLetter{{X: 7, Y: 7, Char: "C"}}); err == nil {
 		t.Fatalf("expected error when cell already occupied")
 	}
 }
 
 func TestComputeMoveScore_SimpleWord(t *testing.T) {
-	var board [15][15]string
+	board := engine.StandardLayout.NewBoard()
 	// Place HELLO horizontally starting from (7,7)
 	placed := []request.PlacedLetter{
 		{X: 7, Y: 7, Char: "H"},
@@ -46,7 +47,7 @@ func TestComputeMoveScore_SimpleWord(t *testing.T) {
 		{X: 11, Y: 7, Char: "O"},
 	}
 	// apply to board to allow compute to read letters
-	if err := applyLetters(&board, placed); err != nil {
+	if err := applyLetters(board, placed); err != nil {
 		t.Fatalf("applyLetters error: %v", err)
 	}
 	score := computeMoveScore(board, placed, map[Pos]bool{})
@@ -68,13 +69,13 @@ func TestRackPoints(t *testing.T) {
 }
 
 func TestComputeMoveScore_Blank(t *testing.T) {
-	var board [15][15]string
+	board := engine.StandardLayout.NewBoard()
 	placed := []request.PlacedLetter{
 		{X: 7, Y: 7, Char: "C"},
 		{X: 8, Y: 7, Char: "A", Blank: true}, // joker utilisé comme 'A'
 		{X: 9, Y: 7, Char: "T"},
 	}
-	if err := applyLetters(&board, placed); err != nil {
+	if err := applyLetters(board, placed); err != nil {
 		t.Fatalf("applyLetters error: %v", err)
 	}
 	// Marque la position (8,7) comme blank pour le calcul
@@ -151,13 +152,13 @@ func TestUpdatePlayerRack_RemovesQuestionMarkForBlank(t *testing.T) {
 
 func TestComputeMoveScore_BlankOnDL_TL_Ignored(t *testing.T) {
 	// Vérifie que DL/TL n'augmentent pas un joker
-	var board [15][15]string
+	board := engine.StandardLayout.NewBoard()
 	// Place un mot vertical à (7,7) centre ★: joker au centre, lettre réelle au-dessus
 	placed := []request.PlacedLetter{
 		{X: 7, Y: 7, Char: "A", Blank: true}, // centre ★
 		{X: 7, Y: 6, Char: "B"},
 	}
-	if err := applyLetters(&board, placed); err != nil {
+	if err := applyLetters(board, placed); err != nil {
 		t.Fatalf("applyLetters: %v", err)
 	}
 	blanks := map[Pos]bool{{X: 7, Y: 7}: true}
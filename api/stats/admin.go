@@ -29,7 +29,8 @@ func GetActiveUsersCountAndVariance() (int, float64, error) {
 		SELECT COUNT(DISTINCT user_id) FROM (
 			SELECT user_id FROM messages WHERE created_at >= now() - interval '7 days' AND deleted_at IS NULL
 			UNION
-			SELECT player_id AS user_id FROM game_moves WHERE created_at >= now() - interval '7 days'
+			SELECT m.player_id AS user_id FROM game_moves m JOIN games g ON m.game_id = g.id
+			WHERE m.created_at >= now() - interval '7 days' AND g.status <> 'archived'
 		) t
 	`).Scan(&curr)
 	if err != nil && err != sql.ErrNoRows {
@@ -41,7 +42,8 @@ func GetActiveUsersCountAndVariance() (int, float64, error) {
 		SELECT COUNT(DISTINCT user_id) FROM (
 			SELECT user_id FROM messages WHERE created_at >= now() - interval '14 days' AND created_at < now() - interval '7 days' AND deleted_at IS NULL
 			UNION
-			SELECT player_id AS user_id FROM game_moves WHERE created_at >= now() - interval '14 days' AND created_at < now() - interval '7 days'
+			SELECT m.player_id AS user_id FROM game_moves m JOIN games g ON m.game_id = g.id
+			WHERE m.created_at >= now() - interval '14 days' AND m.created_at < now() - interval '7 days' AND g.status <> 'archived'
 		) t
 	`).Scan(&prev)
 	if err != nil && err != sql.ErrNoRows {
@@ -55,10 +57,10 @@ func GetActiveUsersCountAndVariance() (int, float64, error) {
 func GetCreatedGamesCountAndVariance() (int, float64, error) {
 	var curr int
 	var prev int
-	if err := database.QueryRow(`SELECT COUNT(*) FROM games WHERE created_at >= now() - interval '7 days'`).Scan(&curr); err != nil && err != sql.ErrNoRows {
+	if err := database.QueryRow(`SELECT COUNT(*) FROM games WHERE created_at >= now() - interval '7 days' AND status <> 'archived'`).Scan(&curr); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
-	if err := database.QueryRow(`SELECT COUNT(*) FROM games WHERE created_at >= now() - interval '14 days' AND created_at < now() - interval '7 days'`).Scan(&prev); err != nil && err != sql.ErrNoRows {
+	if err := database.QueryRow(`SELECT COUNT(*) FROM games WHERE created_at >= now() - interval '14 days' AND created_at < now() - interval '7 days' AND status <> 'archived'`).Scan(&prev); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
 	return curr, pctChange(curr, prev), nil
@@ -72,7 +74,8 @@ func GetActiveGamesCountAndVariance() (int, float64, error) {
 		SELECT COUNT(DISTINCT game_id) FROM (
 			SELECT game_id FROM messages WHERE created_at >= now() - interval '7 days' AND deleted_at IS NULL
 			UNION
-			SELECT game_id FROM game_moves WHERE created_at >= now() - interval '7 days'
+			SELECT m.game_id FROM game_moves m JOIN games g ON m.game_id = g.id
+			WHERE m.created_at >= now() - interval '7 days' AND g.status <> 'archived'
 		) t
 	`).Scan(&curr); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
@@ -81,7 +84,8 @@ func GetActiveGamesCountAndVariance() (int, float64, error) {
 		SELECT COUNT(DISTINCT game_id) FROM (
 			SELECT game_id FROM messages WHERE created_at >= now() - interval '14 days' AND created_at < now() - interval '7 days' AND deleted_at IS NULL
 			UNION
-			SELECT game_id FROM game_moves WHERE created_at >= now() - interval '14 days' AND created_at < now() - interval '7 days'
+			SELECT m.game_id FROM game_moves m JOIN games g ON m.game_id = g.id
+			WHERE m.created_at >= now() - interval '14 days' AND m.created_at < now() - interval '7 days' AND g.status <> 'archived'
 		) t
 	`).Scan(&prev); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
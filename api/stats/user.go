@@ -10,15 +10,22 @@ import (
 // getGamesCountAndTop returns (games_count, top_percent, error)
 func GetGamesCountAndTop(userID int64) (int, int, error) {
 	var count int
-	if err := database.QueryRow("SELECT COUNT(*) FROM game_players WHERE player_id = $1", userID).Scan(&count); err != nil && err != sql.ErrNoRows {
+	if err := database.QueryRow(`
+		SELECT COUNT(*)
+		FROM game_players gp
+		JOIN games g ON gp.game_id = g.id
+		WHERE gp.player_id = $1 AND g.status <> 'archived'
+	`, userID).Scan(&count); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
 	var nf sql.NullFloat64
 	err := database.QueryRow(`
 		WITH per_user AS (
-			SELECT player_id AS user_id, COUNT(*) AS games
-			FROM game_players
-			GROUP BY player_id
+			SELECT gp.player_id AS user_id, COUNT(*) AS games
+			FROM game_players gp
+			JOIN games g ON gp.game_id = g.id
+			WHERE g.status <> 'archived'
+			GROUP BY gp.player_id
 		), ranked AS (
 			SELECT user_id, games,
 				RANK() OVER (ORDER BY games DESC) AS rnk,
@@ -41,15 +48,22 @@ func GetGamesCountAndTop(userID int64) (int, int, error) {
 // getBestScoreAndTop returns (best_score, top_percent, error)
 func GetBestScoreAndTop(userID int64) (int, int, error) {
 	var best int
-	if err := database.QueryRow("SELECT COALESCE(MAX(score), 0) FROM game_players WHERE player_id = $1", userID).Scan(&best); err != nil && err != sql.ErrNoRows {
+	if err := database.QueryRow(`
+		SELECT COALESCE(MAX(gp.score), 0)
+		FROM game_players gp
+		JOIN games g ON gp.game_id = g.id
+		WHERE gp.player_id = $1 AND g.status <> 'archived'
+	`, userID).Scan(&best); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
 	var nf sql.NullFloat64
 	err := database.QueryRow(`
 		WITH per_user AS (
-			SELECT player_id AS user_id, MAX(score) AS best
-			FROM game_players
-			GROUP BY player_id
+			SELECT gp.player_id AS user_id, MAX(gp.score) AS best
+			FROM game_players gp
+			JOIN games g ON gp.game_id = g.id
+			WHERE g.status <> 'archived'
+			GROUP BY gp.player_id
 		), ranked AS (
 			SELECT user_id, best,
 				RANK() OVER (ORDER BY best DESC) AS rnk,
@@ -72,7 +86,7 @@ func GetBestScoreAndTop(userID int64) (int, int, error) {
 // getVictoriesAndTop returns (victories, top_percent, error)
 func GetVictoriesAndTop(userID int64) (int, int, error) {
 	var wins int
-	if err := database.QueryRow("SELECT COUNT(*) FROM games WHERE winner_username = (SELECT username FROM users WHERE id = $1)", userID).Scan(&wins); err != nil && err != sql.ErrNoRows {
+	if err := database.QueryRow("SELECT COUNT(*) FROM games WHERE winner_username = (SELECT username FROM users WHERE id = $1) AND status <> 'archived'", userID).Scan(&wins); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
 	var nf sql.NullFloat64
@@ -84,6 +98,7 @@ func GetVictoriesAndTop(userID int64) (int, int, error) {
 			FROM game_players gp
 			JOIN users u ON u.id = gp.player_id
 			JOIN games g ON gp.game_id = g.id
+			WHERE g.status <> 'archived'
 			GROUP BY gp.player_id
 		), ranked AS (
 			SELECT user_id, wins,
@@ -113,6 +128,7 @@ func GetAvgScoreAndTop(userID int64) (int, int, error) {
 		JOIN games g ON gp.game_id = g.id
 		WHERE gp.player_id = $1
 		  AND g.ended_at IS NOT NULL
+		  AND g.status <> 'archived'
 	`, userID).Scan(&avg); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
@@ -126,7 +142,7 @@ func GetAvgScoreAndTop(userID int64) (int, int, error) {
 			SELECT gp.player_id AS user_id, AVG(gp.score) AS avg_score
 			FROM game_players gp
 			JOIN games g ON gp.game_id = g.id
-			WHERE g.ended_at IS NOT NULL
+			WHERE g.ended_at IS NOT NULL AND g.status <> 'archived'
 			GROUP BY gp.player_id
 		), ranked AS (
 			SELECT user_id, avg_score,
@@ -151,9 +167,11 @@ func GetAvgScoreAndTop(userID int64) (int, int, error) {
 func GetAvgPointsPerMoveAndTop(userID int64) (int, int, error) {
 	var avg sql.NullFloat64
 	if err := database.QueryRow(`
-		SELECT AVG((move->>'score')::INT)
-		FROM game_moves
-		WHERE player_id = $1 AND (move ? 'score')
+		SELECT AVG((m.move->>'score')::INT)
+		FROM game_moves m
+		JOIN games g ON m.game_id = g.id
+		WHERE m.player_id = $1 AND m.move->>'type' = 'play' AND COALESCE(m.move->>'status', '') NOT IN ('withdrawn', 'retracted')
+		  AND g.status <> 'archived'
 	`, userID).Scan(&avg); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
@@ -164,10 +182,12 @@ func GetAvgPointsPerMoveAndTop(userID int64) (int, int, error) {
 	var nf sql.NullFloat64
 	err := database.QueryRow(`
 		WITH per_user AS (
-			SELECT player_id AS user_id, AVG((move->>'score')::INT) AS avg_pm
-			FROM game_moves
-			WHERE (move ? 'score')
-			GROUP BY player_id
+			SELECT m.player_id AS user_id, AVG((m.move->>'score')::INT) AS avg_pm
+			FROM game_moves m
+			JOIN games g ON m.game_id = g.id
+			WHERE m.move->>'type' = 'play' AND COALESCE(m.move->>'status', '') NOT IN ('withdrawn', 'retracted')
+			  AND g.status <> 'archived'
+			GROUP BY m.player_id
 		), ranked AS (
 			SELECT user_id, avg_pm,
 				RANK() OVER (ORDER BY avg_pm DESC) AS rnk,
@@ -191,19 +211,23 @@ func GetAvgPointsPerMoveAndTop(userID int64) (int, int, error) {
 func GetBestMoveScoreAndTop(userID int64) (int, int, error) {
 	var best int
 	if err := database.QueryRow(`
-		SELECT COALESCE(MAX((move->>'score')::INT), 0)
-		FROM game_moves
-		WHERE player_id = $1
+		SELECT COALESCE(MAX((m.move->>'score')::INT), 0)
+		FROM game_moves m
+		JOIN games g ON m.game_id = g.id
+		WHERE m.player_id = $1 AND m.move->>'type' = 'play' AND COALESCE(m.move->>'status', '') NOT IN ('withdrawn', 'retracted')
+		  AND g.status <> 'archived'
 	`, userID).Scan(&best); err != nil && err != sql.ErrNoRows {
 		return 0, 0, err
 	}
 	var nf sql.NullFloat64
 	err := database.QueryRow(`
 		WITH per_user AS (
-			SELECT player_id AS user_id, MAX((move->>'score')::INT) AS best_move
-			FROM game_moves
-			WHERE (move ? 'score')
-			GROUP BY player_id
+			SELECT m.player_id AS user_id, MAX((m.move->>'score')::INT) AS best_move
+			FROM game_moves m
+			JOIN games g ON m.game_id = g.id
+			WHERE m.move->>'type' = 'play' AND COALESCE(m.move->>'status', '') NOT IN ('withdrawn', 'retracted')
+			  AND g.status <> 'archived'
+			GROUP BY m.player_id
 		), ranked AS (
 			SELECT user_id, best_move,
 				RANK() OVER (ORDER BY best_move DESC) AS rnk,
@@ -65,47 +65,50 @@ func seedStatsFixture(t *testing.T) fixture {
 	exec := func(q string, args ...any) { _, err := database.Exec(q, args...); require.NoError(t, err) }
 
 	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, winner_username, ended_at)
-        VALUES ('00000000-0000-0000-0000-000000000001','G1', $1::int, 'ended', $1::int, '{}'::jsonb, 'AAAA', 'alice', now())`, aliceID)
+        VALUES ('00000000-0000-0000-0000-000000000001','G1', $1::int, 'ended', $1::int, '{}'::jsonb, '["A","A","A","A"]', 'alice', now())`, aliceID)
 	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, winner_username, ended_at)
-        VALUES ('00000000-0000-0000-0000-000000000002','G2', $1::int, 'ended', $1::int, '{}'::jsonb, 'AAAA', 'alice', now())`, aliceID)
+        VALUES ('00000000-0000-0000-0000-000000000002','G2', $1::int, 'ended', $1::int, '{}'::jsonb, '["A","A","A","A"]', 'alice', now())`, aliceID)
 	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters)
-        VALUES ('00000000-0000-0000-0000-000000000003','G3', $1::int, 'ongoing', $1::int, '{}'::jsonb, 'AAAA')`, bobID)
+        VALUES ('00000000-0000-0000-0000-000000000003','G3', $1::int, 'ongoing', $1::int, '{}'::jsonb, '["A","A","A","A"]')`, bobID)
 	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, winner_username, ended_at)
-        VALUES ('00000000-0000-0000-0000-000000000004','G4', $1::int, 'ended', $1::int, '{}'::jsonb, 'AAAA', 'bob', now())`, bobID)
+        VALUES ('00000000-0000-0000-0000-000000000004','G4', $1::int, 'ended', $1::int, '{}'::jsonb, '["A","A","A","A"]', 'bob', now())`, bobID)
 	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, winner_username, ended_at)
-        VALUES ('00000000-0000-0000-0000-000000000005','G5', $1::int, 'ended', $1::int, '{}'::jsonb, 'AAAA', 'alice', now())`, aliceID)
+        VALUES ('00000000-0000-0000-0000-000000000005','G5', $1::int, 'ended', $1::int, '{}'::jsonb, '["A","A","A","A"]', 'alice', now())`, aliceID)
 	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, winner_username, ended_at)
-        VALUES ('00000000-0000-0000-0000-000000000006','G6', $1::int, 'ended', $1::int, '{}'::jsonb, 'AAAA', 'alice', now())`, aliceID)
+        VALUES ('00000000-0000-0000-0000-000000000006','G6', $1::int, 'ended', $1::int, '{}'::jsonb, '["A","A","A","A"]', 'alice', now())`, aliceID)
 
 	// game_players scores
 	// G1: alice 60, bob 30 (ended)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '', 1, 60)`, aliceID)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '', 2, 30)`, bobID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '[]', 1, 60)`, aliceID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '[]', 2, 30)`, bobID)
 	// G2: alice 40, carol 10 (ended)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '', 1, 40)`, aliceID)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '', 2, 10)`, carolID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '[]', 1, 40)`, aliceID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '[]', 2, 10)`, carolID)
 	// G3: bob 15, carol 20 (ongoing)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '', 1, 15)`, bobID)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '', 2, 20)`, carolID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '[]', 1, 15)`, bobID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '[]', 2, 20)`, carolID)
 	// G4: bob 50, carol 5 (ended)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000004', $1::int, '', 1, 50)`, bobID)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000004', $1::int, '', 2, 5)`, carolID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000004', $1::int, '[]', 1, 50)`, bobID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000004', $1::int, '[]', 2, 5)`, carolID)
 	// G5: alice 55 (ended)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000005', $1::int, '', 1, 55)`, aliceID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000005', $1::int, '[]', 1, 55)`, aliceID)
 	// G6: alice 10, bob 10 (ended)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000006', $1::int, '', 1, 10)`, aliceID)
-	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000006', $1::int, '', 2, 10)`, bobID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000006', $1::int, '[]', 1, 10)`, aliceID)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000006', $1::int, '[]', 2, 10)`, bobID)
 
-	// game_moves (JSONB with score)
+	// game_moves (JSONB with type and score)
 	// alice: 12, 8  => avg 10, best 12
-	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '{"score":12}')`, aliceID)
-	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '{"score":8}')`, aliceID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '{"type":"play","score":12}')`, aliceID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '{"type":"play","score":8}')`, aliceID)
 	// bob: 20, 25 => avg 22.5 (~23), best 25
-	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000004', $1::int, '{"score":20}')`, bobID)
-	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000006', $1::int, '{"score":25}')`, bobID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000004', $1::int, '{"type":"play","score":20}')`, bobID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000006', $1::int, '{"type":"play","score":25}')`, bobID)
 	// carol: 7, 7 => avg 7, best 7
-	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '{"score":7}')`, carolID)
-	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '{"score":7}')`, carolID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000002', $1::int, '{"type":"play","score":7}')`, carolID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '{"type":"play","score":7}')`, carolID)
+	// passes and exchanges score nothing and must not lower the averages
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000003', $1::int, '{"type":"pass"}')`, carolID)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000001', $1::int, '{"type":"exchange","count":3,"returned":"KWZ","drawn":"EAS"}')`, aliceID)
 
 	return fixture{alice: aliceID, bob: bobID, carol: carolID}
 }
@@ -225,3 +228,36 @@ func TestGetBestMoveScoreAndTop(t *testing.T) {
 	assert.Equal(t, 7, best)
 	assert.Equal(t, 100, top)
 }
+
+func TestStats_IgnoreImportedGames(t *testing.T) {
+	fx := seedStatsFixture(t)
+	exec := func(q string, args ...any) { _, err := database.Exec(q, args...); require.NoError(t, err) }
+
+	// partie importée depuis un fichier GCG : carol y gagne avec des scores record
+	exec(`INSERT INTO games (id, name, created_by, status, current_turn, board, available_letters, winner_username, ended_at)
+        VALUES ('00000000-0000-0000-0000-000000000007','Import', $1::int, 'archived', $1::int, '{}'::jsonb, '[]', 'carol', now())`, fx.alice)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000007', $1::int, '[]', 0, 500)`, fx.carol)
+	exec(`INSERT INTO game_players (game_id, player_id, rack, position, score) VALUES ('00000000-0000-0000-0000-000000000007', $1::int, '[]', 1, 100)`, fx.bob)
+	exec(`INSERT INTO game_moves (game_id, player_id, move) VALUES ('00000000-0000-0000-0000-000000000007', $1::int, '{"type":"play","score":150}')`, fx.carol)
+
+	count, _, err := GetGamesCountAndTop(fx.carol)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	best, top, err := GetBestScoreAndTop(fx.carol)
+	require.NoError(t, err)
+	assert.Equal(t, 20, best)
+	assert.Equal(t, 100, top)
+	wins, _, err := GetVictoriesAndTop(fx.carol)
+	require.NoError(t, err)
+	assert.Equal(t, 0, wins)
+	avg, _, err := GetAvgScoreAndTop(fx.carol)
+	require.NoError(t, err)
+	assert.Equal(t, 8, avg)
+	avg, _, err = GetAvgPointsPerMoveAndTop(fx.carol)
+	require.NoError(t, err)
+	assert.Equal(t, 7, avg)
+	best, top, err = GetBestMoveScoreAndTop(fx.carol)
+	require.NoError(t, err)
+	assert.Equal(t, 7, best)
+	assert.Equal(t, 100, top)
+}
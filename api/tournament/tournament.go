@@ -0,0 +1,226 @@
+// Package tournament apparie les joueurs d'un tournoi et calcule son
+// classement. Le paquet ne connaît ni les parties ni la base : il travaille sur
+// des identifiants de joueurs et sur les résultats déjà connus.
+package tournament
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Formats de tournoi.
+const (
+	RoundRobin = "round_robin" // toutes-rondes : chacun rencontre tous les autres
+	Swiss      = "swiss"       // système suisse : on affronte un joueur au score proche
+)
+
+// Bye tient la place de l'adversaire d'un joueur exempt.
+const Bye int64 = 0
+
+// ByeSpread est l'écart compté pour un bye, et pour un forfait : gagné par le
+// vainqueur, perdu par le forfait.
+const ByeSpread = 50
+
+// ValidFormat indique si format est un format de tournoi connu.
+func ValidFormat(format string) bool {
+	return format == RoundRobin || format == Swiss
+}
+
+// Pairing est un appariement d'une ronde. Player1 commence la partie.
+type Pairing struct {
+	Player1 int64
+	Player2 int64 // Bye si Player1 est exempt
+}
+
+// Result est le résultat d'un appariement terminé.
+type Result struct {
+	Player1, Player2 int64 // Player2 vaut Bye pour un bye
+	Score1, Score2   int
+	Winner           int64 // 0 pour une égalité
+	Forfeit          bool  // le perdant a déclaré forfait
+}
+
+// Standing est la ligne d'un joueur au classement.
+type Standing struct {
+	PlayerID int64
+	Wins     float64 // une égalité compte pour une demi-victoire
+	Losses   float64
+	Spread   int // points marqués moins points encaissés
+	Played   int // appariements terminés, byes compris
+	Byes     int
+}
+
+// RoundRobinRounds retourne le nombre de rondes d'un tour complet entre n
+// joueurs : chacun est exempt une fois quand n est impair.
+func RoundRobinRounds(n int) int {
+	if n%2 == 1 {
+		return n
+	}
+	return n - 1
+}
+
+// RoundRobinRound retourne les appariements de la ronde round (à partir de
+// 1) d'un toutes-rondes entre players, donnés dans l'ordre des têtes de
+// série. Le calendrier suit la méthode du cercle ; au-delà d'un tour complet
+// il recommence en inversant qui commence chaque partie.
+func RoundRobinRound(players []int64, round int) []Pairing {
+	ids := slices.Clone(players)
+	if len(ids)%2 == 1 {
+		ids = append(ids, Bye)
+	}
+	n := len(ids)
+	if n < 2 || round < 1 {
+		return nil
+	}
+	r := (round - 1) % (n - 1)
+	cycle := (round - 1) / (n - 1)
+
+	// le premier reste fixe, les autres tournent d'un cran par ronde
+	rest := ids[1:]
+	circle := make([]int64, 0, n)
+	circle = append(circle, ids[0])
+	for i := range rest {
+		circle = append(circle, rest[(i+len(rest)-r)%len(rest)])
+	}
+
+	pairings := make([]Pairing, 0, n/2)
+	for i := 0; i < n/2; i++ {
+		p := Pairing{Player1: circle[i], Player2: circle[n-1-i]}
+		// alterne qui commence pour la tête de série fixe, et à chaque tour complet
+		if (i == 0 && r%2 == 1) != (cycle%2 == 1) {
+			p.Player1, p.Player2 = p.Player2, p.Player1
+		}
+		if p.Player1 == Bye {
+			p.Player1, p.Player2 = p.Player2, Bye
+		}
+		pairings = append(pairings, p)
+	}
+	return pairings
+}
+
+// Standings calcule le classement de players (dans l'ordre des têtes de
+// série) à partir des résultats : victoires, puis écart, puis tête de série.
+// Un bye compte comme une victoire de ByeSpread points ; un forfait comme une
+// victoire de ByeSpread points pour le vainqueur et une défaite d'autant pour
+// le perdant, quel que soit le score du plateau.
+func Standings(players []int64, results []Result) []Standing {
+	index := make(map[int64]int, len(players))
+	standings := make([]Standing, len(players))
+	for i, pid := range players {
+		index[pid] = i
+		standings[i].PlayerID = pid
+	}
+	add := func(pid int64, win, loss float64, spread int) {
+		i, ok := index[pid]
+		if !ok {
+			return
+		}
+		standings[i].Wins += win
+		standings[i].Losses += loss
+		standings[i].Spread += spread
+		standings[i].Played++
+	}
+
+	for _, r := range results {
+		switch {
+		case r.Player2 == Bye:
+			add(r.Player1, 1, 0, ByeSpread)
+			if i, ok := index[r.Player1]; ok {
+				standings[i].Byes++
+			}
+		case r.Forfeit:
+			loser := r.Player1
+			if r.Winner == r.Player1 {
+				loser = r.Player2
+			}
+			add(r.Winner, 1, 0, ByeSpread)
+			add(loser, 0, 1, -ByeSpread)
+		case r.Winner == 0:
+			add(r.Player1, 0.5, 0.5, r.Score1-r.Score2)
+			add(r.Player2, 0.5, 0.5, r.Score2-r.Score1)
+		default:
+			w1 := 0.0
+			if r.Winner == r.Player1 {
+				w1 = 1
+			}
+			add(r.Player1, w1, 1-w1, r.Score1-r.Score2)
+			add(r.Player2, 1-w1, w1, r.Score2-r.Score1)
+		}
+	}
+
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		if c := cmp.Compare(b.Wins, a.Wins); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Spread, a.Spread); c != 0 {
+			return c
+		}
+		return cmp.Compare(index[a.PlayerID], index[b.PlayerID])
+	})
+	return standings
+}
+
+// SwissRound apparie une ronde suisse entre les joueurs de standings, dans
+// l'ordre du classement. Avec un nombre impair de joueurs, le bye revient au
+// moins bien classé de ceux qui n'en ont pas encore eu. Chacun affronte
+// ensuite le mieux classé des joueurs restants qu'il n'a pas encore
+// rencontré ; une revanche n'est jouée que s'il n'existe aucun appariement
+// sans revanche.
+func SwissRound(standings []Standing, history []Result) []Pairing {
+	ids := make([]int64, 0, len(standings))
+	for _, s := range standings {
+		ids = append(ids, s.PlayerID)
+	}
+
+	var bye []Pairing
+	if len(ids)%2 == 1 {
+		i := len(ids) - 1
+		for j := len(standings) - 1; j >= 0; j-- {
+			if standings[j].Byes == 0 {
+				i = j
+				break
+			}
+		}
+		bye = append(bye, Pairing{Player1: ids[i], Player2: Bye})
+		ids = slices.Delete(ids, i, i+1)
+	}
+
+	met := map[[2]int64]bool{}
+	for _, r := range history {
+		if r.Player2 != Bye {
+			met[[2]int64{r.Player1, r.Player2}] = true
+			met[[2]int64{r.Player2, r.Player1}] = true
+		}
+	}
+
+	if pairs, ok := pairWithoutRematch(ids, met); ok {
+		return append(pairs, bye...)
+	}
+	pairs := make([]Pairing, 0, len(ids)/2+1)
+	for i := 0; i+1 < len(ids); i += 2 {
+		pairs = append(pairs, Pairing{Player1: ids[i], Player2: ids[i+1]})
+	}
+	return append(pairs, bye...)
+}
+
+// pairWithoutRematch apparie ids (en nombre pair, par ordre de classement)
+// sans qu'aucune paire se soit déjà rencontrée, en privilégiant l'adversaire
+// le mieux classé.
+func pairWithoutRematch(ids []int64, met map[[2]int64]bool) ([]Pairing, bool) {
+	if len(ids) == 0 {
+		return nil, true
+	}
+	first := ids[0]
+	for j := 1; j < len(ids); j++ {
+		if met[[2]int64{first, ids[j]}] {
+			continue
+		}
+		rest := make([]int64, 0, len(ids)-2)
+		rest = append(rest, ids[1:j]...)
+		rest = append(rest, ids[j+1:]...)
+		if pairs, ok := pairWithoutRematch(rest, met); ok {
+			return append([]Pairing{{Player1: first, Player2: ids[j]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
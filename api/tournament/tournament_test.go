@@ -0,0 +1,113 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoundRobin_EveryPairMeetsOnce(t *testing.T) {
+	for _, n := range []int{2, 4, 5, 6} {
+		players := make([]int64, n)
+		for i := range players {
+			players[i] = int64(i + 1)
+		}
+		met := map[[2]int64]int{}
+		byes := map[int64]int{}
+		rounds := RoundRobinRounds(n)
+		for round := 1; round <= rounds; round++ {
+			seen := map[int64]bool{}
+			for _, p := range RoundRobinRound(players, round) {
+				if p.Player1 == Bye {
+					t.Fatalf("n=%d round %d: bye listed as first player", n, round)
+				}
+				if seen[p.Player1] || (p.Player2 != Bye && seen[p.Player2]) {
+					t.Fatalf("n=%d round %d: player paired twice", n, round)
+				}
+				seen[p.Player1], seen[p.Player2] = true, true
+				if p.Player2 == Bye {
+					byes[p.Player1]++
+					continue
+				}
+				a, b := min(p.Player1, p.Player2), max(p.Player1, p.Player2)
+				met[[2]int64{a, b}]++
+			}
+		}
+		if want := n * (n - 1) / 2; len(met) != want {
+			t.Fatalf("n=%d: %d distinct pairs, want %d", n, len(met), want)
+		}
+		for pair, count := range met {
+			if count != 1 {
+				t.Fatalf("n=%d: pair %v met %d times", n, pair, count)
+			}
+		}
+		if n%2 == 1 {
+			for _, pid := range players {
+				if byes[pid] != 1 {
+					t.Fatalf("n=%d: player %d had %d byes", n, pid, byes[pid])
+				}
+			}
+		}
+	}
+}
+
+func TestRoundRobin_SecondCycleSwapsFirstPlayer(t *testing.T) {
+	players := []int64{1, 2, 3, 4}
+	first := RoundRobinRound(players, 1)
+	second := RoundRobinRound(players, 1+RoundRobinRounds(4))
+	for i := range first {
+		if first[i].Player1 != second[i].Player2 || first[i].Player2 != second[i].Player1 {
+			t.Fatalf("pairing %d not swapped: %+v then %+v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestStandings_WinsThenSpread(t *testing.T) {
+	results := []Result{
+		{Player1: 1, Player2: 2, Score1: 400, Score2: 350, Winner: 1},
+		{Player1: 3, Player2: 4, Score1: 300, Score2: 300},
+		{Player1: 2, Player2: 3, Score1: 0, Score2: 0, Winner: 2, Forfeit: true},
+		{Player1: 4, Player2: Bye},
+	}
+	got := Standings([]int64{1, 2, 3, 4}, results)
+	want := []Standing{
+		{PlayerID: 4, Wins: 1.5, Losses: 0.5, Spread: 50, Played: 2, Byes: 1},
+		{PlayerID: 1, Wins: 1, Spread: 50, Played: 1},
+		{PlayerID: 2, Wins: 1, Losses: 1, Spread: 0, Played: 2},
+		{PlayerID: 3, Wins: 0.5, Losses: 1.5, Spread: -50, Played: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("standings:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSwiss_AvoidsRematchesAndGivesByeToLowest(t *testing.T) {
+	standings := []Standing{
+		{PlayerID: 1, Wins: 2},
+		{PlayerID: 2, Wins: 2},
+		{PlayerID: 3, Wins: 1},
+		{PlayerID: 4, Wins: 1},
+		{PlayerID: 5, Wins: 0, Byes: 1},
+	}
+	history := []Result{
+		{Player1: 1, Player2: 2, Winner: 1},
+		{Player1: 3, Player2: 4, Winner: 3},
+	}
+	got := SwissRound(standings, history)
+	want := []Pairing{
+		{Player1: 1, Player2: 3},
+		{Player1: 2, Player2: 5},
+		{Player1: 4, Player2: Bye},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("pairings:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSwiss_AllowsRematchWhenUnavoidable(t *testing.T) {
+	standings := []Standing{{PlayerID: 1}, {PlayerID: 2}}
+	history := []Result{{Player1: 1, Player2: 2, Winner: 1}}
+	got := SwissRound(standings, history)
+	if want := []Pairing{{Player1: 1, Player2: 2}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -1,11 +1,13 @@
 package utils
 
-import "math/rand"
+import "math/rand/v2"
 
+// ShuffleRunes mélange r avec la source globale de math/rand/v2 (ChaCha8
+// initialisé par le système) : mélange de Fisher-Yates, sans biais.
 func ShuffleRunes(r []rune) {
 	n := len(r)
 	for i := n - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := rand.IntN(i + 1)
 		r[i], r[j] = r[j], r[i]
 	}
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"os"
 	"time"
 
@@ -33,3 +34,61 @@ func GenerateToken(user dbModels.User) (string, error) {
 	return tokenString, nil
 
 }
+
+// inviteTokenType distingue les jetons de lien d'invitation des jetons
+// d'authentification, signés avec la même clé.
+const inviteTokenType = "game_invite"
+
+// ErrInvalidInviteToken est renvoyée pour un jeton d'invitation mal formé, mal
+// signé ou qui n'est pas un jeton d'invitation.
+var ErrInvalidInviteToken = errors.New("invalid invite token")
+
+// ErrInviteTokenExpired est renvoyée pour un jeton d'invitation correctement
+// signé mais expiré.
+var ErrInviteTokenExpired = errors.New("invite token expired")
+
+// GenerateInviteToken signe le jeton du lien d'invitation linkID à la partie
+// gameID, valable jusqu'à expiresAt.
+func GenerateInviteToken(linkID, gameID string, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ":     inviteTokenType,
+		"link_id": linkID,
+		"game_id": gameID,
+		"exp":     expiresAt.Unix(),
+	})
+
+	tokenString, err := token.SignedString(getJWTSecret())
+	if err != nil {
+		logger.Error(context.Background(), "failed to sign invite token", "error", err, "game_id", gameID)
+		return "", err
+	}
+	return tokenString, nil
+}
+
+// ParseInviteToken vérifie la signature d'un jeton de lien d'invitation et
+// retourne le lien et la partie qu'il désigne. Un jeton expiré retourne tout
+// de même ces identifiants, avec ErrInviteTokenExpired.
+func ParseInviteToken(tokenString string) (linkID, gameID string, err error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return getJWTSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	expired := errors.Is(err, jwt.ErrTokenExpired)
+	if (err != nil && !expired) || token == nil {
+		return "", "", ErrInvalidInviteToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", ErrInvalidInviteToken
+	}
+	typ, _ := claims["typ"].(string)
+	linkID, _ = claims["link_id"].(string)
+	gameID, _ = claims["game_id"].(string)
+	if typ != inviteTokenType || linkID == "" || gameID == "" {
+		return "", "", ErrInvalidInviteToken
+	}
+	if expired {
+		return linkID, gameID, ErrInviteTokenExpired
+	}
+	return linkID, gameID, nil
+}
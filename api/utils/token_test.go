@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	dbModels "github.com/ZiplEix/scrabble/api/models/database"
 )
@@ -35,3 +37,57 @@ func TestGenerateToken_Success(t *testing.T) {
 		t.Fatalf("expected non-empty token")
 	}
 }
+
+func TestInviteToken_RoundTrip(t *testing.T) {
+	old := os.Getenv("JWT_SECRET")
+	os.Setenv("JWT_SECRET", "testsecret")
+	defer os.Setenv("JWT_SECRET", old)
+
+	tok, err := GenerateInviteToken("link-1", "game-1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	linkID, gameID, err := ParseInviteToken(tok)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if linkID != "link-1" || gameID != "game-1" {
+		t.Fatalf("got link %q game %q", linkID, gameID)
+	}
+
+	if _, _, err := ParseInviteToken(tok + "x"); !errors.Is(err, ErrInvalidInviteToken) {
+		t.Fatalf("expected ErrInvalidInviteToken for a tampered token, got %v", err)
+	}
+}
+
+func TestInviteToken_Expired(t *testing.T) {
+	old := os.Getenv("JWT_SECRET")
+	os.Setenv("JWT_SECRET", "testsecret")
+	defer os.Setenv("JWT_SECRET", old)
+
+	tok, err := GenerateInviteToken("link-2", "game-2", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	linkID, gameID, err := ParseInviteToken(tok)
+	if !errors.Is(err, ErrInviteTokenExpired) {
+		t.Fatalf("expected ErrInviteTokenExpired, got %v", err)
+	}
+	if linkID != "link-2" || gameID != "game-2" {
+		t.Fatalf("expired token should still name its link, got %q %q", linkID, gameID)
+	}
+}
+
+func TestInviteToken_RejectsAuthToken(t *testing.T) {
+	old := os.Getenv("JWT_SECRET")
+	os.Setenv("JWT_SECRET", "testsecret")
+	defer os.Setenv("JWT_SECRET", old)
+
+	tok, err := GenerateToken(dbModels.User{ID: 7, Username: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := ParseInviteToken(tok); !errors.Is(err, ErrInvalidInviteToken) {
+		t.Fatalf("expected ErrInvalidInviteToken for an auth token, got %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package word
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Language est un pack de langue : dictionnaire, distribution des tuiles et
+// valeur des lettres. Le dictionnaire est chargé au premier usage.
+type Language struct {
+	Code         string         // code ISO 639-1 ("fr", "en")
+	Name         string         // nom affiché
+	Bag          Tiles          // sac complet de la langue, BlankTile pour les jokers
+	LetterValues map[string]int // valeur de chaque tuile (jokers exclus)
+	// Digraphs liste les tuiles de plusieurs lettres ("CH", "LL"...). Un mot
+	// du dictionnaire est découpé en tuiles en les préférant aux lettres seules.
+	Digraphs []Tile
+	// Bags contient les distributions officielles d'autres tailles de sac
+	// (200 tuiles pour le Super Scrabble), indexées par nombre de tuiles.
+	Bags map[int]Tiles
+
+	dictFile string
+	once     sync.Once
+	dict     *dictionary
+	err      error
+}
+
+// French est le pack de langue par défaut.
+var French = &Language{
+	Code:         "fr",
+	Name:         "Français",
+	Bag:          ParseTiles("AAAAAAAAAEEEEEEEEEEEEIIIIIIIIONNNNNNRRRRRRTTTTTTLLLLSSSSUDDDGGGMMMBBCCPPFFHHVVJQKWXYZ??"),
+	LetterValues: LetterValues,
+	dictFile:     "fr.txt",
+}
+
+// English utilise la distribution et les valeurs du Scrabble anglophone.
+var English = &Language{
+	Code: "en",
+	Name: "English",
+	Bag:  ParseTiles("AAAAAAAAABBCCDDDDEEEEEEEEEEEEFFGGGHHIIIIIIIIIJKLLLLMMNNNNNNOOOOOOOOPPQRRRRRRSSSSTTTTTTUUUUVVWWXYYZ??"),
+	LetterValues: map[string]int{
+		"A": 1, "B": 3, "C": 3, "D": 2, "E": 1,
+		"F": 4, "G": 2, "H": 4, "I": 1, "J": 8,
+		"K": 5, "L": 1, "M": 3, "N": 1, "O": 1,
+		"P": 3, "Q": 10, "R": 1, "S": 1, "T": 1,
+		"U": 1, "V": 4, "W": 4, "X": 8, "Y": 4, "Z": 10,
+	},
+	Bags: map[int]Tiles{
+		200: countedTiles("A16 B4 C6 D8 E24 F4 G5 H5 I13 J2 K2 L7 M6 N13 O15 P4 Q2 R13 S10 T15 U7 V3 W4 X2 Y4 Z2 ?4"),
+	},
+	dictFile: "en.txt",
+}
+
+var languages = map[string]*Language{
+	French.Code:  French,
+	English.Code: English,
+}
+
+// GetLanguage retourne le pack de langue correspondant au code.
+func GetLanguage(code string) (*Language, bool) {
+	l, ok := languages[strings.ToLower(strings.TrimSpace(code))]
+	return l, ok
+}
+
+// Lang retourne le pack de langue correspondant au code, ou French si le code
+// est vide ou inconnu.
+func Lang(code string) *Language {
+	if l, ok := GetLanguage(code); ok {
+		return l
+	}
+	return French
+}
+
+// Languages retourne les packs de langue connus, triés par code.
+func Languages() []*Language {
+	out := make([]*Language, 0, len(languages))
+	for _, l := range languages {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// BagOfSize retourne un sac complet de n tuiles : le sac standard si n vaut 0
+// ou sa taille, la distribution officielle de Bags si elle existe, sinon le
+// sac standard mis à l'échelle (voir ScaleBag).
+func (l *Language) BagOfSize(n int) Tiles {
+	if n <= 0 || n == len(l.Bag) {
+		return l.Bag
+	}
+	if b, ok := l.Bags[n]; ok {
+		return b
+	}
+	return ScaleBag(l.Bag, n)
+}
+
+// ScaleBag retourne un sac de n tuiles dont la distribution est
+// proportionnelle à celle de bag (méthode du plus fort reste, à égalité la
+// tuile apparaissant la première dans bag l'emporte). Chaque tuile de bag
+// garde au moins un exemplaire.
+func ScaleBag(bag Tiles, n int) Tiles {
+	if len(bag) == 0 {
+		return Tiles{}
+	}
+	var order Tiles
+	counts := bag.Count()
+	for _, t := range bag {
+		if order.Index(t) == -1 {
+			order = append(order, t)
+		}
+	}
+
+	scaled := make(map[Tile]int, len(order))
+	remainders := make(map[Tile]int, len(order))
+	total := 0
+	for _, t := range order {
+		c := counts[t] * n
+		scaled[t] = max(c/len(bag), 1)
+		remainders[t] = c % len(bag)
+		total += scaled[t]
+	}
+	byRemainder := append(Tiles(nil), order...)
+	sort.SliceStable(byRemainder, func(i, j int) bool {
+		return remainders[byRemainder[i]] > remainders[byRemainder[j]]
+	})
+	for i := 0; total < n; i++ {
+		scaled[byRemainder[i%len(byRemainder)]]++
+		total++
+	}
+
+	out := make(Tiles, 0, total)
+	for _, t := range order {
+		for i := 0; i < scaled[t]; i++ {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// countedTiles construit un sac à partir d'une liste "A9 B2 ?2" (face suivie
+// du nombre d'exemplaires).
+func countedTiles(spec string) Tiles {
+	var out Tiles
+	for _, f := range strings.Fields(spec) {
+		i := strings.IndexAny(f, "0123456789")
+		n, _ := strconv.Atoi(f[i:])
+		for j := 0; j < n; j++ {
+			out = append(out, Tile(f[:i]))
+		}
+	}
+	return out
+}
+
+func (l *Language) load() error {
+	l.once.Do(func() {
+		l.dict, l.err = loadDictionary(l.dictFile)
+	})
+	return l.err
+}
+
+// Available indique si le dictionnaire de la langue est présent et non vide.
+func (l *Language) Available() bool {
+	return l.load() == nil && len(l.dict.allWords) > 0
+}
+
+// WordExists indique si le mot existe dans le dictionnaire de la langue.
+func (l *Language) WordExists(word string) bool {
+	if l.load() != nil {
+		return false
+	}
+	return l.dict.exists(word)
+}
+
+// WordsContainingLetter retourne les mots contenant une lettre donnée,
+// filtrés par longueur min/max.
+func (l *Language) WordsContainingLetter(letter rune, minLen, maxLen int) []string {
+	if l.load() != nil {
+		return []string{}
+	}
+	return l.dict.containingLetter(letter, minLen, maxLen)
+}
+
+// RandomWord retourne un mot aléatoire dans une plage de longueur.
+func (l *Language) RandomWord(rng *rand.Rand, minLen, maxLen int) (string, bool) {
+	if l.load() != nil {
+		return "", false
+	}
+	return l.dict.random(rng, minLen, maxLen)
+}
+
+// AllWordsCount retourne la taille du dictionnaire.
+func (l *Language) AllWordsCount() int {
+	if l.load() != nil {
+		return 0
+	}
+	return len(l.dict.allWords)
+}
+
+// AllWords retourne la liste de tous les mots du dictionnaire.
+func (l *Language) AllWords() []string {
+	if l.load() != nil {
+		return nil
+	}
+	return l.dict.allWords
+}
+
+// LetterValue retourne la valeur d'une lettre (0 pour une lettre inconnue).
+func (l *Language) LetterValue(letter string) int {
+	return l.LetterValues[strings.ToUpper(letter)]
+}
+
+// SplitWord découpe un mot en tuiles, digrammes de la langue compris
+// (le plus long digramme possible est retenu à chaque position).
+func (l *Language) SplitWord(w string) Tiles {
+	runes := []rune(strings.ToUpper(w))
+	out := make(Tiles, 0, len(runes))
+	for i := 0; i < len(runes); {
+		best := 1
+		for _, d := range l.Digraphs {
+			n := len([]rune(string(d)))
+			if n > best && i+n <= len(runes) && string(runes[i:i+n]) == string(d) {
+				best = n
+			}
+		}
+		out = append(out, Tile(runes[i:i+best]))
+		i += best
+	}
+	return out
+}
+
+// digraphBase est le premier code de la zone à usage privé Unicode, utilisé
+// pour représenter chaque digramme par une seule rune.
+const digraphBase = 0xE000
+
+// TileRune retourne une rune unique représentant t : la lettre elle-même pour
+// une tuile simple, un code privé pour un digramme de la langue. Les
+// algorithmes qui raisonnent lettre par lettre (bot) s'appuient dessus.
+func (l *Language) TileRune(t Tile) rune {
+	for i, d := range l.Digraphs {
+		if d == t {
+			return rune(digraphBase + i)
+		}
+	}
+	for _, r := range string(t) {
+		return r
+	}
+	return 0
+}
+
+// RuneTile est l'inverse de TileRune.
+func (l *Language) RuneTile(r rune) Tile {
+	if i := int(r - digraphBase); i >= 0 && i < len(l.Digraphs) {
+		return l.Digraphs[i]
+	}
+	return Tile(r)
+}
+
+// WordRunes découpe w en tuiles et retourne la rune de chacune (voir TileRune).
+func (l *Language) WordRunes(w string) []rune {
+	tiles := l.SplitWord(w)
+	out := make([]rune, len(tiles))
+	for i, t := range tiles {
+		out[i] = l.TileRune(t)
+	}
+	return out
+}
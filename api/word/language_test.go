@@ -0,0 +1,110 @@
+package word
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetLanguage(t *testing.T) {
+	if l, ok := GetLanguage(" EN "); !ok || l != English {
+		t.Errorf("GetLanguage(\" EN \") = %v, %v; want English", l, ok)
+	}
+	if _, ok := GetLanguage("de"); ok {
+		t.Errorf("GetLanguage(\"de\") should not exist")
+	}
+	if Lang("") != French || Lang("xx") != French {
+		t.Errorf("Lang should fall back to French")
+	}
+	if got := Languages(); len(got) != 2 || got[0] != English || got[1] != French {
+		t.Errorf("Languages() = %v; want [en fr]", got)
+	}
+}
+
+func TestLanguageBags(t *testing.T) {
+	tests := []struct {
+		lang   *Language
+		size   int
+		blanks int
+	}{
+		{French, 87, 2},
+		{English, 100, 2},
+	}
+	for _, test := range tests {
+		if n := len(test.lang.Bag); n != test.size {
+			t.Errorf("%s bag has %d tiles; want %d", test.lang.Code, n, test.size)
+		}
+		if n := test.lang.Bag.Count()[BlankTile]; n != test.blanks {
+			t.Errorf("%s bag has %d blanks; want %d", test.lang.Code, n, test.blanks)
+		}
+		for _, c := range test.lang.Bag {
+			if c != BlankTile && test.lang.LetterValue(string(c)) == 0 {
+				t.Errorf("%s bag letter %q has no value", test.lang.Code, c)
+			}
+		}
+	}
+}
+
+func TestBagOfSize(t *testing.T) {
+	en := English.BagOfSize(200)
+	if len(en) != 200 {
+		t.Fatalf("English super bag has %d tiles; want 200", len(en))
+	}
+	if c := en.Count(); c[BlankTile] != 4 || c["E"] != 24 || c["Q"] != 2 {
+		t.Errorf("unexpected English super bag distribution: %v", c)
+	}
+
+	fr := French.BagOfSize(200)
+	if len(fr) != 200 {
+		t.Fatalf("French scaled bag has %d tiles; want 200", len(fr))
+	}
+	std, scaled := French.Bag.Count(), fr.Count()
+	for tile, n := range std {
+		if scaled[tile] < 2*n {
+			t.Errorf("scaled French bag has %d %q; want at least %d", scaled[tile], tile, 2*n)
+		}
+	}
+	if !reflect.DeepEqual(fr, ScaleBag(French.Bag, 200)) {
+		t.Errorf("ScaleBag should be deterministic")
+	}
+	if got := French.BagOfSize(0); len(got) != len(French.Bag) {
+		t.Errorf("BagOfSize(0) should return the standard bag")
+	}
+}
+
+func TestLetterValue(t *testing.T) {
+	tests := []struct {
+		lang   *Language
+		letter string
+		want   int
+	}{
+		{French, "k", 10},
+		{English, "K", 5},
+		{English, "q", 10},
+		{English, "?", 0},
+	}
+	for _, test := range tests {
+		if got := test.lang.LetterValue(test.letter); got != test.want {
+			t.Errorf("%s.LetterValue(%q) = %d; want %d", test.lang.Code, test.letter, got, test.want)
+		}
+	}
+}
+
+func TestSplitWordAndTileRunes(t *testing.T) {
+	spanish := &Language{Code: "es", Digraphs: []Tile{"CH", "LL", "RR"}}
+	got := spanish.SplitWord("chorro")
+	want := Tiles{"CH", "O", "RR", "O"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SplitWord(chorro) = %v; want %v", got, want)
+	}
+	if got := French.SplitWord("chat"); len(got) != 4 {
+		t.Fatalf("French has no digraphs, got %v", got)
+	}
+
+	runes := spanish.WordRunes("calle")
+	if len(runes) != 4 {
+		t.Fatalf("expected 4 tiles in calle, got %d", len(runes))
+	}
+	if spanish.RuneTile(runes[2]) != "LL" || spanish.RuneTile(runes[0]) != "C" {
+		t.Fatalf("unexpected round trip: %v", runes)
+	}
+}
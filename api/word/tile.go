@@ -0,0 +1,156 @@
+package word
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Tile est une tuile du jeu : une lettre ("A"), une lettre multiple comme les
+// digrammes espagnols ou gallois ("CH", "LL", "RR") ou le joker (BlankTile).
+type Tile string
+
+// BlankTile est le joker dans les racks et le sac.
+const BlankTile Tile = "?"
+
+// IsVowel indique si la tuile est une voyelle (A, E, I, O, U ou Y). Le joker
+// et les digrammes n'en sont pas.
+func (t Tile) IsVowel() bool {
+	return len(t) == 1 && strings.Contains("AEIOUY", string(t))
+}
+
+// Tiles est une suite de tuiles (rack, sac, tuiles piochées...). Elle est
+// stockée en JSON sous forme de tableau ; l'ancien format chaîne ("ABC?",
+// une tuile par caractère) est encore accepté en lecture.
+type Tiles []Tile
+
+// ParseTiles découpe s en tuiles d'un caractère chacune (ancien format des
+// racks et des sacs). Les lettres sont mises en majuscules.
+func ParseTiles(s string) Tiles {
+	out := make(Tiles, 0, len(s))
+	for _, r := range strings.ToUpper(s) {
+		out = append(out, Tile(r))
+	}
+	return out
+}
+
+// TilesFromStrings convertit des faces de tuiles ("a", "ch", "?") en Tiles.
+func TilesFromStrings(faces []string) Tiles {
+	out := make(Tiles, 0, len(faces))
+	for _, f := range faces {
+		out = append(out, Tile(strings.ToUpper(strings.TrimSpace(f))))
+	}
+	return out
+}
+
+// String concatène les faces des tuiles.
+func (t Tiles) String() string {
+	var b strings.Builder
+	for _, tile := range t {
+		b.WriteString(string(tile))
+	}
+	return b.String()
+}
+
+// Strings retourne la face de chaque tuile.
+func (t Tiles) Strings() []string {
+	out := make([]string, len(t))
+	for i, tile := range t {
+		out[i] = string(tile)
+	}
+	return out
+}
+
+// Index retourne la position de la première tuile égale à tile, ou -1.
+func (t Tiles) Index(tile Tile) int {
+	for i, x := range t {
+		if x == tile {
+			return i
+		}
+	}
+	return -1
+}
+
+// Count retourne le nombre d'exemplaires de chaque tuile.
+func (t Tiles) Count() map[Tile]int {
+	out := make(map[Tile]int, len(t))
+	for _, tile := range t {
+		out[tile]++
+	}
+	return out
+}
+
+// Remove retourne une copie de t sans la première occurrence de tile, et
+// false si tile est absente.
+func (t Tiles) Remove(tile Tile) (Tiles, bool) {
+	i := t.Index(tile)
+	if i == -1 {
+		return t, false
+	}
+	out := make(Tiles, 0, len(t)-1)
+	out = append(out, t[:i]...)
+	return append(out, t[i+1:]...), true
+}
+
+// Sorted retourne une copie de t triée par face : l'ordre canonique d'un sac.
+func (t Tiles) Sorted() Tiles {
+	out := append(Tiles{}, t...)
+	slices.Sort(out)
+	return out
+}
+
+// Concat retourne une nouvelle suite contenant t puis other.
+func (t Tiles) Concat(other Tiles) Tiles {
+	out := make(Tiles, 0, len(t)+len(other))
+	out = append(out, t...)
+	return append(out, other...)
+}
+
+// MarshalJSON encode les tuiles en tableau JSON (jamais null).
+func (t Tiles) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Strings())
+}
+
+// UnmarshalJSON accepte un tableau de faces ou l'ancien format chaîne.
+func (t *Tiles) UnmarshalJSON(data []byte) error {
+	var legacy string
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*t = ParseTiles(legacy)
+		return nil
+	}
+	var faces []string
+	if err := json.Unmarshal(data, &faces); err != nil {
+		return fmt.Errorf("invalid tiles: %w", err)
+	}
+	*t = TilesFromStrings(faces)
+	return nil
+}
+
+// Value stocke les tuiles en JSON pour les colonnes JSONB.
+func (t Tiles) Value() (driver.Value, error) {
+	return t.MarshalJSON()
+}
+
+// Scan lit une colonne JSONB de tuiles (ou un ancien rack texte).
+func (t *Tiles) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*t = Tiles{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Tiles", src)
+	}
+	trimmed := strings.TrimSpace(string(raw))
+	if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, `"`) {
+		*t = ParseTiles(trimmed)
+		return nil
+	}
+	return t.UnmarshalJSON(raw)
+}
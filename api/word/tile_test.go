@@ -0,0 +1,82 @@
+package word
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTilesJSON(t *testing.T) {
+	tiles := Tiles{"CH", "A", BlankTile}
+	data, err := json.Marshal(tiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `["CH","A","?"]` {
+		t.Fatalf("unexpected JSON %s", data)
+	}
+
+	tests := []struct {
+		in   string
+		want Tiles
+	}{
+		{`["CH","a","?"]`, Tiles{"CH", "A", "?"}},
+		{`"ab?"`, Tiles{"A", "B", "?"}}, // ancien format chaîne
+		{`[]`, Tiles{}},
+	}
+	for _, test := range tests {
+		var got Tiles
+		if err := json.Unmarshal([]byte(test.in), &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", test.in, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Unmarshal(%s) = %v; want %v", test.in, got, test.want)
+		}
+	}
+	if data, _ := json.Marshal(Tiles(nil)); string(data) != "[]" {
+		t.Errorf("nil tiles should encode as [], got %s", data)
+	}
+}
+
+func TestTilesScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want Tiles
+	}{
+		{[]byte(`["LL","E"]`), Tiles{"LL", "E"}},
+		{"ABC", Tiles{"A", "B", "C"}},
+		{"", Tiles{}},
+		{nil, Tiles{}},
+	}
+	for _, test := range tests {
+		var got Tiles
+		if err := got.Scan(test.src); err != nil {
+			t.Fatalf("Scan(%v): %v", test.src, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Scan(%v) = %v; want %v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestTilesRemove(t *testing.T) {
+	rack := Tiles{"A", "CH", "A"}
+	out, ok := rack.Remove("A")
+	if !ok || !reflect.DeepEqual(out, Tiles{"CH", "A"}) {
+		t.Fatalf("Remove(A) = %v, %v", out, ok)
+	}
+	if !reflect.DeepEqual(rack, Tiles{"A", "CH", "A"}) {
+		t.Fatalf("Remove must not modify the receiver, got %v", rack)
+	}
+	if _, ok := rack.Remove("Z"); ok {
+		t.Fatalf("expected Remove(Z) to fail")
+	}
+}
+
+func TestTileIsVowel(t *testing.T) {
+	for tile, want := range map[Tile]bool{"A": true, "Y": true, "B": false, "CH": false, BlankTile: false} {
+		if got := tile.IsVowel(); got != want {
+			t.Errorf("%s.IsVowel() = %v; want %v", tile, got, want)
+		}
+	}
+}
@@ -6,39 +6,68 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
-	"sync"
 	"time"
 	"unicode"
 
 	"golang.org/x/text/unicode/norm"
 )
 
-//go:embed "fr.txt"
-var dictFile embed.FS
+// Les dictionnaires des packs de langue (fr.txt, en.txt, ...) sont embarqués
+// dans le binaire. Seul fr.txt est obligatoire.
+//
+//go:embed *.txt
+var dictFiles embed.FS
 
-var (
-	dictionary          = make(map[string]struct{})
+// dictionary est un dictionnaire chargé en mémoire et indexé pour le bot et
+// le générateur de parties.
+type dictionary struct {
+	words               map[string]struct{}
 	allWords            []string
-	wordsByLength       = make(map[int][]string)
-	wordsByLetter       = make(map[rune][]string)
-	wordsByLetterLength = make(map[rune]map[int][]string)
-	once                sync.Once
-)
+	wordsByLength       map[int][]string
+	wordsByLetter       map[rune][]string
+	wordsByLetterLength map[rune]map[int][]string
+}
 
 func init() {
-	initDict()
+	if err := French.load(); err != nil {
+		panic(err)
+	}
 }
 
+// WordExists indique si le mot existe dans le dictionnaire français.
 func WordExists(word string) bool {
-	cleanedWord := removeAccents(strings.ToUpper(strings.TrimSpace(word)))
-	_, found := dictionary[cleanedWord]
-	return found
+	return French.WordExists(word)
 }
 
-// WordsContainingLetter retourne les mots contenant une lettre donnée,
+// WordsContainingLetter retourne les mots français contenant une lettre donnée,
 // filtrés par longueur min/max. Le résultat est indexé, pas une recherche
 // complète dans tout le dictionnaire.
 func WordsContainingLetter(letter rune, minLen, maxLen int) []string {
+	return French.WordsContainingLetter(letter, minLen, maxLen)
+}
+
+// RandomWord retourne un mot français aléatoire dans une plage de longueur.
+func RandomWord(rng *rand.Rand, minLen, maxLen int) (string, bool) {
+	return French.RandomWord(rng, minLen, maxLen)
+}
+
+// AllWordsCount retourne la taille du dictionnaire français.
+func AllWordsCount() int {
+	return French.AllWordsCount()
+}
+
+// AllWords retourne la liste de tous les mots du dictionnaire français.
+func AllWords() []string {
+	return French.AllWords()
+}
+
+func (d *dictionary) exists(word string) bool {
+	cleanedWord := removeAccents(strings.ToUpper(strings.TrimSpace(word)))
+	_, found := d.words[cleanedWord]
+	return found
+}
+
+func (d *dictionary) containingLetter(letter rune, minLen, maxLen int) []string {
 	if minLen <= 0 {
 		minLen = 1
 	}
@@ -51,14 +80,14 @@ func WordsContainingLetter(letter rune, minLen, maxLen int) []string {
 		return []string{}
 	}
 
-	byLen, ok := wordsByLetterLength[key]
+	byLen, ok := d.wordsByLetterLength[key]
 	if !ok {
 		return []string{}
 	}
 
 	if maxLen <= 0 {
-		out := make([]string, 0, len(wordsByLetter[key]))
-		for _, w := range wordsByLetter[key] {
+		out := make([]string, 0, len(d.wordsByLetter[key]))
+		for _, w := range d.wordsByLetter[key] {
 			if len(w) >= minLen {
 				out = append(out, w)
 			}
@@ -75,8 +104,7 @@ func WordsContainingLetter(letter rune, minLen, maxLen int) []string {
 	return out
 }
 
-// RandomWord retourne un mot aléatoire dans une plage de longueur.
-func RandomWord(rng *rand.Rand, minLen, maxLen int) (string, bool) {
+func (d *dictionary) random(rng *rand.Rand, minLen, maxLen int) (string, bool) {
 	if rng == nil {
 		return "", false
 	}
@@ -89,14 +117,14 @@ func RandomWord(rng *rand.Rand, minLen, maxLen int) (string, bool) {
 
 	candidates := make([]string, 0)
 	if maxLen <= 0 {
-		for l, words := range wordsByLength {
+		for l, words := range d.wordsByLength {
 			if l >= minLen {
 				candidates = append(candidates, words...)
 			}
 		}
 	} else {
 		for l := minLen; l <= maxLen; l++ {
-			if words, exists := wordsByLength[l]; exists {
+			if words, exists := d.wordsByLength[l]; exists {
 				candidates = append(candidates, words...)
 			}
 		}
@@ -109,64 +137,61 @@ func RandomWord(rng *rand.Rand, minLen, maxLen int) (string, bool) {
 	return candidates[rng.Intn(len(candidates))], true
 }
 
-// AllWordsCount retourne la taille du dictionnaire.
-func AllWordsCount() int {
-	return len(allWords)
-}
+// loadDictionary lit et indexe un fichier de dictionnaire embarqué.
+func loadDictionary(name string) (*dictionary, error) {
+	start := time.Now()
+	f, err := dictFiles.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded dictionary %s: %w", name, err)
+	}
+	defer f.Close()
 
-// AllWords retourne la liste de tous les mots du dictionnaire.
-func AllWords() []string {
-	return allWords
-}
+	d := &dictionary{
+		words:               make(map[string]struct{}),
+		wordsByLength:       make(map[int][]string),
+		wordsByLetter:       make(map[rune][]string),
+		wordsByLetterLength: make(map[rune]map[int][]string),
+	}
 
-func initDict() {
-	once.Do(func() {
-		start := time.Now()
-		f, err := dictFile.Open("fr.txt")
-		if err != nil {
-			panic(fmt.Errorf("failed to load embedded dictionary: %w", err))
-		}
-		defer f.Close()
-
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			word := strings.ToUpper(scanner.Text())
-			word = removeAccents(strings.TrimSpace(word))
-			if word != "" {
-				if _, exists := dictionary[word]; exists {
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.ToUpper(scanner.Text())
+		word = removeAccents(strings.TrimSpace(word))
+		if word != "" {
+			if _, exists := d.words[word]; exists {
+				continue
+			}
+			d.words[word] = struct{}{}
+			d.allWords = append(d.allWords, word)
+
+			wlen := len(word)
+			d.wordsByLength[wlen] = append(d.wordsByLength[wlen], word)
+
+			seenLetters := make(map[rune]struct{})
+			for _, r := range word {
+				r = normalizeRune(r)
+				if r == 0 {
 					continue
 				}
-				dictionary[word] = struct{}{}
-				allWords = append(allWords, word)
-
-				wlen := len(word)
-				wordsByLength[wlen] = append(wordsByLength[wlen], word)
-
-				seenLetters := make(map[rune]struct{})
-				for _, r := range word {
-					r = normalizeRune(r)
-					if r == 0 {
-						continue
-					}
-					if _, seen := seenLetters[r]; seen {
-						continue
-					}
-					seenLetters[r] = struct{}{}
-
-					wordsByLetter[r] = append(wordsByLetter[r], word)
-					if wordsByLetterLength[r] == nil {
-						wordsByLetterLength[r] = make(map[int][]string)
-					}
-					wordsByLetterLength[r][wlen] = append(wordsByLetterLength[r][wlen], word)
+				if _, seen := seenLetters[r]; seen {
+					continue
 				}
+				seenLetters[r] = struct{}{}
+
+				d.wordsByLetter[r] = append(d.wordsByLetter[r], word)
+				if d.wordsByLetterLength[r] == nil {
+					d.wordsByLetterLength[r] = make(map[int][]string)
+				}
+				d.wordsByLetterLength[r][wlen] = append(d.wordsByLetterLength[r][wlen], word)
 			}
 		}
-		if err := scanner.Err(); err != nil {
-			panic(fmt.Errorf("error reading dictionary file: %w", err))
-		}
-		end := time.Now()
-		fmt.Printf("Dictionary loaded with %d words in %s\n", len(dictionary), end.Sub(start))
-	})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading dictionary file %s: %w", name, err)
+	}
+	end := time.Now()
+	fmt.Printf("Dictionary %s loaded with %d words in %s\n", name, len(d.words), end.Sub(start))
+	return d, nil
 }
 
 func normalizeRune(r rune) rune {
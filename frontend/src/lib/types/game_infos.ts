@@ -4,6 +4,7 @@ export type PlayerInfo = {
     score: number;
     position: number;
     is_bot?: boolean;
+    resigned?: boolean;
 };
 
 export type MoveData = {
@@ -32,6 +33,7 @@ export type GameInfo = {
     name: string;
     board: string[][];
     your_rack: string;
+    your_tiles?: string[];
     players: PlayerInfo[];
     moves: MoveInfo[];
     current_turn: number;
@@ -43,4 +45,12 @@ export type GameInfo = {
     is_your_game: boolean;
     blank_tiles?: { x: number; y: number }[];
     pass_count: number;
+    variant?: 'standard' | 'super';
+    board_size?: number;
+    premiums?: { x: number; y: number; type: string }[];
+    turn_time_limit_hours?: number;
+    timeout_action?: 'pass' | 'forfeit';
+    turn_deadline?: string;
+    forfeited_by?: number;
+    training?: boolean;
 };
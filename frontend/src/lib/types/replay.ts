@@ -0,0 +1,41 @@
+import type { PlayerInfo } from './game_infos';
+
+export type ReplayTile = {
+    x: number;
+    y: number;
+    char: string;
+    blank?: boolean;
+};
+
+export type ReplayPly = {
+    ply: number;
+    type: 'start' | 'play' | 'pass' | 'exchange' | 'challenge' | 'forfeit' | 'resign' | 'end';
+    player_id?: number;
+    word?: string;
+    letters?: ReplayTile[];
+    score?: number;
+    words?: { word: string; score: number }[];
+    bonus?: number;
+    withdrawn?: boolean;
+    count?: number;
+    success?: boolean;
+    penalty?: number;
+    lost_turn?: boolean;
+    board: string[][];
+    blank_tiles?: { x: number; y: number }[];
+    scores: Record<number, number>;
+    racks?: Record<number, string[]>;
+};
+
+export type GameReplay = {
+    game_id: string;
+    name: string;
+    status: string;
+    language: string;
+    variant: string;
+    board_size: number;
+    premiums?: { x: number; y: number; type: string }[];
+    players: PlayerInfo[];
+    total_plies: number;
+    plies: ReplayPly[];
+};